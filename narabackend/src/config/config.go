@@ -0,0 +1,641 @@
+// config.go
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/joho/godotenv"
+
+	"narabackend/src/consts"
+	"narabackend/src/notifications"
+	"narabackend/src/storage"
+	"narabackend/src/utils"
+)
+
+// 설정 값의 출처
+const (
+	SOURCE_DEFAULT = "default"
+	SOURCE_YAML    = "yaml"
+	SOURCE_ENV     = "env"
+)
+
+// ENV_SECTION은 YAML 파일에서 환경 변수로 내보낼 값을 적는 섹션 이름입니다.
+// 설정 구조체에 없는 환경 변수(외부 라이브러리가 읽는 값 등)를 YAML 파일에 함께 적을 때 사용합니다.
+const ENV_SECTION = "env"
+
+var (
+	ErrInvalidConfig = errors.New("설정 값이 올바르지 않습니다")
+	ErrUnknownKey    = errors.New("알 수 없는 설정 키입니다")
+)
+
+// Config는 narabackend 실행 설정입니다.
+// 값은 기본값 < YAML 설정 파일 < 환경 변수(.env 포함) 순서로 덮어씁니다.
+// 각 필드의 config 태그는 YAML 경로, env 태그는 환경 변수 이름, secret 태그는 --print-config 출력 시 가릴 값입니다.
+type Config struct {
+	Server        ServerConfig
+	TLS           TLSConfig
+	Database      DatabaseConfig
+	Timeouts      TimeoutConfig
+	CORS          CORSConfig
+	Security      SecurityConfig
+	Jobs          JobConfig
+	Schedulers    SchedulerConfig
+	Storage       StorageConfig
+	Notifications NotificationConfig
+	Naracontrol   NaracontrolConfig
+	Features      FeatureConfig
+	Secrets       SecretConfig
+
+	File    string            // 읽은 YAML 설정 파일 경로 (없으면 "")
+	EnvFile string            // 읽은 .env 파일 경로 (없으면 "")
+	Env     map[string]string // YAML env 섹션 값
+
+	sources map[string]string // YAML 경로별 값의 출처
+}
+
+// ServerConfig는 HTTP 서버 설정입니다. 시간은 초 단위입니다.
+type ServerConfig struct {
+	ListenAddr        string `config:"server.listen_addr" env:"LISTEN_ADDR"`
+	ReadHeaderTimeout int    `config:"server.read_header_timeout_seconds" env:"SERVER_READ_HEADER_TIMEOUT_SECONDS"`
+	ReadTimeout       int    `config:"server.read_timeout_seconds" env:"SERVER_READ_TIMEOUT_SECONDS"`
+	// WriteTimeout이 0이면 제한하지 않습니다. 변경 피드 SSE 스트림처럼 오래 열려 있는 응답이 있어 기본값은 0입니다.
+	WriteTimeout int `config:"server.write_timeout_seconds" env:"SERVER_WRITE_TIMEOUT_SECONDS"`
+	IdleTimeout  int `config:"server.idle_timeout_seconds" env:"SERVER_IDLE_TIMEOUT_SECONDS"`
+}
+
+// TLSConfig는 HTTPS 인증서 설정입니다. 인증서와 키 파일을 모두 지정하면 HTTPS로 실행합니다.
+type TLSConfig struct {
+	CertFile string `config:"tls.cert_file" env:"TLS_CERT_FILE"`
+	KeyFile  string `config:"tls.key_file" env:"TLS_KEY_FILE"`
+}
+
+// Enabled는 HTTPS로 실행해야 하는지 반환합니다.
+func (t TLSConfig) Enabled() bool {
+	return t.CertFile != "" && t.KeyFile != ""
+}
+
+// DatabaseConfig는 PostgreSQL 연결 및 연결 풀 설정입니다.
+type DatabaseConfig struct {
+	URL             string `config:"database.url" env:"DATABASE_URL" secret:"url"`
+	MaxOpenConns    int    `config:"database.max_open_conns" env:"DB_MAX_OPEN_CONNS"`
+	MaxIdleConns    int    `config:"database.max_idle_conns" env:"DB_MAX_IDLE_CONNS"`
+	ConnMaxLifetime int    `config:"database.conn_max_lifetime_seconds" env:"DB_CONN_MAX_LIFETIME_SECONDS"`
+	PingTimeout     int    `config:"database.ping_timeout_seconds" env:"DB_PING_TIMEOUT_SECONDS"`
+}
+
+// TimeoutConfig는 consts의 쿼리/작업 타임아웃(초)을 덮어씁니다.
+type TimeoutConfig struct {
+	ShortQuery   int `config:"timeouts.short_query_seconds" env:"SHORT_QUERY_TIMEOUT_SECONDS"`
+	DefaultQuery int `config:"timeouts.default_query_seconds" env:"DEFAULT_QUERY_TIMEOUT_SECONDS"`
+	LongQuery    int `config:"timeouts.long_query_seconds" env:"LONG_QUERY_TIMEOUT_SECONDS"`
+	ShortWork    int `config:"timeouts.short_work_seconds" env:"SHORT_WORK_TIMEOUT_SECONDS"`
+	DefaultWork  int `config:"timeouts.default_work_seconds" env:"DEFAULT_WORK_TIMEOUT_SECONDS"`
+	LongWork     int `config:"timeouts.long_work_seconds" env:"LONG_WORK_TIMEOUT_SECONDS"`
+	ReportWork   int `config:"timeouts.report_work_seconds" env:"REPORT_WORK_TIMEOUT_SECONDS"`
+}
+
+// CORSConfig는 브라우저 교차 출처 요청 정책입니다. 목록 환경 변수는 쉼표로 구분합니다.
+// 인증 정보(쿠키, Authorization)를 함께 보내려면 allow_credentials=true와 명시적인 출처 목록이 필요합니다.
+type CORSConfig struct {
+	AllowedOrigins   []string `config:"cors.allowed_origins" env:"CORS_ALLOWED_ORIGINS"`
+	AllowedMethods   []string `config:"cors.allowed_methods" env:"CORS_ALLOWED_METHODS"`
+	AllowedHeaders   []string `config:"cors.allowed_headers" env:"CORS_ALLOWED_HEADERS"`
+	ExposedHeaders   []string `config:"cors.exposed_headers" env:"CORS_EXPOSED_HEADERS"`
+	AllowCredentials bool     `config:"cors.allow_credentials" env:"CORS_ALLOW_CREDENTIALS"`
+	MaxAge           int      `config:"cors.max_age_seconds" env:"CORS_MAX_AGE_SECONDS"`
+}
+
+// SecurityConfig는 보안 응답 헤더와 요청 본문 크기 제한입니다.
+// HSTS는 HTTPS 요청(직접 TLS 또는 X-Forwarded-Proto: https)에만 보내며, hsts_max_age_seconds=0이면 보내지 않습니다.
+type SecurityConfig struct {
+	HSTSMaxAge            int    `config:"security.hsts_max_age_seconds" env:"HSTS_MAX_AGE_SECONDS"`
+	HSTSIncludeSubdomains bool   `config:"security.hsts_include_subdomains" env:"HSTS_INCLUDE_SUBDOMAINS"`
+	ContentTypeNosniff    bool   `config:"security.content_type_nosniff" env:"CONTENT_TYPE_NOSNIFF"`
+	FrameOptions          string `config:"security.frame_options" env:"FRAME_OPTIONS"` // DENY, SAMEORIGIN 또는 ""(보내지 않음)
+	ReferrerPolicy        string `config:"security.referrer_policy" env:"REFERRER_POLICY"`
+	// 요청 본문 최대 크기(바이트). multipart/form-data 업로드는 max_upload_bytes를 사용합니다. 0이면 제한하지 않습니다.
+	MaxBodyBytes   int `config:"security.max_body_bytes" env:"MAX_BODY_BYTES"`
+	MaxUploadBytes int `config:"security.max_upload_bytes" env:"MAX_UPLOAD_BYTES"`
+}
+
+// JobConfig는 비동기 작업 큐 설정입니다.
+type JobConfig struct {
+	Workers int `config:"jobs.workers" env:"JOB_WORKERS"`
+}
+
+// SchedulerConfig는 주기 작업의 실행 주기(초)입니다. 0이면 해당 스케줄러를 이 서버에서 실행하지 않습니다.
+type SchedulerConfig struct {
+	SeatExpirationInterval  int  `config:"schedulers.seat_expiration_interval_seconds" env:"SEAT_EXPIRATION_INTERVAL_SECONDS"`
+	SeatExpirationPowerOff  bool `config:"schedulers.seat_expiration_power_off" env:"SEAT_EXPIRATION_POWER_OFF"`
+	DashboardRollupInterval int  `config:"schedulers.dashboard_rollup_interval_seconds" env:"DASHBOARD_ROLLUP_INTERVAL_SECONDS"`
+	PowerScheduleInterval   int  `config:"schedulers.power_schedule_interval_seconds" env:"POWER_SCHEDULE_INTERVAL_SECONDS"`
+	JobSchedulerInterval    int  `config:"schedulers.job_scheduler_interval_seconds" env:"JOB_SCHEDULER_INTERVAL_SECONDS"`
+	OutboxRelayInterval     int  `config:"schedulers.outbox_relay_interval_seconds" env:"OUTBOX_RELAY_INTERVAL_SECONDS"`
+}
+
+// StorageConfig는 업로드 파일 저장소 설정입니다. backend가 s3이면 s3_* 값을, 그 외에는 local_dir을 사용합니다.
+// public_base_url은 저장된 파일을 직접 제공하는 공개 주소입니다(선택).
+type StorageConfig struct {
+	Backend       string `config:"storage.backend" env:"IMAGE_STORAGE"` // local 또는 s3
+	LocalDir      string `config:"storage.local_dir" env:"IMAGE_STORAGE_DIR"`
+	PublicBaseURL string `config:"storage.public_base_url" env:"IMAGE_PUBLIC_BASE_URL"`
+	S3Endpoint    string `config:"storage.s3_endpoint" env:"S3_ENDPOINT"`
+	S3Region      string `config:"storage.s3_region" env:"S3_REGION"`
+	S3Bucket      string `config:"storage.s3_bucket" env:"S3_BUCKET"`
+	S3AccessKey   string `config:"storage.s3_access_key" env:"S3_ACCESS_KEY"`
+	S3SecretKey   string `config:"storage.s3_secret_key" env:"S3_SECRET_KEY" secret:"true"`
+}
+
+// NotificationConfig는 알림 채널별 발송 대행사 설정입니다.
+// smtp_host, sms_api_url, kakao_api_url이 없는 채널은 local_path 파일(비어 있거나 "-"이면 표준 출력)에 기록만 합니다.
+type NotificationConfig struct {
+	LocalPath      string `config:"notifications.local_path" env:"NOTIFY_LOCAL_PATH"`
+	SMTPHost       string `config:"notifications.smtp_host" env:"SMTP_HOST"`
+	SMTPPort       int    `config:"notifications.smtp_port" env:"SMTP_PORT"`
+	SMTPUsername   string `config:"notifications.smtp_username" env:"SMTP_USERNAME"`
+	SMTPPassword   string `config:"notifications.smtp_password" env:"SMTP_PASSWORD" secret:"true"`
+	SMTPFrom       string `config:"notifications.smtp_from" env:"SMTP_FROM"`
+	SMSAPIURL      string `config:"notifications.sms_api_url" env:"SMS_API_URL"`
+	SMSAPIKey      string `config:"notifications.sms_api_key" env:"SMS_API_KEY" secret:"true"`
+	SMSSender      string `config:"notifications.sms_sender" env:"SMS_SENDER"` // 발신번호
+	KakaoAPIURL    string `config:"notifications.kakao_api_url" env:"KAKAO_API_URL"`
+	KakaoAPIKey    string `config:"notifications.kakao_api_key" env:"KAKAO_API_KEY" secret:"true"`
+	KakaoSenderKey string `config:"notifications.kakao_sender_key" env:"KAKAO_SENDER_KEY" secret:"true"` // 발신 프로필 키
+	// 비밀번호 재설정 메일의 링크 주소. 코드가 뒤에 붙습니다 (예: https://admin.example.com/reset?code=). 없으면 코드만 보냅니다.
+	PasswordResetURL string `config:"notifications.password_reset_url" env:"PASSWORD_RESET_URL"`
+}
+
+// NaracontrolConfig는 naracontrol(장치 제어 서버) 연동 설정입니다.
+// url이 없으면 장치 명령과 변경 이벤트를 보내지 않고, api_key가 없으면 naracontrol의 명령 결과 보고를 모두 거부합니다.
+type NaracontrolConfig struct {
+	URL    string `config:"naracontrol.url" env:"NARACONTROL_URL"`
+	APIKey string `config:"naracontrol.api_key" env:"NARACONTROL_API_KEY" secret:"true"`
+}
+
+// FeatureConfig는 서버별로 켜고 끌 수 있는 기능입니다.
+// API 전용 서버와 백그라운드 작업 서버를 나누어 실행할 때 사용합니다.
+type FeatureConfig struct {
+	JobWorkers  bool `config:"features.job_workers" env:"FEATURE_JOB_WORKERS"`
+	Schedulers  bool `config:"features.schedulers" env:"FEATURE_SCHEDULERS"`
+	OutboxRelay bool `config:"features.outbox_relay" env:"FEATURE_OUTBOX_RELAY"`
+	ChangeFeed  bool `config:"features.change_feed" env:"FEATURE_CHANGE_FEED"`
+	// 테스트용 가짜 PG. 기본은 꺼져 있으며, 켜면 secrets.fake_pg_webhook_secret이 필요합니다.
+	FakePaymentGateway bool `config:"features.fake_payment_gateway" env:"FEATURE_FAKE_PAYMENT_GATEWAY"`
+	Debug              bool `config:"features.debug" env:"DEBUG"`
+	// 웹훅을 루프백/사설망 등 내부 주소로도 보낼지 여부. 업체가 URL을 등록하므로 개발 환경에서만 켭니다.
+	WebhookPrivateTargets bool `config:"features.webhook_private_targets" env:"WEBHOOK_ALLOW_PRIVATE_TARGETS"`
+	// 키오스크에서 credential_table에 없는 카드를 seat_table.card_number로 찾을지 여부. 기존 데스크 카드 이전 기간에만 켭니다.
+	LegacySeatCards bool `config:"features.legacy_seat_cards" env:"FEATURE_LEGACY_SEAT_CARDS"`
+}
+
+// SecretConfig는 서명/해시용 비밀 값입니다.
+type SecretConfig struct {
+	FakePGWebhookSecret string `config:"secrets.fake_pg_webhook_secret" env:"FAKE_PG_WEBHOOK_SECRET" secret:"true"`
+	// 비어 있으면 PIN 발급과 PIN 인증만 503으로 거부하고 서버는 그대로 실행합니다.
+	CredentialPinPepper string `config:"secrets.credential_pin_pepper" env:"CREDENTIAL_PIN_PEPPER" secret:"true"`
+}
+
+// LoadOptions는 설정 파일 위치입니다. 비어 있으면 CONFIG_FILE, ENV_FILE 환경 변수를 사용합니다.
+type LoadOptions struct {
+	File    string // YAML 설정 파일 경로 (선택)
+	EnvFile string // .env 파일 경로. 없으면 현재 디렉터리부터 상위로 .env를 찾습니다.
+}
+
+// Default는 기존 하드코딩 값과 같은 기본 설정을 반환합니다.
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			ListenAddr:        ":8080",
+			ReadHeaderTimeout: 10,
+			ReadTimeout:       60,
+			WriteTimeout:      0,
+			IdleTimeout:       120,
+		},
+		Database: DatabaseConfig{
+			MaxOpenConns:    50,
+			MaxIdleConns:    10,
+			ConnMaxLifetime: 300,
+			PingTimeout:     5,
+		},
+		Timeouts: TimeoutConfig{
+			ShortQuery:   consts.SHORT_QUERY_TIMEOUT,
+			DefaultQuery: consts.DEFAULT_QUERY_TIMEOUT,
+			LongQuery:    consts.LONG_QUERY_TIMEOUT,
+			ShortWork:    consts.SHORT_WORK_TIMEOUT,
+			DefaultWork:  consts.DEFAULT_WORK_TIMEOUT,
+			LongWork:     consts.LONG_WORK_TIMEOUT,
+			ReportWork:   consts.REPORT_WORK_TIMEOUT,
+		},
+		CORS: CORSConfig{
+			AllowedOrigins: []string{"*"},
+			AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+			AllowedHeaders: []string{"Content-Type", "Authorization", "X-Fields", "Last-Event-ID", "Idempotency-Key", "If-None-Match"},
+			ExposedHeaders: []string{"Content-Disposition", "ETag", "Location"},
+			MaxAge:         600,
+		},
+		Security: SecurityConfig{
+			HSTSMaxAge:         31536000,
+			ContentTypeNosniff: true,
+			FrameOptions:       "DENY",
+			ReferrerPolicy:     "strict-origin-when-cross-origin",
+			MaxBodyBytes:       1 << 20,
+			MaxUploadBytes:     16 << 20,
+		},
+		Jobs: JobConfig{
+			Workers: consts.JOB_WORKER_COUNT,
+		},
+		Schedulers: SchedulerConfig{
+			SeatExpirationInterval:  consts.SEAT_EXPIRATION_INTERVAL,
+			DashboardRollupInterval: consts.DASHBOARD_ROLLUP_INTERVAL,
+			PowerScheduleInterval:   consts.POWER_SCHEDULE_INTERVAL,
+			JobSchedulerInterval:    consts.JOB_SCHEDULER_INTERVAL,
+			OutboxRelayInterval:     consts.OUTBOX_RELAY_INTERVAL,
+		},
+		Storage: StorageConfig{
+			Backend:  storage.LOCAL_STORAGE_NAME,
+			LocalDir: storage.DEFAULT_LOCAL_DIR,
+		},
+		Notifications: NotificationConfig{
+			SMTPPort: notifications.DEFAULT_SMTP_PORT,
+		},
+		Features: FeatureConfig{
+			JobWorkers:  true,
+			Schedulers:  true,
+			OutboxRelay: true,
+			ChangeFeed:  true,
+		},
+		Env:     map[string]string{},
+		sources: map[string]string{},
+	}
+}
+
+// Load는 .env, YAML 설정 파일, 환경 변수를 읽어 설정을 만듭니다. 검증은 호출자가 Validate로 합니다.
+//  1. .env 파일을 읽어 환경 변수에 반영합니다 (기존과 같이 .env 값이 시스템 환경 변수보다 우선).
+//  2. YAML 파일이 있으면 기본값을 덮어쓰고, env 섹션 중 아직 없는 환경 변수를 설정합니다.
+//  3. 환경 변수가 있는 항목은 환경 변수 값을 사용합니다.
+func Load(opts LoadOptions) (*Config, error) {
+	cfg := Default()
+
+	envFile := opts.EnvFile
+	if envFile == "" {
+		envFile = os.Getenv("ENV_FILE")
+	}
+	if envFile == "" {
+		if rootDir, err := utils.FindProjectRoot(); err == nil {
+			envFile = filepath.Join(rootDir, ".env")
+		}
+	}
+	if envFile != "" {
+		if err := godotenv.Overload(envFile); err != nil {
+			return nil, fmt.Errorf(".env 파일 로드 실패 (%s): %w", envFile, err)
+		}
+		cfg.EnvFile = envFile
+	}
+
+	file := opts.File
+	if file == "" {
+		file = os.Getenv("CONFIG_FILE")
+	}
+	if file != "" {
+		if err := cfg.loadYAML(file); err != nil {
+			return nil, fmt.Errorf("설정 파일 로드 실패 (%s): %w", file, err)
+		}
+		cfg.File = file
+	}
+
+	for _, f := range cfg.fields() {
+		// 빈 값은 설정하지 않은 것으로 봅니다 (.env의 "DEBUG=" 등).
+		raw := os.Getenv(f.env)
+		if raw == "" {
+			continue
+		}
+		if err := setField(f.value, raw); err != nil {
+			return nil, fmt.Errorf("%w: %s=%q: %v", ErrInvalidConfig, f.env, raw, err)
+		}
+		cfg.sources[f.path] = SOURCE_ENV
+	}
+	return cfg, nil
+}
+
+// loadYAML은 YAML 파일 값을 설정에 반영합니다. 모르는 키는 오타일 수 있으므로 오류로 처리합니다.
+func (c *Config) loadYAML(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	values, err := parseYAML(f)
+	if err != nil {
+		return err
+	}
+
+	fields := map[string]field{}
+	for _, f := range c.fields() {
+		fields[f.path] = f
+	}
+	for key, raw := range values {
+		if name, ok := strings.CutPrefix(key, ENV_SECTION+"."); ok {
+			value, isString := raw.(string)
+			if !isString {
+				return fmt.Errorf("%w: %s 값은 문자열이어야 합니다", ErrInvalidConfig, key)
+			}
+			c.Env[name] = value
+			if _, exists := os.LookupEnv(name); !exists {
+				os.Setenv(name, value)
+			}
+			continue
+		}
+		f, ok := fields[key]
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrUnknownKey, key)
+		}
+		if err := setField(f.value, raw); err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrInvalidConfig, key, err)
+		}
+		c.sources[key] = SOURCE_YAML
+	}
+	return nil
+}
+
+// Source는 YAML 경로(예: server.listen_addr) 값의 출처(default, yaml, env)를 반환합니다.
+func (c *Config) Source(path string) string {
+	if s, ok := c.sources[path]; ok {
+		return s
+	}
+	return SOURCE_DEFAULT
+}
+
+// ApplyTimeouts는 타임아웃 설정을 consts의 타임아웃 값에 반영합니다. 요청 처리를 시작하기 전에 호출해야 합니다.
+func (c *Config) ApplyTimeouts() {
+	consts.SHORT_QUERY_TIMEOUT = c.Timeouts.ShortQuery
+	consts.DEFAULT_QUERY_TIMEOUT = c.Timeouts.DefaultQuery
+	consts.LONG_QUERY_TIMEOUT = c.Timeouts.LongQuery
+	consts.SHORT_WORK_TIMEOUT = c.Timeouts.ShortWork
+	consts.DEFAULT_WORK_TIMEOUT = c.Timeouts.DefaultWork
+	consts.LONG_WORK_TIMEOUT = c.Timeouts.LongWork
+	consts.REPORT_WORK_TIMEOUT = c.Timeouts.ReportWork
+}
+
+// Validate는 설정 값을 검사하고 잘못된 항목을 모두 모아 반환합니다.
+func (c *Config) Validate() error {
+	var problems []string
+	add := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if c.Server.ListenAddr == "" {
+		add("server.listen_addr가 비어 있습니다")
+	}
+	if c.Server.ReadHeaderTimeout <= 0 || c.Server.ReadTimeout <= 0 || c.Server.IdleTimeout <= 0 {
+		add("server의 read_header/read/idle 타임아웃은 0보다 커야 합니다")
+	}
+	if c.Server.WriteTimeout < 0 {
+		add("server.write_timeout_seconds는 0 이상이어야 합니다")
+	}
+
+	if (c.TLS.CertFile == "") != (c.TLS.KeyFile == "") {
+		add("tls.cert_file과 tls.key_file은 함께 지정해야 합니다")
+	}
+	for _, path := range []string{c.TLS.CertFile, c.TLS.KeyFile} {
+		if path == "" {
+			continue
+		}
+		if _, err := os.Stat(path); err != nil {
+			add("TLS 파일을 읽을 수 없습니다: %v", err)
+		}
+	}
+
+	if c.Database.URL == "" {
+		add("database.url(DATABASE_URL)이 설정되어 있지 않습니다")
+	}
+	if c.Database.MaxOpenConns <= 0 {
+		add("database.max_open_conns는 0보다 커야 합니다")
+	}
+	if c.Database.MaxIdleConns < 0 || c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+		add("database.max_idle_conns는 0 이상, max_open_conns 이하여야 합니다")
+	}
+	if c.Database.ConnMaxLifetime < 0 {
+		add("database.conn_max_lifetime_seconds는 0 이상이어야 합니다")
+	}
+	if c.Database.PingTimeout <= 0 {
+		add("database.ping_timeout_seconds는 0보다 커야 합니다")
+	}
+
+	for _, t := range []struct {
+		name  string
+		value int
+	}{
+		{"short_query", c.Timeouts.ShortQuery},
+		{"default_query", c.Timeouts.DefaultQuery},
+		{"long_query", c.Timeouts.LongQuery},
+		{"short_work", c.Timeouts.ShortWork},
+		{"default_work", c.Timeouts.DefaultWork},
+		{"long_work", c.Timeouts.LongWork},
+		{"report_work", c.Timeouts.ReportWork},
+	} {
+		if t.value <= 0 {
+			add("timeouts.%s_seconds는 0보다 커야 합니다", t.name)
+		}
+	}
+
+	if len(c.CORS.AllowedOrigins) == 0 {
+		add("cors.allowed_origins가 비어 있습니다 (모두 허용하려면 \"*\")")
+	}
+	for _, origin := range c.CORS.AllowedOrigins {
+		if !validOrigin(origin) {
+			add("cors.allowed_origins 값이 올바르지 않습니다 (scheme://host[:port] 형식): %q", origin)
+		}
+		if origin == "*" && c.CORS.AllowCredentials {
+			add("cors.allow_credentials=true이면 cors.allowed_origins에 \"*\"를 쓸 수 없습니다 (브라우저가 거부)")
+		}
+	}
+	if len(c.CORS.AllowedMethods) == 0 {
+		add("cors.allowed_methods가 비어 있습니다")
+	}
+	for _, method := range c.CORS.AllowedMethods {
+		if method != strings.ToUpper(method) || strings.ContainsAny(method, " ,") {
+			add("cors.allowed_methods 값이 올바르지 않습니다 (대문자 HTTP 메서드): %q", method)
+		}
+	}
+	if c.CORS.MaxAge < 0 {
+		add("cors.max_age_seconds는 0 이상이어야 합니다")
+	}
+
+	if c.Security.HSTSMaxAge < 0 {
+		add("security.hsts_max_age_seconds는 0 이상이어야 합니다")
+	}
+	switch c.Security.FrameOptions {
+	case "", "DENY", "SAMEORIGIN":
+	default:
+		add("security.frame_options는 DENY, SAMEORIGIN 또는 빈 값이어야 합니다: %q", c.Security.FrameOptions)
+	}
+	if c.Security.MaxBodyBytes < 0 || c.Security.MaxUploadBytes < 0 {
+		add("security.max_body_bytes, max_upload_bytes는 0 이상이어야 합니다 (0이면 제한 없음)")
+	}
+
+	if c.Jobs.Workers < 1 {
+		add("jobs.workers는 1 이상이어야 합니다")
+	}
+
+	if c.Features.FakePaymentGateway && c.Secrets.FakePGWebhookSecret == "" {
+		add("features.fake_payment_gateway=true이면 secrets.fake_pg_webhook_secret(FAKE_PG_WEBHOOK_SECRET)이 필요합니다")
+	}
+
+	switch c.Storage.Backend {
+	case storage.LOCAL_STORAGE_NAME:
+		if c.Storage.LocalDir == "" {
+			add("storage.local_dir(IMAGE_STORAGE_DIR)가 비어 있습니다")
+		}
+	case storage.S3_STORAGE_NAME:
+		if c.Storage.S3Endpoint == "" || c.Storage.S3Bucket == "" || c.Storage.S3AccessKey == "" || c.Storage.S3SecretKey == "" {
+			add("storage.backend=s3이면 storage.s3_endpoint, s3_bucket, s3_access_key, s3_secret_key가 필요합니다")
+		}
+	default:
+		add("storage.backend(IMAGE_STORAGE)는 %s 또는 %s여야 합니다: %q",
+			storage.LOCAL_STORAGE_NAME, storage.S3_STORAGE_NAME, c.Storage.Backend)
+	}
+
+	n := c.Notifications
+	if n.SMTPPort < 1 || n.SMTPPort > 65535 {
+		add("notifications.smtp_port는 1~65535여야 합니다")
+	}
+	if n.SMTPHost != "" && n.SMTPFrom == "" {
+		add("notifications.smtp_host를 설정하면 notifications.smtp_from(SMTP_FROM)이 필요합니다")
+	}
+	for _, u := range []struct {
+		path  string
+		value string
+	}{
+		{"storage.s3_endpoint", c.Storage.S3Endpoint},
+		{"storage.public_base_url", c.Storage.PublicBaseURL},
+		{"notifications.sms_api_url", n.SMSAPIURL},
+		{"notifications.kakao_api_url", n.KakaoAPIURL},
+		{"notifications.password_reset_url", n.PasswordResetURL},
+		{"naracontrol.url", c.Naracontrol.URL},
+	} {
+		if u.value != "" && !validHTTPURL(u.value) {
+			add("%s 값이 올바르지 않습니다 (http(s)://로 시작하는 주소): %q", u.path, u.value)
+		}
+	}
+
+	s := c.Schedulers
+	if s.SeatExpirationInterval < 0 || s.DashboardRollupInterval < 0 || s.PowerScheduleInterval < 0 ||
+		s.JobSchedulerInterval < 0 || s.OutboxRelayInterval < 0 {
+		add("schedulers의 주기는 0 이상이어야 합니다 (0이면 비활성화)")
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w:\n  - %s", ErrInvalidConfig, strings.Join(problems, "\n  - "))
+}
+
+// validOrigin은 CORS 출처가 "*" 또는 경로 없는 scheme://host[:port] 형식인지 확인합니다.
+func validOrigin(origin string) bool {
+	if origin == "*" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" &&
+		u.Path == "" && u.RawQuery == "" && u.User == nil
+}
+
+// validHTTPURL은 값이 호스트가 있는 http(s) 주소인지 확인합니다.
+func validHTTPURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// field는 태그가 붙은 설정 필드 하나입니다.
+type field struct {
+	path   string
+	env    string
+	secret string
+	value  reflect.Value
+}
+
+// fields는 Config의 설정 필드를 YAML 경로 순으로 반환합니다.
+func (c *Config) fields() []field {
+	var result []field
+	var walk func(v reflect.Value)
+	walk = func(v reflect.Value) {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			if !sf.IsExported() {
+				continue
+			}
+			path, ok := sf.Tag.Lookup("config")
+			if !ok {
+				if sf.Type.Kind() == reflect.Struct {
+					walk(v.Field(i))
+				}
+				continue
+			}
+			result = append(result, field{
+				path:   path,
+				env:    sf.Tag.Get("env"),
+				secret: sf.Tag.Get("secret"),
+				value:  v.Field(i),
+			})
+		}
+	}
+	walk(reflect.ValueOf(c).Elem())
+	sort.SliceStable(result, func(i, j int) bool { return result[i].path < result[j].path })
+	return result
+}
+
+// setField는 YAML 값(string 또는 []string)이나 환경 변수 문자열을 필드 타입에 맞게 변환하여 설정합니다.
+func setField(v reflect.Value, raw interface{}) error {
+	if v.Kind() == reflect.Slice {
+		var list []string
+		switch value := raw.(type) {
+		case []string:
+			list = value
+		case string:
+			for _, item := range strings.Split(value, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					list = append(list, item)
+				}
+			}
+		}
+		v.Set(reflect.ValueOf(list))
+		return nil
+	}
+
+	s, ok := raw.(string)
+	if !ok {
+		return errors.New("목록이 아닌 값이어야 합니다")
+	}
+	s = strings.TrimSpace(s)
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Int:
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return errors.New("정수여야 합니다")
+		}
+		v.SetInt(int64(n))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return errors.New("true 또는 false여야 합니다")
+		}
+		v.SetBool(b)
+	default:
+		return fmt.Errorf("지원하지 않는 타입입니다: %s", v.Kind())
+	}
+	return nil
+}
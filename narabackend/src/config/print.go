@@ -0,0 +1,97 @@
+// print.go
+package config
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"narabackend/src/utils"
+)
+
+// REDACTED는 --print-config 출력에서 비밀 값을 대신하는 문자열입니다.
+const REDACTED = "******"
+
+// secretEnvWords는 env 섹션에서 이름에 포함되면 값을 가릴 단어입니다.
+var secretEnvWords = []string{"PASSWORD", "SECRET", "TOKEN", "KEY", "PEPPER"}
+
+// Print는 비밀 값을 가린 최종 설정을 YAML 형식으로 출력합니다 (--print-config).
+// 각 줄 끝에 환경 변수 이름과 값의 출처(default, yaml, env)를 주석으로 표시합니다.
+func (c *Config) Print(w io.Writer) {
+	fmt.Fprintf(w, "# 설정 파일: %s\n", orNone(c.File))
+	fmt.Fprintf(w, "# .env 파일: %s\n", orNone(c.EnvFile))
+
+	section := ""
+	for _, f := range c.fields() {
+		dot := strings.Index(f.path, ".")
+		if f.path[:dot] != section {
+			section = f.path[:dot]
+			fmt.Fprintf(w, "%s:\n", section)
+		}
+		fmt.Fprintf(w, "  %s: %s  # %s, %s\n", f.path[dot+1:], c.redactedValue(f), f.env, c.Source(f.path))
+	}
+
+	if len(c.Env) == 0 {
+		return
+	}
+	names := make([]string, 0, len(c.Env))
+	for name := range c.Env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintf(w, "%s:\n", ENV_SECTION)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %s: %s\n", name, strconv.Quote(redactEnv(name, c.Env[name])))
+	}
+}
+
+// redactedValue는 필드 값을 출력용 문자열로 바꾸고 secret 태그가 있으면 가립니다.
+func (c *Config) redactedValue(f field) string {
+	switch f.value.Kind() {
+	case reflect.Slice:
+		items := make([]string, f.value.Len())
+		for i := range items {
+			items[i] = strconv.Quote(f.value.Index(i).String())
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case reflect.String:
+		s := f.value.String()
+		switch {
+		case s == "":
+		case f.secret == "url":
+			s = utils.MaskSensitiveURL(s)
+		case f.secret != "":
+			s = REDACTED
+		}
+		return strconv.Quote(s)
+	default:
+		return fmt.Sprint(f.value.Interface())
+	}
+}
+
+// redactEnv는 env 섹션 값 중 비밀로 보이는 값을 가립니다. 접속 정보가 포함된 URL은 MaskSensitiveURL로 비밀번호만 가립니다.
+func redactEnv(name, value string) string {
+	if value == "" {
+		return value
+	}
+	upper := strings.ToUpper(name)
+	for _, word := range secretEnvWords {
+		if strings.Contains(upper, word) {
+			return REDACTED
+		}
+	}
+	if strings.Contains(value, "://") && strings.Contains(value, "@") {
+		return utils.MaskSensitiveURL(value)
+	}
+	return value
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "(없음)"
+	}
+	return s
+}
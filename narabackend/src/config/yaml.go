@@ -0,0 +1,148 @@
+// yaml.go
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// parseYAML은 설정 파일에 필요한 YAML의 일부 문법만 해석하여 "상위.하위" 경로별 값으로 반환합니다.
+// 값은 string 또는 []string입니다. 지원하는 문법:
+//   - 공백 들여쓰기로 중첩한 매핑 (탭 들여쓰기는 오류)
+//   - 따옴표(' 또는 ") 문자열, 따옴표 없는 스칼라, 줄 끝 # 주석
+//   - "- 값" 형태의 목록과 [a, b] 형태의 한 줄 목록
+//
+// 앵커, 여러 줄 문자열, 객체 목록 등은 지원하지 않으며 오류로 처리합니다.
+func parseYAML(r io.Reader) (map[string]interface{}, error) {
+	type frame struct {
+		indent int
+		path   string
+	}
+	values := map[string]interface{}{}
+	stack := []frame{{indent: -1}}
+	listPath, listIndent := "", -1
+
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		raw := strings.TrimRight(scanner.Text(), " \r")
+		trimmed := strings.TrimLeft(raw, " ")
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || (lineNo == 1 && trimmed == "---") {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "\t") || strings.Contains(raw[:len(raw)-len(trimmed)], "\t") {
+			return nil, fmt.Errorf("%d번째 줄: 들여쓰기에 탭을 사용할 수 없습니다", lineNo)
+		}
+		indent := len(raw) - len(trimmed)
+
+		// 목록 항목은 바로 위의 빈 값 키에 속합니다.
+		if strings.HasPrefix(trimmed, "- ") || trimmed == "-" {
+			if listPath == "" || indent < listIndent {
+				return nil, fmt.Errorf("%d번째 줄: 목록 항목이 키 아래에 있지 않습니다", lineNo)
+			}
+			item, err := parseScalar(strings.TrimSpace(strings.TrimPrefix(trimmed, "-")))
+			if err != nil {
+				return nil, fmt.Errorf("%d번째 줄: %v", lineNo, err)
+			}
+			list, _ := values[listPath].([]string)
+			values[listPath] = append(list, item)
+			continue
+		}
+
+		for len(stack) > 1 && indent <= stack[len(stack)-1].indent {
+			stack = stack[:len(stack)-1]
+		}
+		listPath, listIndent = "", -1
+
+		colon := strings.Index(trimmed, ":")
+		if colon <= 0 {
+			return nil, fmt.Errorf("%d번째 줄: \"키: 값\" 형식이 아닙니다", lineNo)
+		}
+		key := strings.TrimSpace(trimmed[:colon])
+		rest := strings.TrimSpace(trimmed[colon+1:])
+		path := key
+		if parent := stack[len(stack)-1].path; parent != "" {
+			path = parent + "." + key
+		}
+		if _, exists := values[path]; exists {
+			return nil, fmt.Errorf("%d번째 줄: 중복된 키입니다: %s", lineNo, path)
+		}
+
+		if rest == "" || strings.HasPrefix(rest, "#") {
+			// 하위 매핑 또는 목록이 이어집니다.
+			stack = append(stack, frame{indent: indent, path: path})
+			listPath, listIndent = path, indent
+			continue
+		}
+		if strings.HasPrefix(rest, "[") {
+			list, err := parseFlowList(rest)
+			if err != nil {
+				return nil, fmt.Errorf("%d번째 줄: %v", lineNo, err)
+			}
+			values[path] = list
+			continue
+		}
+		value, err := parseScalar(rest)
+		if err != nil {
+			return nil, fmt.Errorf("%d번째 줄: %v", lineNo, err)
+		}
+		values[path] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// parseScalar는 따옴표를 벗기고, 따옴표 없는 값이면 줄 끝 주석을 제거합니다.
+func parseScalar(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	if q := s[0]; q == '"' || q == '\'' {
+		end := strings.IndexByte(s[1:], q)
+		if end < 0 {
+			return "", fmt.Errorf("닫는 따옴표가 없습니다: %s", s)
+		}
+		tail := strings.TrimSpace(s[end+2:])
+		if tail != "" && !strings.HasPrefix(tail, "#") {
+			return "", fmt.Errorf("따옴표 뒤에 알 수 없는 값이 있습니다: %s", s)
+		}
+		return s[1 : end+1], nil
+	}
+	if i := strings.Index(s, " #"); i >= 0 {
+		s = strings.TrimSpace(s[:i])
+	}
+	if strings.ContainsAny(s[:1], "&*!|>{") {
+		return "", fmt.Errorf("지원하지 않는 YAML 문법입니다: %s", s)
+	}
+	return s, nil
+}
+
+// parseFlowList는 [a, "b", c] 형태의 한 줄 목록을 해석합니다.
+func parseFlowList(s string) ([]string, error) {
+	if i := strings.LastIndex(s, "]"); i >= 0 {
+		tail := strings.TrimSpace(s[i+1:])
+		if tail != "" && !strings.HasPrefix(tail, "#") {
+			return nil, fmt.Errorf("목록 뒤에 알 수 없는 값이 있습니다: %s", s)
+		}
+		s = s[1:i]
+	} else {
+		return nil, fmt.Errorf("닫는 ]가 없습니다: %s", s)
+	}
+	list := []string{}
+	if strings.TrimSpace(s) == "" {
+		return list, nil
+	}
+	for _, part := range strings.Split(s, ",") {
+		item, err := parseScalar(strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, item)
+	}
+	return list, nil
+}
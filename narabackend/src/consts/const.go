@@ -1,7 +1,8 @@
 package consts
 
-// 데이터베이스 작업 관련 상수
-const (
+// 데이터베이스 작업 관련 타임아웃(초)
+// 시작 시 config 설정(timeouts)으로 덮어쓸 수 있도록 변수로 둡니다. 요청 처리 중에는 바꾸지 않습니다.
+var (
 	// ShortQueryTimeout은 간단한 쿼리 실행 시 타임아웃입니다.
 	SHORT_QUERY_TIMEOUT int = 5
 
@@ -20,4 +21,127 @@ const (
 
 	// LongWorkTimeout은 복잡한  작업 시 타임아웃입니다.
 	LONG_WORK_TIMEOUT int = 30
+
+	// ReportWorkTimeout은 비동기 보고서 한 건의 생성 타임아웃(초)입니다.
+	REPORT_WORK_TIMEOUT int = 300
+)
+
+// 스케줄러 관련 상수
+const (
+	// SeatExpirationInterval은 좌석 자동 해제 점검 주기(초)의 기본값입니다.
+	SEAT_EXPIRATION_INTERVAL int = 60
+
+	// ReservationNoShowGraceMinutes는 예약 시작 후 입실하지 않으면 노쇼로 취소하기까지의 유예 시간(분)입니다.
+	RESERVATION_NO_SHOW_GRACE_MINUTES int = 15
+
+	// ReservationCheckInEarlyMinutes는 예약 시작 전 입실 확인을 허용하는 시간(분)입니다.
+	RESERVATION_CHECK_IN_EARLY_MINUTES int = 10
+
+	// WaitingOfferTimeoutMinutes는 대기열 좌석 제안의 수락 기한(분)입니다.
+	WAITING_OFFER_TIMEOUT_MINUTES int = 10
+
+	// DashboardRollupInterval은 대시보드 집계 테이블 갱신 주기(초)의 기본값입니다.
+	DASHBOARD_ROLLUP_INTERVAL int = 300
+
+	// PowerScheduleInterval은 열람실 전원 시간표 점검 주기(초)의 기본값입니다.
+	POWER_SCHEDULE_INTERVAL int = 60
+)
+
+// 작업 큐 관련 상수
+const (
+	// JobPollInterval은 처리할 작업이 없을 때 워커가 작업 큐를 다시 확인하기까지의 대기 시간(초)입니다.
+	JOB_POLL_INTERVAL int = 1
+
+	// JobVisibilityTimeout은 실행 중인 작업의 잠금 시간(초)입니다. 워커가 중단되어 이 시간이 지나면 다시 실행됩니다.
+	JOB_VISIBILITY_TIMEOUT int = 120
+
+	// JobMaxAttempts는 작업의 기본 최대 실행 시도 횟수입니다. 모두 실패하면 dead 상태가 됩니다.
+	JOB_MAX_ATTEMPTS int = 5
+
+	// JobRetryBaseDelay는 첫 재시도까지의 대기 시간(초)이며, 재시도마다 두 배로 늘어납니다.
+	JOB_RETRY_BASE_DELAY int = 5
+
+	// JobRetryMaxDelay는 재시도 대기 시간(초)의 상한입니다.
+	JOB_RETRY_MAX_DELAY int = 3600
+
+	// JobWorkerCount는 JOB_WORKERS 환경변수가 없을 때 서버 하나에서 실행할 작업 워커 수입니다.
+	JOB_WORKER_COUNT int = 4
+
+	// JobRetentionDays는 완료(succeeded)·취소(cancelled)된 작업을 보관하는 기간(일)입니다. dead 작업은 관리자가 직접 정리합니다.
+	JOB_RETENTION_DAYS int = 14
+
+	// JobSchedulerInterval은 반복 작업 일정을 확인하는 주기(초)입니다. JOB_SCHEDULER_INTERVAL_SECONDS로 바꿀 수 있습니다.
+	JOB_SCHEDULER_INTERVAL int = 15
+
+	// JobSchedulerLockKey는 반복 작업 스케줄러 리더 선출에 사용하는 Postgres advisory lock 키입니다.
+	JOB_SCHEDULER_LOCK_KEY int64 = 7260043
+
+	// PowerSchedulerLockKey는 전원 시간표 스케줄러가 한 서버에서만 실행되도록 잡는 Postgres advisory lock 키입니다.
+	POWER_SCHEDULER_LOCK_KEY int64 = 7260044
+
+	// SeatExpirationLockKey는 좌석 자동 해제 스케줄러가 한 서버에서만 실행되도록 잡는 Postgres advisory lock 키입니다.
+	SEAT_EXPIRATION_LOCK_KEY int64 = 7260045
+)
+
+// 변경 이벤트 outbox 관련 상수
+const (
+	// OutboxRelayInterval은 NOTIFY를 받지 못한 경우에도 outbox를 다시 확인하는 주기(초)입니다. OUTBOX_RELAY_INTERVAL_SECONDS로 바꿀 수 있습니다.
+	OUTBOX_RELAY_INTERVAL int = 10
+
+	// OutboxBatchSize는 릴레이가 한 번에 가져와 전달하는 이벤트 수입니다.
+	OUTBOX_BATCH_SIZE int = 20
+
+	// OutboxMaxAttempts는 이벤트 전달 최대 시도 횟수입니다. 모두 실패하면 failed 상태가 됩니다.
+	OUTBOX_MAX_ATTEMPTS int = 10
+
+	// OutboxRetentionDays는 전달 완료(delivered) 및 실패(failed) 이벤트를 보관하는 기간(일)입니다.
+	OUTBOX_RETENTION_DAYS int = 7
+)
+
+// 실시간 변경 피드 관련 상수
+const (
+	// ChangeFeedHeartbeatInterval은 변경 피드(SSE) 연결 유지용 주석을 보내는 주기(초)입니다.
+	// LISTEN 연결이 끊어진 동안에도 이 주기마다 새 이벤트를 확인합니다.
+	CHANGE_FEED_HEARTBEAT_INTERVAL int = 20
+
+	// ChangeFeedBatchSize는 변경 피드에서 한 번에 조회하는 이벤트 수입니다.
+	CHANGE_FEED_BATCH_SIZE int = 200
+
+	// ChangeFeedRetentionDays는 변경 이벤트를 보관하는 기간(일)입니다. 이보다 오래 끊겼던 클라이언트는 전체를 다시 조회해야 합니다.
+	CHANGE_FEED_RETENTION_DAYS int = 3
+)
+
+// 웹훅 관련 상수
+const (
+	// WebhookTimeout은 웹훅 요청 한 번의 타임아웃(초)입니다.
+	WEBHOOK_TIMEOUT int = 10
+
+	// WebhookMaxAttempts는 웹훅 전송 최대 시도 횟수입니다. 재시도 간격은 작업 큐의 지수 백오프를 따릅니다.
+	WEBHOOK_MAX_ATTEMPTS int = 8
+
+	// WebhookDeliveryRetentionDays는 완료된 웹훅 전송 기록을 보관하는 기간(일)입니다.
+	WEBHOOK_DELIVERY_RETENTION_DAYS int = 30
+)
+
+// 알림 관련 상수
+const (
+	// NotificationTimeout은 알림 한 건 발송(대행사 API, SMTP) 타임아웃(초)입니다.
+	NOTIFICATION_TIMEOUT int = 15
+
+	// NotificationMaxAttempts는 알림 발송 최대 시도 횟수입니다.
+	NOTIFICATION_MAX_ATTEMPTS int = 5
+
+	// NotificationQuietStart, NotificationQuietEnd는 회원 설정이 없을 때의 방해 금지 시간(HH:MM)입니다.
+	// 이 시간에 생긴 알림은 종료 시각에 발송됩니다. 비밀번호 재설정처럼 긴급한 알림은 예외입니다.
+	NOTIFICATION_QUIET_START string = "22:00"
+	NOTIFICATION_QUIET_END   string = "08:00"
+
+	// NotificationRetentionDays는 알림 발송 기록을 보관하는 기간(일)입니다.
+	NOTIFICATION_RETENTION_DAYS int = 180
+
+	// PassExpiryReminderDays는 이용권 만료 며칠 전에 안내하는지입니다.
+	PASS_EXPIRY_REMINDER_DAYS int = 3
+
+	// PasswordResetTTL은 비밀번호 재설정 코드의 유효 시간(분)입니다.
+	PASSWORD_RESET_TTL int = 30
 )
@@ -0,0 +1,208 @@
+// fake.go
+package gateway
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// FAKE_GATEWAY_NAME은 개발/테스트용 가짜 PG의 이름입니다.
+const FAKE_GATEWAY_NAME = "fake"
+
+// FAKE_SIGNATURE_HEADER는 가짜 PG 웹훅 서명 헤더입니다 (본문의 HMAC-SHA256 hex).
+const FAKE_SIGNATURE_HEADER = "X-Fake-Signature"
+
+// FAKE_DECLINE_SUFFIX로 끝나는 금액(1000으로 나눈 나머지)은 항상 승인 거절됩니다.
+// 예: 10999원, 999원
+const FAKE_DECLINE_SUFFIX = 999
+
+// FakeGateway는 외부 통신 없이 메모리에서 동작하는 결정적(deterministic) PG입니다.
+// 거래 번호는 fake_tx_000001 형태로 순차 발급되고, 같은 OrderID로 다시 승인하면 기존 거래를 반환합니다.
+// 거절된 주문을 다시 승인하면 기존 거래와 함께 ErrDeclined를 반환합니다.
+type FakeGateway struct {
+	secret []byte
+
+	mu           sync.Mutex
+	seq          int
+	refundSeq    int
+	transactions map[string]*Result
+	orders       map[string]string // OrderID -> TransactionID
+}
+
+// NewFakeGateway는 웹훅 서명에 사용할 secret으로 가짜 PG를 생성합니다.
+func NewFakeGateway(secret string) *FakeGateway {
+	return &FakeGateway{
+		secret:       []byte(secret),
+		transactions: make(map[string]*Result),
+		orders:       make(map[string]string),
+	}
+}
+
+// Name은 PG 식별자를 반환합니다.
+func (g *FakeGateway) Name() string {
+	return FAKE_GATEWAY_NAME
+}
+
+// Authorize는 금액을 승인합니다. FAKE_DECLINE_SUFFIX 규칙에 해당하면 거절합니다.
+func (g *FakeGateway) Authorize(ctx context.Context, req AuthorizeRequest) (Result, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if req.Amount <= 0 {
+		return Result{}, fmt.Errorf("%w: 금액은 0보다 커야 합니다", ErrInvalidState)
+	}
+	if req.OrderID != "" {
+		if txID, ok := g.orders[req.OrderID]; ok {
+			tx := g.transactions[txID]
+			if tx.Status == STATUS_FAILED {
+				return *tx, ErrDeclined
+			}
+			return *tx, nil
+		}
+	}
+
+	g.seq++
+	result := &Result{
+		TransactionID: fmt.Sprintf("fake_tx_%06d", g.seq),
+		Status:        STATUS_AUTHORIZED,
+		Amount:        req.Amount,
+	}
+	if req.Amount%1000 == FAKE_DECLINE_SUFFIX {
+		result.Status = STATUS_FAILED
+		result.Message = "한도 초과(가짜 PG 거절 규칙)"
+	}
+	g.transactions[result.TransactionID] = result
+	if req.OrderID != "" {
+		g.orders[req.OrderID] = result.TransactionID
+	}
+	if result.Status == STATUS_FAILED {
+		return *result, ErrDeclined
+	}
+	return *result, nil
+}
+
+// Capture는 승인된 거래를 매입합니다. 이미 매입된 거래는 그대로 반환합니다.
+func (g *FakeGateway) Capture(ctx context.Context, transactionID string) (Result, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	tx, ok := g.transactions[transactionID]
+	if !ok {
+		return Result{}, ErrTransactionNotFound
+	}
+	switch tx.Status {
+	case STATUS_AUTHORIZED:
+		tx.Status = STATUS_CAPTURED
+		tx.CapturedAmount = tx.Amount
+	case STATUS_CAPTURED:
+	default:
+		return *tx, ErrInvalidState
+	}
+	return *tx, nil
+}
+
+// Cancel은 매입 전 승인을 취소합니다. 이미 취소된 거래는 그대로 반환합니다.
+func (g *FakeGateway) Cancel(ctx context.Context, transactionID string) (Result, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	tx, ok := g.transactions[transactionID]
+	if !ok {
+		return Result{}, ErrTransactionNotFound
+	}
+	switch tx.Status {
+	case STATUS_AUTHORIZED:
+		tx.Status = STATUS_CANCELLED
+	case STATUS_CANCELLED:
+	default:
+		return *tx, ErrInvalidState
+	}
+	return *tx, nil
+}
+
+// Refund는 매입 금액 범위 안에서 환불하고 fake_rf_000001 형태의 환불 번호를 발급합니다.
+func (g *FakeGateway) Refund(ctx context.Context, transactionID string, amount int) (Result, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	tx, ok := g.transactions[transactionID]
+	if !ok {
+		return Result{}, ErrTransactionNotFound
+	}
+	if tx.Status != STATUS_CAPTURED && tx.Status != STATUS_PARTIALLY_REFUNDED {
+		return *tx, ErrInvalidState
+	}
+	if amount <= 0 || tx.RefundedAmount+amount > tx.CapturedAmount {
+		return *tx, fmt.Errorf("%w: 환불 가능 금액 %d원", ErrInvalidState, tx.CapturedAmount-tx.RefundedAmount)
+	}
+
+	g.refundSeq++
+	tx.RefundedAmount += amount
+	if tx.RefundedAmount == tx.CapturedAmount {
+		tx.Status = STATUS_REFUNDED
+	} else {
+		tx.Status = STATUS_PARTIALLY_REFUNDED
+	}
+	result := *tx
+	result.RefundID = fmt.Sprintf("fake_rf_%06d", g.refundSeq)
+	return result, nil
+}
+
+// QueryStatus는 거래의 현재 상태를 반환합니다.
+func (g *FakeGateway) QueryStatus(ctx context.Context, transactionID string) (Result, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	tx, ok := g.transactions[transactionID]
+	if !ok {
+		return Result{}, ErrTransactionNotFound
+	}
+	return *tx, nil
+}
+
+// sign은 본문의 HMAC-SHA256 서명을 계산합니다.
+func (g *FakeGateway) sign(body []byte) []byte {
+	mac := hmac.New(sha256.New, g.secret)
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+// VerifyWebhook은 X-Fake-Signature 헤더를 검증하고 본문(JSON WebhookEvent)을 해석합니다.
+// secret이 설정되지 않았으면 누구나 서명을 만들 수 있으므로 모든 웹훅을 거부합니다.
+func (g *FakeGateway) VerifyWebhook(header http.Header, body []byte) (WebhookEvent, error) {
+	var event WebhookEvent
+	if len(g.secret) == 0 {
+		return event, fmt.Errorf("%w: 웹훅 secret이 설정되지 않았습니다", ErrInvalidSignature)
+	}
+	signature, err := hex.DecodeString(header.Get(FAKE_SIGNATURE_HEADER))
+	if err != nil || !hmac.Equal(signature, g.sign(body)) {
+		return event, ErrInvalidSignature
+	}
+	if err := json.Unmarshal(body, &event); err != nil {
+		return event, fmt.Errorf("웹훅 본문 해석 실패: %w", err)
+	}
+	if event.EventID == "" || event.TransactionID == "" {
+		return event, fmt.Errorf("웹훅 본문에 event_id/transaction_id가 없습니다")
+	}
+	return event, nil
+}
+
+// BuildWebhook은 주어진 이벤트로 서명된 웹훅 요청 본문과 헤더를 만듭니다.
+// 개발 환경에서 PG 콜백을 흉내 낼 때 사용합니다.
+func (g *FakeGateway) BuildWebhook(eventID string, event WebhookEvent) ([]byte, http.Header, error) {
+	event.EventID = eventID
+	body, err := json.Marshal(event)
+	if err != nil {
+		return nil, nil, err
+	}
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	header.Set(FAKE_SIGNATURE_HEADER, hex.EncodeToString(g.sign(body)))
+	return body, header, nil
+}
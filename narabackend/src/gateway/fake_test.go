@@ -0,0 +1,163 @@
+// fake_test.go
+package gateway
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestFakeAuthorizeAndCapture(t *testing.T) {
+	ctx := context.Background()
+	g := NewFakeGateway("secret")
+
+	result, err := g.Authorize(ctx, AuthorizeRequest{OrderID: "A:1", Amount: 10000})
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+	if result.TransactionID != "fake_tx_000001" || result.Status != STATUS_AUTHORIZED {
+		t.Fatalf("Authorize 결과가 다릅니다: %+v", result)
+	}
+
+	// 같은 OrderID는 기존 거래를 반환합니다.
+	again, err := g.Authorize(ctx, AuthorizeRequest{OrderID: "A:1", Amount: 10000})
+	if err != nil || again.TransactionID != result.TransactionID {
+		t.Fatalf("재승인이 기존 거래를 반환하지 않습니다: %+v, %v", again, err)
+	}
+
+	captured, err := g.Capture(ctx, result.TransactionID)
+	if err != nil {
+		t.Fatalf("Capture: %v", err)
+	}
+	if captured.Status != STATUS_CAPTURED || captured.CapturedAmount != 10000 {
+		t.Fatalf("Capture 결과가 다릅니다: %+v", captured)
+	}
+	// 이미 매입된 거래의 재매입은 그대로 반환합니다.
+	if _, err := g.Capture(ctx, result.TransactionID); err != nil {
+		t.Fatalf("재매입: %v", err)
+	}
+	if _, err := g.Capture(ctx, "fake_tx_999999"); !errors.Is(err, ErrTransactionNotFound) {
+		t.Fatalf("없는 거래 매입 오류가 다릅니다: %v", err)
+	}
+}
+
+func TestFakeAuthorizeDeclined(t *testing.T) {
+	ctx := context.Background()
+	g := NewFakeGateway("secret")
+
+	result, err := g.Authorize(ctx, AuthorizeRequest{OrderID: "A:2", Amount: 10999})
+	if !errors.Is(err, ErrDeclined) || result.Status != STATUS_FAILED || result.TransactionID == "" {
+		t.Fatalf("거절 규칙이 적용되지 않았습니다: %+v, %v", result, err)
+	}
+
+	// 거절된 주문의 재시도도 거절로 반환해야 합니다.
+	retry, err := g.Authorize(ctx, AuthorizeRequest{OrderID: "A:2", Amount: 10999})
+	if !errors.Is(err, ErrDeclined) || retry.TransactionID != result.TransactionID {
+		t.Fatalf("거절된 주문 재시도가 성공으로 반환되었습니다: %+v, %v", retry, err)
+	}
+
+	if _, err := g.Capture(ctx, result.TransactionID); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("거절된 거래 매입 오류가 다릅니다: %v", err)
+	}
+	if _, err := g.Authorize(ctx, AuthorizeRequest{OrderID: "A:3", Amount: 0}); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("0원 승인 오류가 다릅니다: %v", err)
+	}
+}
+
+func TestFakeStatusTransitions(t *testing.T) {
+	ctx := context.Background()
+	g := NewFakeGateway("secret")
+
+	// 승인 -> 취소 후에는 매입/환불할 수 없습니다.
+	cancelled, _ := g.Authorize(ctx, AuthorizeRequest{OrderID: "B:1", Amount: 5000})
+	if result, err := g.Cancel(ctx, cancelled.TransactionID); err != nil || result.Status != STATUS_CANCELLED {
+		t.Fatalf("Cancel: %+v, %v", result, err)
+	}
+	if _, err := g.Cancel(ctx, cancelled.TransactionID); err != nil {
+		t.Fatalf("재취소: %v", err)
+	}
+	if _, err := g.Capture(ctx, cancelled.TransactionID); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("취소된 거래 매입 오류가 다릅니다: %v", err)
+	}
+	if _, err := g.Refund(ctx, cancelled.TransactionID, 1000); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("취소된 거래 환불 오류가 다릅니다: %v", err)
+	}
+
+	// 승인 -> 매입 -> 부분 환불 -> 전액 환불
+	paid, _ := g.Authorize(ctx, AuthorizeRequest{OrderID: "B:2", Amount: 5000})
+	if _, err := g.Refund(ctx, paid.TransactionID, 1000); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("매입 전 환불 오류가 다릅니다: %v", err)
+	}
+	g.Capture(ctx, paid.TransactionID)
+	if _, err := g.Cancel(ctx, paid.TransactionID); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("매입된 거래 취소 오류가 다릅니다: %v", err)
+	}
+	partial, err := g.Refund(ctx, paid.TransactionID, 2000)
+	if err != nil || partial.Status != STATUS_PARTIALLY_REFUNDED || partial.RefundID != "fake_rf_000001" {
+		t.Fatalf("부분 환불: %+v, %v", partial, err)
+	}
+	if _, err := g.Refund(ctx, paid.TransactionID, 4000); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("환불 가능 금액 초과 오류가 다릅니다: %v", err)
+	}
+	full, err := g.Refund(ctx, paid.TransactionID, 3000)
+	if err != nil || full.Status != STATUS_REFUNDED || full.RefundedAmount != 5000 {
+		t.Fatalf("전액 환불: %+v, %v", full, err)
+	}
+	if _, err := g.Refund(ctx, paid.TransactionID, 1); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("전액 환불 후 환불 오류가 다릅니다: %v", err)
+	}
+
+	status, err := g.QueryStatus(ctx, paid.TransactionID)
+	if err != nil || status.Status != STATUS_REFUNDED {
+		t.Fatalf("QueryStatus: %+v, %v", status, err)
+	}
+}
+
+func TestFakeVerifyWebhook(t *testing.T) {
+	g := NewFakeGateway("secret")
+	event := WebhookEvent{TransactionID: "fake_tx_000001", Status: STATUS_CAPTURED, Amount: 10000}
+
+	body, header, err := g.BuildWebhook("evt_1", event)
+	if err != nil {
+		t.Fatalf("BuildWebhook: %v", err)
+	}
+	got, err := g.VerifyWebhook(header, body)
+	if err != nil {
+		t.Fatalf("VerifyWebhook: %v", err)
+	}
+	if got.EventID != "evt_1" || got.TransactionID != event.TransactionID || got.Status != event.Status {
+		t.Fatalf("이벤트 내용이 다릅니다: %+v", got)
+	}
+
+	// 본문 변조
+	tampered := append([]byte{}, body...)
+	tampered[len(tampered)-2] = '9'
+	if _, err := g.VerifyWebhook(header, tampered); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("변조된 본문 오류가 다릅니다: %v", err)
+	}
+
+	// 다른 secret의 서명
+	_, otherHeader, _ := NewFakeGateway("other").BuildWebhook("evt_1", event)
+	if _, err := g.VerifyWebhook(otherHeader, body); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("다른 secret 서명 오류가 다릅니다: %v", err)
+	}
+
+	// 서명 헤더 누락/형식 오류
+	if _, err := g.VerifyWebhook(http.Header{}, body); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("서명 누락 오류가 다릅니다: %v", err)
+	}
+	bad := http.Header{}
+	bad.Set(FAKE_SIGNATURE_HEADER, "not-hex")
+	if _, err := g.VerifyWebhook(bad, body); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("서명 형식 오류가 다릅니다: %v", err)
+	}
+}
+
+func TestFakeVerifyWebhookWithoutSecret(t *testing.T) {
+	g := NewFakeGateway("")
+	body, header, _ := g.BuildWebhook("evt_1", WebhookEvent{TransactionID: "fake_tx_000001", Status: STATUS_CAPTURED})
+	if _, err := g.VerifyWebhook(header, body); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("secret 없이 서명한 웹훅이 통과했습니다: %v", err)
+	}
+}
@@ -0,0 +1,117 @@
+// gateway.go
+package gateway
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// 거래 상태
+const (
+	STATUS_AUTHORIZED         = "authorized"         // 승인(매입 전)
+	STATUS_CAPTURED           = "captured"           // 매입 완료
+	STATUS_CANCELLED          = "cancelled"          // 승인 취소
+	STATUS_PARTIALLY_REFUNDED = "partially_refunded" // 부분 환불
+	STATUS_REFUNDED           = "refunded"           // 전액 환불
+	STATUS_FAILED             = "failed"             // 승인 거절/실패
+)
+
+var (
+	// ErrUnknownGateway는 등록되지 않은 PG 이름으로 조회할 때 반환됩니다.
+	ErrUnknownGateway = errors.New("등록되지 않은 결제 대행사입니다")
+	// ErrDeclined는 PG가 승인을 거절한 경우 반환됩니다.
+	ErrDeclined = errors.New("결제가 거절되었습니다")
+	// ErrTransactionNotFound는 PG에 해당 거래가 없는 경우 반환됩니다.
+	ErrTransactionNotFound = errors.New("거래를 찾을 수 없습니다")
+	// ErrInvalidState는 현재 거래 상태에서 요청한 작업을 할 수 없는 경우 반환됩니다.
+	ErrInvalidState = errors.New("현재 거래 상태에서 처리할 수 없는 요청입니다")
+	// ErrInvalidSignature는 웹훅 서명 검증에 실패한 경우 반환됩니다.
+	ErrInvalidSignature = errors.New("웹훅 서명이 올바르지 않습니다")
+)
+
+// AuthorizeRequest는 승인 요청 정보입니다.
+type AuthorizeRequest struct {
+	OrderID     string // 가맹점 주문 번호(멱등 키로 사용)
+	Amount      int    // 승인 금액(원)
+	CardToken   string // 카드 토큰(단말/키오스크가 전달)
+	Description string
+}
+
+// Result는 PG 거래의 현재 상태입니다.
+type Result struct {
+	TransactionID  string `json:"transaction_id"`
+	Status         string `json:"status"`
+	Amount         int    `json:"amount"`
+	CapturedAmount int    `json:"captured_amount"`
+	RefundedAmount int    `json:"refunded_amount"`
+	RefundID       string `json:"refund_id,omitempty"` // Refund 호출 시 PG가 발급한 환불 번호
+	Message        string `json:"message,omitempty"`
+}
+
+// WebhookEvent는 검증된 PG 콜백(웹훅) 내용입니다.
+// RefundID/RefundAmount는 환불 이벤트일 때만 채워집니다.
+type WebhookEvent struct {
+	EventID       string `json:"event_id"`
+	TransactionID string `json:"transaction_id"`
+	Status        string `json:"status"`
+	Amount        int    `json:"amount"`
+	RefundID      string `json:"refund_id,omitempty"`
+	RefundAmount  int    `json:"refund_amount,omitempty"`
+}
+
+// PaymentGateway는 결제 대행사(PG) 연동 인터페이스입니다.
+// 실제 PG 연동은 이 인터페이스를 구현하는 별도 어댑터로 추가하며, 비즈니스 로직은 변경하지 않습니다.
+type PaymentGateway interface {
+	// Name은 라우팅과 저장에 사용하는 PG 식별자입니다.
+	Name() string
+	// Authorize는 금액을 승인(한도 확보)합니다.
+	Authorize(ctx context.Context, req AuthorizeRequest) (Result, error)
+	// Capture는 승인된 금액을 매입합니다.
+	Capture(ctx context.Context, transactionID string) (Result, error)
+	// Cancel은 매입 전 승인을 취소합니다.
+	Cancel(ctx context.Context, transactionID string) (Result, error)
+	// Refund는 매입된 금액의 전체 또는 일부를 환불합니다.
+	Refund(ctx context.Context, transactionID string, amount int) (Result, error)
+	// QueryStatus는 PG에 거래 상태를 조회합니다.
+	QueryStatus(ctx context.Context, transactionID string) (Result, error)
+	// VerifyWebhook은 웹훅 요청의 서명을 검증하고 이벤트를 해석합니다.
+	VerifyWebhook(header http.Header, body []byte) (WebhookEvent, error)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]PaymentGateway{}
+)
+
+// Register는 PG 어댑터를 이름으로 등록합니다. 같은 이름이면 교체됩니다.
+func Register(gw PaymentGateway) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[gw.Name()] = gw
+}
+
+// Get은 이름으로 등록된 PG 어댑터를 반환합니다.
+func Get(name string) (PaymentGateway, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	gw, ok := registry[name]
+	if !ok {
+		return nil, ErrUnknownGateway
+	}
+	return gw, nil
+}
+
+// Names는 등록된 PG 이름 목록을 정렬하여 반환합니다.
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -4,16 +4,20 @@ package main
 import (
 	"context"
 	"database/sql"
+	"flag"
 	"log"
 	"net/http"
 	"os"
-	"path/filepath"
+	"strconv"
 	"time"
 
 	"github.com/gorilla/mux"
-	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
 
+	"narabackend/src/config"
+	"narabackend/src/gateway"
+	"narabackend/src/notifications"
+	"narabackend/src/storage"
 	"narabackend/src/tables"
 	"narabackend/src/utils"
 )
@@ -31,28 +35,33 @@ func init() {
 func main() {
 	var err error
 
-	// 프로젝트 루트 디렉토리 찾기
-	rootDir, err := utils.FindProjectRoot()
+	// 실행 옵션: --config(YAML 설정 파일), --env-file(.env 경로), --print-config(비밀 값을 가린 최종 설정 출력 후 종료)
+	configFile := flag.String("config", "", "YAML 설정 파일 경로 (기본: CONFIG_FILE 환경 변수)")
+	envFile := flag.String("env-file", "", ".env 파일 경로 (기본: ENV_FILE 환경 변수, 없으면 상위 디렉터리에서 .env 검색)")
+	printConfig := flag.Bool("print-config", false, "비밀 값을 가린 최종 설정을 출력하고 종료")
+	flag.Parse()
+
+	// 설정 로드: 기본값 < YAML 설정 파일 < 환경 변수(.env 포함)
+	cfg, err := config.Load(config.LoadOptions{File: *configFile, EnvFile: *envFile})
 	if err != nil {
-		log.Fatalf("프로젝트 루트 디렉토리를 찾을 수 없습니다: %v", err)
+		log.Fatalf("설정 로드 실패: %v", err)
 	}
-
-	// .env 파일 경로
-	envPath := filepath.Join(rootDir, ".env")
-	log.Printf("환경 변수 파일 경로: %s", envPath)
-
-	// .env 파일 로드
-	if err := godotenv.Overload(envPath); err != nil {
-		log.Printf("경고: .env 파일 로드 실패: %v", err)
-		log.Println("시스템 환경 변수를 사용합니다.")
+	if *printConfig {
+		cfg.Print(os.Stdout)
+		if err := cfg.Validate(); err != nil {
+			log.Fatalf("설정 검증 실패: %v", err)
+		}
+		return
 	}
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("설정 검증 실패: %v", err)
+	}
+	log.Printf("설정 파일: %s, 환경 변수 파일: %s", cfg.File, cfg.EnvFile)
+	cfg.ApplyTimeouts()
+	utils.SetDebugLogging(cfg.Features.Debug)
 
-	// .env 파일이 로드되었거나 시스템에 설정된 환경 변수 사용
-	databaseURL := os.Getenv("DATABASE_URL")
+	databaseURL := cfg.Database.URL
 	log.Printf("데이터베이스 URL: %s", utils.MaskSensitiveURL(databaseURL))
-	if databaseURL == "" {
-		log.Fatal("DATABASE_URL 환경변수가 설정되어 있지 않습니다.")
-	}
 
 	db, err = sql.Open("postgres", databaseURL)
 	if err != nil {
@@ -60,23 +69,19 @@ func main() {
 	}
 	defer db.Close()
 
-	// DB 연결 풀링 최적화
-	db.SetMaxOpenConns(50)
-	db.SetMaxIdleConns(10)
-	db.SetConnMaxLifetime(5 * time.Minute)
+	// DB 연결 풀 설정 (database.max_open_conns, max_idle_conns, conn_max_lifetime_seconds)
+	db.SetMaxOpenConns(cfg.Database.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.Database.MaxIdleConns)
+	db.SetConnMaxLifetime(time.Duration(cfg.Database.ConnMaxLifetime) * time.Second)
 
 	// DB Ping 시 컨텍스트를 사용하여 타임아웃 적용
 	log.Printf("🔗 [INIT] 데이터베이스 연결 테스트 시작...")
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Database.PingTimeout)*time.Second)
 	defer cancel()
 	if err := db.PingContext(ctx); err != nil {
 		log.Fatalf("❌ [INIT] DB ping 실패: %v", err)
 	}
-<<<<<<< HEAD
 	log.Printf("✅ [INIT] 데이터베이스 연결 성공!")
-=======
-	log.Printf("데이터베이스 연결 성공!")
->>>>>>> eb01e759e3958a53e0ac9aff24e1afc96568c521
 
 	// tables 패키지에 DB 연결 전달
 	utils.DB = db
@@ -85,8 +90,42 @@ func main() {
 	// tables 패키지에 작업 큐 함수 전달
 	utils.SetEnqueueJobFunc(utils.EnqueueJob)
 
-	// 비동기 작업 큐(worker) 시작
-	utils.StartJobWorker()
+	// naracontrol(장치 제어 서버) 연동 설정. naracontrol.url이 없으면 장치 명령과 변경 이벤트를 보내지 않습니다.
+	utils.SetNaracontrol(cfg.Naracontrol.URL, cfg.Naracontrol.APIKey)
+
+	// 알림 채널(문자/알림톡/이메일) 발송 어댑터 등록. 대기 중인 알림 발송 작업이 실행되기 전에 등록해야 합니다.
+	// 대행사 설정(smtp_host, sms_api_url, kakao_api_url)이 없는 채널은 notifications.local_path(기본 표준 출력)에 기록만 합니다.
+	n := cfg.Notifications
+	err = notifications.Configure(notifications.Config{
+		LocalPath: n.LocalPath,
+		SMTP: notifications.SMTPConfig{
+			Host:     n.SMTPHost,
+			Port:     strconv.Itoa(n.SMTPPort),
+			Username: n.SMTPUsername,
+			Password: n.SMTPPassword,
+			From:     n.SMTPFrom,
+		},
+		SMSAPIURL:      n.SMSAPIURL,
+		SMSAPIKey:      n.SMSAPIKey,
+		SMSSender:      n.SMSSender,
+		KakaoAPIURL:    n.KakaoAPIURL,
+		KakaoAPIKey:    n.KakaoAPIKey,
+		KakaoSenderKey: n.KakaoSenderKey,
+	})
+	if err != nil {
+		log.Fatalf("알림 채널 설정 실패: %v", err)
+	}
+	for _, channel := range notifications.Channels() {
+		sender, _ := notifications.Get(channel)
+		log.Printf("알림 채널: %s -> %s", channel, sender.Name())
+	}
+
+	// 작업 처리기를 등록한 뒤 비동기 작업 큐(job_table) worker 시작. 재시작 전에 쌓인 작업도 이어서 처리합니다.
+	// features.job_workers=false이면 이 서버는 작업을 넣기만 하고 처리하지 않습니다.
+	tables.RegisterJobHandlers()
+	if cfg.Features.JobWorkers {
+		utils.StartJobWorkers(cfg.Jobs.Workers)
+	}
 
 	// 라우터 초기화
 	r := mux.NewRouter()
@@ -104,11 +143,35 @@ func main() {
 	tables.RegisterManagerRoutes(r)
 	log.Printf("Manager 라우트 등록 완료")
 
+	// 관리자 비밀번호 재설정(코드 발송/확인) 라우트 등록. 메일의 링크 주소는 notifications.password_reset_url입니다.
+	tables.SetPasswordResetURL(cfg.Notifications.PasswordResetURL)
+	tables.RegisterPasswordResetRoutes(r)
+
 	// user_table 관련 라우트 등록
 	tables.RegisterUserRoutes(r)
 
-	// company_image_table 관련 라우트 등록
+	// 업로드 파일 저장소 설정 (storage.backend=s3이면 S3 호환 저장소, 기본은 storage.local_dir 로컬 디렉터리)
+	imageStorage, err := storage.New(storage.Config{
+		Backend:       cfg.Storage.Backend,
+		LocalDir:      cfg.Storage.LocalDir,
+		PublicBaseURL: cfg.Storage.PublicBaseURL,
+		S3: storage.S3Config{
+			Endpoint:  cfg.Storage.S3Endpoint,
+			Region:    cfg.Storage.S3Region,
+			Bucket:    cfg.Storage.S3Bucket,
+			AccessKey: cfg.Storage.S3AccessKey,
+			SecretKey: cfg.Storage.S3SecretKey,
+		},
+	})
+	if err != nil {
+		log.Fatalf("파일 저장소 설정 실패: %v", err)
+	}
+	storage.SetDefault(imageStorage)
+	log.Printf("파일 저장소: %s", imageStorage.Name())
+
+	// company_image_table 관련 라우트 등록 (업로드/갤러리 포함)
 	tables.RegisterCompanyImageRoutes(r)
+	tables.RegisterCompanyImageUploadRoutes(r)
 
 	// manager_access_table 관련 라우트 등록
 	tables.RegisterManagerAccessRoutes(r)
@@ -116,14 +179,139 @@ func main() {
 	// manager_company_table 관련 라우트 등록
 	tables.RegisterManagerCompanyRoutes(r)
 
-	// 로깅 미들웨어와 CORS 미들웨어를 함께 적용
-	handler := utils.LoggingMiddleware(utils.CorsMiddleware(r))
-	http.Handle("/", handler)
+	// plan_table(이용권 상품), member_pass_table(회원 이용권), seat_session_table(입실/퇴실) 라우트 등록
+	tables.RegisterPlanRoutes(r)
+	tables.RegisterMemberPassRoutes(r)
+	tables.RegisterSeatSessionRoutes(r)
+
+	// payment_table(결제 원장), settlement_table(일일 마감) 라우트 등록
+	tables.RegisterPaymentRoutes(r)
+	tables.RegisterSettlementRoutes(r)
+
+	// 결제 대행사(PG) 어댑터 등록. 실제 PG는 별도 어댑터를 추가로 등록합니다.
+	// 테스트용 가짜 PG는 features.fake_payment_gateway=false이면 등록하지 않습니다.
+	if cfg.Features.FakePaymentGateway {
+		gateway.Register(gateway.NewFakeGateway(cfg.Secrets.FakePGWebhookSecret))
+	}
+	tables.RegisterPaymentGatewayRoutes(r)
+
+	// outing_rule_table(업체별 외출 규칙) 라우트 등록
+	tables.RegisterOutingRuleRoutes(r)
+
+	// reservation_table(좌석/룸 예약), waiting_list_table(열람실 대기열) 라우트 등록
+	tables.RegisterReservationRoutes(r)
+	tables.RegisterWaitingListRoutes(r)
+
+	// locker_table(사물함), locker_rental_table(사물함 대여) 라우트 등록
+	tables.RegisterLockerRoutes(r)
+
+	// cleaning_task_table(좌석 청소 작업), cleaning_rule_table(청소 규칙) 라우트 등록
+	tables.RegisterCleaningRoutes(r)
+
+	// member_profile_table(업체 회원 정보), 회원 요약/이력 라우트 등록
+	tables.RegisterMemberRoutes(r)
 
-	log.Printf("🚀 [INIT] 서버가 :8080 포트에서 실행 중입니다.")
+	// credential_table(회원 카드/QR/PIN) 라우트 등록. PIN 해시에는 CREDENTIAL_PIN_PEPPER를 사용합니다.
+	// seat_table.card_number의 기존 카드는 features.legacy_seat_cards=true일 때만 키오스크에서 인정합니다.
+	tables.SetCredentialPepper(cfg.Secrets.CredentialPinPepper)
+	tables.SetLegacySeatCards(cfg.Features.LegacySeatCards)
+	tables.RegisterCredentialRoutes(r)
+
+	// operating_schedule_table(운영 시간표), unmanned_rule_table(무인 운영 규칙), 키오스크 판정 라우트 등록
+	tables.RegisterUnmannedRoutes(r)
+
+	// power_schedule_table(전원 시간표), power_rule_table(좌석 전원 규칙), power_event_table(전원 이벤트) 라우트 등록
+	tables.RegisterPowerRoutes(r)
+
+	// 대시보드 통계(daily_usage_rollup_table, hourly_usage_rollup_table) 라우트 등록
+	tables.RegisterDashboardRoutes(r)
+
+	// 사용/매출 보고서(report_run_table) 라우트 등록
+	tables.RegisterReportRoutes(r)
+
+	// job_schedule_table(반복 작업 일정) 라우트 등록
+	tables.RegisterJobScheduleRoutes(r)
+
+	// job_table(비동기 작업 큐) 조회/재시도/취소/dead-letter 정리, 작업 큐 통계 라우트 등록
+	tables.RegisterJobAdminRoutes(r)
+
+	// change_event_table(실시간 변경 피드) 이어 받기 및 SSE 스트림 라우트 등록
+	tables.RegisterChangeFeedRoutes(r)
+
+	// webhook_table(업체 웹훅 구독) 및 전송 기록, 테스트 전송 라우트 등록
+	// 내부 주소(루프백, 사설망, 메타데이터)로의 웹훅 전송은 features.webhook_private_targets=true일 때만 허용합니다.
+	tables.SetWebhookAllowPrivate(cfg.Features.WebhookPrivateTargets)
+	tables.RegisterWebhookRoutes(r)
+
+	// 알림 템플릿, 회원 알림 수신 설정(notification_preference_table), 알림 발송 기록(notification_table) 라우트 등록
+	tables.RegisterNotificationRoutes(r)
+
+	// 주기 작업 스케줄러 시작. 주기가 0이거나 features.schedulers=false이면 이 서버에서는 실행하지 않습니다.
+	if cfg.Features.Schedulers {
+		// 만료/외출 초과 좌석 자동 해제 (schedulers.seat_expiration_power_off=true이면 해제 좌석 전원 차단)
+		tables.StartSeatExpirationScheduler(tables.SeatExpirationConfig{
+			Interval: time.Duration(cfg.Schedulers.SeatExpirationInterval) * time.Second,
+			PowerOff: cfg.Schedulers.SeatExpirationPowerOff,
+		})
+
+		// 대시보드 일별/시간대별 집계 갱신
+		tables.StartDashboardRollupScheduler(time.Duration(cfg.Schedulers.DashboardRollupInterval) * time.Second)
+
+		// 열람실 전원 시간표
+		tables.StartPowerScheduler(time.Duration(cfg.Schedulers.PowerScheduleInterval) * time.Second)
+
+		// 반복 작업(cron). 여러 서버 중 advisory lock을 잡은 한 서버만 작업을 넣습니다.
+		utils.StartJobScheduler(time.Duration(cfg.Schedulers.JobSchedulerInterval) * time.Second)
+	}
+
+	// 변경 이벤트(outbox)를 naracontrol로 전달하는 릴레이 시작 (NARACONTROL_URL이 없거나 주기가 0이면 비활성화)
+	if cfg.Features.OutboxRelay {
+		utils.StartOutboxRelay(databaseURL, time.Duration(cfg.Schedulers.OutboxRelayInterval)*time.Second)
+	}
+
+	// 변경 피드 LISTEN 시작. 다른 서버에서 커밋된 변경도 이 서버의 SSE 구독자에게 전달됩니다.
+	if cfg.Features.ChangeFeed {
+		utils.StartChangeFeed(databaseURL)
+	}
+
+	// 서버 재시작으로 중단된 보고서 생성 재개
+	tables.ResumeReportRuns()
+
+	// 로깅, CORS(허용 출처 목록), 보안 헤더, 요청 본문 크기 제한 미들웨어를 함께 적용
+	cors := utils.CorsMiddleware(utils.CorsOptions{
+		AllowedOrigins:   cfg.CORS.AllowedOrigins,
+		AllowedMethods:   cfg.CORS.AllowedMethods,
+		AllowedHeaders:   cfg.CORS.AllowedHeaders,
+		ExposedHeaders:   cfg.CORS.ExposedHeaders,
+		AllowCredentials: cfg.CORS.AllowCredentials,
+		MaxAge:           cfg.CORS.MaxAge,
+	})
+	securityHeaders := utils.SecurityHeadersMiddleware(utils.SecurityHeaderOptions{
+		HSTSMaxAge:            cfg.Security.HSTSMaxAge,
+		HSTSIncludeSubdomains: cfg.Security.HSTSIncludeSubdomains,
+		ContentTypeNosniff:    cfg.Security.ContentTypeNosniff,
+		FrameOptions:          cfg.Security.FrameOptions,
+		ReferrerPolicy:        cfg.Security.ReferrerPolicy,
+	})
+	bodyLimit := utils.BodyLimitMiddleware(int64(cfg.Security.MaxBodyBytes), int64(cfg.Security.MaxUploadBytes))
+	handler := utils.LoggingMiddleware(securityHeaders(cors(bodyLimit(r))))
+	log.Printf("CORS 허용 출처: %v (인증 정보 포함: %t)", cfg.CORS.AllowedOrigins, cfg.CORS.AllowCredentials)
+	server := &http.Server{
+		Addr:              cfg.Server.ListenAddr,
+		Handler:           handler,
+		ReadHeaderTimeout: time.Duration(cfg.Server.ReadHeaderTimeout) * time.Second,
+		ReadTimeout:       time.Duration(cfg.Server.ReadTimeout) * time.Second,
+		WriteTimeout:      time.Duration(cfg.Server.WriteTimeout) * time.Second,
+		IdleTimeout:       time.Duration(cfg.Server.IdleTimeout) * time.Second,
+	}
+
+	log.Printf("🚀 [INIT] 서버가 %s 에서 실행 중입니다. (HTTPS: %t)", cfg.Server.ListenAddr, cfg.TLS.Enabled())
 	log.Printf("📡 [INIT] API 엔드포인트:")
 	log.Printf("   - GET /managers (매니저 목록 조회)")
 	log.Printf("   - GET /managers/{id} (특정 매니저 조회)")
 	log.Printf("🔄 [INIT] 요청 대기 중...")
-	log.Fatal(http.ListenAndServe(":8080", nil))
+	if cfg.TLS.Enabled() {
+		log.Fatal(server.ListenAndServeTLS(cfg.TLS.CertFile, cfg.TLS.KeyFile))
+	}
+	log.Fatal(server.ListenAndServe())
 }
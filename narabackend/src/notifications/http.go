@@ -0,0 +1,113 @@
+// http.go
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// HTTP_SEND_TIMEOUT은 대행사 API 호출 제한 시간입니다.
+const HTTP_SEND_TIMEOUT = 10 * time.Second
+
+// httpSendRequest는 문자/알림톡 중계 API로 보내는 요청 본문입니다.
+type httpSendRequest struct {
+	Channel     string `json:"channel"`
+	From        string `json:"from,omitempty"` // 문자 발신번호 또는 알림톡 발신 프로필 키
+	To          string `json:"to"`
+	Body        string `json:"body"`
+	TemplateKey string `json:"template_key,omitempty"`
+}
+
+// httpSendResponse는 중계 API 응답 본문입니다.
+type httpSendResponse struct {
+	MessageID string `json:"message_id"`
+}
+
+// HTTPSender는 문자/알림톡 대행사의 HTTP 중계 API로 발송하는 어댑터입니다.
+// 요청은 JSON으로 POST하고 Authorization: Bearer <API 키>로 인증합니다.
+// 4xx 응답은 ErrRejected로 간주하여 재시도하지 않고, 5xx와 연결 오류는 재시도합니다.
+type HTTPSender struct {
+	channel string
+	url     string
+	apiKey  string
+	from    string
+	client  *http.Client
+}
+
+// NewHTTPSender는 channel 메시지를 url로 중계하는 어댑터를 생성합니다.
+func NewHTTPSender(channel, url, apiKey, from string) *HTTPSender {
+	return &HTTPSender{
+		channel: channel,
+		url:     url,
+		apiKey:  apiKey,
+		from:    from,
+		client:  &http.Client{Timeout: HTTP_SEND_TIMEOUT},
+	}
+}
+
+// Name은 어댑터 이름(예: sms-http)을 반환합니다.
+func (s *HTTPSender) Name() string {
+	return s.channel + "-http"
+}
+
+// Send는 메시지를 중계 API로 보내고 응답의 message_id를 반환합니다.
+func (s *HTTPSender) Send(ctx context.Context, msg Message) (string, error) {
+	to := normalizePhone(msg.To)
+	if to == "" {
+		return "", ErrInvalidRecipient
+	}
+	body, err := json.Marshal(httpSendRequest{
+		Channel:     s.channel,
+		From:        s.from,
+		To:          to,
+		Body:        msg.Body,
+		TemplateKey: msg.TemplateKey,
+	})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 {
+		return "", fmt.Errorf("%w: %s", ErrRejected, strings.TrimSpace(resp.Status+" "+string(respBody)))
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("%s 발송 실패: %s", s.channel, resp.Status)
+	}
+	var result httpSendResponse
+	json.Unmarshal(respBody, &result)
+	return result.MessageID, nil
+}
+
+// normalizePhone은 전화번호에서 숫자만 남깁니다 (예: 010-1234-5678 -> 01012345678). 숫자가 너무 짧으면 빈 문자열입니다.
+func normalizePhone(phone string) string {
+	var b strings.Builder
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	if b.Len() < 8 {
+		return ""
+	}
+	return b.String()
+}
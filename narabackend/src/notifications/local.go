@@ -0,0 +1,69 @@
+// local.go
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// LOCAL_SENDER_NAME은 개발용 로컬 발송 어댑터의 이름입니다.
+const LOCAL_SENDER_NAME = "local"
+
+// LocalSender는 외부로 발송하지 않고 메시지를 JSON 한 줄씩 파일이나 표준 출력에 기록하는 개발용 어댑터입니다.
+type LocalSender struct {
+	mu  sync.Mutex
+	w   io.Writer
+	seq int64
+}
+
+// localRecord는 로컬 어댑터가 기록하는 한 줄입니다.
+type localRecord struct {
+	ID     string    `json:"id"`
+	SentAt time.Time `json:"sent_at"`
+	Message
+}
+
+// NewLocalSender는 path 파일에 이어서 기록하는 로컬 어댑터를 생성합니다. path가 비어 있거나 "-"이면 표준 출력에 기록합니다.
+func NewLocalSender(path string) (*LocalSender, error) {
+	if path == "" || path == "-" {
+		return &LocalSender{w: os.Stdout}, nil
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &LocalSender{w: f}, nil
+}
+
+// Name은 어댑터 이름을 반환합니다.
+func (s *LocalSender) Name() string {
+	return LOCAL_SENDER_NAME
+}
+
+// Send는 메시지를 기록하고 local-<순번> 형태의 메시지 ID를 반환합니다.
+func (s *LocalSender) Send(ctx context.Context, msg Message) (string, error) {
+	if msg.To == "" {
+		return "", ErrInvalidRecipient
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seq++
+	record := localRecord{
+		ID:      fmt.Sprintf("local-%d-%06d", time.Now().Unix(), s.seq),
+		SentAt:  time.Now(),
+		Message: msg,
+	}
+	line, err := json.Marshal(record)
+	if err != nil {
+		return "", err
+	}
+	if _, err := s.w.Write(append(line, '\n')); err != nil {
+		return "", err
+	}
+	return record.ID, nil
+}
@@ -0,0 +1,139 @@
+// notifications.go
+package notifications
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// 알림 채널
+const (
+	CHANNEL_SMS   = "sms"   // 문자 메시지
+	CHANNEL_KAKAO = "kakao" // 카카오 알림톡
+	CHANNEL_EMAIL = "email" // 이메일
+)
+
+// ALL_CHANNELS는 지원하는 알림 채널 목록입니다.
+var ALL_CHANNELS = []string{CHANNEL_KAKAO, CHANNEL_SMS, CHANNEL_EMAIL}
+
+var (
+	// ErrNoSender는 채널에 등록된 발송 어댑터가 없을 때 반환됩니다.
+	ErrNoSender = errors.New("알림 채널에 등록된 발송 어댑터가 없습니다")
+	// ErrUnknownChannel은 지원하지 않는 알림 채널인 경우 반환됩니다.
+	ErrUnknownChannel = errors.New("지원하지 않는 알림 채널입니다")
+	// ErrUnknownTemplate은 등록되지 않은 알림 템플릿인 경우 반환됩니다.
+	ErrUnknownTemplate = errors.New("등록되지 않은 알림 템플릿입니다")
+	// ErrInvalidRecipient는 수신자 주소(전화번호, 이메일)가 비어 있거나 형식이 맞지 않는 경우 반환됩니다.
+	ErrInvalidRecipient = errors.New("수신자 주소가 올바르지 않습니다")
+	// ErrRejected는 발송 대행사가 요청을 거절한 경우 반환됩니다. 다시 보내도 성공하지 않으므로 재시도하지 않습니다.
+	ErrRejected = errors.New("발송 대행사가 요청을 거절했습니다")
+)
+
+// Message는 채널로 발송할 알림 한 건입니다.
+type Message struct {
+	Channel     string `json:"channel"`
+	To          string `json:"to"`                // 전화번호 또는 이메일 주소
+	Subject     string `json:"subject,omitempty"` // 이메일 제목 (문자/알림톡은 사용하지 않음)
+	Body        string `json:"body"`
+	TemplateKey string `json:"template_key,omitempty"`
+}
+
+// Sender는 알림을 실제로 발송하는 채널 어댑터 인터페이스입니다.
+// 문자/알림톡/이메일 대행사는 이 인터페이스를 구현하는 어댑터로 추가합니다.
+type Sender interface {
+	// Name은 로그와 발송 기록에 사용하는 어댑터 식별자입니다.
+	Name() string
+	// Send는 메시지를 발송하고 대행사가 부여한 메시지 ID를 반환합니다.
+	// 다시 보내도 성공하지 않는 오류는 ErrRejected 또는 ErrInvalidRecipient를 감싸서 반환합니다.
+	Send(ctx context.Context, msg Message) (string, error)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Sender{}
+)
+
+// ValidChannel은 지원하는 알림 채널인지 반환합니다.
+func ValidChannel(channel string) bool {
+	for _, c := range ALL_CHANNELS {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// Register는 채널의 발송 어댑터를 등록합니다. 이미 등록되어 있으면 교체됩니다.
+func Register(channel string, s Sender) error {
+	if !ValidChannel(channel) {
+		return ErrUnknownChannel
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[channel] = s
+	return nil
+}
+
+// Get은 채널에 등록된 발송 어댑터를 반환합니다.
+func Get(channel string) (Sender, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	s, ok := registry[channel]
+	if !ok {
+		return nil, ErrNoSender
+	}
+	return s, nil
+}
+
+// Channels는 발송 어댑터가 등록된 채널 목록을 정렬하여 반환합니다.
+func Channels() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	channels := make([]string, 0, len(registry))
+	for channel := range registry {
+		channels = append(channels, channel)
+	}
+	sort.Strings(channels)
+	return channels
+}
+
+// Config는 채널별 발송 어댑터 설정입니다. main에서 config.NotificationConfig 값으로 채웁니다.
+type Config struct {
+	LocalPath      string     // 로컬 어댑터 기록 파일 (비어 있거나 "-"이면 표준 출력)
+	SMTP           SMTPConfig // Host가 있으면 이메일을 SMTP로 발송
+	SMSAPIURL      string     // 있으면 문자를 대행사 API로 발송
+	SMSAPIKey      string
+	SMSSender      string // 발신번호
+	KakaoAPIURL    string // 있으면 알림톡을 대행사 API로 발송
+	KakaoAPIKey    string
+	KakaoSenderKey string // 발신 프로필 키
+}
+
+// Configure는 설정으로 채널별 발송 어댑터를 등록합니다.
+// 모든 채널은 먼저 LocalPath 파일에 기록하는 로컬 어댑터로 등록되고, 대행사 설정이 있는 채널만 실제 어댑터로 교체됩니다.
+func Configure(cfg Config) error {
+	local, err := NewLocalSender(cfg.LocalPath)
+	if err != nil {
+		return err
+	}
+	for _, channel := range ALL_CHANNELS {
+		Register(channel, local)
+	}
+
+	if cfg.SMTP.Host != "" {
+		if cfg.SMTP.Port == "" {
+			cfg.SMTP.Port = strconv.Itoa(DEFAULT_SMTP_PORT)
+		}
+		Register(CHANNEL_EMAIL, NewSMTPSender(cfg.SMTP))
+	}
+	if cfg.SMSAPIURL != "" {
+		Register(CHANNEL_SMS, NewHTTPSender(CHANNEL_SMS, cfg.SMSAPIURL, cfg.SMSAPIKey, cfg.SMSSender))
+	}
+	if cfg.KakaoAPIURL != "" {
+		Register(CHANNEL_KAKAO, NewHTTPSender(CHANNEL_KAKAO, cfg.KakaoAPIURL, cfg.KakaoAPIKey, cfg.KakaoSenderKey))
+	}
+	return nil
+}
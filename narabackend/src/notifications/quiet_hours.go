@@ -0,0 +1,69 @@
+// quiet_hours.go
+package notifications
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidQuietHours는 방해 금지 시간 형식(HH:MM)이 올바르지 않은 경우 반환됩니다.
+var ErrInvalidQuietHours = errors.New("방해 금지 시간은 HH:MM 형식이어야 합니다")
+
+// QuietHours는 알림을 보내지 않는 하루 중 시간대입니다. Start가 End보다 늦으면 자정을 넘는 시간대입니다 (예: 22:00~08:00).
+// Start와 End가 같으면 방해 금지 시간이 없습니다.
+type QuietHours struct {
+	Start int // 0시부터의 분
+	End   int
+}
+
+// ParseQuietHours는 "HH:MM" 형식의 시작/종료 시각으로 방해 금지 시간을 만듭니다.
+func ParseQuietHours(start, end string) (QuietHours, error) {
+	s, err := parseClock(start)
+	if err != nil {
+		return QuietHours{}, err
+	}
+	e, err := parseClock(end)
+	if err != nil {
+		return QuietHours{}, err
+	}
+	return QuietHours{Start: s, End: e}, nil
+}
+
+// parseClock은 "HH:MM"을 0시부터의 분으로 변환합니다.
+func parseClock(v string) (int, error) {
+	t, err := time.Parse("15:04", v)
+	if err != nil {
+		return 0, ErrInvalidQuietHours
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// String은 "HH:MM-HH:MM" 형식으로 반환합니다.
+func (q QuietHours) String() string {
+	return fmt.Sprintf("%02d:%02d-%02d:%02d", q.Start/60, q.Start%60, q.End/60, q.End%60)
+}
+
+// Contains는 t(t의 시간대 기준)가 방해 금지 시간 안인지 반환합니다.
+func (q QuietHours) Contains(t time.Time) bool {
+	if q.Start == q.End {
+		return false
+	}
+	m := t.Hour()*60 + t.Minute()
+	if q.Start < q.End {
+		return m >= q.Start && m < q.End
+	}
+	return m >= q.Start || m < q.End
+}
+
+// NextAllowed는 t 이후 알림을 보낼 수 있는 가장 이른 시각을 반환합니다. 방해 금지 시간이 아니면 t 그대로입니다.
+func (q QuietHours) NextAllowed(t time.Time) time.Time {
+	if !q.Contains(t) {
+		return t
+	}
+	end := time.Date(t.Year(), t.Month(), t.Day(), q.End/60, q.End%60, 0, 0, t.Location())
+	if !end.After(t) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return end
+}
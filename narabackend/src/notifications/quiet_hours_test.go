@@ -0,0 +1,105 @@
+// quiet_hours_test.go
+package notifications
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func mustQuietHours(t *testing.T, start, end string) QuietHours {
+	t.Helper()
+	q, err := ParseQuietHours(start, end)
+	if err != nil {
+		t.Fatalf("방해 금지 시간 %s-%s 해석 실패: %v", start, end, err)
+	}
+	return q
+}
+
+func TestParseQuietHours(t *testing.T) {
+	q := mustQuietHours(t, "22:00", "08:30")
+	if q.Start != 22*60 || q.End != 8*60+30 || q.String() != "22:00-08:30" {
+		t.Fatalf("해석 결과가 다릅니다: %+v (%s)", q, q)
+	}
+	for _, v := range [][2]string{{"24:00", "08:00"}, {"22:00", "8"}, {"", "08:00"}, {"22:60", "08:00"}} {
+		if _, err := ParseQuietHours(v[0], v[1]); !errors.Is(err, ErrInvalidQuietHours) {
+			t.Errorf("%s-%s가 ErrInvalidQuietHours 없이 처리되었습니다: %v", v[0], v[1], err)
+		}
+	}
+}
+
+func TestQuietHoursContains(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2026, 3, 10, hour, minute, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name       string
+		start, end string
+		t          time.Time
+		want       bool
+	}{
+		{"자정 넘는 시간대 시작 시각", "22:00", "08:00", at(22, 0), true},
+		{"자정 넘는 시간대 시작 직전", "22:00", "08:00", at(21, 59), false},
+		{"자정 넘는 시간대 자정", "22:00", "08:00", at(0, 0), true},
+		{"자정 넘는 시간대 종료 직전", "22:00", "08:00", at(7, 59), true},
+		{"자정 넘는 시간대 종료 시각", "22:00", "08:00", at(8, 0), false},
+		{"자정 넘는 시간대 낮", "22:00", "08:00", at(13, 0), false},
+		{"하루 안 시간대 안", "12:00", "13:30", at(13, 29), true},
+		{"하루 안 시간대 종료 시각", "12:00", "13:30", at(13, 30), false},
+		{"하루 안 시간대 이전", "12:00", "13:30", at(11, 59), false},
+		{"시작과 종료가 같으면 없음", "08:00", "08:00", at(8, 0), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := mustQuietHours(t, tt.start, tt.end)
+			if got := q.Contains(tt.t); got != tt.want {
+				t.Errorf("%s에서 %s의 방해 금지 여부가 %v입니다 (기대값 %v)", q, tt.t.Format("15:04"), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuietHoursContainsUsesLocation(t *testing.T) {
+	q := mustQuietHours(t, "22:00", "08:00")
+	seoul := time.FixedZone("KST", 9*60*60)
+	// UTC 14:00은 서울 23:00입니다.
+	utc := time.Date(2026, 3, 10, 14, 0, 0, 0, time.UTC)
+	if q.Contains(utc) {
+		t.Error("UTC 14:00이 방해 금지 시간으로 판정되었습니다")
+	}
+	if !q.Contains(utc.In(seoul)) {
+		t.Error("서울 23:00이 방해 금지 시간으로 판정되지 않았습니다")
+	}
+}
+
+func TestQuietHoursNextAllowed(t *testing.T) {
+	seoul := time.FixedZone("KST", 9*60*60)
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 3, day, hour, minute, 0, 0, seoul)
+	}
+	tests := []struct {
+		name       string
+		start, end string
+		t          time.Time
+		want       time.Time
+	}{
+		{"방해 금지 시간 밖이면 그대로", "22:00", "08:00", at(10, 13, 5), at(10, 13, 5)},
+		{"자정 전이면 다음 날 종료 시각", "22:00", "08:00", at(10, 23, 30), at(11, 8, 0)},
+		{"자정 후면 같은 날 종료 시각", "22:00", "08:00", at(11, 2, 15), at(11, 8, 0)},
+		{"월말 자정 전이면 다음 달", "22:00", "08:00", at(31, 22, 0), time.Date(2026, 4, 1, 8, 0, 0, 0, seoul)},
+		{"하루 안 시간대", "12:00", "13:30", at(10, 12, 45), at(10, 13, 30)},
+		{"시작과 종료가 같으면 그대로", "08:00", "08:00", at(10, 8, 0), at(10, 8, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := mustQuietHours(t, tt.start, tt.end)
+			got := q.NextAllowed(tt.t)
+			if !got.Equal(tt.want) || got.Location() != seoul {
+				t.Errorf("%s에서 %s 다음 발송 가능 시각이 %s입니다 (기대값 %s)", q, tt.t, got, tt.want)
+			}
+			if q.Contains(got) {
+				t.Errorf("다음 발송 가능 시각 %s가 방해 금지 시간 안입니다", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+// smtp.go
+package notifications
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// SMTP_SENDER_NAME은 SMTP 이메일 발송 어댑터의 이름입니다.
+const SMTP_SENDER_NAME = "smtp"
+
+// DEFAULT_SMTP_PORT는 SMTP 포트를 지정하지 않았을 때 사용하는 포트(submission)입니다.
+const DEFAULT_SMTP_PORT = 587
+
+// SMTPConfig는 SMTP 서버 접속 정보입니다.
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string // 비어 있으면 인증하지 않습니다
+	Password string
+	From     string // 발신 주소 (예: "나라스마트 <no-reply@example.com>")
+}
+
+// SMTPSender는 SMTP 서버로 이메일을 발송하는 어댑터입니다.
+// 서버가 STARTTLS를 지원하면 암호화한 뒤 인증합니다.
+type SMTPSender struct {
+	cfg SMTPConfig
+}
+
+// NewSMTPSender는 SMTP 이메일 발송 어댑터를 생성합니다.
+func NewSMTPSender(cfg SMTPConfig) *SMTPSender {
+	return &SMTPSender{cfg: cfg}
+}
+
+// Name은 어댑터 이름을 반환합니다.
+func (s *SMTPSender) Name() string {
+	return SMTP_SENDER_NAME
+}
+
+// Send는 메시지를 UTF-8 평문 이메일로 발송하고 Message-ID를 반환합니다.
+// 서버가 5xx 응답으로 거절하면 ErrRejected를 감싸서 반환합니다.
+func (s *SMTPSender) Send(ctx context.Context, msg Message) (string, error) {
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidRecipient, err)
+	}
+	from, err := mail.ParseAddress(s.cfg.From)
+	if err != nil {
+		return "", fmt.Errorf("SMTP_FROM 주소가 올바르지 않습니다: %v", err)
+	}
+	messageID, err := newMessageID(from.Address)
+	if err != nil {
+		return "", err
+	}
+
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", net.JoinHostPort(s.cfg.Host, s.cfg.Port))
+	if err != nil {
+		return "", err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	c, err := smtp.NewClient(conn, s.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return "", err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: s.cfg.Host}); err != nil {
+			return "", err
+		}
+	}
+	if s.cfg.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)); err != nil {
+			return "", smtpError(err)
+		}
+	}
+	if err := c.Mail(from.Address); err != nil {
+		return "", smtpError(err)
+	}
+	if err := c.Rcpt(to.Address); err != nil {
+		return "", smtpError(err)
+	}
+	wc, err := c.Data()
+	if err != nil {
+		return "", smtpError(err)
+	}
+	if _, err := wc.Write(buildEmail(from, to, messageID, msg)); err != nil {
+		wc.Close()
+		return "", err
+	}
+	if err := wc.Close(); err != nil {
+		return "", smtpError(err)
+	}
+	c.Quit()
+	return messageID, nil
+}
+
+// smtpError는 서버의 영구 거절(5xx) 응답을 ErrRejected로 감쌉니다.
+func smtpError(err error) error {
+	var tpErr *textproto.Error
+	if errors.As(err, &tpErr) && tpErr.Code >= 500 {
+		return fmt.Errorf("%w: %v", ErrRejected, err)
+	}
+	return err
+}
+
+// newMessageID는 발신 도메인을 사용한 고유 Message-ID를 생성합니다.
+func newMessageID(fromAddress string) (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	domain := "localhost"
+	if i := strings.LastIndex(fromAddress, "@"); i >= 0 {
+		domain = fromAddress[i+1:]
+	}
+	return fmt.Sprintf("<%d.%s@%s>", time.Now().Unix(), hex.EncodeToString(b), domain), nil
+}
+
+// buildEmail은 한글 제목과 본문을 그대로 보낼 수 있도록 제목은 RFC 2047, 본문은 base64로 인코딩한 이메일을 만듭니다.
+func buildEmail(from, to *mail.Address, messageID string, msg Message) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + from.String() + "\r\n")
+	b.WriteString("To: " + to.String() + "\r\n")
+	b.WriteString("Subject: " + mime.BEncoding.Encode("UTF-8", msg.Subject) + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("Message-ID: " + messageID + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+
+	encoded := base64.StdEncoding.EncodeToString([]byte(msg.Body))
+	for len(encoded) > 76 {
+		b.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	b.WriteString(encoded + "\r\n")
+	return []byte(b.String())
+}
@@ -0,0 +1,197 @@
+// templates.go
+package notifications
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// DEFAULT_LOCALE은 회원 설정이 없을 때 사용하는 알림 언어입니다.
+const DEFAULT_LOCALE = "ko"
+
+// SUPPORTED_LOCALES는 모든 템플릿이 제공하는 알림 언어입니다.
+var SUPPORTED_LOCALES = []string{"ko", "en"}
+
+// 알림 템플릿 키
+const (
+	TEMPLATE_PASS_EXPIRY_REMINDER = "pass_expiry_reminder" // 이용권 만료 예정 안내
+	TEMPLATE_OUTING_WARNING       = "outing_warning"       // 외출 시간 임박/초과 경고
+	TEMPLATE_PASSWORD_RESET       = "password_reset"       // 비밀번호 재설정 코드
+	TEMPLATE_PAYMENT_RECEIPT      = "payment_receipt"      // 결제/환불 영수증
+)
+
+// Template은 알림 종류별 메시지 템플릿입니다.
+type Template struct {
+	Key         string   `json:"key"`
+	Description string   `json:"description"`
+	Channels    []string `json:"channels"` // 발송 채널 우선순위. 수신자가 받을 수 있는 첫 채널 하나로 보냅니다
+	Urgent      bool     `json:"urgent"`   // true면 방해 금지 시간에도 바로 보냅니다
+	Fields      []string `json:"fields"`   // 렌더링에 필요한 데이터 키 (Name, CompanyName은 수신자와 업체 정보로 채워집니다)
+	Locales     []string `json:"locales"`
+
+	subjects map[string]*template.Template
+	bodies   map[string]*template.Template
+}
+
+// Rendered는 템플릿을 데이터로 채운 결과입니다.
+type Rendered struct {
+	Subject string
+	Body    string
+}
+
+// templateFuncs는 템플릿에서 사용하는 함수입니다.
+var templateFuncs = template.FuncMap{
+	"won": FormatWon,
+}
+
+// templateSource는 언어별 제목과 본문 원문입니다.
+type templateSource struct {
+	subject string
+	body    string
+}
+
+var templates = map[string]*Template{}
+
+func init() {
+	define(Template{
+		Key:         TEMPLATE_PASS_EXPIRY_REMINDER,
+		Description: "이용권 만료 예정 안내",
+		Channels:    []string{CHANNEL_KAKAO, CHANNEL_SMS, CHANNEL_EMAIL},
+		Fields:      []string{"CompanyName", "Name", "PlanName", "ExpiresAt", "DaysLeft"},
+	}, map[string]templateSource{
+		"ko": {
+			subject: "[{{.CompanyName}}] 이용권 만료 안내",
+			body:    "{{.Name}}님, {{.CompanyName}} {{.PlanName}} 이용권이 {{.ExpiresAt}}에 만료됩니다({{.DaysLeft}}일 남음). 계속 이용하시려면 기간을 연장해 주세요.",
+		},
+		"en": {
+			subject: "[{{.CompanyName}}] Your pass is expiring",
+			body:    "Hi {{.Name}}, your {{.PlanName}} pass at {{.CompanyName}} expires on {{.ExpiresAt}} ({{.DaysLeft}} day(s) left). Please renew to keep using it.",
+		},
+	})
+	define(Template{
+		Key:         TEMPLATE_OUTING_WARNING,
+		Description: "외출 시간 임박/초과 경고",
+		Channels:    []string{CHANNEL_KAKAO, CHANNEL_SMS},
+		Fields:      []string{"CompanyName", "Name", "SeatNumber", "MaxOutingMinutes", "Deadline", "Overrun"},
+	}, map[string]templateSource{
+		"ko": {
+			subject: "[{{.CompanyName}}] 외출 시간 안내",
+			body: "{{.Name}}님, {{if .Overrun}}{{.SeatNumber}}번 좌석의 최대 외출 시간({{.MaxOutingMinutes}}분)이 지났습니다. 좌석이 해제되거나 위약금이 부과될 수 있습니다." +
+				"{{else}}{{.SeatNumber}}번 좌석의 최대 외출 시간({{.MaxOutingMinutes}}분)이 곧 끝납니다. {{.Deadline}}까지 복귀해 주세요.{{end}}",
+		},
+		"en": {
+			subject: "[{{.CompanyName}}] Outing time notice",
+			body: "Hi {{.Name}}, {{if .Overrun}}the maximum outing time ({{.MaxOutingMinutes}} min) for seat {{.SeatNumber}} has passed. The seat may be released or a penalty may be charged." +
+				"{{else}}the maximum outing time ({{.MaxOutingMinutes}} min) for seat {{.SeatNumber}} is almost over. Please return by {{.Deadline}}.{{end}}",
+		},
+	})
+	define(Template{
+		Key:         TEMPLATE_PASSWORD_RESET,
+		Description: "비밀번호 재설정 코드",
+		Channels:    []string{CHANNEL_EMAIL, CHANNEL_SMS},
+		Urgent:      true,
+		Fields:      []string{"Name", "Code", "ExpiresMinutes", "ResetURL"},
+	}, map[string]templateSource{
+		"ko": {
+			subject: "비밀번호 재설정 안내",
+			body: "{{.Name}}님, 비밀번호 재설정 코드는 {{.Code}} 입니다. {{.ExpiresMinutes}}분 안에 입력해 주세요." +
+				"{{if .ResetURL}}\n재설정 페이지: {{.ResetURL}}{{end}}\n본인이 요청하지 않았다면 이 메시지를 무시하세요.",
+		},
+		"en": {
+			subject: "Reset your password",
+			body: "Hi {{.Name}}, your password reset code is {{.Code}}. It expires in {{.ExpiresMinutes}} minutes." +
+				"{{if .ResetURL}}\nReset page: {{.ResetURL}}{{end}}\nIf you did not request this, you can ignore this message.",
+		},
+	})
+	define(Template{
+		Key:         TEMPLATE_PAYMENT_RECEIPT,
+		Description: "결제/환불 영수증",
+		Channels:    []string{CHANNEL_KAKAO, CHANNEL_EMAIL, CHANNEL_SMS},
+		Fields:      []string{"CompanyName", "Name", "PaymentID", "Amount", "PaymentMethod", "Description", "PaidAt", "IsRefund"},
+	}, map[string]templateSource{
+		"ko": {
+			subject: "[{{.CompanyName}}] {{if .IsRefund}}환불{{else}}결제{{end}} 영수증",
+			body: "{{.Name}}님, {{.CompanyName}} {{if .IsRefund}}환불{{else}}결제{{end}} 내역입니다.\n" +
+				"거래번호: {{.PaymentID}}\n금액: {{won .Amount}}\n결제수단: {{.PaymentMethod}}\n내용: {{.Description}}\n일시: {{.PaidAt}}",
+		},
+		"en": {
+			subject: "[{{.CompanyName}}] {{if .IsRefund}}Refund{{else}}Payment{{end}} receipt",
+			body: "Hi {{.Name}}, here is your {{if .IsRefund}}refund{{else}}payment{{end}} receipt from {{.CompanyName}}.\n" +
+				"Transaction: {{.PaymentID}}\nAmount: {{won .Amount}}\nMethod: {{.PaymentMethod}}\nDescription: {{.Description}}\nDate: {{.PaidAt}}",
+		},
+	})
+}
+
+// define은 언어별 원문을 파싱하여 템플릿을 등록합니다. 데이터에 없는 키를 참조하면 렌더링 오류가 나도록 설정합니다.
+func define(t Template, sources map[string]templateSource) {
+	t.subjects = map[string]*template.Template{}
+	t.bodies = map[string]*template.Template{}
+	for locale, src := range sources {
+		name := t.Key + "." + locale
+		t.subjects[locale] = template.Must(template.New(name + ".subject").Option("missingkey=error").Funcs(templateFuncs).Parse(src.subject))
+		t.bodies[locale] = template.Must(template.New(name + ".body").Option("missingkey=error").Funcs(templateFuncs).Parse(src.body))
+		t.Locales = append(t.Locales, locale)
+	}
+	sort.Strings(t.Locales)
+	templates[t.Key] = &t
+}
+
+// ValidLocale은 지원하는 알림 언어인지 반환합니다.
+func ValidLocale(locale string) bool {
+	for _, l := range SUPPORTED_LOCALES {
+		if l == locale {
+			return true
+		}
+	}
+	return false
+}
+
+// Lookup은 키로 템플릿을 조회합니다.
+func Lookup(key string) (*Template, error) {
+	t, ok := templates[key]
+	if !ok {
+		return nil, ErrUnknownTemplate
+	}
+	return t, nil
+}
+
+// Templates는 등록된 템플릿 목록을 키 순으로 반환합니다.
+func Templates() []*Template {
+	list := make([]*Template, 0, len(templates))
+	for _, t := range templates {
+		list = append(list, t)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Key < list[j].Key })
+	return list
+}
+
+// Render는 locale 언어로 템플릿을 렌더링합니다. 해당 언어가 없으면 DEFAULT_LOCALE(한국어)을 사용합니다.
+func (t *Template) Render(locale string, data map[string]interface{}) (Rendered, error) {
+	if _, ok := t.bodies[locale]; !ok {
+		locale = DEFAULT_LOCALE
+	}
+	var subject, body strings.Builder
+	if err := t.subjects[locale].Execute(&subject, data); err != nil {
+		return Rendered{}, err
+	}
+	if err := t.bodies[locale].Execute(&body, data); err != nil {
+		return Rendered{}, err
+	}
+	return Rendered{Subject: subject.String(), Body: body.String()}, nil
+}
+
+// FormatWon은 금액을 천 단위 구분 기호와 함께 원 단위로 표시합니다 (예: 12000 -> 12,000원).
+func FormatWon(amount int) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	s := strconv.Itoa(amount)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return sign + s + "원"
+}
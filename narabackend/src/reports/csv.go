@@ -0,0 +1,54 @@
+// csv.go
+package reports
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// utf8BOM은 엑셀에서 한글 CSV가 깨지지 않도록 파일 앞에 붙이는 바이트 순서 표시입니다.
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// WriteCSV는 보고서 결과를 CSV로 씁니다. 첫 행은 열 제목입니다.
+func WriteCSV(w io.Writer, t Table) error {
+	if _, err := w.Write(utf8BOM); err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+
+	header := make([]string, len(t.Columns))
+	for i, c := range t.Columns {
+		header[i] = c.Title
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	record := make([]string, len(t.Columns))
+	for _, row := range t.Rows {
+		for i, v := range row {
+			record[i] = formatCell(v)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// formatCell은 셀 값을 문자열로 변환합니다. 실수는 소수 둘째 자리까지 표시합니다.
+func formatCell(v interface{}) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case int64:
+		return strconv.FormatInt(t, 10)
+	case float64:
+		return strconv.FormatFloat(t, 'f', 2, 64)
+	default:
+		return fmt.Sprint(t)
+	}
+}
@@ -0,0 +1,54 @@
+// csv_test.go
+package reports
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"testing"
+)
+
+func TestWriteCSV(t *testing.T) {
+	table := Table{
+		Columns: []Column{{Key: "name", Title: "이름"}, {Key: "count", Title: "횟수"}, {Key: "amount", Title: "금액"}},
+		Rows: [][]interface{}{
+			{"김나라", int64(3), 1234.5},
+			{`쉼표, "따옴표"` + "\n줄바꿈", int64(-1), 0.126},
+		},
+	}
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, table); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.Bytes()
+	if !bytes.HasPrefix(out, utf8BOM) {
+		t.Fatalf("CSV 앞에 UTF-8 BOM이 없습니다: % x", out[:3])
+	}
+	if bytes.Count(out, utf8BOM) != 1 {
+		t.Fatal("UTF-8 BOM이 한 번만 기록되어야 합니다")
+	}
+
+	records, err := csv.NewReader(bytes.NewReader(out[len(utf8BOM):])).ReadAll()
+	if err != nil {
+		t.Fatalf("CSV를 다시 읽지 못했습니다: %v", err)
+	}
+	want := [][]string{
+		{"이름", "횟수", "금액"},
+		{"김나라", "3", "1234.50"},
+		{`쉼표, "따옴표"` + "\n줄바꿈", "-1", "0.13"},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Fatalf("CSV 내용이 다릅니다:\n%q\n기대값:\n%q", records, want)
+	}
+}
+
+func TestWriteCSVEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, Table{Columns: []Column{{Key: "a", Title: "A"}}}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), string(utf8BOM)+"A\n"; got != want {
+		t.Fatalf("행이 없는 CSV가 %q입니다 (기대값 %q)", got, want)
+	}
+}
@@ -0,0 +1,146 @@
+// definitions.go
+package reports
+
+// 보고서 정의 목록
+// 모든 쿼리는 $1(company_code)로 업체 범위를 제한하고, $2~$3 일자(포함) 범위를 사용합니다.
+// 퇴실하지 않은 세션은 현재 시각까지 이용한 것으로 계산합니다.
+func init() {
+	register(Definition{
+		Name:        "room_hourly_occupancy",
+		Title:       "열람실 시간대별 점유율",
+		Description: "열람실별, 시간대(0~23시)별 평균 이용 좌석 수와 점유율",
+		Columns: []Column{
+			{Key: "room_code", Title: "열람실"},
+			{Key: "hour", Title: "시간대"},
+			{Key: "seat_count", Title: "좌석 수"},
+			{Key: "avg_occupied_seats", Title: "평균 이용 좌석"},
+			{Key: "occupancy_rate", Title: "점유율(%)"},
+		},
+		Query: `
+			WITH hours AS (
+				SELECT gs AS hour_start
+				FROM generate_series($2::date::timestamp, $3::date + INTERVAL '23 hours', INTERVAL '1 hour') AS gs
+			),
+			rooms AS (
+				SELECT room_code, COUNT(*) AS seat_count
+				FROM seat_table WHERE company_code = $1 AND seat_code IS NOT NULL
+				GROUP BY room_code
+			),
+			usage AS (
+				SELECT st.room_code, EXTRACT(HOUR FROM h.hour_start)::INTEGER AS hour,
+				       SUM(EXTRACT(EPOCH FROM LEAST(COALESCE(s.check_out_at, LOCALTIMESTAMP), h.hour_start + INTERVAL '1 hour')
+				           - GREATEST(s.check_in_at, h.hour_start)) / 60) AS seat_minutes
+				FROM hours h
+				JOIN seat_session_table s
+				  ON s.company_code = $1 AND s.check_in_at < h.hour_start + INTERVAL '1 hour'
+				 AND COALESCE(s.check_out_at, LOCALTIMESTAMP) > h.hour_start
+				JOIN seat_table st ON st.company_code = s.company_code AND st.seat_code = s.seat_code
+				GROUP BY st.room_code, 2
+			)
+			SELECT r.room_code, hr.hour, r.seat_count,
+			       ROUND(COALESCE(u.seat_minutes, 0) / (60.0 * ($3::date - $2::date + 1)), 2)::float8,
+			       ROUND(CASE WHEN r.seat_count > 0
+			             THEN 100 * COALESCE(u.seat_minutes, 0) / (60.0 * ($3::date - $2::date + 1) * r.seat_count)
+			             ELSE 0 END, 2)::float8
+			FROM rooms r
+			CROSS JOIN generate_series(0, 23) AS hr(hour)
+			LEFT JOIN usage u ON u.room_code = r.room_code AND u.hour = hr.hour
+			ORDER BY r.room_code, hr.hour`,
+	})
+
+	register(Definition{
+		Name:        "stay_length",
+		Title:       "일별 평균 이용 시간",
+		Description: "퇴실 완료된 세션 기준 일별 평균/중앙값/최대 이용 시간(분)",
+		Columns: []Column{
+			{Key: "date", Title: "일자"},
+			{Key: "session_count", Title: "이용 건수"},
+			{Key: "member_count", Title: "이용 회원 수"},
+			{Key: "avg_minutes", Title: "평균 이용(분)"},
+			{Key: "median_minutes", Title: "중앙값(분)"},
+			{Key: "max_minutes", Title: "최대(분)"},
+		},
+		Query: `
+			SELECT to_char(s.check_in_at, 'YYYY-MM-DD'), COUNT(*), COUNT(DISTINCT s.member_id),
+			       ROUND(AVG(s.used_minutes), 1)::float8,
+			       PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY s.used_minutes)::float8,
+			       MAX(s.used_minutes)::BIGINT
+			FROM seat_session_table s
+			WHERE s.company_code = $1 AND s.check_out_at IS NOT NULL
+			  AND s.check_in_at >= $2::date AND s.check_in_at < $3::date + 1
+			GROUP BY 1
+			ORDER BY 1`,
+	})
+
+	register(Definition{
+		Name:        "revenue_by_plan_method",
+		Title:       "상품 및 결제수단별 매출",
+		Description: "이용권 상품과 결제 수단별 결제/환불 건수와 금액, 순매출",
+		Columns: []Column{
+			{Key: "plan_name", Title: "상품"},
+			{Key: "payment_method", Title: "결제 수단"},
+			{Key: "payment_count", Title: "결제 건수"},
+			{Key: "gross_amount", Title: "결제 금액"},
+			{Key: "refund_count", Title: "환불 건수"},
+			{Key: "refund_amount", Title: "환불 금액"},
+			{Key: "net_amount", Title: "순매출"},
+		},
+		Query: `
+			SELECT COALESCE(pl.plan_name, '(상품 없음)'), p.payment_method,
+			       COUNT(*) FILTER (WHERE p.payment_type <> 'refund'),
+			       COALESCE(SUM(p.amount) FILTER (WHERE p.payment_type <> 'refund'), 0),
+			       COUNT(*) FILTER (WHERE p.payment_type = 'refund'),
+			       COALESCE(SUM(p.amount) FILTER (WHERE p.payment_type = 'refund'), 0),
+			       COALESCE(SUM(CASE WHEN p.payment_type = 'refund' THEN -p.amount ELSE p.amount END), 0)
+			FROM payment_table p
+			LEFT JOIN member_pass_table mp ON mp.serial_number = p.pass_id
+			LEFT JOIN plan_table pl ON pl.serial_number = mp.plan_id
+			WHERE p.company_code = $1 AND p.created_at >= $2::date AND p.created_at < $3::date + 1
+			GROUP BY 1, 2
+			ORDER BY 1, 2`,
+	})
+
+	register(Definition{
+		Name:        "member_retention",
+		Title:       "월별 회원 유지율",
+		Description: "월별 이용 회원, 신규 회원, 전월 대비 재방문 회원 수와 유지율",
+		Columns: []Column{
+			{Key: "month", Title: "월"},
+			{Key: "active_members", Title: "이용 회원"},
+			{Key: "new_members", Title: "신규 회원"},
+			{Key: "retained_members", Title: "전월 재방문 회원"},
+			{Key: "previous_active_members", Title: "전월 이용 회원"},
+			{Key: "retention_rate", Title: "유지율(%)"},
+		},
+		Query: `
+			WITH activity AS (
+				SELECT DISTINCT member_id, date_trunc('month', check_in_at)::date AS month
+				FROM seat_session_table
+				WHERE company_code = $1 AND check_in_at < $3::date + 1
+			),
+			first_month AS (
+				SELECT member_id, MIN(month) AS first_month FROM activity GROUP BY member_id
+			),
+			months AS (
+				SELECT gs::date AS month
+				FROM generate_series(date_trunc('month', $2::date), date_trunc('month', $3::date), INTERVAL '1 month') AS gs
+			),
+			summary AS (
+				SELECT m.month,
+				       COUNT(a.member_id) AS active_members,
+				       COUNT(a.member_id) FILTER (WHERE f.first_month = m.month) AS new_members,
+				       COUNT(prev.member_id) AS retained_members,
+				       (SELECT COUNT(*) FROM activity pa WHERE pa.month = (m.month - INTERVAL '1 month')::date) AS previous_active_members
+				FROM months m
+				LEFT JOIN activity a ON a.month = m.month
+				LEFT JOIN first_month f ON f.member_id = a.member_id
+				LEFT JOIN activity prev ON prev.member_id = a.member_id AND prev.month = (m.month - INTERVAL '1 month')::date
+				GROUP BY m.month
+			)
+			SELECT to_char(month, 'YYYY-MM'), active_members, new_members, retained_members, previous_active_members,
+			       ROUND(CASE WHEN previous_active_members > 0
+			             THEN 100.0 * retained_members / previous_active_members ELSE 0 END, 2)::float8
+			FROM summary
+			ORDER BY month`,
+	})
+}
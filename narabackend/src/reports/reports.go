@@ -0,0 +1,153 @@
+// reports.go
+package reports
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"time"
+)
+
+// 출력 형식
+const (
+	FORMAT_JSON = "json"
+	FORMAT_CSV  = "csv"
+	FORMAT_XLSX = "xlsx"
+)
+
+var (
+	// ErrUnknownReport는 등록되지 않은 보고서 이름으로 조회할 때 반환됩니다.
+	ErrUnknownReport = errors.New("등록되지 않은 보고서입니다")
+	// ErrUnknownFormat은 지원하지 않는 출력 형식을 요청할 때 반환됩니다.
+	ErrUnknownFormat = errors.New("지원하지 않는 출력 형식입니다")
+)
+
+// Querier는 보고서 쿼리에 필요한 DB 조회 기능입니다 (*sql.DB, *sql.Tx 모두 사용 가능).
+type Querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// Params는 보고서 실행 조건입니다. 모든 보고서는 CompanyCode 한 업체로 범위가 제한됩니다.
+// 기간은 From(포함) ~ To(포함) 일자입니다.
+type Params struct {
+	CompanyCode string    `json:"company_code"`
+	From        time.Time `json:"from"`
+	To          time.Time `json:"to"`
+}
+
+// Days는 조회 기간의 일수를 반환합니다.
+func (p Params) Days() int {
+	return int(p.To.Sub(p.From).Hours()/24) + 1
+}
+
+// Column은 보고서 결과 열 정의입니다.
+type Column struct {
+	Key   string `json:"key"`
+	Title string `json:"title"`
+}
+
+// Table은 보고서 실행 결과입니다. Rows의 각 값은 string, int64, float64 중 하나입니다.
+type Table struct {
+	Columns []Column        `json:"columns"`
+	Rows    [][]interface{} `json:"rows"`
+}
+
+// Definition은 파라미터화된 보고서 정의입니다.
+type Definition struct {
+	Name        string `json:"name"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Columns     []Column
+	Query       string // $1=company_code, $2=from(date), $3=to(date, 포함)
+}
+
+// Run은 보고서 쿼리를 실행하여 결과 표를 만듭니다.
+func (d Definition) Run(ctx context.Context, q Querier, p Params) (Table, error) {
+	table := Table{Columns: d.Columns, Rows: [][]interface{}{}}
+	rows, err := q.QueryContext(ctx, d.Query, p.CompanyCode, p.From.Format("2006-01-02"), p.To.Format("2006-01-02"))
+	if err != nil {
+		return table, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		values := make([]interface{}, len(d.Columns))
+		ptrs := make([]interface{}, len(d.Columns))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return table, err
+		}
+		for i, v := range values {
+			values[i] = normalizeValue(v)
+		}
+		table.Rows = append(table.Rows, values)
+	}
+	return table, rows.Err()
+}
+
+// normalizeValue는 드라이버가 돌려준 값을 출력 가능한 string/int64/float64로 변환합니다.
+func normalizeValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(t)
+	case time.Time:
+		return t.Format("2006-01-02")
+	case bool:
+		if t {
+			return int64(1)
+		}
+		return int64(0)
+	default:
+		return t
+	}
+}
+
+// registry는 이름으로 보고서 정의를 찾기 위한 목록입니다.
+var registry = map[string]Definition{}
+
+// register는 보고서 정의를 등록합니다. definitions.go의 init에서 호출됩니다.
+func register(d Definition) {
+	registry[d.Name] = d
+}
+
+// Get은 이름으로 보고서 정의를 조회합니다.
+func Get(name string) (Definition, error) {
+	d, ok := registry[name]
+	if !ok {
+		return d, ErrUnknownReport
+	}
+	return d, nil
+}
+
+// List는 등록된 보고서 정의를 이름 순으로 반환합니다.
+func List() []Definition {
+	list := make([]Definition, 0, len(registry))
+	for _, d := range registry {
+		list = append(list, d)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// ContentType은 출력 형식의 MIME 타입을 반환합니다.
+func ContentType(format string) (string, error) {
+	switch format {
+	case FORMAT_JSON:
+		return "application/json", nil
+	case FORMAT_CSV:
+		return "text/csv; charset=utf-8", nil
+	case FORMAT_XLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", nil
+	}
+	return "", ErrUnknownFormat
+}
+
+// FileName은 다운로드 파일 이름을 만듭니다.
+func FileName(d Definition, p Params, format string) string {
+	return d.Name + "_" + p.CompanyCode + "_" + p.From.Format("20060102") + "-" + p.To.Format("20060102") + "." + format
+}
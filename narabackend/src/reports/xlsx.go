@@ -0,0 +1,145 @@
+// xlsx.go
+package reports
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// xlsxStaticParts는 시트 하나로 구성된 최소 XLSX(Office Open XML) 패키지의 고정 파일입니다.
+var xlsxStaticParts = []struct {
+	name    string
+	content string
+}{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+</Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+</Relationships>`},
+}
+
+// WriteXLSX는 보고서 결과를 시트 하나짜리 XLSX 파일로 씁니다.
+// 외부 라이브러리 없이 문자열은 인라인 문자열, 숫자는 숫자 셀로 기록합니다.
+func WriteXLSX(w io.Writer, sheetName string, t Table) error {
+	zw := zip.NewWriter(w)
+
+	for _, part := range xlsxStaticParts {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return err
+		}
+	}
+
+	f, err := zw.Create("xl/workbook.xml")
+	if err != nil {
+		return err
+	}
+	var workbook bytes.Buffer
+	workbook.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="`)
+	xml.EscapeText(&workbook, []byte(xlsxSheetName(sheetName)))
+	workbook.WriteString(`" sheetId="1" r:id="rId1"/></sheets></workbook>`)
+	if _, err := f.Write(workbook.Bytes()); err != nil {
+		return err
+	}
+
+	f, err = zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	if err := writeXLSXSheet(f, t); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// writeXLSXSheet는 sheet1.xml 본문을 씁니다. 첫 행은 열 제목입니다.
+func writeXLSXSheet(w io.Writer, t Table) error {
+	var buf bytes.Buffer
+	buf.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	header := make([]interface{}, len(t.Columns))
+	for i, c := range t.Columns {
+		header[i] = c.Title
+	}
+	writeXLSXRow(&buf, 1, header)
+	for i, row := range t.Rows {
+		writeXLSXRow(&buf, i+2, row)
+		// 큰 보고서에서 메모리 사용을 줄이기 위해 주기적으로 내보냅니다.
+		if buf.Len() > 64*1024 {
+			if _, err := w.Write(buf.Bytes()); err != nil {
+				return err
+			}
+			buf.Reset()
+		}
+	}
+
+	buf.WriteString(`</sheetData></worksheet>`)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// writeXLSXRow는 한 행을 씁니다.
+func writeXLSXRow(buf *bytes.Buffer, rowNum int, values []interface{}) {
+	row := strconv.Itoa(rowNum)
+	buf.WriteString(`<row r="` + row + `">`)
+	for i, v := range values {
+		ref := xlsxColumnName(i) + row
+		switch t := v.(type) {
+		case int64:
+			buf.WriteString(`<c r="` + ref + `"><v>` + strconv.FormatInt(t, 10) + `</v></c>`)
+		case float64:
+			buf.WriteString(`<c r="` + ref + `"><v>` + strconv.FormatFloat(t, 'f', -1, 64) + `</v></c>`)
+		default:
+			buf.WriteString(`<c r="` + ref + `" t="inlineStr"><is><t>`)
+			xml.EscapeText(buf, []byte(formatCell(v)))
+			buf.WriteString(`</t></is></c>`)
+		}
+	}
+	buf.WriteString(`</row>`)
+}
+
+// xlsxColumnName은 0부터 시작하는 열 번호를 A, B, ..., Z, AA 형식으로 변환합니다.
+func xlsxColumnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+// xlsxSheetName은 엑셀 시트 이름 제한(31자, 일부 특수문자 금지)에 맞게 이름을 정리합니다.
+func xlsxSheetName(name string) string {
+	runes := []rune(strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`:\/?*[]`, r) {
+			return '_'
+		}
+		return r
+	}, name))
+	if len(runes) > 31 {
+		runes = runes[:31]
+	}
+	if len(runes) == 0 {
+		return "Sheet1"
+	}
+	return string(runes)
+}
@@ -0,0 +1,168 @@
+// xlsx_test.go
+package reports
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+// xlsxCell은 sheet1.xml의 셀 하나입니다. 인라인 문자열은 Text, 숫자는 Value에 들어갑니다.
+type xlsxCell struct {
+	Ref   string `xml:"r,attr"`
+	Type  string `xml:"t,attr"`
+	Value string `xml:"v"`
+	Text  string `xml:"is>t"`
+}
+
+type xlsxSheet struct {
+	Rows []struct {
+		Num   string     `xml:"r,attr"`
+		Cells []xlsxCell `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// readXLSXPart는 XLSX 패키지에서 파일 하나를 읽습니다.
+func readXLSXPart(t *testing.T, data []byte, name string) []byte {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("XLSX를 zip으로 열지 못했습니다: %v", err)
+	}
+	for _, f := range zr.File {
+		if f.Name != name {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rc.Close()
+		b, err := io.ReadAll(rc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+	t.Fatalf("XLSX에 %s가 없습니다", name)
+	return nil
+}
+
+func TestXLSXColumnName(t *testing.T) {
+	for index, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"} {
+		if got := xlsxColumnName(index); got != want {
+			t.Errorf("열 %d의 이름이 %s입니다 (기대값 %s)", index, got, want)
+		}
+	}
+}
+
+func TestXLSXSheetName(t *testing.T) {
+	for name, want := range map[string]string{
+		"":                      "Sheet1",
+		"매출/환불 [3월]":            "매출_환불 _3월_",
+		strings.Repeat("가", 40): strings.Repeat("가", 31),
+	} {
+		if got := xlsxSheetName(name); got != want {
+			t.Errorf("시트 이름 %q가 %q로 정리되었습니다 (기대값 %q)", name, got, want)
+		}
+	}
+}
+
+func TestWriteXLSX(t *testing.T) {
+	// 열 28개로 Z를 넘어 AA, AB 열까지 기록합니다.
+	columns := make([]Column, 28)
+	row := make([]interface{}, 28)
+	for i := range columns {
+		columns[i] = Column{Key: fmt.Sprintf("c%d", i), Title: fmt.Sprintf("열%d", i)}
+		row[i] = fmt.Sprintf("v%d", i)
+	}
+	columns[0].Title = `<이름 & "따옴표">`
+	row[0] = "A&B <C>"
+	row[1] = int64(42)
+	row[2] = 12.25
+	row[27] = "마지막"
+	table := Table{Columns: columns, Rows: [][]interface{}{row}}
+
+	var buf bytes.Buffer
+	if err := WriteXLSX(&buf, "3월 <매출>", table); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/_rels/workbook.xml.rels"} {
+		if err := xml.Unmarshal(readXLSXPart(t, data, name), new(struct{})); err != nil {
+			t.Errorf("%s가 올바른 XML이 아닙니다: %v", name, err)
+		}
+	}
+
+	var workbook struct {
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := xml.Unmarshal(readXLSXPart(t, data, "xl/workbook.xml"), &workbook); err != nil {
+		t.Fatalf("workbook.xml이 올바른 XML이 아닙니다: %v", err)
+	}
+	if len(workbook.Sheets) != 1 || workbook.Sheets[0].Name != "3월 <매출>" {
+		t.Fatalf("시트 이름이 다릅니다: %+v", workbook.Sheets)
+	}
+
+	var sheet xlsxSheet
+	if err := xml.Unmarshal(readXLSXPart(t, data, "xl/worksheets/sheet1.xml"), &sheet); err != nil {
+		t.Fatalf("sheet1.xml이 올바른 XML이 아닙니다: %v", err)
+	}
+	if len(sheet.Rows) != 2 || sheet.Rows[0].Num != "1" || sheet.Rows[1].Num != "2" {
+		t.Fatalf("행 구성이 다릅니다: %+v", sheet.Rows)
+	}
+	header, cells := sheet.Rows[0].Cells, sheet.Rows[1].Cells
+	if len(header) != 28 || len(cells) != 28 {
+		t.Fatalf("열 수가 다릅니다: 제목 %d, 값 %d", len(header), len(cells))
+	}
+
+	if header[0].Ref != "A1" || header[0].Text != `<이름 & "따옴표">` {
+		t.Errorf("제목 셀이 다릅니다: %+v", header[0])
+	}
+	for i, want := range map[int]string{25: "Z2", 26: "AA2", 27: "AB2"} {
+		if cells[i].Ref != want {
+			t.Errorf("%d번째 열의 셀 참조가 %s입니다 (기대값 %s)", i, cells[i].Ref, want)
+		}
+	}
+	if c := cells[0]; c.Type != "inlineStr" || c.Text != "A&B <C>" {
+		t.Errorf("문자열 셀이 다릅니다: %+v", c)
+	}
+	if c := cells[1]; c.Type != "" || c.Value != "42" {
+		t.Errorf("정수 셀이 다릅니다: %+v", c)
+	}
+	if c := cells[2]; c.Type != "" || c.Value != "12.25" {
+		t.Errorf("실수 셀이 다릅니다: %+v", c)
+	}
+	if c := cells[27]; c.Text != "마지막" {
+		t.Errorf("마지막 열 셀이 다릅니다: %+v", c)
+	}
+}
+
+func TestWriteXLSXLargeTable(t *testing.T) {
+	// 64KB마다 나누어 쓰는 경계를 넘겨도 XML이 이어져야 합니다.
+	table := Table{Columns: []Column{{Key: "text", Title: "내용"}, {Key: "n", Title: "번호"}}}
+	for i := 0; i < 3000; i++ {
+		table.Rows = append(table.Rows, []interface{}{strings.Repeat("가", 10) + " & <x>", int64(i)})
+	}
+	var buf bytes.Buffer
+	if err := WriteXLSX(&buf, "큰 보고서", table); err != nil {
+		t.Fatal(err)
+	}
+	var sheet xlsxSheet
+	if err := xml.Unmarshal(readXLSXPart(t, buf.Bytes(), "xl/worksheets/sheet1.xml"), &sheet); err != nil {
+		t.Fatalf("sheet1.xml이 올바른 XML이 아닙니다: %v", err)
+	}
+	if len(sheet.Rows) != 3001 {
+		t.Fatalf("행 수가 %d입니다 (기대값 3001)", len(sheet.Rows))
+	}
+	if last := sheet.Rows[3000].Cells; last[1].Ref != "B3001" || last[1].Value != "2999" {
+		t.Fatalf("마지막 행이 다릅니다: %+v", last)
+	}
+}
@@ -0,0 +1,104 @@
+// local.go
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// LOCAL_STORAGE_NAME은 로컬 파일시스템 저장소의 이름입니다.
+const LOCAL_STORAGE_NAME = "local"
+
+// LocalStorage는 서버 로컬 디렉터리에 파일을 저장하는 저장소입니다.
+// 임시 파일에 쓴 뒤 이름을 바꾸므로 쓰는 도중의 파일이 읽히지 않습니다.
+type LocalStorage struct {
+	root    string
+	baseURL string
+}
+
+// NewLocalStorage는 root 디렉터리를 사용하는 로컬 저장소를 생성합니다.
+// baseURL은 root를 정적 파일로 제공하는 공개 주소이며, 없으면 URL은 빈 문자열을 반환합니다.
+func NewLocalStorage(root, baseURL string) (*LocalStorage, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+	return &LocalStorage{root: root, baseURL: baseURL}, nil
+}
+
+// Name은 저장소 이름을 반환합니다.
+func (s *LocalStorage) Name() string {
+	return LOCAL_STORAGE_NAME
+}
+
+// path는 키를 root 아래의 파일 경로로 바꿉니다.
+func (s *LocalStorage) path(key string) (string, error) {
+	key, err := CleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
+
+// Put은 객체를 파일로 저장합니다.
+func (s *LocalStorage) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, body); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Open은 저장된 파일을 엽니다.
+func (s *LocalStorage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+// Delete는 파일을 삭제합니다.
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// URL은 파일의 공개 URL을 반환합니다.
+func (s *LocalStorage) URL(key string) string {
+	return joinURL(s.baseURL, key)
+}
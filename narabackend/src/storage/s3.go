@@ -0,0 +1,196 @@
+// s3.go
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// S3_STORAGE_NAME은 S3 호환 저장소의 이름입니다.
+const S3_STORAGE_NAME = "s3"
+
+// s3UnsignedPayload는 본문 해시 없이 서명할 때 사용하는 x-amz-content-sha256 값입니다.
+const s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+
+// S3Config는 S3 호환 저장소(AWS S3, MinIO 등) 접속 정보입니다.
+type S3Config struct {
+	Endpoint      string // 예: https://s3.ap-northeast-2.amazonaws.com, http://minio:9000
+	Region        string // 예: ap-northeast-2 (MinIO는 us-east-1)
+	Bucket        string
+	AccessKey     string
+	SecretKey     string
+	PublicBaseURL string // 객체 공개 주소 (CDN 등). 없으면 URL은 빈 문자열을 반환합니다.
+}
+
+// S3Storage는 경로 방식(endpoint/bucket/key) 요청과 AWS Signature V4로 동작하는 S3 호환 저장소입니다.
+type S3Storage struct {
+	cfg    S3Config
+	client *http.Client
+}
+
+// NewS3Storage는 S3 호환 저장소를 생성합니다.
+func NewS3Storage(cfg S3Config) (*S3Storage, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" || cfg.AccessKey == "" || cfg.SecretKey == "" {
+		return nil, fmt.Errorf("S3 설정이 누락되었습니다 (endpoint, bucket, access key, secret key)")
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	cfg.Endpoint = strings.TrimRight(cfg.Endpoint, "/")
+	return &S3Storage{cfg: cfg, client: &http.Client{Timeout: 60 * time.Second}}, nil
+}
+
+// Name은 저장소 이름을 반환합니다.
+func (s *S3Storage) Name() string {
+	return S3_STORAGE_NAME
+}
+
+// Put은 객체를 업로드합니다.
+func (s *S3Storage) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	req, err := s.newRequest(ctx, http.MethodPut, key, body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Open은 객체를 내려받습니다.
+func (s *S3Storage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := s.newRequest(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Delete는 객체를 삭제합니다. S3는 없는 키 삭제도 성공으로 응답합니다.
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	req, err := s.newRequest(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(req)
+	if err == ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// URL은 객체의 공개 URL을 반환합니다.
+func (s *S3Storage) URL(key string) string {
+	return joinURL(s.cfg.PublicBaseURL, key)
+}
+
+// newRequest는 서명된 객체 요청을 만듭니다.
+func (s *S3Storage) newRequest(ctx context.Context, method, key string, body io.Reader) (*http.Request, error) {
+	key, err := CleanKey(key)
+	if err != nil {
+		return nil, err
+	}
+	path := "/" + s3EscapePath(s.cfg.Bucket) + "/" + s3EscapePath(key)
+	req, err := http.NewRequestWithContext(ctx, method, s.cfg.Endpoint+path, body)
+	if err != nil {
+		return nil, err
+	}
+	s.sign(req, path, time.Now().UTC())
+	return req, nil
+}
+
+// do는 요청을 보내고 2xx가 아니면 오류로 바꿉니다. 404는 ErrNotFound입니다.
+func (s *S3Storage) do(req *http.Request) (*http.Response, error) {
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return nil, fmt.Errorf("S3 응답 오류: %s %s", resp.Status, strings.TrimSpace(string(detail)))
+}
+
+// sign은 요청에 AWS Signature V4 인증 헤더를 추가합니다.
+// 본문은 서명하지 않고(UNSIGNED-PAYLOAD) host, x-amz-content-sha256, x-amz-date만 서명합니다.
+func (s *S3Storage) sign(req *http.Request, escapedPath string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", s3UnsignedPayload)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		escapedPath,
+		"",
+		"host:" + req.URL.Host + "\n" +
+			"x-amz-content-sha256:" + s3UnsignedPayload + "\n" +
+			"x-amz-date:" + amzDate + "\n",
+		signedHeaders,
+		s3UnsignedPayload,
+	}, "\n")
+
+	scope := date + "/" + s.cfg.Region + "/s3/aws4_request"
+	hashed := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hashed[:])
+
+	key := s3HMAC([]byte("AWS4"+s.cfg.SecretKey), date)
+	key = s3HMAC(key, s.cfg.Region)
+	key = s3HMAC(key, "s3")
+	key = s3HMAC(key, "aws4_request")
+	signature := hex.EncodeToString(s3HMAC(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.cfg.AccessKey, scope, signedHeaders, signature))
+}
+
+// s3HMAC은 HMAC-SHA256을 계산합니다.
+func s3HMAC(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// s3EscapePath는 슬래시를 유지하며 나머지 문자를 RFC 3986 방식으로 인코딩합니다.
+// 영문자, 숫자, '-', '_', '.', '~'만 그대로 둡니다.
+func s3EscapePath(path string) string {
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/':
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
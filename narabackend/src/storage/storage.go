@@ -0,0 +1,104 @@
+// storage.go
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+)
+
+// DEFAULT_LOCAL_DIR은 로컬 저장소의 기본 디렉터리입니다.
+const DEFAULT_LOCAL_DIR = "./uploads"
+
+var (
+	// ErrNotFound는 저장소에 해당 키의 객체가 없는 경우 반환됩니다.
+	ErrNotFound = errors.New("저장된 파일을 찾을 수 없습니다")
+	// ErrInvalidKey는 저장소 밖을 가리키거나 비어 있는 키인 경우 반환됩니다.
+	ErrInvalidKey = errors.New("잘못된 저장소 키입니다")
+	// ErrNotConfigured는 저장소가 설정되지 않은 상태에서 사용할 때 반환됩니다.
+	ErrNotConfigured = errors.New("파일 저장소가 설정되지 않았습니다")
+)
+
+// Storage는 업로드 파일을 보관하는 저장소 인터페이스입니다.
+// 키는 "companies/<company_id>/images/<hash>.jpg"처럼 슬래시로 구분한 상대 경로입니다.
+// 로컬 파일시스템 외의 저장소(S3 호환 등)는 이 인터페이스를 구현하는 어댑터로 추가합니다.
+type Storage interface {
+	// Name은 로그와 설정에 사용하는 저장소 식별자입니다.
+	Name() string
+	// Put은 객체를 저장합니다. 같은 키가 있으면 덮어씁니다.
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	// Open은 저장된 객체를 읽습니다. 없으면 ErrNotFound를 반환합니다.
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete는 객체를 삭제합니다. 없는 키는 오류 없이 무시합니다.
+	Delete(ctx context.Context, key string) error
+	// URL은 객체의 공개 URL을 반환합니다. 공개 주소가 설정되지 않았으면 빈 문자열입니다.
+	URL(key string) string
+}
+
+var (
+	defaultMu sync.RWMutex
+	current   Storage
+)
+
+// SetDefault는 애플리케이션에서 사용할 저장소를 설정합니다.
+func SetDefault(s Storage) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	current = s
+}
+
+// Default는 설정된 저장소를 반환합니다. 설정되지 않았으면 ErrNotConfigured를 반환합니다.
+func Default() (Storage, error) {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	if current == nil {
+		return nil, ErrNotConfigured
+	}
+	return current, nil
+}
+
+// CleanKey는 키를 검증하고 앞뒤 슬래시를 제거합니다. ".." 경로나 빈 키는 ErrInvalidKey입니다.
+func CleanKey(key string) (string, error) {
+	key = strings.Trim(key, "/")
+	if key == "" || strings.Contains(key, "\\") {
+		return "", ErrInvalidKey
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return "", ErrInvalidKey
+		}
+	}
+	return key, nil
+}
+
+// joinURL은 공개 주소와 키를 이어 붙입니다. 공개 주소가 없으면 빈 문자열입니다.
+func joinURL(baseURL, key string) string {
+	if baseURL == "" {
+		return ""
+	}
+	return strings.TrimRight(baseURL, "/") + "/" + key
+}
+
+// Config는 저장소 설정입니다. main에서 config.StorageConfig 값으로 채웁니다.
+type Config struct {
+	Backend       string   // S3_STORAGE_NAME이면 S3 호환 저장소, 그 외에는 로컬 디렉터리
+	LocalDir      string   // 로컬 저장소 디렉터리 (비어 있으면 DEFAULT_LOCAL_DIR)
+	PublicBaseURL string   // 저장된 파일을 직접 제공하는 공개 주소 (선택)
+	S3            S3Config // Backend가 s3일 때 사용 (PublicBaseURL은 위 값으로 채움)
+}
+
+// New는 설정으로 저장소를 생성합니다.
+func New(cfg Config) (Storage, error) {
+	if cfg.Backend == S3_STORAGE_NAME {
+		s3 := cfg.S3
+		s3.PublicBaseURL = cfg.PublicBaseURL
+		return NewS3Storage(s3)
+	}
+	dir := cfg.LocalDir
+	if dir == "" {
+		dir = DEFAULT_LOCAL_DIR
+	}
+	return NewLocalStorage(dir, cfg.PublicBaseURL)
+}
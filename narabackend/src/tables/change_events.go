@@ -0,0 +1,85 @@
+// change_events.go
+package tables
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+
+	"narabackend/src/utils"
+)
+
+// 변경 이벤트 수신 대상 (데스크는 좌석 현황, 장치는 배치/전원 정보를 갱신합니다)
+var (
+	changeTargetsAll  = []string{utils.CONTROL_TARGET_DESK, utils.CONTROL_TARGET_DEVICE}
+	changeTargetsDesk = []string{utils.CONTROL_TARGET_DESK}
+)
+
+// seatControlMessageTx는 좌석의 naracontrol 메시지 정보(방/좌석/전원 번호)를 조회합니다.
+// seat_table에 없는 좌석이거나 좌석 번호가 없으면 seat_code를 좌석 번호로 사용합니다.
+func seatControlMessageTx(ctx context.Context, tx *sql.Tx, companyCode string, seatCode int) (utils.ControlMessage, error) {
+	message := utils.ControlMessage{CompanyCode: companyCode, SeatNumber: strconv.Itoa(seatCode)}
+	var roomCode, seatNumber, powerNumber *int
+	err := tx.QueryRowContext(ctx, `
+		SELECT room_code, seat_number, power_number
+		FROM seat_table WHERE company_code = $1 AND seat_code = $2`,
+		companyCode, seatCode).Scan(&roomCode, &seatNumber, &powerNumber)
+	if err == sql.ErrNoRows {
+		return message, nil
+	}
+	if err != nil {
+		return message, err
+	}
+	message.RoomCode = utils.ControlNumberString(roomCode)
+	message.PowerNumber = utils.ControlNumberString(powerNumber)
+	if seatNumber != nil {
+		message.SeatNumber = strconv.Itoa(*seatNumber)
+	}
+	return message, nil
+}
+
+// writeRoomChangeTx는 열람실 정보 변경 이벤트를 outbox에 기록합니다.
+func writeRoomChangeTx(ctx context.Context, tx *sql.Tx, companyCode string, roomCode int) error {
+	return utils.WriteOutboxTx(ctx, tx, utils.OutboxEvent{
+		Event:   utils.CONTROL_EVENT_ROOM_UPDATED,
+		Targets: changeTargetsAll,
+		Message: utils.ControlMessage{CompanyCode: companyCode, RoomCode: strconv.Itoa(roomCode)},
+	})
+}
+
+// writeSeatChangeTx는 좌석 변경 이벤트(정보 변경, 입실, 퇴실)를 outbox에 기록합니다. userCode는 입실/퇴실한 회원입니다.
+func writeSeatChangeTx(ctx context.Context, tx *sql.Tx, event string, targets []string, companyCode string, seatCode int, userCode string) error {
+	if !utils.NaracontrolEnabled() {
+		return nil
+	}
+	message, err := seatControlMessageTx(ctx, tx, companyCode, seatCode)
+	if err != nil {
+		return err
+	}
+	message.UserCode = userCode
+	return utils.WriteOutboxTx(ctx, tx, utils.OutboxEvent{Event: event, Targets: targets, Message: message})
+}
+
+// writePowerStateChangeTx는 장치가 처리를 확인한 전원 제어 결과를 outbox에 기록하여 데스크의 전원 상태를 갱신합니다.
+func writePowerStateChangeTx(ctx context.Context, tx *sql.Tx, event PowerEvent) error {
+	if !utils.NaracontrolEnabled() {
+		return nil
+	}
+	name := utils.CONTROL_EVENT_POWER_STATE_OFF
+	if event.Command == utils.CONTROL_COMMAND_POWER_ON {
+		name = utils.CONTROL_EVENT_POWER_STATE_ON
+	}
+
+	message := utils.ControlMessage{CompanyCode: event.CompanyCode, RoomCode: utils.ControlNumberString(event.RoomCode)}
+	if event.SeatCode != nil {
+		var err error
+		message, err = seatControlMessageTx(ctx, tx, event.CompanyCode, *event.SeatCode)
+		if err != nil {
+			return err
+		}
+	}
+	if event.PowerNumber != nil {
+		message.PowerNumber = strconv.Itoa(*event.PowerNumber)
+	}
+	return utils.WriteOutboxTx(ctx, tx, utils.OutboxEvent{Event: name, Targets: changeTargetsDesk, Message: message})
+}
@@ -0,0 +1,207 @@
+// change_feed.go
+package tables
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"narabackend/src/consts"
+	"narabackend/src/utils"
+)
+
+// SSE 재연결 대기 시간(밀리초). 브라우저 EventSource는 끊어지면 이 시간 뒤 Last-Event-ID와 함께 다시 연결합니다.
+const CHANGE_FEED_RETRY_MILLIS = 3000
+
+// ChangeFeedPage는 변경 이벤트 이어 받기(GET /changes) 응답입니다.
+type ChangeFeedPage struct {
+	Events      []utils.ChangeEvent `json:"events"`
+	LastEventID int64               `json:"last_event_id"`
+	HasMore     bool                `json:"has_more"`
+	Reset       bool                `json:"reset"` // true면 보관 기간이 지나 이벤트가 삭제되었으므로 전체를 다시 조회해야 합니다
+}
+
+// RegisterChangeFeedRoutes는 실시간 변경 피드 관련 엔드포인트를 등록합니다.
+func RegisterChangeFeedRoutes(r *mux.Router) {
+	r.HandleFunc("/companies/{company_code}/changes", GetChanges).Methods("GET")
+	r.HandleFunc("/companies/{company_code}/changes/stream", StreamChanges).Methods("GET")
+}
+
+// parseChangeEntities는 entities 쿼리(쉼표 구분)를 대상 집합으로 변환합니다. 비어 있으면 모든 대상입니다.
+func parseChangeEntities(r *http.Request) map[string]bool {
+	v := r.URL.Query().Get("entities")
+	if v == "" {
+		return nil
+	}
+	entities := map[string]bool{}
+	for _, e := range strings.Split(v, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			entities[e] = true
+		}
+	}
+	return entities
+}
+
+// GetChanges: after 이후의 회사 변경 이벤트를 조회합니다. SSE를 쓸 수 없는 클라이언트의 폴링과 이어 받기에 사용합니다.
+// after가 없으면 보관 중인 가장 오래된 이벤트부터 반환합니다.
+func GetChanges(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	companyCode := mux.Vars(r)["company_code"]
+	var after int64
+	if v := r.URL.Query().Get("after"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			http.Error(w, "after는 0 이상의 정수여야 합니다", http.StatusBadRequest)
+			return
+		}
+		after = n
+	}
+	limit := consts.CHANGE_FEED_BATCH_SIZE
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > consts.CHANGE_FEED_BATCH_SIZE {
+			http.Error(w, fmt.Sprintf("limit는 1~%d 사이의 정수여야 합니다", consts.CHANGE_FEED_BATCH_SIZE), http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	page := ChangeFeedPage{LastEventID: after}
+	if after > 0 {
+		purged, err := utils.ChangeEventsPurgedAfter(ctx, after)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		page.Reset = purged
+	}
+	events, err := utils.ChangeEventsAfter(ctx, companyCode, after, limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	page.HasMore = len(events) == limit
+	entities := parseChangeEntities(r)
+	page.Events = []utils.ChangeEvent{}
+	for _, ev := range events {
+		page.LastEventID = ev.ID
+		if entities == nil || entities[ev.Entity] {
+			page.Events = append(page.Events, ev)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+// StreamChanges: 회사의 열람실/좌석/세션/결제 변경 이벤트를 Server-Sent Events로 전송합니다.
+// 이벤트 이름은 "대상.변경종류"(예: seat_session.created)이고 id는 이벤트ID입니다.
+// 다시 연결할 때 Last-Event-ID 헤더(또는 last_event_id 쿼리)를 주면 그 이후의 이벤트부터 이어서 보내며,
+// 없으면 연결 시점 이후의 이벤트만 보냅니다. 이어 받을 이벤트가 보관 기간이 지나 삭제되었으면
+// reset 이벤트를 보내므로 클라이언트는 전체를 다시 조회해야 합니다.
+func StreamChanges(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "스트리밍을 지원하지 않습니다", http.StatusInternalServerError)
+		return
+	}
+	companyCode := mux.Vars(r)["company_code"]
+	entities := parseChangeEntities(r)
+
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("last_event_id")
+	}
+	var lastID int64
+	if lastEventID != "" {
+		n, err := strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil || n < 0 {
+			http.Error(w, "잘못된 Last-Event-ID", http.StatusBadRequest)
+			return
+		}
+		lastID = n
+	}
+
+	// 이어 받기 조회 중에 커밋된 이벤트도 놓치지 않도록 먼저 구독합니다.
+	wake, unsubscribe := utils.SubscribeChanges(companyCode)
+	defer unsubscribe()
+
+	queryTimeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), queryTimeout)
+	reset := false
+	var err error
+	if lastEventID == "" {
+		lastID, err = utils.LatestChangeEventID(ctx, companyCode)
+	} else if reset, err = utils.ChangeEventsPurgedAfter(ctx, lastID); err == nil && reset {
+		lastID, err = utils.LatestChangeEventID(ctx, companyCode)
+	}
+	cancel()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	fmt.Fprintf(w, "retry: %d\n\n", CHANGE_FEED_RETRY_MILLIS)
+	if reset {
+		fmt.Fprintf(w, "id: %d\nevent: reset\ndata: {}\n\n", lastID)
+	}
+	flusher.Flush()
+	log.Printf("변경 피드 연결: company_code=%s, last_event_id=%d", companyCode, lastID)
+
+	heartbeat := time.NewTicker(time.Duration(consts.CHANGE_FEED_HEARTBEAT_INTERVAL) * time.Second)
+	defer heartbeat.Stop()
+	for {
+		// 마지막으로 보낸 이벤트 이후를 모두 보냅니다.
+		for {
+			ctx, cancel := context.WithTimeout(r.Context(), queryTimeout)
+			events, err := utils.ChangeEventsAfter(ctx, companyCode, lastID, consts.CHANGE_FEED_BATCH_SIZE)
+			cancel()
+			if err != nil {
+				// 연결을 끊으면 클라이언트가 Last-Event-ID로 다시 연결하여 이어 받습니다.
+				log.Printf("변경 피드 조회 실패: company_code=%s, 오류: %v", companyCode, err)
+				return
+			}
+			for _, ev := range events {
+				lastID = ev.ID
+				if entities != nil && !entities[ev.Entity] {
+					continue
+				}
+				data, err := json.Marshal(ev)
+				if err != nil {
+					log.Printf("변경 이벤트 변환 실패 (id=%d): %v", ev.ID, err)
+					continue
+				}
+				fmt.Fprintf(w, "id: %d\nevent: %s.%s\ndata: %s\n\n", ev.ID, ev.Entity, ev.Action, data)
+			}
+			flusher.Flush()
+			if len(events) < consts.CHANGE_FEED_BATCH_SIZE {
+				break
+			}
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-wake:
+		case <-heartbeat.C:
+			// 프록시가 유휴 연결을 끊지 않도록 주석 줄을 보내고, LISTEN이 끊겼을 때를 대비해 새 이벤트도 확인합니다.
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+		}
+	}
+}
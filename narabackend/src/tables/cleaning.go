@@ -0,0 +1,527 @@
+// cleaning.go
+package tables
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"narabackend/src/consts"
+	"narabackend/src/utils"
+)
+
+// 청소 작업 상태
+const (
+	CLEANING_STATUS_PENDING   = "pending"   // 청소 대기
+	CLEANING_STATUS_CLEANED   = "cleaned"   // 청소 완료
+	CLEANING_STATUS_CANCELLED = "cancelled" // 취소(청소 불필요)
+)
+
+// errSeatNeedsCleaning은 청소 완료 전 입실이 차단된 좌석에 입실하려 할 때 반환됩니다.
+var errSeatNeedsCleaning = errors.New("청소가 완료되지 않은 좌석입니다")
+
+// CleaningRule 구조체는 cleaning_rule_table의 각 컬럼을 매핑합니다.
+type CleaningRule struct {
+	SerialNumber      int64     `json:"serial_number" db:"serial_number"`
+	CompanyCode       string    `json:"company_code" db:"company_code"`
+	BlockUntilCleaned bool      `json:"block_until_cleaned" db:"block_until_cleaned"`
+	CreatedAt         time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// CleaningRuleRequest는 청소 규칙 저장 요청 시 사용되는 구조체입니다.
+type CleaningRuleRequest struct {
+	BlockUntilCleaned *bool `json:"block_until_cleaned"`
+}
+
+// CleaningTask 구조체는 cleaning_task_table의 각 컬럼을 매핑합니다.
+type CleaningTask struct {
+	SerialNumber int64      `json:"serial_number" db:"serial_number"`
+	CompanyCode  string     `json:"company_code" db:"company_code"`
+	SeatCode     int        `json:"seat_code" db:"seat_code"`
+	SessionID    *int64     `json:"session_id" db:"session_id"`
+	Status       string     `json:"status" db:"status"`
+	RequestedAt  time.Time  `json:"requested_at" db:"requested_at"`
+	CleanedAt    *time.Time `json:"cleaned_at" db:"cleaned_at"`
+	CleanedBy    string     `json:"cleaned_by" db:"cleaned_by"`
+	Memo         string     `json:"memo" db:"memo"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+}
+
+// CleaningTaskRequest는 청소 작업 직접 등록 요청 시 사용되는 구조체입니다.
+type CleaningTaskRequest struct {
+	CompanyCode string `json:"company_code"`
+	SeatCode    int    `json:"seat_code"`
+	Memo        string `json:"memo"`
+}
+
+// CompleteCleaningRequest는 청소 완료/취소 요청 시 사용되는 구조체입니다.
+type CompleteCleaningRequest struct {
+	CleanedBy string `json:"cleaned_by"`
+	Memo      string `json:"memo"`
+}
+
+// CleaningStaffCount는 담당자별 청소 완료 건수입니다.
+type CleaningStaffCount struct {
+	CleanedBy string `json:"cleaned_by"`
+	Count     int    `json:"count"`
+}
+
+// CleaningReport는 하루 동안 요청된 청소 작업의 집계입니다.
+type CleaningReport struct {
+	CompanyCode    string               `json:"company_code"`
+	Date           string               `json:"date"`
+	Requested      int                  `json:"requested"`
+	Cleaned        int                  `json:"cleaned"`
+	Cancelled      int                  `json:"cancelled"`
+	Pending        int                  `json:"pending"`
+	AverageMinutes float64              `json:"average_minutes"` // 요청~완료 평균 소요 시간
+	MaxMinutes     int                  `json:"max_minutes"`
+	ByStaff        []CleaningStaffCount `json:"by_staff"`
+}
+
+// cleaningRuleColumns는 cleaning_rule_table 조회 시 사용하는 컬럼 목록입니다.
+const cleaningRuleColumns = `serial_number, company_code, block_until_cleaned, created_at, updated_at`
+
+// cleaningTaskColumns는 cleaning_task_table 조회 시 사용하는 컬럼 목록입니다.
+const cleaningTaskColumns = `serial_number, company_code, seat_code, session_id, status, requested_at,
+	cleaned_at, COALESCE(cleaned_by, ''), COALESCE(memo, ''), created_at`
+
+// scanCleaningRule은 한 행을 CleaningRule로 읽습니다.
+func scanCleaningRule(row interface{ Scan(...interface{}) error }, rule *CleaningRule) error {
+	return row.Scan(&rule.SerialNumber, &rule.CompanyCode, &rule.BlockUntilCleaned, &rule.CreatedAt, &rule.UpdatedAt)
+}
+
+// scanCleaningTask는 한 행을 CleaningTask로 읽습니다.
+func scanCleaningTask(row interface{ Scan(...interface{}) error }, task *CleaningTask) error {
+	return row.Scan(&task.SerialNumber, &task.CompanyCode, &task.SeatCode, &task.SessionID, &task.Status,
+		&task.RequestedAt, &task.CleanedAt, &task.CleanedBy, &task.Memo, &task.CreatedAt)
+}
+
+// insertCleaningTaskTx는 좌석의 청소 작업을 등록하고 seat_table의 청소등을 켭니다.
+// 이미 대기 중인 작업이 있으면 새로 만들지 않고 created=false를 반환합니다.
+func insertCleaningTaskTx(ctx context.Context, tx *sql.Tx, task CleaningTask) (CleaningTask, bool, error) {
+	err := scanCleaningTask(tx.QueryRowContext(ctx, `
+		INSERT INTO cleaning_task_table (company_code, seat_code, session_id, status, requested_at, memo, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, CURRENT_TIMESTAMP)
+		ON CONFLICT (company_code, seat_code) WHERE status = 'pending' DO NOTHING
+		RETURNING `+cleaningTaskColumns,
+		task.CompanyCode, task.SeatCode, task.SessionID, CLEANING_STATUS_PENDING, task.RequestedAt,
+		nullableString(task.Memo)), &task)
+	if err == sql.ErrNoRows {
+		return task, false, nil
+	}
+	if err != nil {
+		return task, false, err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE seat_table SET cleaning_light = TRUE WHERE company_code = $1 AND seat_code = $2",
+		task.CompanyCode, task.SeatCode)
+	return task, true, err
+}
+
+// enqueueCheckoutCleaningTx는 퇴실한 세션의 좌석을 청소 대기 상태로 만듭니다.
+// seat_table에서 청소 제외(exclude_cleaning)로 설정된 좌석은 등록하지 않습니다.
+func enqueueCheckoutCleaningTx(ctx context.Context, tx *sql.Tx, session SeatSession, at time.Time) error {
+	var excluded bool
+	err := tx.QueryRowContext(ctx,
+		"SELECT COALESCE(exclude_cleaning, FALSE) FROM seat_table WHERE company_code = $1 AND seat_code = $2",
+		session.CompanyCode, session.SeatCode).Scan(&excluded)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	if excluded {
+		return nil
+	}
+
+	sessionID := session.SerialNumber
+	_, _, err = insertCleaningTaskTx(ctx, tx, CleaningTask{
+		CompanyCode: session.CompanyCode,
+		SeatCode:    session.SeatCode,
+		SessionID:   &sessionID,
+		RequestedAt: at,
+	})
+	return err
+}
+
+// seatAwaitingCleaning은 업체 규칙상 청소 완료 전까지 좌석을 배정할 수 없는지 확인합니다.
+func seatAwaitingCleaning(ctx context.Context, tx *sql.Tx, companyCode string, seatCode int) (bool, error) {
+	var blocked bool
+	err := tx.QueryRowContext(ctx, `
+		SELECT EXISTS(
+			SELECT 1 FROM cleaning_task_table t
+			JOIN cleaning_rule_table r ON r.company_code = t.company_code
+			WHERE t.company_code = $1 AND t.seat_code = $2 AND t.status = $3 AND r.block_until_cleaned)`,
+		companyCode, seatCode, CLEANING_STATUS_PENDING).Scan(&blocked)
+	return blocked, err
+}
+
+// pushCleaningLight는 seat_table의 현재 청소등 상태를 naracontrol 장치에 전송합니다.
+// seat_table에 없는 좌석이거나 전송에 실패하면 로그만 남깁니다.
+func pushCleaningLight(ctx context.Context, companyCode string, seatCode int) {
+	var on bool
+	var roomCode, seatNumber, powerNumber *int
+	err := utils.DB.QueryRowContext(ctx, `
+		SELECT COALESCE(cleaning_light, FALSE), room_code, seat_number, power_number
+		FROM seat_table WHERE company_code = $1 AND seat_code = $2`,
+		companyCode, seatCode).Scan(&on, &roomCode, &seatNumber, &powerNumber)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("청소등 상태 조회 실패: company_code=%s, seat_code=%d, 오류: %v", companyCode, seatCode, err)
+		}
+		return
+	}
+
+	command := utils.CONTROL_COMMAND_CLEANING_LIGHT_OFF
+	if on {
+		command = utils.CONTROL_COMMAND_CLEANING_LIGHT_ON
+	}
+	number := strconv.Itoa(seatCode)
+	if seatNumber != nil {
+		number = strconv.Itoa(*seatNumber)
+	}
+	_, err = utils.SendControlCommand(ctx, utils.ControlCommand{
+		Command: command,
+		Target:  utils.CONTROL_TARGET_DEVICE,
+		Message: utils.ControlMessage{
+			CompanyCode: companyCode,
+			RoomCode:    utils.ControlNumberString(roomCode),
+			SeatNumber:  number,
+			PowerNumber: utils.ControlNumberString(powerNumber),
+		},
+	})
+	if err != nil {
+		log.Printf("청소등 명령 실패: company_code=%s, seat_code=%d, 오류: %v", companyCode, seatCode, err)
+	}
+}
+
+// RegisterCleaningRoutes는 cleaning_rule_table, cleaning_task_table 관련 엔드포인트를 등록합니다.
+func RegisterCleaningRoutes(r *mux.Router) {
+	r.HandleFunc("/cleaning-tasks", GetCleaningTasks).Methods("GET")
+	r.HandleFunc("/cleaning-tasks", CreateCleaningTask).Methods("POST")
+	r.HandleFunc("/cleaning-tasks/{id:[0-9]+}/complete", CompleteCleaningTask).Methods("POST")
+	r.HandleFunc("/cleaning-tasks/{id:[0-9]+}/cancel", CancelCleaningTask).Methods("POST")
+	r.HandleFunc("/companies/{company_code}/cleaning-rule", GetCleaningRule).Methods("GET")
+	r.HandleFunc("/companies/{company_code}/cleaning-rule", SaveCleaningRule).Methods("PUT", "PATCH")
+	r.HandleFunc("/companies/{company_code}/cleaning-rule", DeleteCleaningRule).Methods("DELETE")
+	r.HandleFunc("/companies/{company_code}/cleaning-report", GetCleaningReport).Methods("GET")
+}
+
+// GetCleaningTasks: 청소 작업 목록을 조회합니다.
+// company_code, seat_code, status 쿼리 파라미터로 필터링합니다. 키오스크는 status=pending으로 청소 대기 좌석을 확인합니다.
+func GetCleaningTasks(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	filters := []string{}
+	args := []interface{}{}
+	paramIdx := 1
+
+	filterParams := map[string]string{
+		"company_code": "company_code",
+		"seat_code":    "seat_code",
+		"status":       "status",
+	}
+	for param, dbField := range filterParams {
+		if value := r.URL.Query().Get(param); value != "" {
+			filters = append(filters, fmt.Sprintf("%s = $%d", dbField, paramIdx))
+			args = append(args, value)
+			paramIdx++
+		}
+	}
+
+	query := "SELECT " + cleaningTaskColumns + " FROM cleaning_task_table"
+	if len(filters) > 0 {
+		query += " WHERE " + strings.Join(filters, " AND ")
+	}
+	query += " ORDER BY requested_at ASC, serial_number ASC"
+
+	rows, err := utils.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("데이터베이스 쿼리 오류: %v", err)
+		http.Error(w, "데이터 조회 중 오류가 발생했습니다", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	result := []CleaningTask{}
+	for rows.Next() {
+		var task CleaningTask
+		if err := scanCleaningTask(rows, &task); err != nil {
+			log.Printf("행 스캔 오류: %v", err)
+			http.Error(w, "데이터 처리 중 오류가 발생했습니다", http.StatusInternalServerError)
+			return
+		}
+		result = append(result, task)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// CreateCleaningTask: 직원이 좌석 청소를 직접 요청합니다. 청소 제외 좌석도 등록할 수 있습니다.
+func CreateCleaningTask(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	var req CleaningTaskRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "잘못된 요청 데이터", http.StatusBadRequest)
+		return
+	}
+	if req.CompanyCode == "" || req.SeatCode == 0 {
+		http.Error(w, "필수 필드가 누락되었습니다 (company_code, seat_code)", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := utils.DB.BeginTx(ctx, nil)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	task, created, err := insertCleaningTaskTx(ctx, tx, CleaningTask{
+		CompanyCode: req.CompanyCode,
+		SeatCode:    req.SeatCode,
+		RequestedAt: time.Now(),
+		Memo:        req.Memo,
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !created {
+		http.Error(w, "이미 청소 대기 중인 좌석입니다", http.StatusConflict)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	pushCleaningLight(ctx, task.CompanyCode, task.SeatCode)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(task)
+}
+
+// CompleteCleaningTask: 청소 완료를 처리합니다.
+// 청소등을 끄고, 비어 있는 좌석이면 열람실 대기열에 제안합니다.
+func CompleteCleaningTask(w http.ResponseWriter, r *http.Request) {
+	finishCleaningTask(w, r, CLEANING_STATUS_CLEANED)
+}
+
+// CancelCleaningTask: 청소가 필요 없는 작업을 취소합니다. 청소등도 함께 끕니다.
+func CancelCleaningTask(w http.ResponseWriter, r *http.Request) {
+	finishCleaningTask(w, r, CLEANING_STATUS_CANCELLED)
+}
+
+// finishCleaningTask는 대기 중인 청소 작업을 status로 종료하고 좌석 청소등을 끕니다.
+func finishCleaningTask(w http.ResponseWriter, r *http.Request, status string) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	id, _ := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	var req CompleteCleaningRequest
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "잘못된 요청 데이터", http.StatusBadRequest)
+			return
+		}
+	}
+
+	tx, err := utils.DB.BeginTx(ctx, nil)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	var task CleaningTask
+	err = scanCleaningTask(tx.QueryRowContext(ctx, `
+		UPDATE cleaning_task_table
+		SET status = $2, cleaned_at = $3, cleaned_by = $4, memo = COALESCE($5, memo)
+		WHERE serial_number = $1 AND status = $6
+		RETURNING `+cleaningTaskColumns,
+		id, status, now, nullableString(req.CleanedBy), nullableString(req.Memo), CLEANING_STATUS_PENDING), &task)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "처리할 수 있는 청소 작업이 없습니다 (없거나 이미 처리됨)", http.StatusConflict)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	_, err = tx.ExecContext(ctx,
+		"UPDATE seat_table SET cleaning_light = FALSE WHERE company_code = $1 AND seat_code = $2",
+		task.CompanyCode, task.SeatCode)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	pushCleaningLight(ctx, task.CompanyCode, task.SeatCode)
+	offerFreedSeat(ctx, task.CompanyCode, task.SeatCode, now)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(task)
+}
+
+// GetCleaningRule: 업체의 청소 규칙을 조회합니다.
+func GetCleaningRule(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	var rule CleaningRule
+	err := scanCleaningRule(utils.DB.QueryRowContext(ctx,
+		"SELECT "+cleaningRuleColumns+" FROM cleaning_rule_table WHERE company_code = $1",
+		mux.Vars(r)["company_code"]), &rule)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "청소 규칙이 없습니다.", http.StatusNotFound)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rule)
+}
+
+// SaveCleaningRule: 업체의 청소 규칙을 생성하거나 수정합니다.
+func SaveCleaningRule(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	companyCode := mux.Vars(r)["company_code"]
+	var req CleaningRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "잘못된 요청 데이터", http.StatusBadRequest)
+		return
+	}
+	blockUntilCleaned := true
+	if req.BlockUntilCleaned != nil {
+		blockUntilCleaned = *req.BlockUntilCleaned
+	}
+
+	var rule CleaningRule
+	err := scanCleaningRule(utils.DB.QueryRowContext(ctx, `
+		INSERT INTO cleaning_rule_table (company_code, block_until_cleaned, created_at, updated_at)
+		VALUES ($1, $2, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		ON CONFLICT (company_code) DO UPDATE SET
+			block_until_cleaned = EXCLUDED.block_until_cleaned,
+			updated_at = CURRENT_TIMESTAMP
+		RETURNING `+cleaningRuleColumns,
+		companyCode, blockUntilCleaned), &rule)
+	if err != nil {
+		log.Printf("청소 규칙 저장 오류: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rule)
+}
+
+// DeleteCleaningRule: 업체의 청소 규칙을 삭제합니다. 이후 청소 대기 좌석도 바로 배정할 수 있습니다.
+func DeleteCleaningRule(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	res, err := utils.DB.ExecContext(ctx, "DELETE FROM cleaning_rule_table WHERE company_code = $1", mux.Vars(r)["company_code"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		http.Error(w, "청소 규칙이 없습니다.", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetCleaningReport: 하루 동안 요청된 청소 작업을 집계합니다.
+// date 쿼리 파라미터(YYYY-MM-DD)가 없으면 오늘을 기준으로 합니다.
+func GetCleaningReport(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	date := r.URL.Query().Get("date")
+	if date == "" {
+		date = time.Now().Format("2006-01-02")
+	}
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		http.Error(w, "잘못된 date 형식입니다 (YYYY-MM-DD)", http.StatusBadRequest)
+		return
+	}
+
+	report := CleaningReport{
+		CompanyCode: mux.Vars(r)["company_code"],
+		Date:        date,
+		ByStaff:     []CleaningStaffCount{},
+	}
+	err := utils.DB.QueryRowContext(ctx, `
+		SELECT COUNT(*),
+			COUNT(*) FILTER (WHERE status = $3),
+			COUNT(*) FILTER (WHERE status = $4),
+			COUNT(*) FILTER (WHERE status = $5),
+			COALESCE(AVG(EXTRACT(EPOCH FROM cleaned_at - requested_at) / 60) FILTER (WHERE status = $3), 0),
+			COALESCE(CEIL(MAX(EXTRACT(EPOCH FROM cleaned_at - requested_at) / 60) FILTER (WHERE status = $3)), 0)::INTEGER
+		FROM cleaning_task_table
+		WHERE company_code = $1 AND requested_at >= $2::date AND requested_at < $2::date + 1`,
+		report.CompanyCode, date, CLEANING_STATUS_CLEANED, CLEANING_STATUS_CANCELLED, CLEANING_STATUS_PENDING,
+	).Scan(&report.Requested, &report.Cleaned, &report.Cancelled, &report.Pending,
+		&report.AverageMinutes, &report.MaxMinutes)
+	if err != nil {
+		log.Printf("데이터베이스 쿼리 오류: %v", err)
+		http.Error(w, "데이터 조회 중 오류가 발생했습니다", http.StatusInternalServerError)
+		return
+	}
+
+	rows, err := utils.DB.QueryContext(ctx, `
+		SELECT COALESCE(cleaned_by, ''), COUNT(*)
+		FROM cleaning_task_table
+		WHERE company_code = $1 AND requested_at >= $2::date AND requested_at < $2::date + 1 AND status = $3
+		GROUP BY 1 ORDER BY 2 DESC, 1 ASC`,
+		report.CompanyCode, date, CLEANING_STATUS_CLEANED)
+	if err != nil {
+		log.Printf("데이터베이스 쿼리 오류: %v", err)
+		http.Error(w, "데이터 조회 중 오류가 발생했습니다", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var c CleaningStaffCount
+		if err := rows.Scan(&c.CleanedBy, &c.Count); err != nil {
+			log.Printf("행 스캔 오류: %v", err)
+			http.Error(w, "데이터 처리 중 오류가 발생했습니다", http.StatusInternalServerError)
+			return
+		}
+		report.ByStaff = append(report.ByStaff, c)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
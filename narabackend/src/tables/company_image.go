@@ -15,6 +15,7 @@ import (
 	"github.com/gorilla/mux"
 
 	"narabackend/src/consts"
+	"narabackend/src/storage"
 	"narabackend/src/utils"
 )
 
@@ -309,6 +310,7 @@ func CreateCompanyImage(w http.ResponseWriter, r *http.Request) {
 
 // UpdateCompanyImage: 기존 회사 이미지 정보를 업데이트합니다.
 // URL 경로의 ID와 JSON 요청 본문의 데이터를 사용하여 UPDATE 연산을 수행합니다.
+// 업로드로 저장된 이미지(content_hash 있음)의 image_path는 저장소 키이므로 다른 값으로 바꿀 수 없습니다 (409).
 func UpdateCompanyImage(w http.ResponseWriter, r *http.Request) {
 	// 요청 컨텍스트에 타임아웃 설정
 	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
@@ -343,16 +345,25 @@ func UpdateCompanyImage(w http.ResponseWriter, r *http.Request) {
 			image_type = COALESCE(NULLIF($6, ''), image_type),
 			updated_at = CURRENT_TIMESTAMP
 		WHERE serial_number = $1
+		  AND (NULLIF($4, '') IS NULL OR content_hash IS NULL OR image_path = $4)
 		RETURNING serial_number, company_id, image_name, image_path, image_size, image_type, created_at, updated_at`,
 		id, req.CompanyID, req.ImageName, req.ImagePath, req.ImageSize, req.ImageType).
 		Scan(&companyImage.SerialNumber, &companyImage.CompanyID, &companyImage.ImageName,
 			&companyImage.ImagePath, &companyImage.ImageSize, &companyImage.ImageType,
 			&companyImage.CreatedAt, &companyImage.UpdatedAt)
 
-	// 에러 처리 - 레코드가 없는 경우와 일반적인 데이터베이스 오류 구분
+	// 에러 처리 - 레코드가 없는 경우, 업로드 이미지의 경로 변경, 일반적인 데이터베이스 오류 구분
 	if err != nil {
 		if err == sql.ErrNoRows {
-			http.Error(w, "CompanyImage를 찾을 수 없습니다.", http.StatusNotFound)
+			var exists bool
+			if err := utils.DB.QueryRowContext(ctx,
+				"SELECT EXISTS(SELECT 1 FROM company_image_table WHERE serial_number = $1)", id).Scan(&exists); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			} else if exists {
+				http.Error(w, "업로드된 이미지의 image_path는 변경할 수 없습니다", http.StatusConflict)
+			} else {
+				http.Error(w, "CompanyImage를 찾을 수 없습니다.", http.StatusNotFound)
+			}
 		} else {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 		}
@@ -366,6 +377,7 @@ func UpdateCompanyImage(w http.ResponseWriter, r *http.Request) {
 
 // DeleteCompanyImage: 특정 회사 이미지를 삭제합니다.
 // URL 경로에서 ID를 추출하여 해당 레코드를 데이터베이스에서 삭제합니다.
+// 업로드로 저장된 이미지(content_hash 있음)는 저장소의 원본과 썸네일도 함께 삭제합니다.
 func DeleteCompanyImage(w http.ResponseWriter, r *http.Request) {
 	// 요청 컨텍스트에 타임아웃 설정
 	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
@@ -380,24 +392,29 @@ func DeleteCompanyImage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// DELETE 쿼리 실행
-	result, err := utils.DB.ExecContext(ctx, "DELETE FROM company_image_table WHERE serial_number = $1", id)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
+	// DELETE 쿼리 실행 (저장소 파일 삭제를 위해 저장소 키를 반환)
+	var imagePath, thumbnailPath, contentHash *string
+	err = utils.DB.QueryRowContext(ctx,
+		"DELETE FROM company_image_table WHERE serial_number = $1 RETURNING image_path, thumbnail_path, content_hash",
+		id).Scan(&imagePath, &thumbnailPath, &contentHash)
 
-	// 영향받은 행 수 확인
-	rowsAffected, err := result.RowsAffected()
+	// 삭제된 레코드가 없는 경우 404 에러 반환
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		if err == sql.ErrNoRows {
+			http.Error(w, "CompanyImage를 찾을 수 없습니다.", http.StatusNotFound)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
 		return
 	}
 
-	// 삭제된 레코드가 없는 경우 404 에러 반환
-	if rowsAffected == 0 {
-		http.Error(w, "CompanyImage를 찾을 수 없습니다.", http.StatusNotFound)
-		return
+	// 업로드 저장소의 파일 삭제 (외부에서 관리하는 image_path는 건드리지 않음)
+	if contentHash != nil {
+		if store, err := storage.Default(); err == nil {
+			deleteStoredImage(ctx, store, imagePath, thumbnailPath)
+		} else {
+			log.Printf("저장소 파일 삭제 생략: id=%d, 오류: %v", id, err)
+		}
 	}
 
 	// 204 No Content 상태 반환
@@ -0,0 +1,433 @@
+// company_image_upload.go
+package tables
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/lib/pq"
+
+	"narabackend/src/consts"
+	"narabackend/src/storage"
+	"narabackend/src/utils"
+)
+
+// 회사 이미지 구분 (company_image_table.image_type)
+const (
+	COMPANY_IMAGE_TYPE_LOGO   = 1 // 로고
+	COMPANY_IMAGE_TYPE_MAIN   = 2 // 대표 이미지
+	COMPANY_IMAGE_TYPE_DETAIL = 3 // 상세 이미지
+)
+
+// COMPANY_IMAGE_MAX_BYTES는 업로드할 수 있는 이미지 파일의 최대 크기(바이트)입니다.
+const COMPANY_IMAGE_MAX_BYTES = 10 << 20
+
+// companyImageFormOverhead는 multipart 본문에서 파일 외 필드와 경계 문자열에 허용하는 여유 크기입니다.
+const companyImageFormOverhead = 1 << 20
+
+// GalleryImage는 업로드 저장소를 사용하는 회사 이미지입니다.
+// content_url/thumbnail_url은 API로 파일을 내려받는 경로이고, image_url은 저장소 공개 주소(설정된 경우)입니다.
+type GalleryImage struct {
+	SerialNumber  int64     `json:"serial_number" db:"serial_number"`
+	CompanyID     string    `json:"company_id" db:"company_id"`
+	ImageType     *int      `json:"image_type" db:"image_type"`
+	ImageOrder    *int      `json:"image_order" db:"image_order"`
+	ImageURL      *string   `json:"image_url" db:"image_url"`
+	Title         *string   `json:"title" db:"title"`
+	Description   *string   `json:"description" db:"description"`
+	ImageName     *string   `json:"image_name" db:"image_name"`
+	ImageSize     *int      `json:"image_size" db:"image_size"`
+	ContentType   *string   `json:"content_type" db:"content_type"`
+	ContentHash   *string   `json:"content_hash" db:"content_hash"`
+	Width         *int      `json:"width" db:"width"`
+	Height        *int      `json:"height" db:"height"`
+	ContentURL    string    `json:"content_url"`
+	ThumbnailURL  string    `json:"thumbnail_url"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+	imagePath     *string
+	thumbnailPath *string
+}
+
+// GalleryOrderRequest는 갤러리 순서 변경 요청입니다. image_ids 순서대로 image_order를 1부터 매깁니다.
+type GalleryOrderRequest struct {
+	ImageIDs []int64 `json:"image_ids"`
+}
+
+// galleryImageColumns는 갤러리 이미지 조회 시 사용하는 컬럼 목록입니다.
+const galleryImageColumns = `serial_number, company_id, image_type, image_order, image_url, title, description,
+	image_name, image_size, content_type, content_hash, width, height, created_at, updated_at,
+	image_path, thumbnail_path`
+
+// scanGalleryImage는 galleryImageColumns 순서로 조회된 행을 GalleryImage로 읽고 내려받기 경로를 채웁니다.
+func scanGalleryImage(row interface{ Scan(...interface{}) error }, image *GalleryImage) error {
+	err := row.Scan(&image.SerialNumber, &image.CompanyID, &image.ImageType, &image.ImageOrder, &image.ImageURL,
+		&image.Title, &image.Description, &image.ImageName, &image.ImageSize, &image.ContentType, &image.ContentHash,
+		&image.Width, &image.Height, &image.CreatedAt, &image.UpdatedAt, &image.imagePath, &image.thumbnailPath)
+	if err != nil {
+		return err
+	}
+	image.ContentURL, image.ThumbnailURL = "", ""
+	if image.ContentHash != nil {
+		base := "/company-images/" + strconv.FormatInt(image.SerialNumber, 10)
+		image.ContentURL = base + "/content"
+		if image.thumbnailPath != nil {
+			image.ThumbnailURL = base + "/thumbnail"
+		}
+	}
+	return nil
+}
+
+// companyImageKey는 회사별 저장소 키를 만듭니다. 내용 해시를 이름으로 사용하므로 같은 파일은 같은 키입니다.
+func companyImageKey(companyID, hash, suffix string) string {
+	return "companies/" + url.PathEscape(companyID) + "/images/" + hash + suffix
+}
+
+// deleteStoredImage는 업로드 저장소에서 원본과 썸네일을 삭제합니다. 실패하면 로그만 남깁니다.
+func deleteStoredImage(ctx context.Context, store storage.Storage, keys ...*string) {
+	for _, key := range keys {
+		if key == nil || *key == "" {
+			continue
+		}
+		if err := store.Delete(ctx, *key); err != nil {
+			log.Printf("저장소 파일 삭제 실패: %s (%s), 오류: %v", *key, store.Name(), err)
+		}
+	}
+}
+
+// loadGallery는 회사의 업로드 이미지를 image_type, image_order 순으로 조회합니다.
+func loadGallery(ctx context.Context, companyID, imageType string) ([]GalleryImage, error) {
+	query := "SELECT " + galleryImageColumns + " FROM company_image_table WHERE company_id = $1"
+	args := []interface{}{companyID}
+	if imageType != "" {
+		query += " AND image_type = $2"
+		args = append(args, imageType)
+	}
+	query += " ORDER BY image_type NULLS LAST, image_order NULLS LAST, serial_number"
+
+	rows, err := utils.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	images := []GalleryImage{}
+	for rows.Next() {
+		var image GalleryImage
+		if err := scanGalleryImage(rows, &image); err != nil {
+			return nil, err
+		}
+		images = append(images, image)
+	}
+	return images, rows.Err()
+}
+
+// RegisterCompanyImageUploadRoutes는 회사 이미지 업로드, 갤러리, 파일 내려받기 엔드포인트를 등록합니다.
+func RegisterCompanyImageUploadRoutes(r *mux.Router) {
+	r.HandleFunc("/companies/{company_id}/images", GetCompanyGallery).Methods("GET")
+	r.HandleFunc("/companies/{company_id}/images", UploadCompanyImage).Methods("POST")
+	r.HandleFunc("/companies/{company_id}/images/order", ReorderCompanyGallery).Methods("PUT")
+	r.HandleFunc("/company-images/{id:[0-9]+}/content", GetCompanyImageContent).Methods("GET")
+	r.HandleFunc("/company-images/{id:[0-9]+}/thumbnail", GetCompanyImageThumbnail).Methods("GET")
+}
+
+// GetCompanyGallery: 회사 이미지 갤러리를 구분(image_type)과 표시 순서대로 조회합니다.
+func GetCompanyGallery(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	imageType := r.URL.Query().Get("image_type")
+	if imageType != "" {
+		if _, err := strconv.Atoi(imageType); err != nil {
+			http.Error(w, "잘못된 image_type", http.StatusBadRequest)
+			return
+		}
+	}
+	images, err := loadGallery(ctx, mux.Vars(r)["company_id"], imageType)
+	if err != nil {
+		log.Printf("데이터베이스 쿼리 오류: %v", err)
+		http.Error(w, "데이터 조회 중 오류가 발생했습니다", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(images)
+}
+
+// UploadCompanyImage: multipart/form-data로 회사 이미지를 업로드합니다.
+// 필드: file(필수), image_type(1=로고, 2=대표, 3=상세, 기본 3), title, description.
+// 파일 내용으로 형식(jpeg, png, gif)을 확인하고 썸네일을 만들며, 같은 회사에 같은 내용의 이미지가 있으면
+// 새로 저장하지 않고 기존 이미지를 200으로 반환합니다. 새 이미지는 같은 구분의 마지막 순서에 추가됩니다.
+func UploadCompanyImage(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.LONG_WORK_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	store, err := storage.Default()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	companyID := mux.Vars(r)["company_id"]
+
+	r.Body = http.MaxBytesReader(w, r.Body, COMPANY_IMAGE_MAX_BYTES+companyImageFormOverhead)
+	if err := r.ParseMultipartForm(COMPANY_IMAGE_MAX_BYTES); err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			http.Error(w, fmt.Sprintf("파일이 너무 큽니다 (최대 %dMB)", COMPANY_IMAGE_MAX_BYTES>>20), http.StatusRequestEntityTooLarge)
+		} else {
+			http.Error(w, "잘못된 multipart 요청입니다", http.StatusBadRequest)
+		}
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	imageType := COMPANY_IMAGE_TYPE_DETAIL
+	if v := r.FormValue("image_type"); v != "" {
+		imageType, err = strconv.Atoi(v)
+		if err != nil || imageType < COMPANY_IMAGE_TYPE_LOGO || imageType > COMPANY_IMAGE_TYPE_DETAIL {
+			http.Error(w, "image_type은 1(로고), 2(대표), 3(상세) 중 하나여야 합니다", http.StatusBadRequest)
+			return
+		}
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "필수 필드가 누락되었습니다 (file)", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, COMPANY_IMAGE_MAX_BYTES+1))
+	if err != nil {
+		http.Error(w, "파일을 읽을 수 없습니다", http.StatusBadRequest)
+		return
+	}
+	if len(data) > COMPANY_IMAGE_MAX_BYTES {
+		http.Error(w, fmt.Sprintf("파일이 너무 큽니다 (최대 %dMB)", COMPANY_IMAGE_MAX_BYTES>>20), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	info, err := utils.InspectImage(data)
+	if err != nil {
+		if errors.Is(err, utils.ErrUnsupportedImage) {
+			http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+		} else {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+		return
+	}
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	// 같은 회사에 같은 내용이 이미 있으면 기존 이미지를 반환합니다.
+	var image GalleryImage
+	err = scanGalleryImage(utils.DB.QueryRowContext(ctx,
+		"SELECT "+galleryImageColumns+" FROM company_image_table WHERE company_id = $1 AND content_hash = $2",
+		companyID, hash), &image)
+	if err == nil {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(image)
+		return
+	}
+	if err != sql.ErrNoRows {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	thumbnail, err := utils.MakeThumbnail(data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+		return
+	}
+	imageKey := companyImageKey(companyID, hash, info.Extension)
+	thumbnailKey := companyImageKey(companyID, hash, "_thumb.jpg")
+	if err := store.Put(ctx, imageKey, bytes.NewReader(data), int64(len(data)), info.ContentType); err != nil {
+		log.Printf("이미지 저장 실패: %s (%s), 오류: %v", imageKey, store.Name(), err)
+		http.Error(w, "이미지 저장 중 오류가 발생했습니다", http.StatusInternalServerError)
+		return
+	}
+	if err := store.Put(ctx, thumbnailKey, bytes.NewReader(thumbnail), int64(len(thumbnail)), "image/jpeg"); err != nil {
+		log.Printf("썸네일 저장 실패: %s (%s), 오류: %v", thumbnailKey, store.Name(), err)
+		deleteStoredImage(ctx, store, &imageKey)
+		http.Error(w, "이미지 저장 중 오류가 발생했습니다", http.StatusInternalServerError)
+		return
+	}
+
+	imageName := header.Filename
+	err = scanGalleryImage(utils.DB.QueryRowContext(ctx, `
+		INSERT INTO company_image_table
+		(company_id, image_type, image_order, image_url, title, description, image_name, image_path, thumbnail_path,
+		 image_size, content_type, content_hash, width, height, created_at, updated_at)
+		VALUES ($1, $2,
+			(SELECT COALESCE(MAX(image_order), 0) + 1 FROM company_image_table WHERE company_id = $1 AND image_type = $2),
+			$3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		RETURNING `+galleryImageColumns,
+		companyID, imageType, nullableString(store.URL(imageKey)), nullableString(r.FormValue("title")),
+		nullableString(r.FormValue("description")), nullableString(imageName), imageKey, thumbnailKey,
+		len(data), info.ContentType, hash, info.Width, info.Height), &image)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "duplicate key"):
+			// 동시에 같은 파일이 올라온 경우입니다. 저장소 키가 같으므로 파일은 지우지 않습니다.
+			http.Error(w, "이미 업로드된 이미지입니다", http.StatusConflict)
+		case strings.Contains(err.Error(), "foreign key"):
+			deleteStoredImage(ctx, store, &imageKey, &thumbnailKey)
+			http.Error(w, "회사를 찾을 수 없습니다.", http.StatusNotFound)
+		default:
+			log.Printf("DB 오류: %v", err)
+			deleteStoredImage(ctx, store, &imageKey, &thumbnailKey)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	log.Printf("회사 이미지 업로드: company_id=%s, id=%d, %s %dx%d %d바이트",
+		companyID, image.SerialNumber, info.ContentType, info.Width, info.Height, len(data))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(image)
+}
+
+// ReorderCompanyGallery: image_ids 순서대로 갤러리 표시 순서를 다시 매깁니다.
+// 목록에 없는 이미지의 순서는 바뀌지 않으며, 다른 회사의 이미지가 포함되면 400입니다.
+func ReorderCompanyGallery(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	companyID := mux.Vars(r)["company_id"]
+	var req GalleryOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.ImageIDs) == 0 {
+		http.Error(w, "잘못된 요청 데이터 (image_ids)", http.StatusBadRequest)
+		return
+	}
+	seen := make(map[int64]bool, len(req.ImageIDs))
+	for _, id := range req.ImageIDs {
+		if seen[id] {
+			http.Error(w, "image_ids에 중복된 이미지가 있습니다", http.StatusBadRequest)
+			return
+		}
+		seen[id] = true
+	}
+
+	tx, err := utils.DB.BeginTx(ctx, nil)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE company_image_table c SET image_order = t.ord, updated_at = CURRENT_TIMESTAMP
+		FROM unnest($2::bigint[]) WITH ORDINALITY AS t(id, ord)
+		WHERE c.serial_number = t.id AND c.company_id = $1`,
+		companyID, pq.Int64Array(req.ImageIDs))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if n, _ := res.RowsAffected(); n != int64(len(req.ImageIDs)) {
+		http.Error(w, "회사 이미지가 아닌 항목이 포함되어 있습니다", http.StatusBadRequest)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	images, err := loadGallery(ctx, companyID, "")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(images)
+}
+
+// GetCompanyImageContent: 업로드된 원본 이미지를 내려받습니다.
+func GetCompanyImageContent(w http.ResponseWriter, r *http.Request) {
+	serveCompanyImage(w, r, false)
+}
+
+// GetCompanyImageThumbnail: 업로드된 이미지의 썸네일(JPEG)을 내려받습니다.
+func GetCompanyImageThumbnail(w http.ResponseWriter, r *http.Request) {
+	serveCompanyImage(w, r, true)
+}
+
+// serveCompanyImage는 저장소의 파일을 응답으로 복사합니다.
+// 저장소 키가 내용 해시이므로 파일은 바뀌지 않으며, 오래 캐시하도록 응답합니다.
+func serveCompanyImage(w http.ResponseWriter, r *http.Request, thumbnail bool) {
+	timeout := time.Duration(consts.LONG_WORK_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	store, err := storage.Default()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	id, _ := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	var image GalleryImage
+	err = scanGalleryImage(utils.DB.QueryRowContext(ctx,
+		"SELECT "+galleryImageColumns+" FROM company_image_table WHERE serial_number = $1 AND content_hash IS NOT NULL",
+		id), &image)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "업로드된 이미지를 찾을 수 없습니다.", http.StatusNotFound)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	key, contentType, etag := image.imagePath, "", *image.ContentHash
+	if image.ContentType != nil {
+		contentType = *image.ContentType
+	}
+	if thumbnail {
+		key, contentType, etag = image.thumbnailPath, "image/jpeg", etag+"-thumb"
+	}
+	if key == nil {
+		http.Error(w, "업로드된 이미지를 찾을 수 없습니다.", http.StatusNotFound)
+		return
+	}
+	etag = `"` + etag + `"`
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	body, err := store.Open(ctx, *key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			http.Error(w, "저장된 파일을 찾을 수 없습니다.", http.StatusNotFound)
+		} else {
+			log.Printf("저장소 파일 읽기 실패: %s (%s), 오류: %v", *key, store.Name(), err)
+			http.Error(w, "파일을 읽는 중 오류가 발생했습니다", http.StatusInternalServerError)
+		}
+		return
+	}
+	defer body.Close()
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Header().Set("ETag", etag)
+	if _, err := io.Copy(w, body); err != nil {
+		log.Printf("이미지 전송 오류: id=%d, 오류: %v", id, err)
+	}
+}
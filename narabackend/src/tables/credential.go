@@ -0,0 +1,787 @@
+// credential.go
+package tables
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"golang.org/x/crypto/pbkdf2"
+
+	"narabackend/src/consts"
+	"narabackend/src/utils"
+)
+
+// 인증 수단 종류
+const (
+	CREDENTIAL_TYPE_CARD = "card" // RFID 카드
+	CREDENTIAL_TYPE_QR   = "qr"   // 시간 기반 서명 QR
+	CREDENTIAL_TYPE_PIN  = "pin"  // 숫자 PIN
+)
+
+// 인증 수단 상태
+const (
+	CREDENTIAL_STATUS_ACTIVE  = "active"  // 사용 가능
+	CREDENTIAL_STATUS_REVOKED = "revoked" // 폐기(분실, 재발급)
+)
+
+// 키오스크에서 허용되는 동작
+const (
+	KIOSK_ACTION_ENTRY     = "entry"     // 출입문 입장
+	KIOSK_ACTION_CHECK_IN  = "check_in"  // 입실
+	KIOSK_ACTION_CHECK_OUT = "check_out" // 퇴실
+	KIOSK_ACTION_PURCHASE  = "purchase"  // 이용권 구매
+)
+
+const (
+	QR_TOKEN_PREFIX         = "NQ1" // QR 토큰 형식 버전
+	QR_TOKEN_STEP_SECONDS   = 30    // QR 토큰 교체 주기(초)
+	QR_TOKEN_ALLOWED_SKEW   = 1     // 앞뒤로 허용하는 주기 수 (시계 오차, 화면 표시 지연)
+	PIN_MIN_LENGTH          = 4
+	PIN_MAX_LENGTH          = 8
+	PIN_MAX_FAILED_ATTEMPTS = 5  // 연속 실패 시 잠금
+	PIN_LOCK_MINUTES        = 15 // 잠금 시간(분)
+	PIN_HASH_SCHEME         = "pbkdf2-sha256"
+	PIN_HASH_ITERATIONS     = 600000 // PBKDF2 반복 횟수 (저장 값에 함께 기록되므로 올려도 기존 PIN은 그대로 검증됩니다)
+	PIN_SALT_BYTES          = 16
+)
+
+// credentialPepper는 PIN 해시에 섞는 서버 비밀 값입니다. DB가 유출되어도 PIN을 역산하기 어렵게 합니다.
+var credentialPepper []byte
+
+// legacySeatCards는 credential_table에 없는 카드를 seat_table.card_number에서 찾을지 여부입니다.
+var legacySeatCards bool
+
+// SetCredentialPepper는 PIN 해시에 사용할 서버 비밀 값을 설정합니다.
+// 값을 바꾸면 기존 PIN은 모두 다시 발급해야 합니다. 값이 없으면 PIN 발급과 인증이 거부됩니다.
+func SetCredentialPepper(pepper string) {
+	if pepper == "" {
+		log.Printf("경고: CREDENTIAL_PIN_PEPPER가 설정되지 않았습니다. PIN 발급과 인증을 사용할 수 없습니다.")
+	}
+	credentialPepper = []byte(pepper)
+}
+
+// SetLegacySeatCards는 seat_table.card_number에 등록된 기존 카드를 키오스크 인증에 인정할지 설정합니다.
+func SetLegacySeatCards(enabled bool) {
+	legacySeatCards = enabled
+}
+
+// Credential 구조체는 credential_table의 각 컬럼(비밀 값 제외)을 매핑합니다.
+type Credential struct {
+	SerialNumber   int64      `json:"serial_number" db:"serial_number"`
+	CompanyCode    string     `json:"company_code" db:"company_code"`
+	MemberID       int64      `json:"member_id" db:"member_id"`
+	CredentialType string     `json:"credential_type" db:"credential_type"`
+	CardNumber     *string    `json:"card_number" db:"card_number"`
+	Label          string     `json:"label" db:"label"`
+	Status         string     `json:"status" db:"status"`
+	FailedAttempts int        `json:"failed_attempts" db:"failed_attempts"`
+	LockedUntil    *time.Time `json:"locked_until" db:"locked_until"`
+	LastUsedAt     *time.Time `json:"last_used_at" db:"last_used_at"`
+	RevokedAt      *time.Time `json:"revoked_at" db:"revoked_at"`
+	RevokedBy      *string    `json:"revoked_by" db:"revoked_by"`
+	RevokeReason   *string    `json:"revoke_reason" db:"revoke_reason"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+	secret         string
+	qrLastStep     *int64
+}
+
+// CredentialRequest는 인증 수단 발급 요청 시 사용되는 구조체입니다.
+// card는 card_number, pin은 pin이 필수입니다. qr과 pin은 회원당 하나이며 다시 발급하면 기존 것이 폐기됩니다.
+type CredentialRequest struct {
+	CredentialType string `json:"credential_type"`
+	CardNumber     string `json:"card_number"`
+	Pin            string `json:"pin"`
+	Label          string `json:"label"`
+}
+
+// CredentialRevokeRequest는 인증 수단 폐기 요청 시 사용되는 구조체입니다.
+type CredentialRevokeRequest struct {
+	Reason    string `json:"reason"`
+	RevokedBy string `json:"revoked_by"`
+}
+
+// CredentialResolveRequest는 키오스크가 인증 수단으로 회원을 찾을 때 사용하는 구조체입니다.
+// card는 카드 번호, qr은 토큰, pin은 PIN을 value로 보내며 pin은 member_id 또는 phone이 함께 필요합니다.
+type CredentialResolveRequest struct {
+	CredentialType string `json:"credential_type"`
+	Value          string `json:"value"`
+	MemberID       int64  `json:"member_id"`
+	Phone          string `json:"phone"`
+}
+
+// CredentialResolution은 키오스크 인증 결과입니다.
+type CredentialResolution struct {
+	CredentialID    *int64   `json:"credential_id"`
+	CredentialType  string   `json:"credential_type"`
+	MemberID        int64    `json:"member_id"`
+	Name            string   `json:"name"`
+	MemberStatus    string   `json:"member_status"`
+	ActivePassCount int      `json:"active_pass_count"`
+	SessionID       *int64   `json:"session_id"`
+	SeatCode        *int     `json:"seat_code"`
+	OperatingMode   string   `json:"operating_mode"`
+	Actions         []string `json:"actions"`
+}
+
+// QRToken은 회원 앱에 표시할 QR 토큰입니다.
+type QRToken struct {
+	Token       string    `json:"token"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	StepSeconds int       `json:"step_seconds"`
+}
+
+// credentialColumns는 credential_table 조회 시 사용하는 컬럼 목록입니다.
+const credentialColumns = `serial_number, company_code, member_id, credential_type, card_number,
+	COALESCE(label, ''), status, failed_attempts, locked_until, last_used_at, revoked_at, revoked_by,
+	revoke_reason, created_at, updated_at, COALESCE(secret, ''), qr_last_step`
+
+// scanCredential은 한 행을 Credential로 읽습니다.
+func scanCredential(row interface{ Scan(...interface{}) error }, c *Credential) error {
+	return row.Scan(&c.SerialNumber, &c.CompanyCode, &c.MemberID, &c.CredentialType, &c.CardNumber,
+		&c.Label, &c.Status, &c.FailedAttempts, &c.LockedUntil, &c.LastUsedAt, &c.RevokedAt, &c.RevokedBy,
+		&c.RevokeReason, &c.CreatedAt, &c.UpdatedAt, &c.secret, &c.qrLastStep)
+}
+
+var (
+	errCredentialNotFound  = errors.New("인증 수단을 찾을 수 없습니다")
+	errCredentialRevoked   = errors.New("폐기된 인증 수단입니다")
+	errCredentialLocked    = errors.New("PIN 입력 횟수를 초과하여 잠겼습니다. 잠시 후 다시 시도하세요")
+	errCredentialInvalid   = errors.New("인증 정보가 올바르지 않습니다")
+	errCredentialReplayed  = errors.New("이미 사용된 QR 코드입니다. 새로 표시된 QR 코드로 다시 시도하세요")
+	errPinPepperMissing    = errors.New("PIN 서버 비밀 값(CREDENTIAL_PIN_PEPPER)이 설정되지 않았습니다")
+	errCredentialAmbiguous = errors.New("같은 전화번호의 회원이 여러 명입니다. 회원 번호로 다시 시도하세요")
+	errCredentialType      = errors.New("credential_type은 card, qr, pin 중 하나여야 합니다")
+	errPinTargetRequired   = errors.New("pin 인증에는 member_id 또는 phone이 필요합니다")
+)
+
+// randomSecret은 base64url로 인코딩된 임의 값을 만듭니다.
+func randomSecret(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// pinKey는 서버 비밀 값으로 PIN을 HMAC한 뒤 솔트와 반복 횟수로 PBKDF2 키를 만듭니다.
+func pinKey(pin string, salt []byte, iterations int) []byte {
+	mac := hmac.New(sha256.New, credentialPepper)
+	mac.Write([]byte(pin))
+	return pbkdf2.Key(mac.Sum(nil), salt, iterations, sha256.Size, sha256.New)
+}
+
+// hashPin은 새 솔트로 PIN 저장 값을 만듭니다. 서버 비밀 값이 없으면 발급하지 않습니다.
+// 형식: pbkdf2-sha256$<반복 횟수>$<솔트>$<해시>
+func hashPin(pin string) (string, error) {
+	if len(credentialPepper) == 0 {
+		return "", errPinPepperMissing
+	}
+	salt := make([]byte, PIN_SALT_BYTES)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s$%d$%s$%s", PIN_HASH_SCHEME, PIN_HASH_ITERATIONS,
+		base64.RawURLEncoding.EncodeToString(salt),
+		base64.RawURLEncoding.EncodeToString(pinKey(pin, salt, PIN_HASH_ITERATIONS))), nil
+}
+
+// pinMatches는 저장된 PIN 값과 입력한 PIN을 비교합니다.
+// 반복 횟수는 저장 값에 기록된 것을 사용하므로 PIN_HASH_ITERATIONS를 올려도 기존 PIN은 그대로 검증됩니다.
+func pinMatches(stored, pin string) bool {
+	if len(credentialPepper) == 0 {
+		return false
+	}
+	parts := strings.Split(stored, "$")
+	if len(parts) != 4 || parts[0] != PIN_HASH_SCHEME {
+		return false
+	}
+	iterations, err := strconv.Atoi(parts[1])
+	salt, err1 := base64.RawURLEncoding.DecodeString(parts[2])
+	hash, err2 := base64.RawURLEncoding.DecodeString(parts[3])
+	if err != nil || err1 != nil || err2 != nil || iterations < 1 {
+		return false
+	}
+	return hmac.Equal(hash, pinKey(pin, salt, iterations))
+}
+
+// validPin은 PIN이 허용 길이의 숫자인지 확인합니다.
+func validPin(pin string) bool {
+	if len(pin) < PIN_MIN_LENGTH || len(pin) > PIN_MAX_LENGTH {
+		return false
+	}
+	for _, r := range pin {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// signQRToken은 인증 수단 번호와 시간 주기에 대한 QR 토큰을 만듭니다.
+// 형식: NQ1.<인증 수단 번호>.<주기>.<서명>
+func signQRToken(c Credential, step int64) string {
+	payload := fmt.Sprintf("%s.%d.%d", QR_TOKEN_PREFIX, c.SerialNumber, step)
+	mac := hmac.New(sha256.New, []byte(c.secret))
+	mac.Write([]byte(payload))
+	return payload + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// parseQRToken은 QR 토큰에서 인증 수단 번호와 시간 주기를 읽습니다.
+func parseQRToken(token string) (int64, int64, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 4 || parts[0] != QR_TOKEN_PREFIX {
+		return 0, 0, false
+	}
+	id, err1 := strconv.ParseInt(parts[1], 10, 64)
+	step, err2 := strconv.ParseInt(parts[2], 10, 64)
+	return id, step, err1 == nil && err2 == nil
+}
+
+// qrStep은 시각이 속한 QR 토큰 주기입니다.
+func qrStep(t time.Time) int64 {
+	return t.Unix() / QR_TOKEN_STEP_SECONDS
+}
+
+// findCredential은 인증 요청으로 업체의 인증 수단을 찾아 검증합니다.
+// QR 토큰은 주기마다 한 번만 사용할 수 있고, PIN이 틀리면 실패 횟수를 올리고 허용 횟수를 넘으면 일정 시간 잠급니다.
+func findCredential(ctx context.Context, companyCode string, req CredentialResolveRequest, now time.Time) (Credential, error) {
+	var c Credential
+	switch req.CredentialType {
+	case CREDENTIAL_TYPE_CARD:
+		// 사용 중인 카드를 우선하고, 없으면 가장 최근에 폐기된 카드를 찾아 분실 카드임을 알립니다.
+		err := scanCredential(utils.DB.QueryRowContext(ctx, `
+			SELECT `+credentialColumns+` FROM credential_table
+			WHERE company_code = $1 AND credential_type = $2 AND card_number = $3
+			ORDER BY (status = $4) DESC, serial_number DESC LIMIT 1`,
+			companyCode, CREDENTIAL_TYPE_CARD, req.Value, CREDENTIAL_STATUS_ACTIVE), &c)
+		if err == sql.ErrNoRows {
+			return c, errCredentialNotFound
+		}
+		if err != nil {
+			return c, err
+		}
+
+	case CREDENTIAL_TYPE_QR:
+		id, step, ok := parseQRToken(req.Value)
+		if !ok {
+			return c, errCredentialInvalid
+		}
+		err := scanCredential(utils.DB.QueryRowContext(ctx,
+			"SELECT "+credentialColumns+" FROM credential_table WHERE serial_number = $1 AND company_code = $2 AND credential_type = $3",
+			id, companyCode, CREDENTIAL_TYPE_QR), &c)
+		if err == sql.ErrNoRows {
+			return c, errCredentialInvalid
+		}
+		if err != nil {
+			return c, err
+		}
+		current := qrStep(now)
+		if step < current-QR_TOKEN_ALLOWED_SKEW || step > current+QR_TOKEN_ALLOWED_SKEW ||
+			!hmac.Equal([]byte(req.Value), []byte(signQRToken(c, step))) {
+			return c, errCredentialInvalid
+		}
+		if c.Status != CREDENTIAL_STATUS_ACTIVE {
+			return c, errCredentialRevoked
+		}
+		// 같은 주기 또는 이미 사용한 주기보다 이전 토큰은 재사용(화면 캡처 등)으로 보고 거부합니다.
+		err = utils.DB.QueryRowContext(ctx, `
+			UPDATE credential_table SET qr_last_step = $2
+			WHERE serial_number = $1 AND (qr_last_step IS NULL OR qr_last_step < $2)
+			RETURNING qr_last_step`, c.SerialNumber, step).Scan(&c.qrLastStep)
+		if err == sql.ErrNoRows {
+			return c, errCredentialReplayed
+		}
+		if err != nil {
+			return c, err
+		}
+
+	case CREDENTIAL_TYPE_PIN:
+		query := "SELECT " + credentialColumns + " FROM credential_table WHERE company_code = $1 AND credential_type = $2 AND status = $3"
+		args := []interface{}{companyCode, CREDENTIAL_TYPE_PIN, CREDENTIAL_STATUS_ACTIVE}
+		switch {
+		case req.MemberID > 0:
+			query += " AND member_id = $4"
+			args = append(args, req.MemberID)
+		case req.Phone != "":
+			query += " AND member_id IN (SELECT serial_number FROM user_table WHERE phone1 = $4)"
+			args = append(args, req.Phone)
+		default:
+			return c, errPinTargetRequired
+		}
+		rows, err := utils.DB.QueryContext(ctx, query+" LIMIT 2", args...)
+		if err != nil {
+			return c, err
+		}
+		found := 0
+		for rows.Next() {
+			if err := scanCredential(rows, &c); err != nil {
+				rows.Close()
+				return c, err
+			}
+			found++
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return c, err
+		}
+		switch {
+		case found == 0:
+			return c, errCredentialInvalid
+		case found > 1:
+			return c, errCredentialAmbiguous
+		}
+		// 성공 시 사용 기록까지 verifyPin이 남깁니다.
+		return c, verifyPin(ctx, &c, req.Value, now)
+
+	default:
+		return c, errCredentialType
+	}
+
+	if c.Status != CREDENTIAL_STATUS_ACTIVE {
+		return c, errCredentialRevoked
+	}
+	_, err := utils.DB.ExecContext(ctx, `
+		UPDATE credential_table SET last_used_at = $2, failed_attempts = 0, locked_until = NULL
+		WHERE serial_number = $1`, c.SerialNumber, now)
+	return c, err
+}
+
+// verifyPin은 조회한 PIN 인증 수단의 잠금 여부를 확인하고 PIN을 비교합니다.
+// PBKDF2 계산은 행 잠금과 DB 연결 없이 하고, 결과는 잠기지 않은 경우에만 반영하는 조건부 UPDATE로 기록합니다.
+// 동시에 여러 PIN을 시도해도 실패 횟수가 빠짐없이 쌓이고, 그 사이 잠긴 인증 수단은 PIN이 맞아도 통과하지 않습니다.
+func verifyPin(ctx context.Context, c *Credential, pin string, now time.Time) error {
+	if len(credentialPepper) == 0 {
+		return errPinPepperMissing
+	}
+	if c.Status != CREDENTIAL_STATUS_ACTIVE {
+		return errCredentialRevoked
+	}
+	if c.LockedUntil != nil && c.LockedUntil.After(now) {
+		return errCredentialLocked
+	}
+
+	if !pinMatches(c.secret, pin) {
+		err := utils.DB.QueryRowContext(ctx, `
+			UPDATE credential_table
+			SET failed_attempts = failed_attempts + 1,
+			    locked_until = CASE WHEN failed_attempts + 1 >= $2 THEN $3::timestamp ELSE locked_until END,
+			    updated_at = CURRENT_TIMESTAMP
+			WHERE serial_number = $1 AND status = $4 AND (locked_until IS NULL OR locked_until <= $5)
+			RETURNING failed_attempts`,
+			c.SerialNumber, PIN_MAX_FAILED_ATTEMPTS, now.Add(PIN_LOCK_MINUTES*time.Minute),
+			CREDENTIAL_STATUS_ACTIVE, now).Scan(&c.FailedAttempts)
+		if err == sql.ErrNoRows {
+			// 다른 요청이 먼저 잠갔거나 폐기했습니다.
+			return errCredentialLocked
+		}
+		if err != nil {
+			return err
+		}
+		if c.FailedAttempts >= PIN_MAX_FAILED_ATTEMPTS {
+			return errCredentialLocked
+		}
+		return errCredentialInvalid
+	}
+
+	res, err := utils.DB.ExecContext(ctx, `
+		UPDATE credential_table SET last_used_at = $2, failed_attempts = 0, locked_until = NULL
+		WHERE serial_number = $1 AND status = $3 AND (locked_until IS NULL OR locked_until <= $2)`,
+		c.SerialNumber, now, CREDENTIAL_STATUS_ACTIVE)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return errCredentialLocked
+	}
+	return nil
+}
+
+// findLegacySeatCardMember는 credential_table에 없는 카드를 seat_table.card_number에서 찾습니다.
+// 기존 데스크에서 좌석에 등록한 카드를 credential_table로 옮기는 동안만 사용하며,
+// features.legacy_seat_cards가 꺼져 있으면 찾지 않습니다.
+func findLegacySeatCardMember(ctx context.Context, companyCode, cardNumber string) (int64, error) {
+	if !legacySeatCards {
+		return 0, errCredentialNotFound
+	}
+	var memberID string
+	err := utils.DB.QueryRowContext(ctx, `
+		SELECT member_id FROM seat_table
+		WHERE company_code = $1 AND card_number = $2 AND member_id IS NOT NULL AND member_id <> ''
+		LIMIT 1`, companyCode, cardNumber).Scan(&memberID)
+	if err == sql.ErrNoRows {
+		return 0, errCredentialNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	id, err := strconv.ParseInt(memberID, 10, 64)
+	if err != nil {
+		return 0, errCredentialNotFound
+	}
+	return id, nil
+}
+
+// kioskActions는 회원 상태와 이용 현황에 따라 키오스크에서 허용할 동작을 정합니다.
+func kioskActions(member Member) []string {
+	actions := []string{}
+	if member.Status != MEMBER_STATUS_ACTIVE {
+		return actions
+	}
+	actions = append(actions, KIOSK_ACTION_ENTRY)
+	if member.CurrentSeat != nil && member.CurrentSeat.SessionID != nil {
+		actions = append(actions, KIOSK_ACTION_CHECK_OUT)
+	} else if len(member.ActivePasses) > 0 {
+		actions = append(actions, KIOSK_ACTION_CHECK_IN)
+	}
+	return append(actions, KIOSK_ACTION_PURCHASE)
+}
+
+// RegisterCredentialRoutes는 credential_table 관련 엔드포인트를 등록합니다.
+func RegisterCredentialRoutes(r *mux.Router) {
+	r.HandleFunc("/companies/{company_code}/credentials", GetCredentials).Methods("GET")
+	r.HandleFunc("/companies/{company_code}/credentials/resolve", ResolveCredential).Methods("POST")
+	r.HandleFunc("/companies/{company_code}/credentials/{id:[0-9]+}/revoke", RevokeCredential).Methods("POST")
+	r.HandleFunc("/companies/{company_code}/credentials/{id:[0-9]+}/qr-token", GetQRToken).Methods("GET")
+	r.HandleFunc("/companies/{company_code}/members/{member_id:[0-9]+}/credentials", IssueCredential).Methods("POST")
+}
+
+// GetCredentials: 업체의 인증 수단 목록을 조회합니다.
+// member_id, credential_type, status, card_number로 필터링합니다.
+func GetCredentials(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	filters := []string{"company_code = $1"}
+	args := []interface{}{mux.Vars(r)["company_code"]}
+	filterParams := map[string]string{
+		"member_id":       "member_id",
+		"credential_type": "credential_type",
+		"status":          "status",
+		"card_number":     "card_number",
+	}
+	for param, dbField := range filterParams {
+		if value := r.URL.Query().Get(param); value != "" {
+			args = append(args, value)
+			filters = append(filters, fmt.Sprintf("%s = $%d", dbField, len(args)))
+		}
+	}
+	query := "SELECT " + credentialColumns + " FROM credential_table WHERE " +
+		strings.Join(filters, " AND ") + " ORDER BY serial_number DESC"
+
+	rows, err := utils.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("데이터베이스 쿼리 오류: %v", err)
+		http.Error(w, "데이터 조회 중 오류가 발생했습니다", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	result := []Credential{}
+	for rows.Next() {
+		var c Credential
+		if err := scanCredential(rows, &c); err != nil {
+			log.Printf("행 스캔 오류: %v", err)
+			http.Error(w, "데이터 처리 중 오류가 발생했습니다", http.StatusInternalServerError)
+			return
+		}
+		result = append(result, c)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// IssueCredential: 회원에게 인증 수단(card, qr, pin)을 발급합니다.
+// qr과 pin은 회원당 하나만 사용할 수 있어, 다시 발급하면 기존 것은 즉시 폐기됩니다.
+func IssueCredential(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	vars := mux.Vars(r)
+	memberID, _ := strconv.ParseInt(vars["member_id"], 10, 64)
+	var req CredentialRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "잘못된 요청 데이터", http.StatusBadRequest)
+		return
+	}
+
+	var cardNumber *string
+	var secret string
+	switch req.CredentialType {
+	case CREDENTIAL_TYPE_CARD:
+		req.CardNumber = strings.TrimSpace(req.CardNumber)
+		if req.CardNumber == "" {
+			http.Error(w, "card_number는 필수입니다", http.StatusBadRequest)
+			return
+		}
+		cardNumber = &req.CardNumber
+	case CREDENTIAL_TYPE_QR:
+		key, err := randomSecret(32)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		secret = key
+	case CREDENTIAL_TYPE_PIN:
+		if !validPin(req.Pin) {
+			http.Error(w, fmt.Sprintf("pin은 %d~%d자리 숫자여야 합니다", PIN_MIN_LENGTH, PIN_MAX_LENGTH), http.StatusBadRequest)
+			return
+		}
+		hash, err := hashPin(req.Pin)
+		if err == errPinPepperMissing {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		if err != nil {
+			log.Printf("PIN 해시 오류: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		secret = hash
+	default:
+		http.Error(w, "credential_type은 card, qr, pin 중 하나여야 합니다", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := utils.DB.BeginTx(ctx, nil)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	if req.CredentialType != CREDENTIAL_TYPE_CARD {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE credential_table
+			SET status = $4, revoked_at = CURRENT_TIMESTAMP, revoke_reason = '재발급', updated_at = CURRENT_TIMESTAMP
+			WHERE company_code = $1 AND member_id = $2 AND credential_type = $3 AND status = $5`,
+			vars["company_code"], memberID, req.CredentialType, CREDENTIAL_STATUS_REVOKED, CREDENTIAL_STATUS_ACTIVE)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	var c Credential
+	err = scanCredential(tx.QueryRowContext(ctx, `
+		INSERT INTO credential_table
+		(company_code, member_id, credential_type, card_number, secret, label, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		RETURNING `+credentialColumns,
+		vars["company_code"], memberID, req.CredentialType, cardNumber, nullableString(secret),
+		nullableString(req.Label), CREDENTIAL_STATUS_ACTIVE), &c)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "duplicate key"):
+			http.Error(w, "이미 사용 중인 카드 번호입니다", http.StatusConflict)
+		case strings.Contains(err.Error(), "foreign key"):
+			http.Error(w, "회원을 찾을 수 없습니다.", http.StatusNotFound)
+		default:
+			log.Printf("인증 수단 발급 오류: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("인증 수단 발급: company_code=%s, member_id=%d, type=%s, id=%d",
+		c.CompanyCode, c.MemberID, c.CredentialType, c.SerialNumber)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(c)
+}
+
+// RevokeCredential: 인증 수단을 즉시 폐기합니다. 분실 카드 신고 시 사용합니다.
+func RevokeCredential(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	vars := mux.Vars(r)
+	id, _ := strconv.ParseInt(vars["id"], 10, 64)
+	var req CredentialRevokeRequest
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "잘못된 요청 데이터", http.StatusBadRequest)
+			return
+		}
+	}
+
+	var c Credential
+	err := scanCredential(utils.DB.QueryRowContext(ctx, `
+		UPDATE credential_table
+		SET status = $3, revoked_at = CURRENT_TIMESTAMP, revoked_by = $4, revoke_reason = $5, updated_at = CURRENT_TIMESTAMP
+		WHERE serial_number = $1 AND company_code = $2 AND status = $6
+		RETURNING `+credentialColumns,
+		id, vars["company_code"], CREDENTIAL_STATUS_REVOKED, nullableString(req.RevokedBy),
+		nullableString(req.Reason), CREDENTIAL_STATUS_ACTIVE), &c)
+	if err == sql.ErrNoRows {
+		var status string
+		err = utils.DB.QueryRowContext(ctx,
+			"SELECT status FROM credential_table WHERE serial_number = $1 AND company_code = $2",
+			id, vars["company_code"]).Scan(&status)
+		if err == sql.ErrNoRows {
+			http.Error(w, errCredentialNotFound.Error(), http.StatusNotFound)
+			return
+		}
+		if err == nil {
+			http.Error(w, "이미 폐기된 인증 수단입니다", http.StatusConflict)
+			return
+		}
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("인증 수단 폐기: company_code=%s, id=%d, reason=%s", c.CompanyCode, c.SerialNumber, req.Reason)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(c)
+}
+
+// GetQRToken: 회원 앱에 표시할 현재 QR 토큰을 발급합니다. 토큰은 QR_TOKEN_STEP_SECONDS마다 바뀝니다.
+func GetQRToken(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	vars := mux.Vars(r)
+	id, _ := strconv.ParseInt(vars["id"], 10, 64)
+	var c Credential
+	err := scanCredential(utils.DB.QueryRowContext(ctx,
+		"SELECT "+credentialColumns+" FROM credential_table WHERE serial_number = $1 AND company_code = $2",
+		id, vars["company_code"]), &c)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, errCredentialNotFound.Error(), http.StatusNotFound)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	if c.CredentialType != CREDENTIAL_TYPE_QR {
+		http.Error(w, "QR 인증 수단이 아닙니다", http.StatusBadRequest)
+		return
+	}
+	if c.Status != CREDENTIAL_STATUS_ACTIVE {
+		http.Error(w, errCredentialRevoked.Error(), http.StatusConflict)
+		return
+	}
+
+	step := qrStep(time.Now())
+	token := QRToken{
+		Token:       signQRToken(c, step),
+		ExpiresAt:   time.Unix((step+1)*QR_TOKEN_STEP_SECONDS, 0),
+		StepSeconds: QR_TOKEN_STEP_SECONDS,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(token)
+}
+
+// ResolveCredential: 키오스크가 카드/QR/PIN으로 회원을 찾고 허용되는 동작을 조회합니다.
+// 허용 동작은 운영 시간표와 무인 운영 규칙을 반영합니다.
+// 폐기된 카드는 403, 잠긴 PIN은 423, 재사용된 QR 토큰과 그 밖의 인증 실패는 401을 반환합니다.
+func ResolveCredential(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	companyCode := mux.Vars(r)["company_code"]
+	var req CredentialResolveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "잘못된 요청 데이터", http.StatusBadRequest)
+		return
+	}
+	req.Value = strings.TrimSpace(req.Value)
+	if req.Value == "" {
+		http.Error(w, "value는 필수입니다", http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	result := CredentialResolution{CredentialType: req.CredentialType}
+	c, err := findCredential(ctx, companyCode, req, now)
+	switch {
+	case err == nil:
+		result.CredentialID = &c.SerialNumber
+		result.MemberID = c.MemberID
+	case err == errCredentialNotFound && req.CredentialType == CREDENTIAL_TYPE_CARD:
+		result.MemberID, err = findLegacySeatCardMember(ctx, companyCode, req.Value)
+	}
+	if err != nil {
+		switch err {
+		case errCredentialNotFound, errCredentialInvalid, errCredentialReplayed:
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+		case errCredentialRevoked:
+			http.Error(w, err.Error(), http.StatusForbidden)
+		case errCredentialLocked:
+			http.Error(w, err.Error(), http.StatusLocked)
+		case errCredentialAmbiguous:
+			http.Error(w, err.Error(), http.StatusConflict)
+		case errCredentialType, errPinTargetRequired:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errPinPepperMissing:
+			log.Printf("인증 수단 조회 오류: %v", err)
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		default:
+			log.Printf("인증 수단 조회 오류: %v", err)
+			http.Error(w, "데이터 조회 중 오류가 발생했습니다", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	member, err := loadMember(ctx, companyCode, result.MemberID, now)
+	if err != nil && err != errNotCompanyMember {
+		if err == sql.ErrNoRows {
+			http.Error(w, "회원을 찾을 수 없습니다.", http.StatusNotFound)
+			return
+		}
+		log.Printf("회원 조회 오류: %v", err)
+		http.Error(w, "데이터 조회 중 오류가 발생했습니다", http.StatusInternalServerError)
+		return
+	}
+	result.Name = member.Name
+	result.MemberStatus = member.Status
+	result.ActivePassCount = len(member.ActivePasses)
+	if member.CurrentSeat != nil {
+		result.SessionID = member.CurrentSeat.SessionID
+		result.SeatCode = &member.CurrentSeat.SeatCode
+	}
+	mode, err := operatingModeAt(ctx, companyCode, now)
+	if err == nil {
+		result.OperatingMode = mode.Mode
+		result.Actions, err = filterKioskActions(ctx, companyCode, member, kioskActions(member), mode)
+	}
+	if err != nil {
+		log.Printf("키오스크 판정 오류: %v", err)
+		http.Error(w, "데이터 조회 중 오류가 발생했습니다", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
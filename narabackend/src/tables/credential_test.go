@@ -0,0 +1,57 @@
+// credential_test.go
+package tables
+
+import (
+	"strings"
+	"testing"
+)
+
+// withPepper는 테스트 동안 PIN 서버 비밀 값을 바꾸고 끝나면 되돌립니다.
+func withPepper(t *testing.T, pepper string) {
+	prev := credentialPepper
+	credentialPepper = []byte(pepper)
+	t.Cleanup(func() { credentialPepper = prev })
+}
+
+func TestHashPin(t *testing.T) {
+	withPepper(t, "pepper")
+
+	stored, err := hashPin("1234")
+	if err != nil {
+		t.Fatalf("hashPin: %v", err)
+	}
+	if !strings.HasPrefix(stored, PIN_HASH_SCHEME+"$") {
+		t.Fatalf("저장 형식이 다릅니다: %s", stored)
+	}
+	if again, _ := hashPin("1234"); again == stored {
+		t.Fatal("같은 PIN의 저장 값이 같습니다 (솔트 미사용)")
+	}
+	if !pinMatches(stored, "1234") {
+		t.Fatal("올바른 PIN이 통과하지 못했습니다")
+	}
+	if pinMatches(stored, "4321") {
+		t.Fatal("틀린 PIN이 통과했습니다")
+	}
+
+	// 다른 서버 비밀 값으로는 검증되지 않습니다.
+	withPepper(t, "other")
+	if pinMatches(stored, "1234") {
+		t.Fatal("다른 서버 비밀 값으로 PIN이 통과했습니다")
+	}
+}
+
+func TestPinRequiresPepper(t *testing.T) {
+	withPepper(t, "pepper")
+	stored, err := hashPin("1234")
+	if err != nil {
+		t.Fatalf("hashPin: %v", err)
+	}
+
+	withPepper(t, "")
+	if _, err := hashPin("1234"); err != errPinPepperMissing {
+		t.Fatalf("서버 비밀 값 없이 PIN이 발급되었습니다: %v", err)
+	}
+	if pinMatches(stored, "1234") {
+		t.Fatal("서버 비밀 값 없이 PIN이 통과했습니다")
+	}
+}
@@ -0,0 +1,414 @@
+// dashboard.go
+package tables
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"narabackend/src/consts"
+	"narabackend/src/utils"
+)
+
+// DASHBOARD_MAX_RANGE_DAYS는 대시보드/집계 갱신에서 한 번에 조회할 수 있는 최대 일수입니다.
+const DASHBOARD_MAX_RANGE_DAYS = 366
+
+// DashboardStats는 naradesk DashboardStats 모델과 같은 필드 이름으로 응답하는 대시보드 통계입니다.
+type DashboardStats struct {
+	CompanyCode     string         `json:"companyCode"`
+	From            string         `json:"from"`
+	To              string         `json:"to"`
+	TotalMembers    int            `json:"totalMembers"`
+	ActiveMembers   int            `json:"activeMembers"`
+	TotalSeats      int            `json:"totalSeats"`
+	OccupiedSeats   int            `json:"occupiedSeats"`
+	TodayRevenue    int64          `json:"todayRevenue"`
+	MonthlyRevenue  int64          `json:"monthlyRevenue"`
+	DailyUsageData  []DailyUsage   `json:"dailyUsageData"`
+	HourlyUsageData []HourlyUsage  `json:"hourlyUsageData"`
+	SeatTypeUsage   map[string]int `json:"seatTypeUsage"` // 이용 중인 좌석의 이용권 구분별 개수
+	Lockers         LockerCounts   `json:"lockers"`
+}
+
+// DailyUsage는 일자별 이용/매출 집계입니다.
+type DailyUsage struct {
+	Date         string `json:"date"`
+	UserCount    int    `json:"userCount"`
+	SessionCount int    `json:"sessionCount"`
+	UsedMinutes  int    `json:"usedMinutes"`
+	PaymentCount int    `json:"paymentCount"`
+	Revenue      int64  `json:"revenue"`
+}
+
+// HourlyUsage는 시간대별 이용 회원 수 합계입니다.
+type HourlyUsage struct {
+	Hour      int `json:"hour"`
+	UserCount int `json:"userCount"`
+}
+
+// dailyUsageQuery는 $1 일자의 업체별 일일 집계를 계산합니다. $2가 NULL이면 모든 업체를 계산합니다.
+// 아직 퇴실하지 않은 세션은 $3(기준 시각)까지 이용한 것으로 봅니다.
+const dailyUsageQuery = `
+	WITH bounds AS (SELECT $1::date AS day_start, $1::date + 1 AS day_end),
+	usage AS (
+		SELECT s.company_code,
+		       COUNT(*) FILTER (WHERE s.check_in_at >= b.day_start) AS session_count,
+		       COUNT(DISTINCT s.member_id) AS user_count,
+		       COALESCE(SUM(CEIL(EXTRACT(EPOCH FROM
+		           LEAST(COALESCE(s.check_out_at, $3), b.day_end) - GREATEST(s.check_in_at, b.day_start)) / 60)), 0)::BIGINT AS used_minutes
+		FROM seat_session_table s, bounds b
+		WHERE s.check_in_at < b.day_end AND COALESCE(s.check_out_at, $3) > b.day_start
+		  AND ($2::text IS NULL OR s.company_code = $2)
+		GROUP BY s.company_code
+	),
+	sales AS (
+		SELECT p.company_code,
+		       COUNT(*) FILTER (WHERE p.payment_type <> 'refund') AS payment_count,
+		       COALESCE(SUM(CASE WHEN p.payment_type = 'refund' THEN -p.amount ELSE p.amount END), 0) AS revenue
+		FROM payment_table p, bounds b
+		WHERE p.created_at >= b.day_start AND p.created_at < b.day_end
+		  AND ($2::text IS NULL OR p.company_code = $2)
+		GROUP BY p.company_code
+	)
+	SELECT COALESCE(u.company_code, s.company_code), $1::date,
+	       COALESCE(u.session_count, 0), COALESCE(u.user_count, 0), COALESCE(u.used_minutes, 0),
+	       COALESCE(s.payment_count, 0), COALESCE(s.revenue, 0)
+	FROM usage u FULL OUTER JOIN sales s ON s.company_code = u.company_code`
+
+// hourlyUsageQuery는 $1 일자의 업체별 시간대별 이용 회원 수를 계산합니다. 파라미터는 dailyUsageQuery와 같습니다.
+const hourlyUsageQuery = `
+	WITH hours AS (
+		SELECT h, $1::date + make_interval(hours => h) AS hour_start FROM generate_series(0, 23) AS h
+	)
+	SELECT s.company_code, $1::date, hours.h, COUNT(DISTINCT s.member_id)
+	FROM hours
+	JOIN seat_session_table s
+	  ON s.check_in_at < hours.hour_start + INTERVAL '1 hour' AND COALESCE(s.check_out_at, $3) > hours.hour_start
+	WHERE ($2::text IS NULL OR s.company_code = $2)
+	GROUP BY s.company_code, hours.h`
+
+// refreshDashboardRollups는 date 일자의 모든 업체 집계를 다시 계산하여 집계 테이블에 저장합니다.
+func refreshDashboardRollups(ctx context.Context, date string, now time.Time) error {
+	tx, err := utils.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// 같은 일자의 동시 갱신을 직렬화합니다.
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock(hashtext('dashboard_rollup:' || $1))", date); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM daily_usage_rollup_table WHERE usage_date = $1::date", date); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO daily_usage_rollup_table
+		(company_code, usage_date, session_count, user_count, used_minutes, payment_count, revenue)
+		`+dailyUsageQuery, date, nil, now)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM hourly_usage_rollup_table WHERE usage_date = $1::date", date); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO hourly_usage_rollup_table (company_code, usage_date, hour, user_count)
+		`+hourlyUsageQuery, date, nil, now)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// StartDashboardRollupScheduler는 주기적으로 어제와 오늘의 대시보드 집계를 갱신하는 백그라운드 작업을 시작합니다.
+// 어제를 함께 갱신하여 자정을 넘긴 세션과 늦게 기록된 결제를 반영합니다.
+func StartDashboardRollupScheduler(interval time.Duration) {
+	if interval <= 0 {
+		log.Printf("대시보드 집계 스케줄러 비활성화 (interval=%s)", interval)
+		return
+	}
+	log.Printf("대시보드 집계 스케줄러 시작 (interval=%s)", interval)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			runDashboardRollup()
+			<-ticker.C
+		}
+	}()
+}
+
+// runDashboardRollup은 한 번의 집계 갱신을 실행합니다.
+func runDashboardRollup() {
+	timeout := time.Duration(consts.LONG_WORK_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	now := time.Now()
+	for _, day := range []time.Time{now.AddDate(0, 0, -1), now} {
+		date := day.Format("2006-01-02")
+		if err := refreshDashboardRollups(ctx, date, now); err != nil {
+			log.Printf("대시보드 집계 갱신 오류 (%s): %v", date, err)
+		}
+	}
+}
+
+// parseDashboardRange는 from/to 쿼리 파라미터(YYYY-MM-DD)를 읽습니다.
+// 둘 다 없으면 오늘을 포함한 최근 7일을 사용합니다.
+func parseDashboardRange(r *http.Request) (time.Time, time.Time, string) {
+	y, m, d := time.Now().Date()
+	to := time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+	from := to.AddDate(0, 0, -6)
+
+	if v := r.URL.Query().Get("to"); v != "" {
+		t, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			return from, to, "잘못된 to 형식입니다 (YYYY-MM-DD)"
+		}
+		to = t
+		from = to.AddDate(0, 0, -6)
+	}
+	if v := r.URL.Query().Get("from"); v != "" {
+		t, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			return from, to, "잘못된 from 형식입니다 (YYYY-MM-DD)"
+		}
+		from = t
+	}
+	if from.After(to) {
+		return from, to, "from은 to보다 늦을 수 없습니다"
+	}
+	if to.Sub(from) >= DASHBOARD_MAX_RANGE_DAYS*24*time.Hour {
+		return from, to, "조회 기간이 너무 깁니다"
+	}
+	return from, to, ""
+}
+
+// RegisterDashboardRoutes는 대시보드 통계 관련 엔드포인트를 등록합니다.
+func RegisterDashboardRoutes(r *mux.Router) {
+	r.HandleFunc("/companies/{company_code}/dashboard", GetDashboard).Methods("GET")
+	r.HandleFunc("/dashboard/rollups/refresh", RefreshDashboardRollups).Methods("POST")
+}
+
+// GetDashboard: 업체의 대시보드 통계를 조회합니다.
+// from, to 쿼리 파라미터(YYYY-MM-DD)로 일별/시간대별 집계 기간을 지정합니다.
+// 지난 일자는 집계 테이블에서 읽고, 오늘은 원본 테이블에서 바로 계산합니다.
+func GetDashboard(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.LONG_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	companyCode := mux.Vars(r)["company_code"]
+	from, to, msg := parseDashboardRange(r)
+	if msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+
+	stats, err := loadDashboardStats(ctx, companyCode, from, to, time.Now())
+	if err != nil {
+		log.Printf("대시보드 조회 오류: %v", err)
+		http.Error(w, "데이터 조회 중 오류가 발생했습니다", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
+
+// loadDashboardStats는 업체의 현재 현황과 기간별 집계를 모읍니다.
+func loadDashboardStats(ctx context.Context, companyCode string, from, to, now time.Time) (DashboardStats, error) {
+	today := now.Format("2006-01-02")
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).Format("2006-01-02")
+	stats := DashboardStats{
+		CompanyCode:     companyCode,
+		From:            from.Format("2006-01-02"),
+		To:              to.Format("2006-01-02"),
+		DailyUsageData:  []DailyUsage{},
+		HourlyUsageData: make([]HourlyUsage, 24),
+		SeatTypeUsage:   map[string]int{},
+	}
+	for h := range stats.HourlyUsageData {
+		stats.HourlyUsageData[h].Hour = h
+	}
+
+	// 회원 현황: 업체에서 이용권을 발급받은 회원 기준
+	err := utils.DB.QueryRowContext(ctx, `
+		SELECT COUNT(DISTINCT member_id),
+		       COUNT(DISTINCT member_id) FILTER (WHERE status = $2 AND (valid_until IS NULL OR valid_until > $3))
+		FROM member_pass_table WHERE company_code = $1`,
+		companyCode, PASS_STATUS_ACTIVE, now).Scan(&stats.TotalMembers, &stats.ActiveMembers)
+	if err != nil {
+		return stats, err
+	}
+
+	// 좌석 현황
+	err = utils.DB.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM seat_table WHERE company_code = $1", companyCode).Scan(&stats.TotalSeats)
+	if err != nil {
+		return stats, err
+	}
+	rows, err := utils.DB.QueryContext(ctx, `
+		SELECT COALESCE(p.pass_type, 'unknown'), COUNT(*)
+		FROM seat_session_table s
+		LEFT JOIN member_pass_table p ON p.serial_number = s.pass_id
+		WHERE s.company_code = $1 AND s.check_out_at IS NULL
+		GROUP BY 1`, companyCode)
+	if err != nil {
+		return stats, err
+	}
+	for rows.Next() {
+		var passType string
+		var count int
+		if err := rows.Scan(&passType, &count); err != nil {
+			rows.Close()
+			return stats, err
+		}
+		stats.SeatTypeUsage[passType] = count
+		stats.OccupiedSeats += count
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return stats, err
+	}
+
+	if stats.Lockers, err = lockerCounts(ctx, companyCode); err != nil {
+		return stats, err
+	}
+
+	// 오늘 집계는 원본에서 계산합니다.
+	todayUsage := DailyUsage{Date: today}
+	var ignoredCompany, ignoredDate interface{}
+	err = utils.DB.QueryRowContext(ctx, dailyUsageQuery, today, companyCode, now).Scan(&ignoredCompany, &ignoredDate,
+		&todayUsage.SessionCount, &todayUsage.UserCount, &todayUsage.UsedMinutes, &todayUsage.PaymentCount, &todayUsage.Revenue)
+	if err != nil && err != sql.ErrNoRows {
+		return stats, err
+	}
+	stats.TodayRevenue = todayUsage.Revenue
+
+	// 이번 달 매출: 지난 일자는 집계 테이블 + 오늘
+	err = utils.DB.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(revenue), 0) FROM daily_usage_rollup_table
+		WHERE company_code = $1 AND usage_date >= $2::date AND usage_date < $3::date`,
+		companyCode, monthStart, today).Scan(&stats.MonthlyRevenue)
+	if err != nil {
+		return stats, err
+	}
+	stats.MonthlyRevenue += stats.TodayRevenue
+
+	// 일별 집계
+	daily := map[string]DailyUsage{}
+	rows, err = utils.DB.QueryContext(ctx, `
+		SELECT to_char(usage_date, 'YYYY-MM-DD'), session_count, user_count, used_minutes, payment_count, revenue
+		FROM daily_usage_rollup_table
+		WHERE company_code = $1 AND usage_date BETWEEN $2::date AND $3::date AND usage_date < $4::date`,
+		companyCode, stats.From, stats.To, today)
+	if err != nil {
+		return stats, err
+	}
+	for rows.Next() {
+		var u DailyUsage
+		if err := rows.Scan(&u.Date, &u.SessionCount, &u.UserCount, &u.UsedMinutes, &u.PaymentCount, &u.Revenue); err != nil {
+			rows.Close()
+			return stats, err
+		}
+		daily[u.Date] = u
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return stats, err
+	}
+	if stats.From <= today && today <= stats.To {
+		daily[today] = todayUsage
+	}
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		date := day.Format("2006-01-02")
+		u, ok := daily[date]
+		if !ok {
+			u = DailyUsage{Date: date}
+		}
+		stats.DailyUsageData = append(stats.DailyUsageData, u)
+	}
+
+	// 시간대별 집계: 기간 내 지난 일자는 집계 테이블, 오늘은 원본
+	rows, err = utils.DB.QueryContext(ctx, `
+		SELECT hour, SUM(user_count) FROM hourly_usage_rollup_table
+		WHERE company_code = $1 AND usage_date BETWEEN $2::date AND $3::date AND usage_date < $4::date
+		GROUP BY hour`,
+		companyCode, stats.From, stats.To, today)
+	if err != nil {
+		return stats, err
+	}
+	for rows.Next() {
+		var hour, count int
+		if err := rows.Scan(&hour, &count); err != nil {
+			rows.Close()
+			return stats, err
+		}
+		stats.HourlyUsageData[hour].UserCount += count
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return stats, err
+	}
+	if stats.From <= today && today <= stats.To {
+		rows, err = utils.DB.QueryContext(ctx, hourlyUsageQuery, today, companyCode, now)
+		if err != nil {
+			return stats, err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var company, date interface{}
+			var hour, count int
+			if err := rows.Scan(&company, &date, &hour, &count); err != nil {
+				return stats, err
+			}
+			stats.HourlyUsageData[hour].UserCount += count
+		}
+		if err := rows.Err(); err != nil {
+			return stats, err
+		}
+	}
+	return stats, nil
+}
+
+// RefreshDashboardRollups: 지정한 기간의 대시보드 집계를 다시 계산합니다 (과거 데이터 백필용).
+// from, to 쿼리 파라미터(YYYY-MM-DD)를 사용하며, 없으면 최근 7일을 갱신합니다.
+func RefreshDashboardRollups(w http.ResponseWriter, r *http.Request) {
+	from, to, msg := parseDashboardRange(r)
+	if msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+
+	// 기간이 길 수 있으므로 일자마다 타임아웃을 적용합니다.
+	now := time.Now()
+	refreshed := 0
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		timeout := time.Duration(consts.LONG_WORK_TIMEOUT) * time.Second
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		err := refreshDashboardRollups(ctx, day.Format("2006-01-02"), now)
+		cancel()
+		if err != nil {
+			log.Printf("대시보드 집계 갱신 오류 (%s): %v", day.Format("2006-01-02"), err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		refreshed++
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"from":      from.Format("2006-01-02"),
+		"to":        to.Format("2006-01-02"),
+		"refreshed": refreshed,
+	})
+}
@@ -0,0 +1,277 @@
+// job_admin.go
+package tables
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"narabackend/src/consts"
+	"narabackend/src/utils"
+)
+
+// JobRecord 구조체는 job_table의 각 컬럼을 매핑합니다.
+type JobRecord struct {
+	SerialNumber   int64           `json:"serial_number" db:"serial_number"`
+	Name           string          `json:"name" db:"name"`
+	Payload        json.RawMessage `json:"payload" db:"payload"`
+	IdempotencyKey *string         `json:"idempotency_key" db:"idempotency_key"`
+	Priority       int             `json:"priority" db:"priority"`
+	Status         string          `json:"status" db:"status"`
+	Attempts       int             `json:"attempts" db:"attempts"`
+	MaxAttempts    int             `json:"max_attempts" db:"max_attempts"`
+	RunAt          time.Time       `json:"run_at" db:"run_at"`
+	LockedBy       *string         `json:"locked_by" db:"locked_by"`
+	LockedUntil    *time.Time      `json:"locked_until" db:"locked_until"`
+	LastError      *string         `json:"last_error" db:"last_error"`
+	ErrorHistory   json.RawMessage `json:"error_history" db:"error_history"`
+	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
+	StartedAt      *time.Time      `json:"started_at" db:"started_at"`
+	FinishedAt     *time.Time      `json:"finished_at" db:"finished_at"`
+	UpdatedAt      time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// JobQueueCount는 작업 이름/상태별 작업 개수입니다.
+type JobQueueCount struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Count  int64  `json:"count"`
+}
+
+// JobQueueMetrics는 작업 큐 모니터링 응답입니다.
+// Process는 이 서버의 처리 통계이고, 나머지는 job_table 전체의 현재 상태입니다.
+type JobQueueMetrics struct {
+	Process            utils.JobMetrics `json:"process"`
+	Counts             []JobQueueCount  `json:"counts"`
+	ReadyCount         int64            `json:"ready_count"`          // 지금 실행 가능한 대기 작업 수
+	OldestReadySeconds float64          `json:"oldest_ready_seconds"` // 가장 오래 기다린 실행 가능 작업의 대기 시간(초)
+	Handlers           []string         `json:"handlers"`             // 이 서버에 등록된 작업 처리기
+}
+
+const jobRecordColumns = `serial_number, name, payload, idempotency_key, priority, status, attempts, max_attempts,
+	run_at, locked_by, locked_until, last_error, error_history, created_at, started_at, finished_at, updated_at`
+
+// scanJobRecord는 한 행을 JobRecord로 읽습니다.
+func scanJobRecord(row interface{ Scan(...interface{}) error }, job *JobRecord) error {
+	return row.Scan(&job.SerialNumber, &job.Name, &job.Payload, &job.IdempotencyKey, &job.Priority, &job.Status,
+		&job.Attempts, &job.MaxAttempts, &job.RunAt, &job.LockedBy, &job.LockedUntil, &job.LastError,
+		&job.ErrorHistory, &job.CreatedAt, &job.StartedAt, &job.FinishedAt, &job.UpdatedAt)
+}
+
+// RegisterJobAdminRoutes는 작업 큐 조회/재시도/취소/dead-letter 정리와 통계 엔드포인트를 등록합니다.
+func RegisterJobAdminRoutes(r *mux.Router) {
+	r.HandleFunc("/jobs", GetJobs).Methods("GET")
+	r.HandleFunc("/jobs/metrics", GetJobMetrics).Methods("GET")
+	r.HandleFunc("/jobs/dead", PurgeDeadJobs).Methods("DELETE")
+	r.HandleFunc("/jobs/{id:[0-9]+}", GetJob).Methods("GET")
+	r.HandleFunc("/jobs/{id:[0-9]+}/retry", RetryJob).Methods("POST")
+	r.HandleFunc("/jobs/{id:[0-9]+}/cancel", CancelJob).Methods("POST")
+}
+
+// GetJobs: 작업 목록을 최근 순으로 조회합니다. status, name, idempotency_key로 필터링할 수 있습니다.
+func GetJobs(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	filters := []string{"TRUE"}
+	args := []interface{}{}
+	paramIdx := 1
+
+	filterParams := map[string]string{
+		"status":          "status",
+		"name":            "name",
+		"idempotency_key": "idempotency_key",
+	}
+	for param, dbField := range filterParams {
+		if value := r.URL.Query().Get(param); value != "" {
+			filters = append(filters, fmt.Sprintf("%s = $%d", dbField, paramIdx))
+			args = append(args, value)
+			paramIdx++
+		}
+	}
+
+	page, pageSize, err := parsePagination(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	query := "SELECT " + jobRecordColumns + " FROM job_table WHERE " + strings.Join(filters, " AND ") +
+		fmt.Sprintf(" ORDER BY created_at DESC, serial_number DESC LIMIT $%d OFFSET $%d", paramIdx, paramIdx+1)
+	args = append(args, pageSize, (page-1)*pageSize)
+
+	rows, err := utils.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("데이터베이스 쿼리 오류: %v", err)
+		http.Error(w, "데이터 조회 중 오류가 발생했습니다", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	result := []JobRecord{}
+	for rows.Next() {
+		var job JobRecord
+		if err := scanJobRecord(rows, &job); err != nil {
+			log.Printf("행 스캔 오류: %v", err)
+			http.Error(w, "데이터 처리 중 오류가 발생했습니다", http.StatusInternalServerError)
+			return
+		}
+		result = append(result, job)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// GetJob: 작업 하나를 데이터(payload)와 실패 이력(error_history)까지 조회합니다.
+func GetJob(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	id, _ := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	writeJobRecord(ctx, w, id)
+}
+
+// writeJobRecord는 작업 하나를 조회하여 응답합니다.
+func writeJobRecord(ctx context.Context, w http.ResponseWriter, id int64) {
+	var job JobRecord
+	err := scanJobRecord(utils.DB.QueryRowContext(ctx,
+		"SELECT "+jobRecordColumns+" FROM job_table WHERE serial_number = $1", id), &job)
+	if err == sql.ErrNoRows {
+		http.Error(w, utils.ErrJobNotFound.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job)
+}
+
+// RetryJob: dead 또는 취소된 작업을 다시 실행합니다. 재시도 대기 중인 작업은 바로 실행합니다.
+func RetryJob(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	id, _ := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err := utils.RetryJob(ctx, id); err != nil {
+		writeJobAdminError(w, err)
+		return
+	}
+	log.Printf("작업 재시도 요청: id=%d", id)
+	writeJobRecord(ctx, w, id)
+}
+
+// CancelJob: 실행 대기 중인 작업을 취소합니다.
+func CancelJob(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	id, _ := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err := utils.CancelJob(ctx, id); err != nil {
+		writeJobAdminError(w, err)
+		return
+	}
+	log.Printf("작업 취소 요청: id=%d", id)
+	writeJobRecord(ctx, w, id)
+}
+
+// writeJobAdminError는 작업 관리 오류를 HTTP 상태 코드로 바꿔 응답합니다.
+func writeJobAdminError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, utils.ErrJobNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, utils.ErrJobNotRetryable), errors.Is(err, utils.ErrJobNotQueued):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		log.Printf("작업 관리 오류: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// PurgeDeadJobs: dead 작업(dead-letter)을 삭제합니다.
+// name으로 작업을, before(YYYY-MM-DD)로 그 날짜 이전에 dead가 된 작업만 지정할 수 있습니다.
+func PurgeDeadJobs(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.LONG_WORK_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	var before *time.Time
+	if v := r.URL.Query().Get("before"); v != "" {
+		t, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			http.Error(w, "before는 YYYY-MM-DD 형식이어야 합니다", http.StatusBadRequest)
+			return
+		}
+		before = &t
+	}
+	name := r.URL.Query().Get("name")
+
+	deleted, err := utils.PurgeDeadJobs(ctx, name, before)
+	if err != nil {
+		log.Printf("dead 작업 삭제 오류: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	log.Printf("dead 작업 삭제: %d건 (name=%q, before=%v)", deleted, name, before)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int64{"deleted": deleted})
+}
+
+// GetJobMetrics: 이 서버의 작업 처리 통계(enqueued/succeeded/failed/retried, 큐 대기 시간)와
+// 작업 이름/상태별 개수, 실행 가능 작업의 대기 현황을 조회합니다.
+func GetJobMetrics(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	metrics := JobQueueMetrics{
+		Process:  utils.JobMetricsSnapshot(),
+		Counts:   []JobQueueCount{},
+		Handlers: utils.JobHandlerNames(),
+	}
+
+	rows, err := utils.DB.QueryContext(ctx,
+		"SELECT name, status, COUNT(*) FROM job_table GROUP BY name, status ORDER BY name, status")
+	if err != nil {
+		log.Printf("데이터베이스 쿼리 오류: %v", err)
+		http.Error(w, "데이터 조회 중 오류가 발생했습니다", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var c JobQueueCount
+		if err := rows.Scan(&c.Name, &c.Status, &c.Count); err != nil {
+			log.Printf("행 스캔 오류: %v", err)
+			http.Error(w, "데이터 처리 중 오류가 발생했습니다", http.StatusInternalServerError)
+			return
+		}
+		metrics.Counts = append(metrics.Counts, c)
+	}
+
+	err = utils.DB.QueryRowContext(ctx, `
+		SELECT COUNT(*), COALESCE(EXTRACT(EPOCH FROM CURRENT_TIMESTAMP - MIN(run_at)), 0)::float8
+		FROM job_table WHERE status = $1 AND run_at <= CURRENT_TIMESTAMP`,
+		utils.JOB_STATUS_QUEUED).Scan(&metrics.ReadyCount, &metrics.OldestReadySeconds)
+	if err != nil {
+		log.Printf("데이터베이스 쿼리 오류: %v", err)
+		http.Error(w, "데이터 조회 중 오류가 발생했습니다", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(metrics)
+}
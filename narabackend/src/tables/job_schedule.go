@@ -0,0 +1,372 @@
+// job_schedule.go
+package tables
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"narabackend/src/consts"
+	"narabackend/src/utils"
+)
+
+// JobSchedule 구조체는 job_schedule_table의 각 컬럼과 마지막 작업의 실행 결과를 매핑합니다.
+// company_code가 빈 값이면 코드에서 정의한 전체 공통 일정입니다.
+type JobSchedule struct {
+	SerialNumber      int64           `json:"serial_number" db:"serial_number"`
+	ScheduleKey       string          `json:"schedule_key" db:"schedule_key"`
+	CompanyCode       string          `json:"company_code" db:"company_code"`
+	CronExpr          string          `json:"cron_expr" db:"cron_expr"`
+	Timezone          *string         `json:"timezone" db:"timezone"`
+	JobName           string          `json:"job_name" db:"job_name"`
+	Payload           json.RawMessage `json:"payload" db:"payload"`
+	Description       *string         `json:"description" db:"description"`
+	Enabled           bool            `json:"enabled" db:"enabled"`
+	LastRunAt         *time.Time      `json:"last_run_at" db:"last_run_at"`
+	NextRunAt         *time.Time      `json:"next_run_at" db:"next_run_at"`
+	LastJobID         *int64          `json:"last_job_id" db:"last_job_id"`
+	LastError         *string         `json:"last_error" db:"last_error"`
+	CreatedAt         time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time       `json:"updated_at" db:"updated_at"`
+	LastJobStatus     *string         `json:"last_job_status"`      // 마지막 작업 상태 (job_table.status)
+	LastJobError      *string         `json:"last_job_error"`       // 마지막 작업 오류 (job_table.last_error)
+	LastJobFinishedAt *time.Time      `json:"last_job_finished_at"` // 마지막 작업 완료 시각
+}
+
+// JobScheduleRequest는 반복 작업 일정 생성/수정 요청 시 사용되는 구조체입니다.
+// 수정 시 포인터 필드는 생략하면 기존 값을 유지합니다.
+type JobScheduleRequest struct {
+	ScheduleKey string           `json:"schedule_key"`
+	CronExpr    *string          `json:"cron_expr"`
+	Timezone    *string          `json:"timezone"`
+	JobName     *string          `json:"job_name"`
+	Payload     *json.RawMessage `json:"payload"`
+	Description *string          `json:"description"`
+	Enabled     *bool            `json:"enabled"`
+}
+
+const jobScheduleColumns = `s.serial_number, s.schedule_key, s.company_code, s.cron_expr, s.timezone, s.job_name, s.payload,
+	s.description, s.enabled, s.last_run_at, s.next_run_at, s.last_job_id, s.last_error, s.created_at, s.updated_at,
+	j.status, j.last_error, j.finished_at`
+
+// jobScheduleFrom은 일정과 마지막으로 넣은 작업을 함께 조회하는 FROM 절입니다.
+const jobScheduleFrom = ` FROM job_schedule_table s LEFT JOIN job_table j ON j.serial_number = s.last_job_id`
+
+// scanJobSchedule은 한 행을 JobSchedule로 읽습니다.
+func scanJobSchedule(row interface{ Scan(...interface{}) error }, s *JobSchedule) error {
+	return row.Scan(&s.SerialNumber, &s.ScheduleKey, &s.CompanyCode, &s.CronExpr, &s.Timezone, &s.JobName, &s.Payload,
+		&s.Description, &s.Enabled, &s.LastRunAt, &s.NextRunAt, &s.LastJobID, &s.LastError, &s.CreatedAt, &s.UpdatedAt,
+		&s.LastJobStatus, &s.LastJobError, &s.LastJobFinishedAt)
+}
+
+// loadJobSchedule은 일정 하나를 조회합니다.
+func loadJobSchedule(ctx context.Context, id int64) (JobSchedule, error) {
+	var s JobSchedule
+	err := scanJobSchedule(utils.DB.QueryRowContext(ctx,
+		"SELECT "+jobScheduleColumns+jobScheduleFrom+" WHERE s.serial_number = $1", id), &s)
+	return s, err
+}
+
+// companyScheduleJobs는 업체 일정에 사용할 수 있는 작업입니다.
+// 처리기가 스케줄러가 넣은 company_code로 대상을 그 업체로 한정하는 작업만 추가합니다.
+// 전체 공통 작업(정리, 만료 처리 등)이나 다른 업체의 데이터를 가리킬 수 있는 작업(GenerateReport 등)은 넣지 않습니다.
+var companyScheduleJobs = map[string]bool{
+	JOB_SETTLEMENT_CLOSE: true,
+}
+
+// validateJobSchedule은 일정의 cron 식, 시간대, 작업 이름, 작업 데이터를 확인하고 다음 실행 시각을 계산합니다.
+// 업체 일정(companyCode가 있음)은 companyScheduleJobs의 작업만 허용합니다.
+func validateJobSchedule(companyCode, cronExpr, timezone, jobName string, payload json.RawMessage) (time.Time, error) {
+	if !utils.HasJobHandler(jobName) {
+		return time.Time{}, fmt.Errorf("등록되지 않은 작업 이름입니다: %s", jobName)
+	}
+	if companyCode != "" && !companyScheduleJobs[jobName] {
+		return time.Time{}, fmt.Errorf("업체 일정에 사용할 수 없는 작업입니다: %s", jobName)
+	}
+	var data map[string]interface{}
+	if err := json.Unmarshal(payload, &data); err != nil {
+		return time.Time{}, fmt.Errorf("payload는 JSON 객체여야 합니다")
+	}
+	return utils.NextScheduleRun(cronExpr, timezone, time.Now())
+}
+
+// RegisterJobScheduleRoutes는 반복 작업 일정 엔드포인트를 등록합니다.
+func RegisterJobScheduleRoutes(r *mux.Router) {
+	r.HandleFunc("/job-schedules", GetJobSchedules).Methods("GET")
+	r.HandleFunc("/job-schedules/{id:[0-9]+}", GetJobSchedule).Methods("GET")
+	r.HandleFunc("/job-schedules/{id:[0-9]+}", UpdateJobSchedule).Methods("PUT", "PATCH")
+	r.HandleFunc("/job-schedules/{id:[0-9]+}", DeleteJobSchedule).Methods("DELETE")
+	r.HandleFunc("/companies/{company_code}/job-schedules", GetCompanyJobSchedules).Methods("GET")
+	r.HandleFunc("/companies/{company_code}/job-schedules", CreateCompanyJobSchedule).Methods("POST")
+}
+
+// GetJobSchedules: 반복 작업 일정 목록을 마지막/다음 실행 시각, 마지막 결과와 함께 조회합니다.
+// company_code(빈 값은 전체 공통 일정만), job_name, enabled로 필터링할 수 있습니다.
+func GetJobSchedules(w http.ResponseWriter, r *http.Request) {
+	filters := []string{}
+	args := []interface{}{}
+	paramIdx := 1
+
+	query := r.URL.Query()
+	filterParams := map[string]string{
+		"job_name": "s.job_name",
+		"enabled":  "s.enabled",
+	}
+	for param, dbField := range filterParams {
+		if value := query.Get(param); value != "" {
+			filters = append(filters, fmt.Sprintf("%s = $%d", dbField, paramIdx))
+			args = append(args, value)
+			paramIdx++
+		}
+	}
+	if values, ok := query["company_code"]; ok {
+		filters = append(filters, fmt.Sprintf("s.company_code = $%d", paramIdx))
+		args = append(args, values[0])
+	}
+	writeJobSchedules(w, r, filters, args)
+}
+
+// GetCompanyJobSchedules: 업체의 반복 작업 일정 목록을 조회합니다.
+func GetCompanyJobSchedules(w http.ResponseWriter, r *http.Request) {
+	writeJobSchedules(w, r, []string{"s.company_code = $1"}, []interface{}{mux.Vars(r)["company_code"]})
+}
+
+// writeJobSchedules는 조건에 맞는 일정 목록을 다음 실행 시각 순으로 응답합니다.
+func writeJobSchedules(w http.ResponseWriter, r *http.Request, filters []string, args []interface{}) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	query := "SELECT " + jobScheduleColumns + jobScheduleFrom
+	if len(filters) > 0 {
+		query += " WHERE " + strings.Join(filters, " AND ")
+	}
+	query += " ORDER BY s.company_code, s.next_run_at NULLS LAST, s.serial_number"
+
+	rows, err := utils.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("데이터베이스 쿼리 오류: %v", err)
+		http.Error(w, "데이터 조회 중 오류가 발생했습니다", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	result := []JobSchedule{}
+	for rows.Next() {
+		var s JobSchedule
+		if err := scanJobSchedule(rows, &s); err != nil {
+			log.Printf("행 스캔 오류: %v", err)
+			http.Error(w, "데이터 처리 중 오류가 발생했습니다", http.StatusInternalServerError)
+			return
+		}
+		result = append(result, s)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// GetJobSchedule: 반복 작업 일정 하나를 조회합니다.
+func GetJobSchedule(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	id, _ := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	s, err := loadJobSchedule(ctx, id)
+	if err == sql.ErrNoRows {
+		http.Error(w, "반복 작업 일정을 찾을 수 없습니다.", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s)
+}
+
+// CreateCompanyJobSchedule: 업체의 반복 작업 일정을 추가합니다 (예: 지점 마감 시각의 SettlementClose).
+// companyScheduleJobs의 작업만 등록할 수 있으며, 작업 데이터에는 실행 시 company_code가 함께 들어갑니다.
+func CreateCompanyJobSchedule(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	companyCode := mux.Vars(r)["company_code"]
+	var req JobScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "잘못된 요청 데이터", http.StatusBadRequest)
+		return
+	}
+	if req.ScheduleKey == "" || req.CronExpr == nil || req.JobName == nil {
+		http.Error(w, "필수 필드가 누락되었습니다 (schedule_key, cron_expr, job_name)", http.StatusBadRequest)
+		return
+	}
+	timezone := ""
+	if req.Timezone != nil {
+		timezone = *req.Timezone
+	}
+	payload := json.RawMessage("{}")
+	if req.Payload != nil {
+		payload = *req.Payload
+	}
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+	description := ""
+	if req.Description != nil {
+		description = *req.Description
+	}
+
+	next, err := validateJobSchedule(companyCode, *req.CronExpr, timezone, *req.JobName, payload)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var id int64
+	err = utils.DB.QueryRowContext(ctx, `
+		INSERT INTO job_schedule_table
+		(schedule_key, company_code, cron_expr, timezone, job_name, payload, description, enabled, next_run_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		RETURNING serial_number`,
+		req.ScheduleKey, companyCode, *req.CronExpr, nullableString(timezone), *req.JobName, []byte(payload),
+		nullableString(description), enabled, next).Scan(&id)
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key") {
+			http.Error(w, "이미 존재하는 일정 키입니다", http.StatusConflict)
+			return
+		}
+		log.Printf("반복 작업 일정 저장 오류: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s, err := loadJobSchedule(ctx, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(s)
+}
+
+// UpdateJobSchedule: 반복 작업 일정의 cron 식, 시간대, 작업, 사용 여부 등을 수정합니다.
+// 일정 키와 업체는 바꿀 수 없으며, 수정하면 다음 실행 시각을 지금부터 다시 계산합니다.
+func UpdateJobSchedule(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	id, _ := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	var req JobScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "잘못된 요청 데이터", http.StatusBadRequest)
+		return
+	}
+
+	s, err := loadJobSchedule(ctx, id)
+	if err == sql.ErrNoRows {
+		http.Error(w, "반복 작업 일정을 찾을 수 없습니다.", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if req.CronExpr != nil {
+		s.CronExpr = *req.CronExpr
+	}
+	if req.Timezone != nil {
+		s.Timezone = req.Timezone
+	}
+	if req.JobName != nil {
+		s.JobName = *req.JobName
+	}
+	if req.Payload != nil {
+		s.Payload = *req.Payload
+	}
+	if req.Description != nil {
+		s.Description = req.Description
+	}
+	if req.Enabled != nil {
+		s.Enabled = *req.Enabled
+	}
+	timezone := ""
+	if s.Timezone != nil {
+		timezone = *s.Timezone
+	}
+
+	next, err := validateJobSchedule(s.CompanyCode, s.CronExpr, timezone, s.JobName, s.Payload)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	description := ""
+	if s.Description != nil {
+		description = *s.Description
+	}
+
+	log.Printf("반복 작업 일정 수정 요청: id=%d, cron=%s, job=%s, enabled=%v", id, s.CronExpr, s.JobName, s.Enabled)
+	_, err = utils.DB.ExecContext(ctx, `
+		UPDATE job_schedule_table
+		SET cron_expr = $2, timezone = $3, job_name = $4, payload = $5, description = $6, enabled = $7,
+			next_run_at = $8, last_error = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE serial_number = $1`,
+		id, s.CronExpr, nullableString(timezone), s.JobName, []byte(s.Payload), nullableString(description), s.Enabled, next)
+	if err != nil {
+		log.Printf("반복 작업 일정 수정 오류: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s, err = loadJobSchedule(ctx, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s)
+}
+
+// DeleteJobSchedule: 업체의 반복 작업 일정을 삭제합니다.
+// 전체 공통 일정은 서버 시작 시 다시 생성되므로 삭제 대신 enabled=false로 끕니다.
+func DeleteJobSchedule(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	id, _ := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	var companyCode string
+	err := utils.DB.QueryRowContext(ctx,
+		"SELECT company_code FROM job_schedule_table WHERE serial_number = $1", id).Scan(&companyCode)
+	if err == sql.ErrNoRows {
+		http.Error(w, "반복 작업 일정을 찾을 수 없습니다.", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if companyCode == "" {
+		http.Error(w, "전체 공통 일정은 삭제할 수 없습니다. enabled=false로 끄세요", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := utils.DB.ExecContext(ctx, "DELETE FROM job_schedule_table WHERE serial_number = $1", id); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,277 @@
+// jobs.go
+package tables
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"narabackend/src/consts"
+	"narabackend/src/utils"
+)
+
+// 작업 큐(job_table) 작업 이름
+const (
+	JOB_GENERATE_REPORT      = "GenerateReport"      // 보고서 파일 생성
+	JOB_ROOM_UPDATED         = "RoomUpdated"         // 방 정보 변경 알림
+	JOB_SEAT_UPDATED         = "SeatUpdated"         // 좌석 정보 변경 알림
+	JOB_DASHBOARD_CLOSE      = "DashboardClose"      // 전날 대시보드 집계 확정
+	JOB_OUTBOX_CLEANUP       = "OutboxCleanup"       // 보관 기간이 지난 변경 이벤트 삭제
+	JOB_CHANGE_FEED_CLEANUP  = "ChangeFeedCleanup"   // 보관 기간이 지난 변경 피드 이벤트 삭제
+	JOB_DELIVER_WEBHOOK      = "DeliverWebhook"      // 웹훅 전송
+	JOB_WEBHOOK_CLEANUP      = "WebhookCleanup"      // 보관 기간이 지난 웹훅 전송 기록 삭제
+	JOB_SEND_NOTIFICATION    = "SendNotification"    // 알림 발송
+	JOB_PASS_EXPIRY_REMINDER = "PassExpiryReminder"  // 이용권 만료 예정 알림 등록
+	JOB_NOTIFICATION_CLEANUP = "NotificationCleanup" // 보관 기간이 지난 알림 발송 기록 삭제
+	JOB_PASS_EXPIRE          = "PassExpire"          // 유효 기간이 지난 이용권 만료 처리
+	JOB_JOB_CLEANUP          = "JobCleanup"          // 보관 기간이 지난 완료/취소 작업 삭제
+	JOB_SETTLEMENT_CLOSE     = "SettlementClose"     // 업체 일일 마감 (업체 일정 전용)
+)
+
+// 보고서 생성은 DB 부하가 크므로 서버 하나에서 동시에 실행하는 수를 제한합니다.
+const REPORT_JOB_CONCURRENCY = 2
+
+// generateReportPayload는 GenerateReport 작업 데이터입니다.
+type generateReportPayload struct {
+	ReportRunID int64 `json:"report_run_id"`
+}
+
+// roomUpdatedPayload는 RoomUpdated 작업 데이터입니다.
+type roomUpdatedPayload struct {
+	RoomCode int       `json:"room_code"`
+	Time     time.Time `json:"time"`
+}
+
+// seatUpdatedPayload는 SeatUpdated 작업 데이터입니다.
+type seatUpdatedPayload struct {
+	SeatCode int       `json:"seat_code"`
+	Time     time.Time `json:"time"`
+}
+
+// dashboardClosePayload는 DashboardClose 작업 데이터입니다. date가 없으면 전날을 확정합니다.
+type dashboardClosePayload struct {
+	Date string `json:"date"`
+}
+
+// settlementClosePayload는 SettlementClose 작업 데이터입니다. company_code는 업체 일정을 실행할 때 스케줄러가 넣습니다.
+// days_before는 실행일로부터 며칠 전을 마감할지이며(0이면 당일), 자정이 지나 마감하는 지점은 1로 둡니다.
+type settlementClosePayload struct {
+	CompanyCode string `json:"company_code"`
+	DaysBefore  int    `json:"days_before"`
+}
+
+// RegisterJobHandlers는 tables 패키지의 작업 처리기와 반복 작업 일정을 등록합니다.
+// 작업 워커와 반복 작업 스케줄러를 시작하기 전에 호출해야 합니다.
+func RegisterJobHandlers() {
+	utils.RegisterTypedJobHandler(JOB_GENERATE_REPORT, utils.JobHandlerOptions{
+		Timeout:     time.Duration(consts.REPORT_WORK_TIMEOUT) * time.Second,
+		Concurrency: REPORT_JOB_CONCURRENCY,
+	}, func(ctx context.Context, job utils.Job, payload generateReportPayload) error {
+		return generateReportRun(ctx, payload.ReportRunID)
+	})
+
+	utils.RegisterTypedJobHandler(JOB_ROOM_UPDATED, utils.JobHandlerOptions{},
+		func(ctx context.Context, job utils.Job, payload roomUpdatedPayload) error {
+			log.Printf("방 변경 처리 (room_code=%d, time=%s)", payload.RoomCode, payload.Time.Format(time.RFC3339))
+			return nil
+		})
+
+	utils.RegisterTypedJobHandler(JOB_SEAT_UPDATED, utils.JobHandlerOptions{},
+		func(ctx context.Context, job utils.Job, payload seatUpdatedPayload) error {
+			log.Printf("좌석 변경 처리 (seat_code=%d, time=%s)", payload.SeatCode, payload.Time.Format(time.RFC3339))
+			return nil
+		})
+
+	utils.RegisterTypedJobHandler(JOB_DASHBOARD_CLOSE, utils.JobHandlerOptions{
+		Timeout:     time.Duration(consts.LONG_WORK_TIMEOUT) * time.Second,
+		Concurrency: 1,
+	}, func(ctx context.Context, job utils.Job, payload dashboardClosePayload) error {
+		now := time.Now()
+		date := payload.Date
+		if date == "" {
+			date = now.AddDate(0, 0, -1).Format("2006-01-02")
+		}
+		return refreshDashboardRollups(ctx, date, now)
+	})
+
+	utils.RegisterTypedJobHandler(JOB_OUTBOX_CLEANUP, utils.JobHandlerOptions{
+		Timeout:     time.Duration(consts.LONG_WORK_TIMEOUT) * time.Second,
+		Concurrency: 1,
+	}, func(ctx context.Context, job utils.Job, payload struct{}) error {
+		before := time.Now().AddDate(0, 0, -consts.OUTBOX_RETENTION_DAYS)
+		deleted, err := utils.PurgeOutbox(ctx, before)
+		if err != nil {
+			return err
+		}
+		log.Printf("outbox 정리: %d건 삭제 (%s 이전)", deleted, before.Format("2006-01-02 15:04"))
+		return nil
+	})
+
+	utils.RegisterTypedJobHandler(JOB_CHANGE_FEED_CLEANUP, utils.JobHandlerOptions{
+		Timeout:     time.Duration(consts.LONG_WORK_TIMEOUT) * time.Second,
+		Concurrency: 1,
+	}, func(ctx context.Context, job utils.Job, payload struct{}) error {
+		before := time.Now().AddDate(0, 0, -consts.CHANGE_FEED_RETENTION_DAYS)
+		deleted, err := utils.PurgeChangeEvents(ctx, before)
+		if err != nil {
+			return err
+		}
+		log.Printf("변경 피드 정리: %d건 삭제 (%s 이전)", deleted, before.Format("2006-01-02 15:04"))
+		return nil
+	})
+
+	utils.RegisterTypedJobHandler(JOB_DELIVER_WEBHOOK, utils.JobHandlerOptions{
+		Timeout:     time.Duration(consts.DEFAULT_QUERY_TIMEOUT+consts.WEBHOOK_TIMEOUT) * time.Second,
+		MaxAttempts: consts.WEBHOOK_MAX_ATTEMPTS,
+	}, deliverWebhook)
+
+	utils.RegisterTypedJobHandler(JOB_WEBHOOK_CLEANUP, utils.JobHandlerOptions{
+		Timeout:     time.Duration(consts.LONG_WORK_TIMEOUT) * time.Second,
+		Concurrency: 1,
+	}, func(ctx context.Context, job utils.Job, payload struct{}) error {
+		before := time.Now().AddDate(0, 0, -consts.WEBHOOK_DELIVERY_RETENTION_DAYS)
+		deleted, err := PurgeWebhookDeliveries(ctx, before)
+		if err != nil {
+			return err
+		}
+		log.Printf("웹훅 전송 기록 정리: %d건 삭제 (%s 이전)", deleted, before.Format("2006-01-02 15:04"))
+		return nil
+	})
+
+	utils.RegisterTypedJobHandler(JOB_SEND_NOTIFICATION, utils.JobHandlerOptions{
+		Timeout:     time.Duration(consts.DEFAULT_QUERY_TIMEOUT+consts.NOTIFICATION_TIMEOUT) * time.Second,
+		MaxAttempts: consts.NOTIFICATION_MAX_ATTEMPTS,
+	}, sendNotification)
+
+	utils.RegisterTypedJobHandler(JOB_PASS_EXPIRY_REMINDER, utils.JobHandlerOptions{
+		Timeout:     time.Duration(consts.LONG_WORK_TIMEOUT) * time.Second,
+		Concurrency: 1,
+	}, func(ctx context.Context, job utils.Job, payload struct{}) error {
+		queued, err := remindExpiringPasses(ctx, time.Now(), consts.PASS_EXPIRY_REMINDER_DAYS)
+		log.Printf("이용권 만료 예정 알림 처리: %d건", queued)
+		return err
+	})
+
+	utils.RegisterTypedJobHandler(JOB_PASS_EXPIRE, utils.JobHandlerOptions{
+		Timeout:     time.Duration(consts.LONG_WORK_TIMEOUT) * time.Second,
+		Concurrency: 1,
+	}, func(ctx context.Context, job utils.Job, payload struct{}) error {
+		expired, err := expireMemberPasses(ctx, time.Now())
+		if err != nil {
+			return err
+		}
+		if expired > 0 {
+			log.Printf("이용권 만료 처리: %d건", expired)
+		}
+		return nil
+	})
+
+	utils.RegisterTypedJobHandler(JOB_NOTIFICATION_CLEANUP, utils.JobHandlerOptions{
+		Timeout:     time.Duration(consts.LONG_WORK_TIMEOUT) * time.Second,
+		Concurrency: 1,
+	}, func(ctx context.Context, job utils.Job, payload struct{}) error {
+		before := time.Now().AddDate(0, 0, -consts.NOTIFICATION_RETENTION_DAYS)
+		deleted, err := PurgeNotifications(ctx, before)
+		if err != nil {
+			return err
+		}
+		log.Printf("알림 발송 기록 정리: %d건 삭제 (%s 이전)", deleted, before.Format("2006-01-02 15:04"))
+		return nil
+	})
+
+	utils.RegisterTypedJobHandler(JOB_JOB_CLEANUP, utils.JobHandlerOptions{
+		Timeout:     time.Duration(consts.LONG_WORK_TIMEOUT) * time.Second,
+		Concurrency: 1,
+	}, func(ctx context.Context, job utils.Job, payload struct{}) error {
+		before := time.Now().AddDate(0, 0, -consts.JOB_RETENTION_DAYS)
+		deleted, err := utils.PurgeFinishedJobs(ctx, before)
+		if err != nil {
+			return err
+		}
+		log.Printf("작업 큐 정리: %d건 삭제 (%s 이전)", deleted, before.Format("2006-01-02 15:04"))
+		return nil
+	})
+
+	// 업체 일정(/companies/{company_code}/job-schedules)으로 등록하는 지점 마감입니다.
+	utils.RegisterTypedJobHandler(JOB_SETTLEMENT_CLOSE, utils.JobHandlerOptions{
+		Timeout: time.Duration(consts.LONG_WORK_TIMEOUT) * time.Second,
+	}, func(ctx context.Context, job utils.Job, payload settlementClosePayload) error {
+		if payload.CompanyCode == "" {
+			return utils.PermanentJobError(errors.New("업체 일정이 아닌 마감 작업입니다 (company_code 없음)"))
+		}
+		if payload.DaysBefore < 0 {
+			return utils.PermanentJobError(fmt.Errorf("days_before는 0 이상이어야 합니다: %d", payload.DaysBefore))
+		}
+		date := time.Now().AddDate(0, 0, -payload.DaysBefore).Format("2006-01-02")
+		err := closeSettlement(ctx, payload.CompanyCode, date, "scheduler")
+		if errors.Is(err, errAlreadySettled) {
+			log.Printf("일일 마감 건너뜀: 이미 마감됨 (company_code=%s, date=%s)", payload.CompanyCode, date)
+			return nil
+		}
+		if err == nil {
+			log.Printf("일일 마감 완료 (company_code=%s, date=%s)", payload.CompanyCode, date)
+		}
+		return err
+	})
+
+	// 주기 갱신(StartDashboardRollupScheduler)과 별개로, 자정 이후 늦게 기록된 세션/결제까지 반영하여 전날 집계를 확정합니다.
+	utils.DefineJobSchedule(utils.JobScheduleDefinition{
+		Key:         "dashboard-close-daily",
+		CronExpr:    "10 0 * * *",
+		JobName:     JOB_DASHBOARD_CLOSE,
+		Description: "전날 대시보드 집계 확정",
+	})
+
+	utils.DefineJobSchedule(utils.JobScheduleDefinition{
+		Key:         "outbox-cleanup-daily",
+		CronExpr:    "30 3 * * *",
+		JobName:     JOB_OUTBOX_CLEANUP,
+		Description: "보관 기간이 지난 변경 이벤트(outbox) 삭제",
+	})
+
+	utils.DefineJobSchedule(utils.JobScheduleDefinition{
+		Key:         "change-feed-cleanup-daily",
+		CronExpr:    "40 3 * * *",
+		JobName:     JOB_CHANGE_FEED_CLEANUP,
+		Description: "보관 기간이 지난 변경 피드 이벤트 삭제",
+	})
+
+	utils.DefineJobSchedule(utils.JobScheduleDefinition{
+		Key:         "webhook-cleanup-daily",
+		CronExpr:    "50 3 * * *",
+		JobName:     JOB_WEBHOOK_CLEANUP,
+		Description: "보관 기간이 지난 웹훅 전송 기록 삭제",
+	})
+
+	// 회원이 바로 받을 수 있도록 기본 방해 금지 시간(22:00~08:00)을 피해 오전에 등록합니다.
+	utils.DefineJobSchedule(utils.JobScheduleDefinition{
+		Key:         "pass-expiry-reminder-daily",
+		CronExpr:    "0 10 * * *",
+		JobName:     JOB_PASS_EXPIRY_REMINDER,
+		Description: "이용권 만료 예정 알림 등록",
+	})
+
+	utils.DefineJobSchedule(utils.JobScheduleDefinition{
+		Key:         "job-cleanup-daily",
+		CronExpr:    "20 4 * * *",
+		JobName:     JOB_JOB_CLEANUP,
+		Description: "보관 기간이 지난 완료/취소 작업 삭제",
+	})
+
+	// 조회는 valid_until로도 만료 이용권을 거르지만, 상태 필터(status=active)를 쓰는 화면을 위해 상태도 바꿉니다.
+	utils.DefineJobSchedule(utils.JobScheduleDefinition{
+		Key:         "pass-expire-10min",
+		CronExpr:    "*/10 * * * *",
+		JobName:     JOB_PASS_EXPIRE,
+		Description: "유효 기간이 지난 이용권 만료 처리",
+	})
+
+	utils.DefineJobSchedule(utils.JobScheduleDefinition{
+		Key:         "notification-cleanup-daily",
+		CronExpr:    "0 4 * * *",
+		JobName:     JOB_NOTIFICATION_CLEANUP,
+		Description: "보관 기간이 지난 알림 발송 기록 삭제",
+	})
+}
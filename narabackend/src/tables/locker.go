@@ -0,0 +1,732 @@
+// locker.go
+package tables
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"narabackend/src/consts"
+	"narabackend/src/utils"
+)
+
+// 사물함 상태
+const (
+	LOCKER_STATUS_AVAILABLE   = "available"   // 대여 가능
+	LOCKER_STATUS_OCCUPIED    = "occupied"    // 대여 중
+	LOCKER_STATUS_EXPIRED     = "expired"     // 대여 만료(물품 회수 대기)
+	LOCKER_STATUS_MAINTENANCE = "maintenance" // 점검/사용 중지
+)
+
+// 사물함 크기
+const (
+	LOCKER_SIZE_SMALL  = "small"
+	LOCKER_SIZE_MEDIUM = "medium"
+	LOCKER_SIZE_LARGE  = "large"
+)
+
+// 사물함 대여 상태
+const (
+	LOCKER_RENTAL_STATUS_ACTIVE   = "active"   // 대여 중
+	LOCKER_RENTAL_STATUS_EXPIRED  = "expired"  // 만료(반납 처리 전)
+	LOCKER_RENTAL_STATUS_RETURNED = "returned" // 반납 완료
+)
+
+// lockerSizes는 허용된 사물함 크기 목록입니다.
+var lockerSizes = map[string]bool{
+	LOCKER_SIZE_SMALL:  true,
+	LOCKER_SIZE_MEDIUM: true,
+	LOCKER_SIZE_LARGE:  true,
+}
+
+// lockerStatuses는 관리자가 직접 지정할 수 있는 사물함 상태 목록입니다.
+// occupied/expired는 대여/반납/만료 처리로만 변경됩니다.
+var lockerStatuses = map[string]bool{
+	LOCKER_STATUS_AVAILABLE:   true,
+	LOCKER_STATUS_MAINTENANCE: true,
+}
+
+// Locker 구조체는 locker_table의 각 컬럼을 매핑합니다.
+type Locker struct {
+	SerialNumber int64     `json:"serial_number" db:"serial_number"`
+	CompanyCode  string    `json:"company_code" db:"company_code"`
+	LockerNumber int       `json:"locker_number" db:"locker_number"`
+	RoomCode     *int      `json:"room_code" db:"room_code"`
+	Zone         string    `json:"zone" db:"zone"`
+	Size         string    `json:"size" db:"size"`
+	Status       string    `json:"status" db:"status"`
+	Memo         string    `json:"memo" db:"memo"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// LockerRequest는 사물함 등록/수정 요청 시 사용되는 구조체입니다.
+// 업데이트 시 포인터 필드가 nil이면 기존 값을 유지합니다.
+type LockerRequest struct {
+	CompanyCode  string `json:"company_code"`
+	LockerNumber *int   `json:"locker_number"`
+	RoomCode     *int   `json:"room_code"`
+	Zone         string `json:"zone"`
+	Size         string `json:"size"`
+	Status       string `json:"status"`
+	Memo         string `json:"memo"`
+}
+
+// LockerRental 구조체는 locker_rental_table의 각 컬럼을 매핑합니다.
+type LockerRental struct {
+	SerialNumber int64      `json:"serial_number" db:"serial_number"`
+	CompanyCode  string     `json:"company_code" db:"company_code"`
+	LockerID     int64      `json:"locker_id" db:"locker_id"`
+	MemberID     int64      `json:"member_id" db:"member_id"`
+	PassID       *int64     `json:"pass_id" db:"pass_id"`
+	PaymentID    *int64     `json:"payment_id" db:"payment_id"`
+	StartAt      time.Time  `json:"start_at" db:"start_at"`
+	ExpiresAt    time.Time  `json:"expires_at" db:"expires_at"`
+	ReturnedAt   *time.Time `json:"returned_at" db:"returned_at"`
+	Status       string     `json:"status" db:"status"`
+	Memo         string     `json:"memo" db:"memo"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+}
+
+// LockerAssignRequest는 사물함 배정 요청 시 사용되는 구조체입니다.
+// expires_at이 없으면 연결된 이용권의 유효 기간 종료 시각을 사용합니다.
+type LockerAssignRequest struct {
+	MemberID  int64      `json:"member_id"`
+	PassID    *int64     `json:"pass_id"`
+	PaymentID *int64     `json:"payment_id"`
+	StartAt   *time.Time `json:"start_at"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	Memo      string     `json:"memo"`
+}
+
+// LockerCounts는 업체별 사물함 상태 집계입니다.
+type LockerCounts struct {
+	Total       int `json:"total"`
+	Available   int `json:"available"`
+	Occupied    int `json:"occupied"`
+	Expired     int `json:"expired"`
+	Maintenance int `json:"maintenance"`
+}
+
+// lockerColumns는 locker_table 조회 시 사용하는 컬럼 목록입니다.
+const lockerColumns = `serial_number, company_code, locker_number, room_code, COALESCE(zone, ''),
+	size, status, COALESCE(memo, ''), created_at, updated_at`
+
+// lockerRentalColumns는 locker_rental_table 조회 시 사용하는 컬럼 목록입니다.
+const lockerRentalColumns = `serial_number, company_code, locker_id, member_id, pass_id, payment_id,
+	start_at, expires_at, returned_at, status, COALESCE(memo, ''), created_at`
+
+// scanLocker는 lockerColumns 순서로 조회된 행을 Locker 구조체로 변환합니다.
+func scanLocker(row interface{ Scan(...interface{}) error }, l *Locker) error {
+	return row.Scan(&l.SerialNumber, &l.CompanyCode, &l.LockerNumber, &l.RoomCode, &l.Zone,
+		&l.Size, &l.Status, &l.Memo, &l.CreatedAt, &l.UpdatedAt)
+}
+
+// scanLockerRental은 lockerRentalColumns 순서로 조회된 행을 LockerRental 구조체로 변환합니다.
+func scanLockerRental(row interface{ Scan(...interface{}) error }, rt *LockerRental) error {
+	return row.Scan(&rt.SerialNumber, &rt.CompanyCode, &rt.LockerID, &rt.MemberID, &rt.PassID, &rt.PaymentID,
+		&rt.StartAt, &rt.ExpiresAt, &rt.ReturnedAt, &rt.Status, &rt.Memo, &rt.CreatedAt)
+}
+
+// validateLocker는 사물함 번호와 크기가 올바른지 확인합니다.
+func validateLocker(l *Locker) error {
+	if l.LockerNumber <= 0 {
+		return fmt.Errorf("locker_number는 1 이상이어야 합니다")
+	}
+	if !lockerSizes[l.Size] {
+		return fmt.Errorf("지원하지 않는 size입니다: %s", l.Size)
+	}
+	return nil
+}
+
+// lockerCounts는 업체의 사물함을 상태별로 집계합니다. 대시보드 집계에서도 사용합니다.
+func lockerCounts(ctx context.Context, companyCode string) (LockerCounts, error) {
+	var c LockerCounts
+	err := utils.DB.QueryRowContext(ctx, `
+		SELECT COUNT(*),
+			COUNT(*) FILTER (WHERE status = $2),
+			COUNT(*) FILTER (WHERE status = $3),
+			COUNT(*) FILTER (WHERE status = $4),
+			COUNT(*) FILTER (WHERE status = $5)
+		FROM locker_table WHERE company_code = $1`,
+		companyCode, LOCKER_STATUS_AVAILABLE, LOCKER_STATUS_OCCUPIED, LOCKER_STATUS_EXPIRED, LOCKER_STATUS_MAINTENANCE,
+	).Scan(&c.Total, &c.Available, &c.Occupied, &c.Expired, &c.Maintenance)
+	return c, err
+}
+
+// expiredLocker는 만료 처리된 사물함 대여 정보입니다.
+type expiredLocker struct {
+	CompanyCode  string `json:"company_code"`
+	MemberID     int64  `json:"member_id"`
+	RoomCode     *int   `json:"room_code"`
+	LockerNumber int    `json:"locker_number"`
+}
+
+// expireLockerRentals는 만료 시각이 지난 대여를 expired로, 해당 사물함을 expired로 변경합니다.
+// 사물함은 데스크에서 물품을 회수하고 반납 처리할 때까지 다시 배정되지 않습니다.
+func expireLockerRentals(ctx context.Context, now time.Time) ([]expiredLocker, error) {
+	rows, err := utils.DB.QueryContext(ctx, `
+		WITH expired AS (
+			UPDATE locker_rental_table SET status = $2
+			WHERE status = $3 AND expires_at <= $1
+			RETURNING locker_id, member_id
+		)
+		UPDATE locker_table l SET status = $4, updated_at = CURRENT_TIMESTAMP
+		FROM expired e
+		WHERE l.serial_number = e.locker_id
+		RETURNING l.company_code, e.member_id, l.room_code, l.locker_number`,
+		now, LOCKER_RENTAL_STATUS_EXPIRED, LOCKER_RENTAL_STATUS_ACTIVE, LOCKER_STATUS_EXPIRED)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []expiredLocker{}
+	for rows.Next() {
+		var e expiredLocker
+		if err := rows.Scan(&e.CompanyCode, &e.MemberID, &e.RoomCode, &e.LockerNumber); err != nil {
+			return nil, err
+		}
+		result = append(result, e)
+	}
+	return result, rows.Err()
+}
+
+// notifyLockerExpired는 데스크에 사물함 대여 만료를 알립니다. 사물함 번호는 seatNumber로 전달됩니다.
+func notifyLockerExpired(ctx context.Context, e expiredLocker) {
+	_, err := utils.SendControlCommand(ctx, utils.ControlCommand{
+		Command: utils.CONTROL_COMMAND_LOCKER_EXPIRED,
+		Target:  utils.CONTROL_TARGET_DESK,
+		Message: utils.ControlMessage{
+			CompanyCode: e.CompanyCode,
+			UserCode:    strconv.FormatInt(e.MemberID, 10),
+			RoomCode:    utils.ControlNumberString(e.RoomCode),
+			SeatNumber:  strconv.Itoa(e.LockerNumber),
+		},
+	})
+	if err != nil {
+		log.Printf("데스크 사물함 만료 알림 실패: company_code=%s, locker_number=%d, 오류: %v", e.CompanyCode, e.LockerNumber, err)
+	}
+}
+
+// RegisterLockerRoutes는 locker_table, locker_rental_table 관련 엔드포인트를 등록합니다.
+func RegisterLockerRoutes(r *mux.Router) {
+	r.HandleFunc("/lockers", GetLockers).Methods("GET")
+	r.HandleFunc("/lockers/{id:[0-9]+}", GetLocker).Methods("GET")
+	r.HandleFunc("/lockers", CreateLocker).Methods("POST")
+	r.HandleFunc("/lockers/{id:[0-9]+}", UpdateLocker).Methods("PUT", "PATCH")
+	r.HandleFunc("/lockers/{id:[0-9]+}", DeleteLocker).Methods("DELETE")
+	r.HandleFunc("/lockers/{id:[0-9]+}/assign", AssignLocker).Methods("POST")
+	r.HandleFunc("/locker-rentals", GetLockerRentals).Methods("GET")
+	r.HandleFunc("/locker-rentals/{id:[0-9]+}/return", ReturnLockerRental).Methods("POST")
+	r.HandleFunc("/companies/{company_code}/lockers/summary", GetLockerSummary).Methods("GET")
+}
+
+// GetLockers: 사물함 목록을 조회합니다.
+// company_code, status, room_code, size 쿼리 파라미터로 필터링합니다.
+func GetLockers(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	filters := []string{}
+	args := []interface{}{}
+	paramIdx := 1
+
+	filterParams := map[string]string{
+		"company_code": "company_code",
+		"status":       "status",
+		"room_code":    "room_code",
+		"size":         "size",
+	}
+	for param, dbField := range filterParams {
+		if value := r.URL.Query().Get(param); value != "" {
+			filters = append(filters, fmt.Sprintf("%s = $%d", dbField, paramIdx))
+			args = append(args, value)
+			paramIdx++
+		}
+	}
+
+	query := "SELECT " + lockerColumns + " FROM locker_table"
+	if len(filters) > 0 {
+		query += " WHERE " + strings.Join(filters, " AND ")
+	}
+	query += " ORDER BY company_code ASC, locker_number ASC"
+
+	rows, err := utils.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("데이터베이스 쿼리 오류: %v", err)
+		http.Error(w, "데이터 조회 중 오류가 발생했습니다", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	result := []Locker{}
+	for rows.Next() {
+		var l Locker
+		if err := scanLocker(rows, &l); err != nil {
+			log.Printf("행 스캔 오류: %v", err)
+			http.Error(w, "데이터 처리 중 오류가 발생했습니다", http.StatusInternalServerError)
+			return
+		}
+		result = append(result, l)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// GetLocker: 단일 사물함을 조회합니다.
+func GetLocker(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	id, _ := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	var l Locker
+	err := scanLocker(utils.DB.QueryRowContext(ctx,
+		"SELECT "+lockerColumns+" FROM locker_table WHERE serial_number = $1", id), &l)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "사물함을 찾을 수 없습니다.", http.StatusNotFound)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(l)
+}
+
+// CreateLocker: 새로운 사물함을 등록합니다.
+func CreateLocker(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	var req LockerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "잘못된 요청 데이터", http.StatusBadRequest)
+		return
+	}
+	if req.CompanyCode == "" || req.LockerNumber == nil {
+		http.Error(w, "필수 필드가 누락되었습니다 (company_code, locker_number)", http.StatusBadRequest)
+		return
+	}
+
+	l := Locker{
+		CompanyCode:  req.CompanyCode,
+		LockerNumber: *req.LockerNumber,
+		RoomCode:     req.RoomCode,
+		Zone:         req.Zone,
+		Size:         LOCKER_SIZE_MEDIUM,
+		Status:       LOCKER_STATUS_AVAILABLE,
+		Memo:         req.Memo,
+	}
+	if req.Size != "" {
+		l.Size = req.Size
+	}
+	if req.Status != "" {
+		if !lockerStatuses[req.Status] {
+			http.Error(w, "status는 available 또는 maintenance만 지정할 수 있습니다", http.StatusBadRequest)
+			return
+		}
+		l.Status = req.Status
+	}
+	if err := validateLocker(&l); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err := scanLocker(utils.DB.QueryRowContext(ctx, `
+		INSERT INTO locker_table
+		(company_code, locker_number, room_code, zone, size, status, memo, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		RETURNING `+lockerColumns,
+		l.CompanyCode, l.LockerNumber, l.RoomCode, nullableString(l.Zone), l.Size, l.Status,
+		nullableString(l.Memo)), &l)
+	if err != nil {
+		log.Printf("DB 오류: %v", err)
+		if strings.Contains(err.Error(), "duplicate key") {
+			http.Error(w, "이미 등록된 사물함 번호입니다", http.StatusConflict)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(l)
+}
+
+// UpdateLocker: 사물함 정보를 업데이트합니다.
+// 대여 중이거나 만료된 사물함의 상태는 반납 처리로만 변경할 수 있습니다.
+func UpdateLocker(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	id, _ := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	var req LockerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "잘못된 요청 데이터", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := utils.DB.BeginTx(ctx, nil)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	var l Locker
+	err = scanLocker(tx.QueryRowContext(ctx,
+		"SELECT "+lockerColumns+" FROM locker_table WHERE serial_number = $1 FOR UPDATE", id), &l)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "사물함을 찾을 수 없습니다.", http.StatusNotFound)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	if req.LockerNumber != nil {
+		l.LockerNumber = *req.LockerNumber
+	}
+	if req.RoomCode != nil {
+		l.RoomCode = req.RoomCode
+	}
+	if req.Zone != "" {
+		l.Zone = req.Zone
+	}
+	if req.Size != "" {
+		l.Size = req.Size
+	}
+	if req.Memo != "" {
+		l.Memo = req.Memo
+	}
+	if req.Status != "" && req.Status != l.Status {
+		if !lockerStatuses[req.Status] {
+			http.Error(w, "status는 available 또는 maintenance만 지정할 수 있습니다", http.StatusBadRequest)
+			return
+		}
+		if l.Status == LOCKER_STATUS_OCCUPIED || l.Status == LOCKER_STATUS_EXPIRED {
+			http.Error(w, "대여 중인 사물함은 반납 처리 후 상태를 변경할 수 있습니다", http.StatusConflict)
+			return
+		}
+		l.Status = req.Status
+	}
+	if err := validateLocker(&l); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = scanLocker(tx.QueryRowContext(ctx, `
+		UPDATE locker_table SET
+			locker_number = $2, room_code = $3, zone = $4, size = $5, status = $6, memo = $7,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE serial_number = $1
+		RETURNING `+lockerColumns,
+		id, l.LockerNumber, l.RoomCode, nullableString(l.Zone), l.Size, l.Status, nullableString(l.Memo)), &l)
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key") {
+			http.Error(w, "이미 등록된 사물함 번호입니다", http.StatusConflict)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(l)
+}
+
+// DeleteLocker: 사물함을 삭제합니다. 대여 이력이 있는 사물함은 삭제할 수 없습니다.
+func DeleteLocker(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	id, _ := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	result, err := utils.DB.ExecContext(ctx,
+		"DELETE FROM locker_table WHERE serial_number = $1", id)
+	if err != nil {
+		if strings.Contains(err.Error(), "foreign key") {
+			http.Error(w, "대여 이력이 있는 사물함은 삭제할 수 없습니다 (maintenance 상태로 변경하세요)", http.StatusConflict)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		http.Error(w, "사물함을 찾을 수 없습니다.", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AssignLocker: 사물함을 회원에게 배정합니다.
+// pass_id가 있으면 같은 회원/업체의 사용 가능한 이용권이어야 하며, expires_at 기본값은 이용권 종료 시각입니다.
+func AssignLocker(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	id, _ := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	var req LockerAssignRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "잘못된 요청 데이터", http.StatusBadRequest)
+		return
+	}
+	if req.MemberID == 0 {
+		http.Error(w, "필수 필드가 누락되었습니다 (member_id)", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := utils.DB.BeginTx(ctx, nil)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	var l Locker
+	err = scanLocker(tx.QueryRowContext(ctx,
+		"SELECT "+lockerColumns+" FROM locker_table WHERE serial_number = $1 FOR UPDATE", id), &l)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "사물함을 찾을 수 없습니다.", http.StatusNotFound)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	if l.Status != LOCKER_STATUS_AVAILABLE {
+		http.Error(w, fmt.Sprintf("배정할 수 없는 사물함입니다: 상태=%s", l.Status), http.StatusConflict)
+		return
+	}
+
+	now := time.Now()
+	rental := LockerRental{
+		CompanyCode: l.CompanyCode,
+		LockerID:    l.SerialNumber,
+		MemberID:    req.MemberID,
+		PassID:      req.PassID,
+		PaymentID:   req.PaymentID,
+		StartAt:     now,
+		Status:      LOCKER_RENTAL_STATUS_ACTIVE,
+		Memo:        req.Memo,
+	}
+	if req.StartAt != nil {
+		rental.StartAt = *req.StartAt
+	}
+
+	if req.PassID != nil {
+		var pass MemberPass
+		err = scanMemberPass(tx.QueryRowContext(ctx,
+			"SELECT "+memberPassColumns+" FROM member_pass_table WHERE serial_number = $1", *req.PassID), &pass)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				http.Error(w, "이용권을 찾을 수 없습니다.", http.StatusNotFound)
+			} else {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		if pass.MemberID != req.MemberID || pass.CompanyCode != l.CompanyCode {
+			http.Error(w, "회원 또는 업체가 일치하지 않는 이용권입니다", http.StatusBadRequest)
+			return
+		}
+		if pass.Status != PASS_STATUS_ACTIVE {
+			http.Error(w, fmt.Sprintf("사용할 수 없는 이용권입니다: 상태=%s", pass.Status), http.StatusBadRequest)
+			return
+		}
+		if pass.ValidUntil != nil && !time.Now().Before(*pass.ValidUntil) {
+			http.Error(w, "유효 기간이 지난 이용권입니다", http.StatusBadRequest)
+			return
+		}
+		if req.ExpiresAt == nil && pass.ValidUntil != nil {
+			rental.ExpiresAt = *pass.ValidUntil
+		}
+	}
+	if req.ExpiresAt != nil {
+		rental.ExpiresAt = *req.ExpiresAt
+	}
+	if rental.ExpiresAt.IsZero() {
+		http.Error(w, "expires_at이 필요합니다 (유효 기간이 없는 이용권)", http.StatusBadRequest)
+		return
+	}
+	if !rental.ExpiresAt.After(rental.StartAt) || !rental.ExpiresAt.After(now) {
+		http.Error(w, "expires_at은 시작 시각과 현재 시각 이후여야 합니다", http.StatusBadRequest)
+		return
+	}
+
+	err = scanLockerRental(tx.QueryRowContext(ctx, `
+		INSERT INTO locker_rental_table
+		(company_code, locker_id, member_id, pass_id, payment_id, start_at, expires_at, status, memo, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, CURRENT_TIMESTAMP)
+		RETURNING `+lockerRentalColumns,
+		rental.CompanyCode, rental.LockerID, rental.MemberID, rental.PassID, rental.PaymentID,
+		rental.StartAt, rental.ExpiresAt, rental.Status, nullableString(rental.Memo)), &rental)
+	if err != nil {
+		log.Printf("DB 오류: %v", err)
+		switch {
+		case strings.Contains(err.Error(), "duplicate key"):
+			http.Error(w, "이미 대여 중인 사물함입니다", http.StatusConflict)
+		case strings.Contains(err.Error(), "foreign key"):
+			http.Error(w, "존재하지 않는 회원 또는 결제입니다", http.StatusBadRequest)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	_, err = tx.ExecContext(ctx,
+		"UPDATE locker_table SET status = $2, updated_at = CURRENT_TIMESTAMP WHERE serial_number = $1",
+		l.SerialNumber, LOCKER_STATUS_OCCUPIED)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("사물함 배정: company_code=%s, locker_number=%d, member_id=%d", l.CompanyCode, l.LockerNumber, req.MemberID)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(rental)
+}
+
+// GetLockerRentals: 사물함 대여 목록을 조회합니다.
+// company_code, locker_id, member_id, pass_id, status 쿼리 파라미터로 필터링합니다.
+func GetLockerRentals(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	filters := []string{}
+	args := []interface{}{}
+	paramIdx := 1
+
+	filterParams := map[string]string{
+		"company_code": "company_code",
+		"locker_id":    "locker_id",
+		"member_id":    "member_id",
+		"pass_id":      "pass_id",
+		"status":       "status",
+	}
+	for param, dbField := range filterParams {
+		if value := r.URL.Query().Get(param); value != "" {
+			filters = append(filters, fmt.Sprintf("%s = $%d", dbField, paramIdx))
+			args = append(args, value)
+			paramIdx++
+		}
+	}
+
+	query := "SELECT " + lockerRentalColumns + " FROM locker_rental_table"
+	if len(filters) > 0 {
+		query += " WHERE " + strings.Join(filters, " AND ")
+	}
+	query += " ORDER BY serial_number DESC"
+
+	rows, err := utils.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("데이터베이스 쿼리 오류: %v", err)
+		http.Error(w, "데이터 조회 중 오류가 발생했습니다", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	result := []LockerRental{}
+	for rows.Next() {
+		var rt LockerRental
+		if err := scanLockerRental(rows, &rt); err != nil {
+			log.Printf("행 스캔 오류: %v", err)
+			http.Error(w, "데이터 처리 중 오류가 발생했습니다", http.StatusInternalServerError)
+			return
+		}
+		result = append(result, rt)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// ReturnLockerRental: 사물함 반납을 처리합니다. 대여 중이거나 만료된 대여만 반납할 수 있습니다.
+// 사물함이 점검 상태가 아니면 다시 대여 가능 상태가 됩니다.
+func ReturnLockerRental(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	id, _ := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+
+	tx, err := utils.DB.BeginTx(ctx, nil)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	var rental LockerRental
+	err = scanLockerRental(tx.QueryRowContext(ctx, `
+		UPDATE locker_rental_table
+		SET status = $2, returned_at = CURRENT_TIMESTAMP
+		WHERE serial_number = $1 AND status IN ($3, $4)
+		RETURNING `+lockerRentalColumns,
+		id, LOCKER_RENTAL_STATUS_RETURNED, LOCKER_RENTAL_STATUS_ACTIVE, LOCKER_RENTAL_STATUS_EXPIRED), &rental)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "반납할 수 있는 대여가 없습니다 (없거나 이미 반납됨)", http.StatusConflict)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	_, err = tx.ExecContext(ctx, `
+		UPDATE locker_table SET status = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE serial_number = $1 AND status <> $3`,
+		rental.LockerID, LOCKER_STATUS_AVAILABLE, LOCKER_STATUS_MAINTENANCE)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rental)
+}
+
+// GetLockerSummary: 업체의 사물함 상태별 개수를 조회합니다.
+func GetLockerSummary(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	counts, err := lockerCounts(ctx, mux.Vars(r)["company_code"])
+	if err != nil {
+		log.Printf("데이터베이스 쿼리 오류: %v", err)
+		http.Error(w, "데이터 조회 중 오류가 발생했습니다", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(counts)
+}
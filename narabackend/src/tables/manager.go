@@ -21,18 +21,14 @@ import (
 // 시스템 관리자의 기본 정보와 권한을 나타내며, 보안을 위해 비밀번호는 응답에서 제외됩니다.
 // JSON 태그는 API 응답 시 필드명을 정의하고, db 태그는 데이터베이스 컬럼명을 정의합니다.
 type Manager struct {
-	ManagerID   string    `json:"manager_id" db:"manager_id"`
-<<<<<<< HEAD
-	ManagerName string    `json:"name" db:"name"`
-=======
-	Name        string    `json:"name" db:"name"`
->>>>>>> eb01e759e3958a53e0ac9aff24e1afc96568c521
-	Password    string    `json:"password,omitempty" db:"password"`
-	Email       string    `json:"email" db:"email"`
-	Phone       string    `json:"phone" db:"phone"`
-	Role        string    `json:"role" db:"role"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	ManagerID string    `json:"manager_id" db:"manager_id"`
+	Name      string    `json:"name" db:"name"`
+	Password  string    `json:"password,omitempty" db:"password"`
+	Email     string    `json:"email" db:"email"`
+	Phone     string    `json:"phone" db:"phone"`
+	Role      string    `json:"role" db:"role"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
 }
 
 // ManagerRequest는 HTTP 요청 시 사용되는 구조체입니다.
@@ -69,13 +65,9 @@ func GetManagers(w http.ResponseWriter, r *http.Request) {
 	defer cancel()
 
 	// 허용된 필드 목록 정의 - 보안을 위해 화이트리스트 방식 사용
-	// 인증을 위해 password 필드도 포함 (로그인 시 필요)
+	// password 필드는 응답에 포함하지 않음 (비밀번호 해시 노출 방지)
 	allowedFields := []string{
-<<<<<<< HEAD
-		"manager_id", "name", "password", "email", "phone", "role", "created_at", "updated_at",
-=======
 		"manager_id", "name", "email", "phone", "role", "created_at", "updated_at",
->>>>>>> eb01e759e3958a53e0ac9aff24e1afc96568c521
 	}
 
 	// X-Fields 헤더를 통한 필드 선택 처리
@@ -130,10 +122,7 @@ func GetManagers(w http.ResponseWriter, r *http.Request) {
 	// 검색 기능 추가 (name, email에 대한 부분 검색)
 	// 관리자 이름이나 이메일 주소를 통한 유연한 검색 지원
 	if search := r.URL.Query().Get("search"); search != "" {
-<<<<<<< HEAD
-=======
 		log.Printf("검색어 추가: %s", search)
->>>>>>> eb01e759e3958a53e0ac9aff24e1afc96568c521
 		filters = append(filters, fmt.Sprintf("(name LIKE $%d OR email LIKE $%d)", paramIdx, paramIdx+1))
 		args = append(args, "%"+search+"%", "%"+search+"%")
 		paramIdx += 2
@@ -180,13 +169,8 @@ func GetManagers(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// 쿼리 로깅
-<<<<<<< HEAD
-	log.Printf("📊 [GetManagers] 실행 쿼리: %s", query)
-	log.Printf("🔢 [GetManagers] 쿼리 인자: %v", args)
-=======
 	log.Printf("최종 필터 조건: %v", filters)
 	log.Printf("실행 쿼리: %s, 인자: %v", query, args)
->>>>>>> eb01e759e3958a53e0ac9aff24e1afc96568c521
 
 	// 쿼리 실행 - 인자 유무에 따른 조건부 실행
 	var rows *sql.Rows
@@ -198,17 +182,13 @@ func GetManagers(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err != nil {
-<<<<<<< HEAD
-		log.Printf("❌ [GetManagers] 데이터베이스 쿼리 오류: %v", err)
-=======
 		log.Printf("데이터베이스 쿼리 오류: %v", err)
 		log.Printf("실패한 쿼리: %s", query)
 		log.Printf("실패한 인자: %v", args)
->>>>>>> eb01e759e3958a53e0ac9aff24e1afc96568c521
 		http.Error(w, "데이터 조회 중 오류가 발생했습니다", http.StatusInternalServerError)
 		return
 	}
-	log.Printf("✅ [GetManagers] 데이터베이스 연결 및 쿼리 실행 성공", )
+	log.Printf("✅ [GetManagers] 데이터베이스 연결 및 쿼리 실행 성공")
 	defer rows.Close()
 
 	// 결과 처리 - 컬럼 정보 가져오기
@@ -348,17 +328,10 @@ func CreateManager(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// 필수 필드 검증 - 관리자 계정 생성에 반드시 필요한 정보들
-<<<<<<< HEAD
-	if req.ManagerID == "" || req.ManagerName == "" || req.Password == "" || req.Email == "" {
-		log.Printf("필수 필드 누락 - ManagerID: %s, ManagerName: %s, Email: %s",
-			req.ManagerID, req.ManagerName, req.Email)
-		http.Error(w, "필수 필드가 누락되었습니다 (manager_id, manager_name, password, email)", http.StatusBadRequest)
-=======
 	if req.ManagerID == "" || req.Name == "" || req.Password == "" || req.Email == "" {
-		log.Printf("필수 필드 누락 - ManagerID: %s, Name: %s, Email: %s", 
+		log.Printf("필수 필드 누락 - ManagerID: %s, Name: %s, Email: %s",
 			req.ManagerID, req.Name, req.Email)
 		http.Error(w, "필수 필드가 누락되었습니다 (manager_id, name, password, email)", http.StatusBadRequest)
->>>>>>> eb01e759e3958a53e0ac9aff24e1afc96568c521
 		return
 	}
 
@@ -368,13 +341,8 @@ func CreateManager(w http.ResponseWriter, r *http.Request) {
 
 	// 시작 시간 로깅
 	startTime := time.Now()
-<<<<<<< HEAD
 	log.Printf("Manager 생성 요청 시작 - ID: %s, Name: %s, Email: %s",
-		req.ManagerID, req.ManagerName, req.Email)
-=======
-	log.Printf("Manager 생성 요청 시작 - ID: %s, Name: %s, Email: %s", 
 		req.ManagerID, req.Name, req.Email)
->>>>>>> eb01e759e3958a53e0ac9aff24e1afc96568c521
 
 	// 비밀번호 해싱 (실제 환경에서는 bcrypt 등을 사용해야 함)
 	// TODO: bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost) 구현 필요
@@ -0,0 +1,587 @@
+// member.go
+package tables
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"narabackend/src/consts"
+	"narabackend/src/utils"
+)
+
+// 업체 회원 구분
+const (
+	MEMBERSHIP_TYPE_BASIC   = "basic"   // 기본 회원
+	MEMBERSHIP_TYPE_PREMIUM = "premium" // 프리미엄
+	MEMBERSHIP_TYPE_VIP     = "vip"     // VIP
+)
+
+// 업체 회원 상태
+const (
+	MEMBER_STATUS_ACTIVE    = "active"    // 활성
+	MEMBER_STATUS_INACTIVE  = "inactive"  // 비활성
+	MEMBER_STATUS_SUSPENDED = "suspended" // 정지
+)
+
+// 회원 이력 항목 종류
+const (
+	MEMBER_HISTORY_SESSION = "session" // 입실/퇴실
+	MEMBER_HISTORY_PAYMENT = "payment" // 결제/환불
+)
+
+// 목록 조회 페이지 크기
+const (
+	DEFAULT_PAGE_SIZE = 20
+	MAX_PAGE_SIZE     = 100
+)
+
+var membershipTypes = map[string]bool{
+	MEMBERSHIP_TYPE_BASIC:   true,
+	MEMBERSHIP_TYPE_PREMIUM: true,
+	MEMBERSHIP_TYPE_VIP:     true,
+}
+
+var memberStatuses = map[string]bool{
+	MEMBER_STATUS_ACTIVE:    true,
+	MEMBER_STATUS_INACTIVE:  true,
+	MEMBER_STATUS_SUSPENDED: true,
+}
+
+// MemberProfile 구조체는 member_profile_table의 각 컬럼을 매핑합니다.
+type MemberProfile struct {
+	SerialNumber   int64     `json:"serial_number" db:"serial_number"`
+	CompanyCode    string    `json:"company_code" db:"company_code"`
+	MemberID       int64     `json:"member_id" db:"member_id"`
+	MembershipType string    `json:"membership_type" db:"membership_type"`
+	Status         string    `json:"status" db:"status"`
+	GradeNumber    *int      `json:"grade_number" db:"grade_number"`
+	GradeName      *string   `json:"grade_name" db:"grade_name"`
+	UnmannedGrade  *int      `json:"unmanned_grade" db:"unmanned_grade"`
+	Memo           string    `json:"memo" db:"memo"`
+	RegisteredAt   time.Time `json:"registered_at" db:"registered_at"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// MemberProfileRequest는 업체 회원 정보 저장 요청 시 사용되는 구조체입니다.
+// 포인터 필드는 생략 시 기존 값(없으면 기본값)을 유지합니다.
+type MemberProfileRequest struct {
+	MembershipType *string    `json:"membership_type"`
+	Status         *string    `json:"status"`
+	GradeNumber    *int       `json:"grade_number"`
+	GradeName      *string    `json:"grade_name"`
+	UnmannedGrade  *int       `json:"unmanned_grade"`
+	Memo           *string    `json:"memo"`
+	RegisteredAt   *time.Time `json:"registered_at"`
+}
+
+// MemberGrade는 회원 등급입니다. 업체 회원 정보에 등급이 없으면 현재 좌석(seat_table)의 등급을 사용합니다.
+type MemberGrade struct {
+	GradeNumber   *int    `json:"gradeNumber"`
+	GradeName     *string `json:"gradeName"`
+	UnmannedGrade *int    `json:"unmannedGrade"`
+	Source        string  `json:"source"` // profile, seat
+}
+
+// MemberSeat는 회원이 현재 사용 중인 좌석입니다.
+type MemberSeat struct {
+	SessionID  *int64     `json:"sessionId"`
+	SeatCode   int        `json:"seatCode"`
+	RoomCode   *int       `json:"roomCode"`
+	SeatNumber *int       `json:"seatNumber"`
+	CheckInAt  *time.Time `json:"checkInAt"`
+}
+
+// Member는 데스크 회원 화면에 필요한 회원 정보를 한 번에 모은 구조체입니다.
+// 필드 이름은 naradesk의 Member 모델과 같습니다.
+type Member struct {
+	ID               int64        `json:"id"`
+	CompanyCode      string       `json:"companyCode"`
+	Name             string       `json:"name"`
+	Email            string       `json:"email"`
+	Phone            string       `json:"phone"`
+	RegistrationDate time.Time    `json:"registrationDate"`
+	MembershipType   string       `json:"membershipType"`
+	Status           string       `json:"status"`
+	TotalHours       int          `json:"totalHours"`
+	TotalMinutes     int64        `json:"totalMinutes"`
+	TotalPayment     int64        `json:"totalPayment"`
+	Memo             string       `json:"memo"`
+	HasProfile       bool         `json:"hasProfile"`
+	Grade            *MemberGrade `json:"grade"`
+	CurrentSeat      *MemberSeat  `json:"currentSeat"`
+	ActivePasses     []MemberPass `json:"activePasses,omitempty"`
+}
+
+// MemberHistoryItem은 회원 이력(입실/퇴실, 결제/환불) 한 건입니다.
+type MemberHistoryItem struct {
+	Type          string     `json:"type"`
+	ID            int64      `json:"id"`
+	OccurredAt    time.Time  `json:"occurredAt"`
+	SeatCode      *int       `json:"seatCode,omitempty"`
+	CheckOutAt    *time.Time `json:"checkOutAt,omitempty"`
+	UsedMinutes   *int       `json:"usedMinutes,omitempty"`
+	ReleaseReason *string    `json:"releaseReason,omitempty"`
+	PaymentType   *string    `json:"paymentType,omitempty"`
+	PaymentMethod *string    `json:"paymentMethod,omitempty"`
+	Amount        *int       `json:"amount,omitempty"`
+	Description   *string    `json:"description,omitempty"`
+}
+
+// MemberHistoryPage는 회원 이력 페이지 응답입니다.
+type MemberHistoryPage struct {
+	Items    []MemberHistoryItem `json:"items"`
+	Page     int                 `json:"page"`
+	PageSize int                 `json:"pageSize"`
+	Total    int                 `json:"total"`
+}
+
+// MemberPage는 업체 회원 목록 페이지 응답입니다.
+type MemberPage struct {
+	Items    []Member `json:"items"`
+	Page     int      `json:"page"`
+	PageSize int      `json:"pageSize"`
+	Total    int      `json:"total"`
+}
+
+// memberProfileColumns는 member_profile_table 조회 시 사용하는 컬럼 목록입니다.
+const memberProfileColumns = `serial_number, company_code, member_id, membership_type, status,
+	grade_number, grade_name, unmanned_grade, COALESCE(memo, ''), registered_at, created_at, updated_at`
+
+// scanMemberProfile은 한 행을 MemberProfile로 읽습니다.
+func scanMemberProfile(row interface{ Scan(...interface{}) error }, profile *MemberProfile) error {
+	return row.Scan(&profile.SerialNumber, &profile.CompanyCode, &profile.MemberID, &profile.MembershipType,
+		&profile.Status, &profile.GradeNumber, &profile.GradeName, &profile.UnmannedGrade, &profile.Memo,
+		&profile.RegisteredAt, &profile.CreatedAt, &profile.UpdatedAt)
+}
+
+// memberSelect는 업체 회원 정보와 누적 이용 시간/결제 금액을 함께 조회합니다.
+// $1은 회사 코드, $2는 진행 중인 세션의 이용 시간을 계산할 기준 시각입니다.
+// 업체 회원 정보가 없으면 기본 회원/활성으로 보고, 가입일은 첫 이용권 발급일(없으면 계정 생성일)입니다.
+const memberSelect = `
+	SELECT u.serial_number, $1::text, u.name, u.email, COALESCE(u.phone1, ''),
+	       COALESCE(mp.registered_at,
+	                (SELECT MIN(p.created_at) FROM member_pass_table p WHERE p.company_code = $1 AND p.member_id = u.serial_number),
+	                u.created_at),
+	       COALESCE(mp.membership_type, 'basic'), COALESCE(mp.status, 'active'), COALESCE(mp.memo, ''),
+	       mp.serial_number IS NOT NULL, mp.grade_number, mp.grade_name, mp.unmanned_grade,
+	       (SELECT COALESCE(SUM(CASE WHEN s.check_out_at IS NULL
+	                                 THEN GREATEST(CEIL(EXTRACT(EPOCH FROM ($2::timestamp - s.check_in_at)) / 60), 0)
+	                                 ELSE s.used_minutes END), 0)::BIGINT
+	        FROM seat_session_table s WHERE s.company_code = $1 AND s.member_id = u.serial_number),
+	       (SELECT COALESCE(SUM(CASE WHEN pay.payment_type = 'refund' THEN -pay.amount ELSE pay.amount END), 0)::BIGINT
+	        FROM payment_table pay WHERE pay.company_code = $1 AND pay.member_id = u.serial_number)
+	FROM user_table u
+	LEFT JOIN member_profile_table mp ON mp.company_code = $1 AND mp.member_id = u.serial_number`
+
+// memberOfCompanyCondition은 업체 회원 정보가 있거나 업체에서 이용권을 발급받은 회원 조건입니다.
+const memberOfCompanyCondition = `(mp.serial_number IS NOT NULL OR EXISTS (
+	SELECT 1 FROM member_pass_table p WHERE p.company_code = $1 AND p.member_id = u.serial_number))`
+
+// scanMember는 memberSelect 순서로 조회된 행을 Member 구조체로 변환합니다.
+func scanMember(row interface{ Scan(...interface{}) error }, member *Member) error {
+	var grade MemberGrade
+	err := row.Scan(&member.ID, &member.CompanyCode, &member.Name, &member.Email, &member.Phone,
+		&member.RegistrationDate, &member.MembershipType, &member.Status, &member.Memo,
+		&member.HasProfile, &grade.GradeNumber, &grade.GradeName, &grade.UnmannedGrade,
+		&member.TotalMinutes, &member.TotalPayment)
+	if err != nil {
+		return err
+	}
+	member.TotalHours = int(member.TotalMinutes / 60)
+	if grade.GradeNumber != nil || grade.GradeName != nil || grade.UnmannedGrade != nil {
+		grade.Source = "profile"
+		member.Grade = &grade
+	}
+	return nil
+}
+
+// errNotCompanyMember는 업체 회원이 아닌 사용자를 조회한 경우 반환됩니다.
+var errNotCompanyMember = errors.New("업체 회원이 아닙니다")
+
+// loadMember는 업체 회원 한 명의 전체 정보(현재 좌석, 사용 가능한 이용권, 등급 포함)를 조회합니다.
+func loadMember(ctx context.Context, companyCode string, memberID int64, now time.Time) (Member, error) {
+	var member Member
+	err := scanMember(utils.DB.QueryRowContext(ctx,
+		memberSelect+" WHERE u.serial_number = $3", companyCode, now, memberID), &member)
+	if err != nil {
+		return member, err
+	}
+
+	// 사용 가능한 이용권 (만료 작업이 아직 처리하지 않은 유효 기간 경과 이용권은 제외)
+	member.ActivePasses = []MemberPass{}
+	rows, err := utils.DB.QueryContext(ctx, `
+		SELECT `+memberPassColumns+` FROM member_pass_table
+		WHERE company_code = $1 AND member_id = $2 AND status = $3 AND (valid_until IS NULL OR valid_until > $4)
+		ORDER BY valid_until ASC NULLS LAST, serial_number ASC`,
+		companyCode, memberID, PASS_STATUS_ACTIVE, now)
+	if err != nil {
+		return member, err
+	}
+	for rows.Next() {
+		var pass MemberPass
+		if err := scanMemberPass(rows, &pass); err != nil {
+			rows.Close()
+			return member, err
+		}
+		member.ActivePasses = append(member.ActivePasses, pass)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return member, err
+	}
+
+	if !member.HasProfile && len(member.ActivePasses) == 0 {
+		var exists bool
+		err = utils.DB.QueryRowContext(ctx, `
+			SELECT EXISTS (SELECT 1 FROM member_pass_table WHERE company_code = $1 AND member_id = $2)`,
+			companyCode, memberID).Scan(&exists)
+		if err != nil {
+			return member, err
+		}
+		if !exists {
+			return member, errNotCompanyMember
+		}
+	}
+
+	// 현재 좌석: 열린 세션을 우선하고, 없으면 seat_table에 배정된 좌석을 찾습니다.
+	var seat MemberSeat
+	var seatGrade MemberGrade
+	err = utils.DB.QueryRowContext(ctx, `
+		SELECT s.serial_number, s.seat_code, st.room_code, st.seat_number, s.check_in_at,
+		       st.grade_number, st.grade_name, st.unmanned_grade
+		FROM seat_session_table s
+		LEFT JOIN seat_table st ON st.company_code = s.company_code AND st.seat_code = s.seat_code
+		WHERE s.company_code = $1 AND s.member_id = $2 AND s.check_out_at IS NULL
+		ORDER BY s.check_in_at DESC LIMIT 1`, companyCode, memberID).Scan(
+		&seat.SessionID, &seat.SeatCode, &seat.RoomCode, &seat.SeatNumber, &seat.CheckInAt,
+		&seatGrade.GradeNumber, &seatGrade.GradeName, &seatGrade.UnmannedGrade)
+	if err == sql.ErrNoRows {
+		err = utils.DB.QueryRowContext(ctx, `
+			SELECT seat_code, room_code, seat_number, grade_number, grade_name, unmanned_grade
+			FROM seat_table WHERE company_code = $1 AND member_id = $2 AND seat_code IS NOT NULL
+			ORDER BY seat_code LIMIT 1`, companyCode, strconv.FormatInt(memberID, 10)).Scan(
+			&seat.SeatCode, &seat.RoomCode, &seat.SeatNumber,
+			&seatGrade.GradeNumber, &seatGrade.GradeName, &seatGrade.UnmannedGrade)
+	}
+	switch {
+	case err == nil:
+		member.CurrentSeat = &seat
+		if member.Grade == nil && (seatGrade.GradeNumber != nil || seatGrade.GradeName != nil || seatGrade.UnmannedGrade != nil) {
+			seatGrade.Source = "seat"
+			member.Grade = &seatGrade
+		}
+	case err != sql.ErrNoRows:
+		return member, err
+	}
+	return member, nil
+}
+
+// parsePagination은 page, page_size 쿼리 파라미터를 읽습니다. page는 1부터 시작합니다.
+func parsePagination(r *http.Request) (int, int, error) {
+	page, pageSize := 1, DEFAULT_PAGE_SIZE
+	if v := r.URL.Query().Get("page"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return 0, 0, errors.New("page는 1 이상의 정수여야 합니다")
+		}
+		page = n
+	}
+	if v := r.URL.Query().Get("page_size"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > MAX_PAGE_SIZE {
+			return 0, 0, fmt.Errorf("page_size는 1~%d 사이의 정수여야 합니다", MAX_PAGE_SIZE)
+		}
+		pageSize = n
+	}
+	return page, pageSize, nil
+}
+
+// RegisterMemberRoutes는 업체 회원(member_profile_table 및 회원 이력) 관련 엔드포인트를 등록합니다.
+func RegisterMemberRoutes(r *mux.Router) {
+	r.HandleFunc("/companies/{company_code}/members", GetMembers).Methods("GET")
+	r.HandleFunc("/companies/{company_code}/members/{member_id:[0-9]+}", GetMember).Methods("GET")
+	r.HandleFunc("/companies/{company_code}/members/{member_id:[0-9]+}/profile", SaveMemberProfile).Methods("PUT", "PATCH")
+	r.HandleFunc("/companies/{company_code}/members/{member_id:[0-9]+}/history", GetMemberHistory).Methods("GET")
+}
+
+// GetMembers: 업체 회원 목록을 조회합니다.
+// status, membership_type, search(이름/이메일/전화번호 부분 검색), page, page_size 쿼리 파라미터를 지원합니다.
+func GetMembers(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	page, pageSize, err := parsePagination(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	filters := []string{memberOfCompanyCondition}
+	args := []interface{}{mux.Vars(r)["company_code"], time.Now()}
+	filterParams := map[string]string{
+		"status":          "COALESCE(mp.status, 'active')",
+		"membership_type": "COALESCE(mp.membership_type, 'basic')",
+	}
+	for param, expr := range filterParams {
+		if value := r.URL.Query().Get(param); value != "" {
+			args = append(args, value)
+			filters = append(filters, fmt.Sprintf("%s = $%d", expr, len(args)))
+		}
+	}
+	if search := r.URL.Query().Get("search"); search != "" {
+		args = append(args, "%"+search+"%")
+		filters = append(filters, fmt.Sprintf("(u.name LIKE $%d OR u.email LIKE $%d OR u.phone1 LIKE $%d)",
+			len(args), len(args), len(args)))
+	}
+	where := " WHERE " + strings.Join(filters, " AND ")
+
+	result := MemberPage{Items: []Member{}, Page: page, PageSize: pageSize}
+	err = utils.DB.QueryRowContext(ctx, "SELECT COUNT(*) FROM ("+memberSelect+where+") m", args...).Scan(&result.Total)
+	if err != nil {
+		log.Printf("데이터베이스 쿼리 오류: %v", err)
+		http.Error(w, "데이터 조회 중 오류가 발생했습니다", http.StatusInternalServerError)
+		return
+	}
+
+	query := memberSelect + where + fmt.Sprintf(" ORDER BY u.name ASC, u.serial_number ASC LIMIT %d OFFSET %d",
+		pageSize, (page-1)*pageSize)
+	rows, err := utils.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("데이터베이스 쿼리 오류: %v", err)
+		http.Error(w, "데이터 조회 중 오류가 발생했습니다", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var member Member
+		if err := scanMember(rows, &member); err != nil {
+			log.Printf("행 스캔 오류: %v", err)
+			http.Error(w, "데이터 처리 중 오류가 발생했습니다", http.StatusInternalServerError)
+			return
+		}
+		result.Items = append(result.Items, member)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// GetMember: 업체 회원 한 명의 정보, 현재 좌석, 사용 가능한 이용권, 등급, 누적 이용 시간/결제 금액을 조회합니다.
+func GetMember(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	vars := mux.Vars(r)
+	memberID, _ := strconv.ParseInt(vars["member_id"], 10, 64)
+	member, err := loadMember(ctx, vars["company_code"], memberID, time.Now())
+	if err != nil {
+		switch {
+		case err == sql.ErrNoRows:
+			http.Error(w, "회원을 찾을 수 없습니다.", http.StatusNotFound)
+		case err == errNotCompanyMember:
+			http.Error(w, err.Error(), http.StatusNotFound)
+		default:
+			log.Printf("회원 조회 오류: %v", err)
+			http.Error(w, "데이터 조회 중 오류가 발생했습니다", http.StatusInternalServerError)
+		}
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(member)
+}
+
+// SaveMemberProfile: 업체 회원 정보(회원 구분, 상태, 등급, 메모)를 생성하거나 수정합니다.
+func SaveMemberProfile(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	vars := mux.Vars(r)
+	memberID, _ := strconv.ParseInt(vars["member_id"], 10, 64)
+	var req MemberProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "잘못된 요청 데이터", http.StatusBadRequest)
+		return
+	}
+
+	profile := MemberProfile{
+		CompanyCode:    vars["company_code"],
+		MemberID:       memberID,
+		MembershipType: MEMBERSHIP_TYPE_BASIC,
+		Status:         MEMBER_STATUS_ACTIVE,
+		RegisteredAt:   time.Now(),
+	}
+	err := scanMemberProfile(utils.DB.QueryRowContext(ctx,
+		"SELECT "+memberProfileColumns+" FROM member_profile_table WHERE company_code = $1 AND member_id = $2",
+		profile.CompanyCode, memberID), &profile)
+	if err != nil && err != sql.ErrNoRows {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if req.MembershipType != nil {
+		profile.MembershipType = *req.MembershipType
+	}
+	if req.Status != nil {
+		profile.Status = *req.Status
+	}
+	if req.GradeNumber != nil {
+		profile.GradeNumber = req.GradeNumber
+	}
+	if req.GradeName != nil {
+		profile.GradeName = req.GradeName
+	}
+	if req.UnmannedGrade != nil {
+		profile.UnmannedGrade = req.UnmannedGrade
+	}
+	if req.Memo != nil {
+		profile.Memo = *req.Memo
+	}
+	if req.RegisteredAt != nil {
+		profile.RegisteredAt = *req.RegisteredAt
+	}
+	if !membershipTypes[profile.MembershipType] {
+		http.Error(w, "membership_type은 basic, premium, vip 중 하나여야 합니다", http.StatusBadRequest)
+		return
+	}
+	if !memberStatuses[profile.Status] {
+		http.Error(w, "status는 active, inactive, suspended 중 하나여야 합니다", http.StatusBadRequest)
+		return
+	}
+
+	err = scanMemberProfile(utils.DB.QueryRowContext(ctx, `
+		INSERT INTO member_profile_table
+		(company_code, member_id, membership_type, status, grade_number, grade_name, unmanned_grade,
+		 memo, registered_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		ON CONFLICT (company_code, member_id) DO UPDATE SET
+			membership_type = EXCLUDED.membership_type,
+			status = EXCLUDED.status,
+			grade_number = EXCLUDED.grade_number,
+			grade_name = EXCLUDED.grade_name,
+			unmanned_grade = EXCLUDED.unmanned_grade,
+			memo = EXCLUDED.memo,
+			registered_at = EXCLUDED.registered_at,
+			updated_at = CURRENT_TIMESTAMP
+		RETURNING `+memberProfileColumns,
+		profile.CompanyCode, profile.MemberID, profile.MembershipType, profile.Status, profile.GradeNumber,
+		profile.GradeName, profile.UnmannedGrade, nullableString(profile.Memo), profile.RegisteredAt), &profile)
+	if err != nil {
+		if strings.Contains(err.Error(), "foreign key") {
+			http.Error(w, "회원을 찾을 수 없습니다.", http.StatusNotFound)
+			return
+		}
+		log.Printf("회원 정보 저장 오류: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(profile)
+}
+
+// GetMemberHistory: 회원의 입실/퇴실과 결제/환불 이력을 최신순으로 조회합니다.
+// type(session, payment), from/to(YYYY-MM-DD), page, page_size 쿼리 파라미터를 지원합니다.
+func GetMemberHistory(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	vars := mux.Vars(r)
+	memberID, _ := strconv.ParseInt(vars["member_id"], 10, 64)
+	page, pageSize, err := parsePagination(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	args := []interface{}{vars["company_code"], memberID}
+	sessionFilter := "company_code = $1 AND member_id = $2"
+	paymentFilter := "company_code = $1 AND member_id = $2"
+	if from := r.URL.Query().Get("from"); from != "" {
+		if _, err := time.Parse("2006-01-02", from); err != nil {
+			http.Error(w, "잘못된 from 형식입니다 (YYYY-MM-DD)", http.StatusBadRequest)
+			return
+		}
+		args = append(args, from)
+		sessionFilter += fmt.Sprintf(" AND check_in_at >= $%d::date", len(args))
+		paymentFilter += fmt.Sprintf(" AND created_at >= $%d::date", len(args))
+	}
+	if to := r.URL.Query().Get("to"); to != "" {
+		if _, err := time.Parse("2006-01-02", to); err != nil {
+			http.Error(w, "잘못된 to 형식입니다 (YYYY-MM-DD)", http.StatusBadRequest)
+			return
+		}
+		args = append(args, to)
+		sessionFilter += fmt.Sprintf(" AND check_in_at < $%d::date + 1", len(args))
+		paymentFilter += fmt.Sprintf(" AND created_at < $%d::date + 1", len(args))
+	}
+
+	sessionQuery := `SELECT 'session' AS item_type, serial_number, check_in_at AS occurred_at, seat_code,
+		check_out_at, used_minutes, release_reason, NULL::text AS payment_type, NULL::text AS payment_method,
+		NULL::integer AS amount, NULL::text AS description
+		FROM seat_session_table WHERE ` + sessionFilter
+	paymentQuery := `SELECT 'payment' AS item_type, serial_number, created_at AS occurred_at, NULL::integer AS seat_code,
+		NULL::timestamp AS check_out_at, NULL::integer AS used_minutes, NULL::text AS release_reason,
+		payment_type, payment_method, amount, description
+		FROM payment_table WHERE ` + paymentFilter
+	var history string
+	switch r.URL.Query().Get("type") {
+	case "":
+		history = sessionQuery + " UNION ALL " + paymentQuery
+	case MEMBER_HISTORY_SESSION:
+		history = sessionQuery
+	case MEMBER_HISTORY_PAYMENT:
+		history = paymentQuery
+	default:
+		http.Error(w, "type은 session, payment 중 하나여야 합니다", http.StatusBadRequest)
+		return
+	}
+
+	result := MemberHistoryPage{Items: []MemberHistoryItem{}, Page: page, PageSize: pageSize}
+	err = utils.DB.QueryRowContext(ctx, "SELECT COUNT(*) FROM ("+history+") h", args...).Scan(&result.Total)
+	if err != nil {
+		log.Printf("데이터베이스 쿼리 오류: %v", err)
+		http.Error(w, "데이터 조회 중 오류가 발생했습니다", http.StatusInternalServerError)
+		return
+	}
+
+	query := "SELECT * FROM (" + history + ") h" +
+		fmt.Sprintf(" ORDER BY occurred_at DESC, serial_number DESC LIMIT %d OFFSET %d", pageSize, (page-1)*pageSize)
+	rows, err := utils.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("데이터베이스 쿼리 오류: %v", err)
+		http.Error(w, "데이터 조회 중 오류가 발생했습니다", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item MemberHistoryItem
+		err := rows.Scan(&item.Type, &item.ID, &item.OccurredAt, &item.SeatCode, &item.CheckOutAt,
+			&item.UsedMinutes, &item.ReleaseReason, &item.PaymentType, &item.PaymentMethod,
+			&item.Amount, &item.Description)
+		if err != nil {
+			log.Printf("행 스캔 오류: %v", err)
+			http.Error(w, "데이터 처리 중 오류가 발생했습니다", http.StatusInternalServerError)
+			return
+		}
+		result.Items = append(result.Items, item)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,335 @@
+// member_pass.go
+package tables
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"narabackend/src/consts"
+	"narabackend/src/utils"
+)
+
+// 회원 이용권 상태
+const (
+	PASS_STATUS_ACTIVE    = "active"    // 사용 가능
+	PASS_STATUS_EXHAUSTED = "exhausted" // 잔여 시간 소진
+	PASS_STATUS_EXPIRED   = "expired"   // 유효 기간 만료
+	PASS_STATUS_CANCELLED = "cancelled" // 취소
+)
+
+// MemberPass 구조체는 member_pass_table의 각 컬럼을 매핑합니다.
+// 시간제 이용권은 RemainingMinutes를, 기간권은 ValidFrom~ValidUntil을 기준으로 사용합니다.
+type MemberPass struct {
+	SerialNumber     int64      `json:"serial_number" db:"serial_number"`
+	CompanyCode      string     `json:"company_code" db:"company_code"`
+	MemberID         int64      `json:"member_id" db:"member_id"`
+	PlanID           int64      `json:"plan_id" db:"plan_id"`
+	PassType         string     `json:"pass_type" db:"pass_type"`
+	RemainingMinutes *int       `json:"remaining_minutes" db:"remaining_minutes"`
+	ValidFrom        time.Time  `json:"valid_from" db:"valid_from"`
+	ValidUntil       *time.Time `json:"valid_until" db:"valid_until"`
+	SeatCode         *int       `json:"seat_code" db:"seat_code"`
+	Status           string     `json:"status" db:"status"`
+	PurchasedAmount  int        `json:"purchased_amount" db:"purchased_amount"`
+	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// MemberPassRequest는 이용권 발급 요청 시 사용되는 구조체입니다.
+type MemberPassRequest struct {
+	MemberID  int64      `json:"member_id"`
+	PlanID    int64      `json:"plan_id"`
+	SeatCode  *int       `json:"seat_code"`
+	ValidFrom *time.Time `json:"valid_from"`
+}
+
+// memberPassColumns는 member_pass_table 조회 시 사용하는 컬럼 목록입니다.
+const memberPassColumns = `serial_number, company_code, member_id, plan_id, pass_type,
+	remaining_minutes, valid_from, valid_until, seat_code, status, purchased_amount,
+	created_at, updated_at`
+
+// scanMemberPass는 memberPassColumns 순서로 조회된 행을 MemberPass 구조체로 변환합니다.
+func scanMemberPass(row interface{ Scan(...interface{}) error }, pass *MemberPass) error {
+	return row.Scan(&pass.SerialNumber, &pass.CompanyCode, &pass.MemberID, &pass.PlanID, &pass.PassType,
+		&pass.RemainingMinutes, &pass.ValidFrom, &pass.ValidUntil, &pass.SeatCode, &pass.Status,
+		&pass.PurchasedAmount, &pass.CreatedAt, &pass.UpdatedAt)
+}
+
+// newPassFromPlan은 상품 조건을 복사하여 발급할 이용권을 만듭니다.
+// 상품이 나중에 변경되더라도 발급된 이용권은 구매 시점의 조건을 유지합니다.
+func newPassFromPlan(plan Plan, memberID int64, validFrom time.Time) MemberPass {
+	pass := MemberPass{
+		CompanyCode:     plan.CompanyCode,
+		MemberID:        memberID,
+		PlanID:          plan.SerialNumber,
+		PassType:        plan.PlanType,
+		ValidFrom:       validFrom,
+		Status:          PASS_STATUS_ACTIVE,
+		PurchasedAmount: plan.Price,
+	}
+
+	if isTimeBasedPlan(plan.PlanType) {
+		minutes := *plan.DurationMinutes
+		pass.RemainingMinutes = &minutes
+	}
+
+	switch {
+	case plan.PlanType == PLAN_TYPE_DAILY:
+		// 당일권은 구매일 자정까지 유효합니다.
+		y, m, d := validFrom.Date()
+		until := time.Date(y, m, d+1, 0, 0, 0, 0, validFrom.Location())
+		pass.ValidUntil = &until
+	case plan.ValidityDays != nil && *plan.ValidityDays > 0:
+		until := validFrom.AddDate(0, 0, *plan.ValidityDays)
+		pass.ValidUntil = &until
+	}
+	return pass
+}
+
+// errPassNotUsable은 이용권으로 입실할 수 없는 경우 반환됩니다.
+var errPassNotUsable = errors.New("사용할 수 없는 이용권입니다")
+
+// checkPassUsable은 at 시점에 이용권으로 입실 가능한지 확인합니다.
+func checkPassUsable(pass MemberPass, at time.Time) error {
+	if pass.Status != PASS_STATUS_ACTIVE {
+		return fmt.Errorf("%w: 상태=%s", errPassNotUsable, pass.Status)
+	}
+	if at.Before(pass.ValidFrom) {
+		return fmt.Errorf("%w: 유효 기간 시작 전입니다", errPassNotUsable)
+	}
+	if pass.ValidUntil != nil && !at.Before(*pass.ValidUntil) {
+		return fmt.Errorf("%w: 유효 기간이 지났습니다", errPassNotUsable)
+	}
+	if pass.RemainingMinutes != nil && *pass.RemainingMinutes <= 0 {
+		return fmt.Errorf("%w: 잔여 시간이 없습니다", errPassNotUsable)
+	}
+	return nil
+}
+
+// passDeadline은 checkInAt에 입실한 세션이 이용권으로 머무를 수 있는 마지막 시각을 계산합니다.
+// 잔여 시간과 유효 기간 중 먼저 도래하는 시각이며, 둘 다 없으면 nil을 반환합니다.
+func passDeadline(pass MemberPass, checkInAt time.Time) *time.Time {
+	var deadline *time.Time
+	if pass.RemainingMinutes != nil {
+		t := checkInAt.Add(time.Duration(*pass.RemainingMinutes) * time.Minute)
+		deadline = &t
+	}
+	if pass.ValidUntil != nil && (deadline == nil || pass.ValidUntil.Before(*deadline)) {
+		t := *pass.ValidUntil
+		deadline = &t
+	}
+	return deadline
+}
+
+// expireMemberPasses는 유효 기간이 지난 사용 가능 이용권을 expired로 바꾸고 바뀐 수를 반환합니다.
+// 이용 중인 세션은 좌석 자동 해제(passDeadline)가 따로 정리하므로 상태만 바꿉니다.
+func expireMemberPasses(ctx context.Context, now time.Time) (int64, error) {
+	result, err := utils.DB.ExecContext(ctx, `
+		UPDATE member_pass_table SET status = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE status = $2 AND valid_until <= $3`,
+		PASS_STATUS_EXPIRED, PASS_STATUS_ACTIVE, now)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// RegisterMemberPassRoutes는 member_pass_table 관련 엔드포인트를 등록합니다.
+func RegisterMemberPassRoutes(r *mux.Router) {
+	r.HandleFunc("/member-passes", GetMemberPasses).Methods("GET")
+	r.HandleFunc("/member-passes/{id}", GetMemberPass).Methods("GET")
+	r.HandleFunc("/member-passes", CreateMemberPass).Methods("POST")
+	r.HandleFunc("/member-passes/{id}/cancel", CancelMemberPass).Methods("POST")
+}
+
+// GetMemberPasses: 회원 이용권 목록을 조회합니다.
+// company_code, member_id, status 쿼리 파라미터로 필터링합니다.
+func GetMemberPasses(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	filters := []string{}
+	args := []interface{}{}
+	paramIdx := 1
+
+	filterParams := map[string]string{
+		"company_code": "company_code",
+		"member_id":    "member_id",
+		"status":       "status",
+	}
+	for param, dbField := range filterParams {
+		if value := r.URL.Query().Get(param); value != "" {
+			filters = append(filters, fmt.Sprintf("%s = $%d", dbField, paramIdx))
+			args = append(args, value)
+			paramIdx++
+		}
+	}
+
+	query := "SELECT " + memberPassColumns + " FROM member_pass_table"
+	if len(filters) > 0 {
+		query += " WHERE " + strings.Join(filters, " AND ")
+	}
+	query += " ORDER BY serial_number DESC"
+
+	rows, err := utils.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("데이터베이스 쿼리 오류: %v", err)
+		http.Error(w, "데이터 조회 중 오류가 발생했습니다", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	result := []MemberPass{}
+	for rows.Next() {
+		var pass MemberPass
+		if err := scanMemberPass(rows, &pass); err != nil {
+			log.Printf("행 스캔 오류: %v", err)
+			http.Error(w, "데이터 처리 중 오류가 발생했습니다", http.StatusInternalServerError)
+			return
+		}
+		result = append(result, pass)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// GetMemberPass: 단일 회원 이용권을 조회합니다.
+func GetMemberPass(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "잘못된 id", http.StatusBadRequest)
+		return
+	}
+
+	var pass MemberPass
+	err = scanMemberPass(utils.DB.QueryRowContext(ctx,
+		"SELECT "+memberPassColumns+" FROM member_pass_table WHERE serial_number = $1", id), &pass)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "이용권을 찾을 수 없습니다.", http.StatusNotFound)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(pass)
+}
+
+// CreateMemberPass: 상품(plan)을 기준으로 회원에게 이용권을 발급합니다.
+// 가격, 제공 시간, 유효 기간은 상품에서 복사되며 클라이언트가 지정할 수 없습니다.
+func CreateMemberPass(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	var req MemberPassRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "잘못된 요청 데이터", http.StatusBadRequest)
+		return
+	}
+	if req.MemberID == 0 || req.PlanID == 0 {
+		http.Error(w, "필수 필드가 누락되었습니다 (member_id, plan_id)", http.StatusBadRequest)
+		return
+	}
+
+	var plan Plan
+	err := scanPlan(utils.DB.QueryRowContext(ctx,
+		"SELECT "+planColumns+" FROM plan_table WHERE serial_number = $1", req.PlanID), &plan)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Plan을 찾을 수 없습니다.", http.StatusNotFound)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	if !plan.Active {
+		http.Error(w, "판매 중지된 상품입니다", http.StatusBadRequest)
+		return
+	}
+	if plan.FixedSeat && req.SeatCode == nil {
+		http.Error(w, "고정석 상품은 seat_code가 필요합니다", http.StatusBadRequest)
+		return
+	}
+
+	validFrom := time.Now()
+	if req.ValidFrom != nil {
+		validFrom = *req.ValidFrom
+	}
+	pass := newPassFromPlan(plan, req.MemberID, validFrom)
+	if plan.FixedSeat {
+		pass.SeatCode = req.SeatCode
+	}
+
+	log.Printf("이용권 발급 요청 시작: member_id=%d, plan_id=%d", req.MemberID, req.PlanID)
+	err = scanMemberPass(utils.DB.QueryRowContext(ctx, `
+		INSERT INTO member_pass_table
+		(company_code, member_id, plan_id, pass_type, remaining_minutes, valid_from, valid_until,
+		 seat_code, status, purchased_amount, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		RETURNING `+memberPassColumns,
+		pass.CompanyCode, pass.MemberID, pass.PlanID, pass.PassType, pass.RemainingMinutes,
+		pass.ValidFrom, pass.ValidUntil, pass.SeatCode, pass.Status, pass.PurchasedAmount), &pass)
+	if err != nil {
+		log.Printf("DB 오류: %v", err)
+		if strings.Contains(err.Error(), "foreign key") {
+			http.Error(w, "존재하지 않는 회원입니다", http.StatusBadRequest)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(pass)
+}
+
+// CancelMemberPass: 회원 이용권을 취소합니다.
+// 이용 중인 세션이 있으면 먼저 퇴실 처리해야 합니다.
+func CancelMemberPass(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "잘못된 id", http.StatusBadRequest)
+		return
+	}
+
+	var pass MemberPass
+	err = scanMemberPass(utils.DB.QueryRowContext(ctx, `
+		UPDATE member_pass_table SET status = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE serial_number = $1 AND status = $3
+		  AND NOT EXISTS (SELECT 1 FROM seat_session_table s WHERE s.pass_id = $1 AND s.check_out_at IS NULL)
+		RETURNING `+memberPassColumns,
+		id, PASS_STATUS_CANCELLED, PASS_STATUS_ACTIVE), &pass)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "취소할 수 있는 이용권이 없습니다 (사용 중이거나 이미 종료됨)", http.StatusConflict)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(pass)
+}
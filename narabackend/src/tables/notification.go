@@ -0,0 +1,396 @@
+// notification.go
+package tables
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"narabackend/src/consts"
+	"narabackend/src/notifications"
+	"narabackend/src/utils"
+)
+
+// NotificationPreference 구조체는 notification_preference_table의 각 컬럼을 매핑합니다.
+// 저장된 설정이 없으면 모든 채널 수신, 기본 방해 금지 시간, 한국어로 간주하며 updated_at이 null입니다.
+type NotificationPreference struct {
+	CompanyCode  string     `json:"company_code" db:"company_code"`
+	MemberID     int64      `json:"member_id" db:"member_id"`
+	SMSEnabled   bool       `json:"sms_enabled" db:"sms_enabled"`
+	KakaoEnabled bool       `json:"kakao_enabled" db:"kakao_enabled"`
+	EmailEnabled bool       `json:"email_enabled" db:"email_enabled"`
+	QuietStart   *string    `json:"quiet_start" db:"quiet_start"` // HH:MM, null이면 기본값
+	QuietEnd     *string    `json:"quiet_end" db:"quiet_end"`
+	Locale       string     `json:"locale" db:"locale"`
+	UpdatedAt    *time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// NotificationPreferenceRequest는 알림 수신 설정 저장 요청 시 사용되는 구조체입니다.
+// 포인터 필드는 생략하면 기존 값(없으면 기본값)을 유지합니다. quiet_start와 quiet_end를 빈 문자열로 보내면 기본값으로 돌아갑니다.
+type NotificationPreferenceRequest struct {
+	SMSEnabled   *bool   `json:"sms_enabled"`
+	KakaoEnabled *bool   `json:"kakao_enabled"`
+	EmailEnabled *bool   `json:"email_enabled"`
+	QuietStart   *string `json:"quiet_start"`
+	QuietEnd     *string `json:"quiet_end"`
+	Locale       *string `json:"locale"`
+}
+
+// Notification 구조체는 notification_table(알림 발송 기록)의 각 컬럼을 매핑합니다.
+type Notification struct {
+	SerialNumber      int64      `json:"serial_number" db:"serial_number"`
+	CompanyCode       string     `json:"company_code" db:"company_code"`
+	RecipientType     string     `json:"recipient_type" db:"recipient_type"`
+	RecipientID       string     `json:"recipient_id" db:"recipient_id"`
+	Channel           string     `json:"channel" db:"channel"`
+	Address           string     `json:"address" db:"address"`
+	TemplateKey       string     `json:"template_key" db:"template_key"`
+	Locale            string     `json:"locale" db:"locale"`
+	Subject           *string    `json:"subject" db:"subject"`
+	Body              string     `json:"body" db:"body"`
+	DedupKey          *string    `json:"dedup_key" db:"dedup_key"`
+	Status            string     `json:"status" db:"status"`
+	Attempts          int        `json:"attempts" db:"attempts"`
+	Provider          *string    `json:"provider" db:"provider"`
+	ProviderMessageID *string    `json:"provider_message_id" db:"provider_message_id"`
+	LastError         *string    `json:"last_error" db:"last_error"`
+	ScheduledAt       time.Time  `json:"scheduled_at" db:"scheduled_at"`
+	SentAt            *time.Time `json:"sent_at" db:"sent_at"`
+	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
+}
+
+// NotificationSendRequest는 회원에게 알림을 직접 보내는 요청입니다.
+type NotificationSendRequest struct {
+	MemberID    int64                  `json:"member_id"`
+	TemplateKey string                 `json:"template_key"`
+	Data        map[string]interface{} `json:"data"`
+	Channel     string                 `json:"channel"`   // 비어 있으면 템플릿의 채널 우선순위에 따라 고릅니다
+	DedupKey    string                 `json:"dedup_key"` // 같은 키로 다시 요청하면 보내지 않습니다
+}
+
+// notificationPreferenceColumns는 notification_preference_table 조회 시 사용하는 컬럼 목록입니다.
+const notificationPreferenceColumns = `company_code, member_id, sms_enabled, kakao_enabled, email_enabled,
+	quiet_start, quiet_end, locale, updated_at`
+
+// notificationColumns는 notification_table 조회 시 사용하는 컬럼 목록입니다.
+const notificationColumns = `serial_number, company_code, recipient_type, recipient_id, channel, address, template_key,
+	locale, subject, body, dedup_key, status, attempts, provider, provider_message_id, last_error,
+	scheduled_at, sent_at, created_at`
+
+// scanNotificationPreference는 notificationPreferenceColumns 순서로 조회된 행을 NotificationPreference 구조체로 변환합니다.
+func scanNotificationPreference(row interface{ Scan(...interface{}) error }, p *NotificationPreference) error {
+	return row.Scan(&p.CompanyCode, &p.MemberID, &p.SMSEnabled, &p.KakaoEnabled, &p.EmailEnabled,
+		&p.QuietStart, &p.QuietEnd, &p.Locale, &p.UpdatedAt)
+}
+
+// scanNotification은 notificationColumns 순서로 조회된 행을 Notification 구조체로 변환합니다.
+func scanNotification(row interface{ Scan(...interface{}) error }, n *Notification) error {
+	return row.Scan(&n.SerialNumber, &n.CompanyCode, &n.RecipientType, &n.RecipientID, &n.Channel, &n.Address,
+		&n.TemplateKey, &n.Locale, &n.Subject, &n.Body, &n.DedupKey, &n.Status, &n.Attempts, &n.Provider,
+		&n.ProviderMessageID, &n.LastError, &n.ScheduledAt, &n.SentAt, &n.CreatedAt)
+}
+
+// defaultNotificationPreference는 저장된 설정이 없을 때의 수신 설정입니다.
+func defaultNotificationPreference(companyCode string, memberID int64) NotificationPreference {
+	return NotificationPreference{
+		CompanyCode:  companyCode,
+		MemberID:     memberID,
+		SMSEnabled:   true,
+		KakaoEnabled: true,
+		EmailEnabled: true,
+		Locale:       notifications.DEFAULT_LOCALE,
+	}
+}
+
+// loadNotificationPreference는 회원의 업체별 알림 수신 설정을 조회합니다. 없으면 기본값을 반환합니다.
+func loadNotificationPreference(ctx context.Context, q notificationQuerier, companyCode string, memberID int64) (NotificationPreference, error) {
+	var p NotificationPreference
+	err := scanNotificationPreference(q.QueryRowContext(ctx,
+		"SELECT "+notificationPreferenceColumns+" FROM notification_preference_table WHERE company_code = $1 AND member_id = $2",
+		companyCode, memberID), &p)
+	if err == sql.ErrNoRows {
+		return defaultNotificationPreference(companyCode, memberID), nil
+	}
+	return p, err
+}
+
+// channelEnabled는 채널 수신 동의 여부를 반환합니다.
+func (p NotificationPreference) channelEnabled(channel string) bool {
+	switch channel {
+	case notifications.CHANNEL_SMS:
+		return p.SMSEnabled
+	case notifications.CHANNEL_KAKAO:
+		return p.KakaoEnabled
+	case notifications.CHANNEL_EMAIL:
+		return p.EmailEnabled
+	}
+	return false
+}
+
+// quietHours는 회원의 방해 금지 시간을 반환합니다. 설정이 없거나 잘못되었으면 기본값입니다.
+func (p NotificationPreference) quietHours() notifications.QuietHours {
+	if p.QuietStart != nil && p.QuietEnd != nil {
+		if q, err := notifications.ParseQuietHours(*p.QuietStart, *p.QuietEnd); err == nil {
+			return q
+		}
+	}
+	q, _ := notifications.ParseQuietHours(consts.NOTIFICATION_QUIET_START, consts.NOTIFICATION_QUIET_END)
+	return q
+}
+
+// RegisterNotificationRoutes는 알림 템플릿, 회원 알림 수신 설정, 알림 발송 기록 관련 엔드포인트를 등록합니다.
+func RegisterNotificationRoutes(r *mux.Router) {
+	r.HandleFunc("/notification-templates", GetNotificationTemplates).Methods("GET")
+	r.HandleFunc("/companies/{company_code}/notifications", GetNotifications).Methods("GET")
+	r.HandleFunc("/companies/{company_code}/notifications", SendNotification).Methods("POST")
+	r.HandleFunc("/companies/{company_code}/members/{member_id:[0-9]+}/notification-preferences", GetNotificationPreference).Methods("GET")
+	r.HandleFunc("/companies/{company_code}/members/{member_id:[0-9]+}/notification-preferences", SaveNotificationPreference).Methods("PUT", "PATCH")
+}
+
+// GetNotificationTemplates: 알림 템플릿 목록과 채널별 발송 어댑터 등록 현황을 조회합니다.
+func GetNotificationTemplates(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"templates": notifications.Templates(),
+		"channels":  notifications.Channels(),
+		"locales":   notifications.SUPPORTED_LOCALES,
+	})
+}
+
+// GetNotificationPreference: 회원의 알림 수신 설정을 조회합니다. 저장된 설정이 없으면 기본값을 반환합니다.
+func GetNotificationPreference(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	vars := mux.Vars(r)
+	memberID, _ := strconv.ParseInt(vars["member_id"], 10, 64)
+	p, err := loadNotificationPreference(ctx, utils.DB, vars["company_code"], memberID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p)
+}
+
+// SaveNotificationPreference: 회원의 알림 수신 설정을 생성하거나 수정합니다.
+func SaveNotificationPreference(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	vars := mux.Vars(r)
+	companyCode := vars["company_code"]
+	memberID, _ := strconv.ParseInt(vars["member_id"], 10, 64)
+	var req NotificationPreferenceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "잘못된 요청 데이터", http.StatusBadRequest)
+		return
+	}
+
+	p, err := loadNotificationPreference(ctx, utils.DB, companyCode, memberID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if req.SMSEnabled != nil {
+		p.SMSEnabled = *req.SMSEnabled
+	}
+	if req.KakaoEnabled != nil {
+		p.KakaoEnabled = *req.KakaoEnabled
+	}
+	if req.EmailEnabled != nil {
+		p.EmailEnabled = *req.EmailEnabled
+	}
+	if req.QuietStart != nil {
+		p.QuietStart = nullableString(*req.QuietStart)
+	}
+	if req.QuietEnd != nil {
+		p.QuietEnd = nullableString(*req.QuietEnd)
+	}
+	if req.Locale != nil {
+		p.Locale = *req.Locale
+	}
+	if (p.QuietStart == nil) != (p.QuietEnd == nil) {
+		http.Error(w, "quiet_start와 quiet_end는 함께 지정해야 합니다", http.StatusBadRequest)
+		return
+	}
+	if p.QuietStart != nil {
+		if _, err := notifications.ParseQuietHours(*p.QuietStart, *p.QuietEnd); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if !notifications.ValidLocale(p.Locale) {
+		http.Error(w, fmt.Sprintf("locale은 %s 중 하나여야 합니다", strings.Join(notifications.SUPPORTED_LOCALES, ", ")), http.StatusBadRequest)
+		return
+	}
+
+	err = scanNotificationPreference(utils.DB.QueryRowContext(ctx, `
+		INSERT INTO notification_preference_table
+		(company_code, member_id, sms_enabled, kakao_enabled, email_enabled, quiet_start, quiet_end, locale, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		ON CONFLICT (company_code, member_id) DO UPDATE SET
+			sms_enabled = EXCLUDED.sms_enabled, kakao_enabled = EXCLUDED.kakao_enabled, email_enabled = EXCLUDED.email_enabled,
+			quiet_start = EXCLUDED.quiet_start, quiet_end = EXCLUDED.quiet_end, locale = EXCLUDED.locale,
+			updated_at = CURRENT_TIMESTAMP
+		RETURNING `+notificationPreferenceColumns,
+		companyCode, memberID, p.SMSEnabled, p.KakaoEnabled, p.EmailEnabled, p.QuietStart, p.QuietEnd, p.Locale), &p)
+	if err != nil {
+		log.Printf("알림 수신 설정 저장 오류: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p)
+}
+
+// GetNotifications: 회사의 알림 발송 기록을 최신순으로 조회합니다.
+// member_id, recipient_type, recipient_id, channel, status, template_key 쿼리 파라미터로 필터링합니다.
+func GetNotifications(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	filters := []string{"company_code = $1"}
+	args := []interface{}{mux.Vars(r)["company_code"]}
+	paramIdx := 2
+
+	if v := r.URL.Query().Get("member_id"); v != "" {
+		filters = append(filters, fmt.Sprintf("recipient_type = $%d AND recipient_id = $%d", paramIdx, paramIdx+1))
+		args = append(args, NOTIFICATION_RECIPIENT_MEMBER, v)
+		paramIdx += 2
+	}
+	filterParams := map[string]string{
+		"recipient_type": "recipient_type",
+		"recipient_id":   "recipient_id",
+		"channel":        "channel",
+		"status":         "status",
+		"template_key":   "template_key",
+	}
+	for param, dbField := range filterParams {
+		if value := r.URL.Query().Get(param); value != "" {
+			filters = append(filters, fmt.Sprintf("%s = $%d", dbField, paramIdx))
+			args = append(args, value)
+			paramIdx++
+		}
+	}
+
+	page, pageSize, err := parsePagination(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	query := "SELECT " + notificationColumns + " FROM notification_table WHERE " + strings.Join(filters, " AND ") +
+		fmt.Sprintf(" ORDER BY created_at DESC, serial_number DESC LIMIT $%d OFFSET $%d", paramIdx, paramIdx+1)
+	args = append(args, pageSize, (page-1)*pageSize)
+
+	rows, err := utils.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("데이터베이스 쿼리 오류: %v", err)
+		http.Error(w, "데이터 조회 중 오류가 발생했습니다", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	result := []Notification{}
+	for rows.Next() {
+		var n Notification
+		if err := scanNotification(rows, &n); err != nil {
+			log.Printf("행 스캔 오류: %v", err)
+			http.Error(w, "데이터 처리 중 오류가 발생했습니다", http.StatusInternalServerError)
+			return
+		}
+		result = append(result, n)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// SendNotification: 회원에게 템플릿 알림을 보냅니다. 발송은 작업 큐에서 처리되므로 202와 발송 기록을 반환합니다.
+// dedup_key가 같은 알림이 이미 있으면 409를 반환합니다.
+func SendNotification(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	companyCode := mux.Vars(r)["company_code"]
+	var req NotificationSendRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "잘못된 요청 데이터", http.StatusBadRequest)
+		return
+	}
+	if req.MemberID == 0 || req.TemplateKey == "" {
+		http.Error(w, "필수 필드가 누락되었습니다 (member_id, template_key)", http.StatusBadRequest)
+		return
+	}
+	if _, err := notifications.Lookup(req.TemplateKey); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Channel != "" && !notifications.ValidChannel(req.Channel) {
+		http.Error(w, notifications.ErrUnknownChannel.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tx, err := utils.DB.BeginTx(ctx, nil)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	recipient, err := loadMemberRecipient(ctx, tx, companyCode, req.MemberID)
+	if err == sql.ErrNoRows {
+		http.Error(w, "회원을 찾을 수 없습니다.", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	id, created, err := queueNotificationTx(ctx, tx, recipient, notificationRequest{
+		CompanyCode: companyCode,
+		TemplateKey: req.TemplateKey,
+		Data:        req.Data,
+		DedupKey:    req.DedupKey,
+		Channel:     req.Channel,
+	})
+	if errors.Is(err, errNotificationRender) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("알림 등록 오류: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !created {
+		http.Error(w, "이미 같은 dedup_key로 보낸 알림이 있습니다.", http.StatusConflict)
+		return
+	}
+	var n Notification
+	if err := scanNotification(tx.QueryRowContext(ctx,
+		"SELECT "+notificationColumns+" FROM notification_table WHERE serial_number = $1", id), &n); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(n)
+}
@@ -0,0 +1,363 @@
+// notification_delivery.go
+package tables
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+
+	"narabackend/src/notifications"
+	"narabackend/src/utils"
+)
+
+// 알림 발송 상태
+const (
+	NOTIFICATION_STATUS_QUEUED  = "queued"  // 발송 대기 (방해 금지 시간 대기, 재시도 대기 포함)
+	NOTIFICATION_STATUS_SENT    = "sent"    // 발송 완료
+	NOTIFICATION_STATUS_FAILED  = "failed"  // 최대 시도 횟수 초과 또는 대행사 거절
+	NOTIFICATION_STATUS_SKIPPED = "skipped" // 수신 가능한 채널이 없어 보내지 않음
+)
+
+// 알림 수신자 종류
+const (
+	NOTIFICATION_RECIPIENT_MEMBER  = "member"  // 회원 (user_table)
+	NOTIFICATION_RECIPIENT_MANAGER = "manager" // 관리자 (manager_table)
+)
+
+// errNoNotificationChannel은 수신 동의한 채널 중 주소와 발송 어댑터가 모두 있는 채널이 없는 경우입니다.
+var errNoNotificationChannel = errors.New("수신 가능한 알림 채널이 없습니다")
+
+// errNotificationRender는 템플릿 데이터가 부족하여 알림을 만들 수 없는 경우입니다.
+var errNotificationRender = errors.New("알림 템플릿을 렌더링할 수 없습니다")
+
+// sendNotificationPayload는 SendNotification 작업 데이터입니다.
+type sendNotificationPayload struct {
+	NotificationID int64 `json:"notification_id"`
+}
+
+// notificationQuerier는 *sql.DB와 *sql.Tx에 공통된 단건 조회 메서드입니다.
+type notificationQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// notificationRecipient는 알림을 받을 회원 또는 관리자와 수신 설정입니다.
+type notificationRecipient struct {
+	Type       string
+	ID         string
+	Name       string
+	Phone      string
+	Email      string
+	Preference NotificationPreference
+}
+
+// address는 채널의 수신 주소를 반환합니다.
+func (r notificationRecipient) address(channel string) string {
+	if channel == notifications.CHANNEL_EMAIL {
+		return r.Email
+	}
+	return r.Phone
+}
+
+// notificationRequest는 알림 한 건의 발송 요청입니다.
+// Data의 Name, CompanyName은 비어 있으면 수신자 이름과 업체명으로 채웁니다. 업체와 무관한 알림은 CompanyCode가 빈 문자열입니다.
+type notificationRequest struct {
+	CompanyCode string
+	TemplateKey string
+	Data        map[string]interface{}
+	DedupKey    string // 같은 업체에서 같은 키의 알림은 한 번만 보냅니다 (비어 있으면 검사하지 않음)
+	Channel     string // 비어 있으면 템플릿의 채널 우선순위에 따라 고릅니다
+}
+
+// loadMemberRecipient는 회원 연락처와 업체별 알림 수신 설정을 조회합니다.
+func loadMemberRecipient(ctx context.Context, q notificationQuerier, companyCode string, memberID int64) (notificationRecipient, error) {
+	recipient := notificationRecipient{Type: NOTIFICATION_RECIPIENT_MEMBER, ID: strconv.FormatInt(memberID, 10)}
+	var email, phone sql.NullString
+	err := q.QueryRowContext(ctx, "SELECT name, email, phone FROM user_table WHERE serial_number = $1", memberID).
+		Scan(&recipient.Name, &email, &phone)
+	if err != nil {
+		return recipient, err
+	}
+	recipient.Email, recipient.Phone = email.String, phone.String
+	recipient.Preference, err = loadNotificationPreference(ctx, q, companyCode, memberID)
+	return recipient, err
+}
+
+// loadManagerRecipient는 관리자 연락처를 조회합니다. 관리자는 모든 채널을 받고 기본 방해 금지 시간을 따릅니다.
+func loadManagerRecipient(ctx context.Context, q notificationQuerier, managerID string) (notificationRecipient, error) {
+	recipient := notificationRecipient{
+		Type:       NOTIFICATION_RECIPIENT_MANAGER,
+		ID:         managerID,
+		Preference: defaultNotificationPreference("", 0),
+	}
+	var phone sql.NullString
+	err := q.QueryRowContext(ctx, "SELECT name, email, phone FROM manager_table WHERE manager_id = $1", managerID).
+		Scan(&recipient.Name, &recipient.Email, &phone)
+	recipient.Phone = phone.String
+	return recipient, err
+}
+
+// chooseNotificationChannel은 수신자가 받을 수 있는 첫 채널을 고릅니다.
+// 수신 동의, 수신 주소, 등록된 발송 어댑터가 모두 있어야 합니다.
+func chooseNotificationChannel(recipient notificationRecipient, channels []string) (string, error) {
+	for _, channel := range channels {
+		if !recipient.Preference.channelEnabled(channel) || recipient.address(channel) == "" {
+			continue
+		}
+		if _, err := notifications.Get(channel); err != nil {
+			continue
+		}
+		return channel, nil
+	}
+	return "", errNoNotificationChannel
+}
+
+// queueNotificationTx는 템플릿을 렌더링해 발송 기록을 만들고 SendNotification 작업을 작업 큐에 넣습니다.
+// 방해 금지 시간이면 종료 시각에 실행되도록 예약하고(긴급 템플릿 제외), 받을 채널이 없으면 skipped로 기록만 합니다.
+// 중복 방지 키가 같은 알림이 이미 있으면 아무것도 하지 않고 created=false를 반환합니다.
+func queueNotificationTx(ctx context.Context, tx *sql.Tx, recipient notificationRecipient, req notificationRequest) (int64, bool, error) {
+	tmpl, err := notifications.Lookup(req.TemplateKey)
+	if err != nil {
+		return 0, false, err
+	}
+
+	data := map[string]interface{}{}
+	for k, v := range req.Data {
+		data[k] = v
+	}
+	if _, ok := data["Name"]; !ok {
+		data["Name"] = recipient.Name
+	}
+	if _, ok := data["CompanyName"]; !ok && req.CompanyCode != "" {
+		companyName := req.CompanyCode
+		var name sql.NullString
+		err := tx.QueryRowContext(ctx, "SELECT company_name FROM company_table WHERE company_code = $1", req.CompanyCode).Scan(&name)
+		if err != nil && err != sql.ErrNoRows {
+			return 0, false, err
+		}
+		if name.String != "" {
+			companyName = name.String
+		}
+		data["CompanyName"] = companyName
+	}
+	locale := recipient.Preference.Locale
+	rendered, err := tmpl.Render(locale, data)
+	if err != nil {
+		return 0, false, fmt.Errorf("%w: %v", errNotificationRender, err)
+	}
+
+	channels := tmpl.Channels
+	if req.Channel != "" {
+		channels = []string{req.Channel}
+	}
+	status := NOTIFICATION_STATUS_QUEUED
+	var lastError *string
+	channel, err := chooseNotificationChannel(recipient, channels)
+	if err != nil {
+		status = NOTIFICATION_STATUS_SKIPPED
+		lastError = nullableString(err.Error())
+	}
+	scheduledAt := time.Now()
+	if !tmpl.Urgent {
+		scheduledAt = recipient.Preference.quietHours().NextAllowed(scheduledAt)
+	}
+
+	var id int64
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO notification_table
+		(company_code, recipient_type, recipient_id, channel, address, template_key, locale, subject, body,
+		 dedup_key, status, last_error, scheduled_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, CURRENT_TIMESTAMP)
+		ON CONFLICT (company_code, dedup_key) WHERE dedup_key IS NOT NULL DO NOTHING
+		RETURNING serial_number`,
+		req.CompanyCode, recipient.Type, recipient.ID, channel, recipient.address(channel), req.TemplateKey, locale,
+		nullableString(rendered.Subject), rendered.Body, nullableString(req.DedupKey), status, lastError, scheduledAt).Scan(&id)
+	if err == sql.ErrNoRows {
+		log.Printf("중복 알림 건너뜀: company_code=%s, dedup_key=%s", req.CompanyCode, req.DedupKey)
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	if status != NOTIFICATION_STATUS_QUEUED {
+		return id, true, nil
+	}
+
+	job := utils.Job{
+		Name:           JOB_SEND_NOTIFICATION,
+		Payload:        sendNotificationPayload{NotificationID: id},
+		IdempotencyKey: "notification-" + strconv.FormatInt(id, 10),
+	}
+	if scheduledAt.After(time.Now()) {
+		job.RunAt = scheduledAt
+	}
+	_, err = utils.EnqueueJobTx(ctx, tx, job)
+	return id, true, err
+}
+
+// queueMemberNotificationTx는 회원에게 알림을 보냅니다. 회원 번호가 없으면 아무것도 하지 않습니다.
+// 결제 등 본 작업의 트랜잭션에서 호출하므로 템플릿 렌더링 실패는 로그만 남기고 본 작업을 막지 않습니다.
+func queueMemberNotificationTx(ctx context.Context, tx *sql.Tx, memberID *int64, req notificationRequest) error {
+	if memberID == nil {
+		return nil
+	}
+	recipient, err := loadMemberRecipient(ctx, tx, req.CompanyCode, *memberID)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	_, _, err = queueNotificationTx(ctx, tx, recipient, req)
+	if errors.Is(err, errNotificationRender) {
+		log.Printf("알림 생성 실패: company_code=%s, template=%s, 오류: %v", req.CompanyCode, req.TemplateKey, err)
+		return nil
+	}
+	return err
+}
+
+// recordNotificationAttempt는 발송 시도 결과와 상태를 발송 기록에 저장합니다.
+func recordNotificationAttempt(ctx context.Context, id int64, status, provider, providerMessageID string, sendErr error) error {
+	var lastError *string
+	if sendErr != nil {
+		lastError = nullableString(sendErr.Error())
+	}
+	_, err := utils.DB.ExecContext(ctx, `
+		UPDATE notification_table SET
+			status = $2, attempts = attempts + 1, provider = $3,
+			provider_message_id = COALESCE($4, provider_message_id), last_error = $5,
+			sent_at = CASE WHEN $2 = $6 THEN CURRENT_TIMESTAMP ELSE sent_at END
+		WHERE serial_number = $1`,
+		id, status, nullableString(provider), nullableString(providerMessageID), lastError, NOTIFICATION_STATUS_SENT)
+	return err
+}
+
+// sendNotification은 SendNotification 작업을 처리합니다. 일시적인 실패는 작업 큐가 백오프로 재시도하며,
+// 대행사 거절이나 잘못된 수신 주소처럼 다시 보내도 실패할 오류는 바로 failed로 둡니다.
+func sendNotification(ctx context.Context, job utils.Job, payload sendNotificationPayload) error {
+	var msg notifications.Message
+	var subject sql.NullString
+	var status string
+	err := utils.DB.QueryRowContext(ctx, `
+		SELECT channel, address, template_key, subject, body, status
+		FROM notification_table WHERE serial_number = $1`, payload.NotificationID).
+		Scan(&msg.Channel, &msg.To, &msg.TemplateKey, &subject, &msg.Body, &status)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if status != NOTIFICATION_STATUS_QUEUED {
+		return nil
+	}
+	msg.Subject = subject.String
+
+	sender, err := notifications.Get(msg.Channel)
+	if err != nil {
+		if recErr := recordNotificationAttempt(ctx, payload.NotificationID, NOTIFICATION_STATUS_FAILED, "", "", err); recErr != nil {
+			log.Printf("알림 발송 기록 실패 (notification=%d): %v", payload.NotificationID, recErr)
+		}
+		return utils.PermanentJobError(err)
+	}
+
+	providerMessageID, sendErr := sender.Send(ctx, msg)
+	permanent := errors.Is(sendErr, notifications.ErrRejected) || errors.Is(sendErr, notifications.ErrInvalidRecipient)
+	status = NOTIFICATION_STATUS_SENT
+	if sendErr != nil {
+		status = NOTIFICATION_STATUS_QUEUED
+		if permanent || job.Attempt >= job.MaxAttempts {
+			status = NOTIFICATION_STATUS_FAILED
+		}
+	}
+	if err := recordNotificationAttempt(ctx, payload.NotificationID, status, sender.Name(), providerMessageID, sendErr); err != nil {
+		log.Printf("알림 발송 기록 실패 (notification=%d): %v", payload.NotificationID, err)
+	}
+	if permanent {
+		return utils.PermanentJobError(sendErr)
+	}
+	return sendErr
+}
+
+// PurgeNotifications는 before 이전에 생성된 완료(sent, failed, skipped) 알림 기록을 삭제하고 삭제한 개수를 반환합니다.
+// 중복 방지 키도 함께 삭제되므로 보관 기간은 중복 방지가 필요한 기간보다 길어야 합니다.
+func PurgeNotifications(ctx context.Context, before time.Time) (int64, error) {
+	res, err := utils.DB.ExecContext(ctx, `
+		DELETE FROM notification_table WHERE status = ANY($1) AND created_at < $2`,
+		pq.StringArray{NOTIFICATION_STATUS_SENT, NOTIFICATION_STATUS_FAILED, NOTIFICATION_STATUS_SKIPPED}, before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// remindExpiringPasses는 days일 안에 만료되는 활성 이용권의 회원에게 만료 예정 알림을 보내고 처리한 이용권 수를 반환합니다.
+// 이용권마다 만료일 기준 중복 방지 키를 쓰므로 하루에 여러 번 실행되어도 한 번만 보냅니다.
+func remindExpiringPasses(ctx context.Context, now time.Time, days int) (int, error) {
+	rows, err := utils.DB.QueryContext(ctx, `
+		SELECT mp.serial_number, mp.company_code, mp.member_id, mp.valid_until, COALESCE(p.plan_name, '')
+		FROM member_pass_table mp
+		LEFT JOIN plan_table p ON p.serial_number = mp.plan_id
+		WHERE mp.status = $1 AND mp.valid_until IS NOT NULL
+		  AND mp.valid_until > $2 AND mp.valid_until <= $3`,
+		PASS_STATUS_ACTIVE, now, now.AddDate(0, 0, days))
+	if err != nil {
+		return 0, err
+	}
+	type expiringPass struct {
+		ID          int64
+		CompanyCode string
+		MemberID    int64
+		ValidUntil  time.Time
+		PlanName    string
+	}
+	var passes []expiringPass
+	for rows.Next() {
+		var p expiringPass
+		if err := rows.Scan(&p.ID, &p.CompanyCode, &p.MemberID, &p.ValidUntil, &p.PlanName); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		passes = append(passes, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	queued := 0
+	var lastErr error
+	for _, p := range passes {
+		tx, err := utils.DB.BeginTx(ctx, nil)
+		if err != nil {
+			return queued, err
+		}
+		daysLeft := int(p.ValidUntil.Sub(now).Hours()/24) + 1
+		err = queueMemberNotificationTx(ctx, tx, &p.MemberID, notificationRequest{
+			CompanyCode: p.CompanyCode,
+			TemplateKey: notifications.TEMPLATE_PASS_EXPIRY_REMINDER,
+			Data: map[string]interface{}{
+				"PlanName":  p.PlanName,
+				"ExpiresAt": p.ValidUntil.Format("2006-01-02 15:04"),
+				"DaysLeft":  daysLeft,
+			},
+			DedupKey: "pass-expiry:" + strconv.FormatInt(p.ID, 10) + ":" + p.ValidUntil.Format("20060102"),
+		})
+		if err == nil {
+			err = tx.Commit()
+		}
+		tx.Rollback()
+		if err != nil {
+			log.Printf("이용권 만료 알림 실패: pass_id=%d, 오류: %v", p.ID, err)
+			lastErr = err
+			continue
+		}
+		queued++
+	}
+	return queued, lastErr
+}
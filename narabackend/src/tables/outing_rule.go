@@ -0,0 +1,451 @@
+// outing_rule.go
+package tables
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"narabackend/src/consts"
+	"narabackend/src/notifications"
+	"narabackend/src/utils"
+)
+
+// 외출 이벤트 종류
+const (
+	OUTING_EVENT_WARNING = "warning" // 최대 외출 시간 임박 경고
+	OUTING_EVENT_OVERRUN = "overrun" // 최대 외출 시간 초과
+)
+
+// OutingRule 구조체는 outing_rule_table의 각 컬럼을 매핑합니다.
+type OutingRule struct {
+	SerialNumber         int64     `json:"serial_number" db:"serial_number"`
+	CompanyCode          string    `json:"company_code" db:"company_code"`
+	MaxOutingMinutes     int       `json:"max_outing_minutes" db:"max_outing_minutes"`
+	WarningBeforeMinutes int       `json:"warning_before_minutes" db:"warning_before_minutes"`
+	AutoRelease          bool      `json:"auto_release" db:"auto_release"`
+	PenaltyAmount        int       `json:"penalty_amount" db:"penalty_amount"`
+	PenaltyPaymentMethod string    `json:"penalty_payment_method" db:"penalty_payment_method"`
+	Enabled              bool      `json:"enabled" db:"enabled"`
+	CreatedAt            time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// OutingRuleRequest는 외출 규칙 저장 요청 시 사용되는 구조체입니다.
+// 포인터 필드는 생략 시 기존 값(없으면 기본값)을 유지합니다.
+type OutingRuleRequest struct {
+	MaxOutingMinutes     *int    `json:"max_outing_minutes"`
+	WarningBeforeMinutes *int    `json:"warning_before_minutes"`
+	AutoRelease          *bool   `json:"auto_release"`
+	PenaltyAmount        *int    `json:"penalty_amount"`
+	PenaltyPaymentMethod *string `json:"penalty_payment_method"`
+	Enabled              *bool   `json:"enabled"`
+}
+
+// OutingEvent 구조체는 outing_event_table의 각 컬럼을 매핑합니다.
+type OutingEvent struct {
+	SerialNumber   int64     `json:"serial_number" db:"serial_number"`
+	CompanyCode    string    `json:"company_code" db:"company_code"`
+	SeatCode       int       `json:"seat_code" db:"seat_code"`
+	OutingDatetime time.Time `json:"outing_datetime" db:"outing_datetime"`
+	EventType      string    `json:"event_type" db:"event_type"`
+	PaymentID      *int64    `json:"payment_id" db:"payment_id"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+}
+
+// outingRuleColumns는 outing_rule_table 조회 시 사용하는 컬럼 목록입니다.
+const outingRuleColumns = `serial_number, company_code, max_outing_minutes, warning_before_minutes,
+	auto_release, penalty_amount, penalty_payment_method, enabled, created_at, updated_at`
+
+// scanOutingRule은 한 행을 OutingRule로 읽습니다.
+func scanOutingRule(row interface{ Scan(...interface{}) error }, rule *OutingRule) error {
+	return row.Scan(&rule.SerialNumber, &rule.CompanyCode, &rule.MaxOutingMinutes, &rule.WarningBeforeMinutes,
+		&rule.AutoRelease, &rule.PenaltyAmount, &rule.PenaltyPaymentMethod, &rule.Enabled,
+		&rule.CreatedAt, &rule.UpdatedAt)
+}
+
+// validateOutingRule은 외출 규칙 값을 검증합니다.
+func validateOutingRule(rule *OutingRule) error {
+	if rule.MaxOutingMinutes <= 0 {
+		return errors.New("max_outing_minutes는 0보다 커야 합니다")
+	}
+	if rule.WarningBeforeMinutes < 0 || rule.WarningBeforeMinutes >= rule.MaxOutingMinutes {
+		return errors.New("warning_before_minutes는 0 이상, max_outing_minutes 미만이어야 합니다")
+	}
+	if rule.PenaltyAmount < 0 {
+		return errors.New("penalty_amount는 0 이상이어야 합니다")
+	}
+	if !paymentMethods[rule.PenaltyPaymentMethod] {
+		return errors.New("지원하지 않는 penalty_payment_method입니다")
+	}
+	return nil
+}
+
+// outingCandidate는 외출 규칙 평가 대상 좌석입니다.
+type outingCandidate struct {
+	CompanyCode    string
+	SeatCode       int
+	RoomCode       *int
+	SeatNumber     *int
+	OutingDatetime time.Time
+	SeatMemberID   string // 외출 기록이 있는 seat_table 행의 회원 번호
+	Rule           OutingRule
+}
+
+// evaluateOutingRules는 업체별 외출 규칙에 따라 경고를 보내고, 초과한 좌석에 위약금을 기록하며 해제 기한을 설정합니다.
+// 실제 좌석 해제는 이어서 실행되는 releaseExpiredSeats가 seat_release_datetime을 보고 처리합니다.
+func evaluateOutingRules(ctx context.Context, now time.Time) error {
+	rows, err := utils.DB.QueryContext(ctx, `
+		SELECT st.company_code, st.seat_code, st.room_code, st.seat_number, st.outing_datetime, st.member_id,
+		       r.serial_number, r.company_code, r.max_outing_minutes, r.warning_before_minutes,
+		       r.auto_release, r.penalty_amount, r.penalty_payment_method, r.enabled, r.created_at, r.updated_at
+		FROM seat_table st
+		JOIN outing_rule_table r ON r.company_code = st.company_code
+		WHERE r.enabled AND st.member_id IS NOT NULL AND st.seat_code IS NOT NULL
+		  AND st.outing_datetime IS NOT NULL
+		  AND st.outing_datetime + make_interval(mins => r.max_outing_minutes - r.warning_before_minutes) <= $1`, now)
+	if err != nil {
+		return err
+	}
+	var candidates []outingCandidate
+	for rows.Next() {
+		var c outingCandidate
+		r := &c.Rule
+		if err := rows.Scan(&c.CompanyCode, &c.SeatCode, &c.RoomCode, &c.SeatNumber, &c.OutingDatetime, &c.SeatMemberID,
+			&r.SerialNumber, &r.CompanyCode, &r.MaxOutingMinutes, &r.WarningBeforeMinutes,
+			&r.AutoRelease, &r.PenaltyAmount, &r.PenaltyPaymentMethod, &r.Enabled,
+			&r.CreatedAt, &r.UpdatedAt); err != nil {
+			rows.Close()
+			return err
+		}
+		candidates = append(candidates, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	var lastErr error
+	for _, c := range candidates {
+		deadline := c.OutingDatetime.Add(time.Duration(c.Rule.MaxOutingMinutes) * time.Minute)
+		eventType := OUTING_EVENT_WARNING
+		if !deadline.After(now) {
+			eventType = OUTING_EVENT_OVERRUN
+		} else if c.Rule.WarningBeforeMinutes == 0 {
+			continue
+		}
+
+		created, err := recordOutingEvent(ctx, c, eventType, deadline)
+		if err != nil {
+			log.Printf("외출 규칙 처리 실패: company_code=%s, seat_code=%d, 오류: %v", c.CompanyCode, c.SeatCode, err)
+			lastErr = err
+			continue
+		}
+		if created {
+			notifyOutingEvent(ctx, c, eventType)
+		}
+	}
+	return lastErr
+}
+
+// recordOutingEvent는 외출 이벤트를 한 번만 기록하고 회원 알림을 등록하며, 초과 이벤트이면 위약금과 해제 기한을 함께 반영합니다.
+// 이미 기록된 이벤트이면 created=false를 반환합니다.
+func recordOutingEvent(ctx context.Context, c outingCandidate, eventType string, deadline time.Time) (bool, error) {
+	tx, err := utils.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var eventID int64
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO outing_event_table (company_code, seat_code, outing_datetime, event_type, created_at)
+		VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP)
+		ON CONFLICT (company_code, seat_code, outing_datetime, event_type) DO NOTHING
+		RETURNING serial_number`,
+		c.CompanyCode, c.SeatCode, c.OutingDatetime, eventType).Scan(&eventID)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	// 알림과 위약금은 좌석의 열린 세션 회원에게 보내고, 세션이 없으면 외출 기록(seat_table)의 회원에게 보냅니다.
+	// 회원을 찾지 못하면 알림과 위약금은 건너뜁니다.
+	var memberID, passID *int64
+	err = tx.QueryRowContext(ctx,
+		"SELECT member_id, pass_id FROM seat_session_table WHERE company_code = $1 AND seat_code = $2 AND check_out_at IS NULL",
+		c.CompanyCode, c.SeatCode).Scan(&memberID, &passID)
+	if err != nil && err != sql.ErrNoRows {
+		return false, err
+	}
+	if memberID == nil {
+		if id, err := strconv.ParseInt(strings.TrimSpace(c.SeatMemberID), 10, 64); err == nil && id > 0 {
+			memberID = &id
+		}
+	}
+	seatNumber := c.SeatCode
+	if c.SeatNumber != nil {
+		seatNumber = *c.SeatNumber
+	}
+	err = queueMemberNotificationTx(ctx, tx, memberID, notificationRequest{
+		CompanyCode: c.CompanyCode,
+		TemplateKey: notifications.TEMPLATE_OUTING_WARNING,
+		Data: map[string]interface{}{
+			"SeatNumber":       seatNumber,
+			"MaxOutingMinutes": c.Rule.MaxOutingMinutes,
+			"Deadline":         deadline.Format("15:04"),
+			"Overrun":          eventType == OUTING_EVENT_OVERRUN,
+		},
+		DedupKey: fmt.Sprintf("outing:%d", eventID),
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if eventType == OUTING_EVENT_OVERRUN {
+		if c.Rule.PenaltyAmount > 0 && memberID == nil {
+			log.Printf("외출 위약금 생략 - 회원을 찾을 수 없습니다: company_code=%s, seat_code=%d, member_id=%q",
+				c.CompanyCode, c.SeatCode, c.SeatMemberID)
+		}
+		if c.Rule.PenaltyAmount > 0 && memberID != nil {
+			payment, _, err := insertPaymentTx(ctx, tx, Payment{
+				CompanyCode:    c.CompanyCode,
+				MemberID:       memberID,
+				PassID:         passID,
+				PaymentType:    PAYMENT_TYPE_PENALTY,
+				PaymentMethod:  c.Rule.PenaltyPaymentMethod,
+				Amount:         c.Rule.PenaltyAmount,
+				IdempotencyKey: nullableString(fmt.Sprintf("outing:%d:penalty", eventID)),
+				Description:    fmt.Sprintf("외출 시간 초과 위약금 (좌석 %d, 최대 %d분)", c.SeatCode, c.Rule.MaxOutingMinutes),
+				CreatedBy:      "scheduler",
+			})
+			if err != nil {
+				return false, err
+			}
+			_, err = tx.ExecContext(ctx, "UPDATE outing_event_table SET payment_id = $2 WHERE serial_number = $1",
+				eventID, payment.SerialNumber)
+			if err != nil {
+				return false, err
+			}
+		}
+
+		if c.Rule.AutoRelease {
+			_, err = tx.ExecContext(ctx, `
+				UPDATE seat_table SET seat_release_datetime = LEAST(COALESCE(seat_release_datetime, $4), $4)
+				WHERE company_code = $1 AND seat_code = $2 AND outing_datetime = $3`,
+				c.CompanyCode, c.SeatCode, c.OutingDatetime, deadline)
+			if err != nil {
+				return false, err
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+	log.Printf("외출 이벤트 기록: company_code=%s, seat_code=%d, event=%s", c.CompanyCode, c.SeatCode, eventType)
+	return true, nil
+}
+
+// notifyOutingEvent는 외출 경고/초과를 데스크에 알립니다. 회원 알림은 recordOutingEvent에서 이벤트와 함께 등록합니다.
+func notifyOutingEvent(ctx context.Context, c outingCandidate, eventType string) {
+	command := utils.CONTROL_COMMAND_OUTING_WARNING
+	if eventType == OUTING_EVENT_OVERRUN {
+		command = utils.CONTROL_COMMAND_OUTING_OVERRUN
+	}
+	seatNumber := strconv.Itoa(c.SeatCode)
+	if c.SeatNumber != nil {
+		seatNumber = strconv.Itoa(*c.SeatNumber)
+	}
+	_, err := utils.SendControlCommand(ctx, utils.ControlCommand{
+		Command: command,
+		Target:  utils.CONTROL_TARGET_DESK,
+		Message: utils.ControlMessage{
+			CompanyCode: c.CompanyCode,
+			RoomCode:    utils.ControlNumberString(c.RoomCode),
+			SeatNumber:  seatNumber,
+		},
+	})
+	if err != nil {
+		log.Printf("외출 알림 실패: company_code=%s, seat_code=%d, 오류: %v", c.CompanyCode, c.SeatCode, err)
+	}
+}
+
+// RegisterOutingRuleRoutes는 outing_rule_table 관련 엔드포인트를 등록합니다.
+func RegisterOutingRuleRoutes(r *mux.Router) {
+	r.HandleFunc("/companies/{company_code}/outing-rule", GetOutingRule).Methods("GET")
+	r.HandleFunc("/companies/{company_code}/outing-rule", SaveOutingRule).Methods("PUT", "PATCH")
+	r.HandleFunc("/companies/{company_code}/outing-rule", DeleteOutingRule).Methods("DELETE")
+	r.HandleFunc("/companies/{company_code}/outing-events", GetOutingEvents).Methods("GET")
+}
+
+// GetOutingRule: 업체의 외출 규칙을 조회합니다.
+func GetOutingRule(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	var rule OutingRule
+	err := scanOutingRule(utils.DB.QueryRowContext(ctx,
+		"SELECT "+outingRuleColumns+" FROM outing_rule_table WHERE company_code = $1",
+		mux.Vars(r)["company_code"]), &rule)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "외출 규칙이 없습니다.", http.StatusNotFound)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rule)
+}
+
+// SaveOutingRule: 업체의 외출 규칙을 생성하거나 수정합니다.
+// 규칙이 없을 때는 max_outing_minutes가 필수입니다.
+func SaveOutingRule(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	companyCode := mux.Vars(r)["company_code"]
+	var req OutingRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "잘못된 요청 데이터", http.StatusBadRequest)
+		return
+	}
+
+	rule := OutingRule{
+		CompanyCode:          companyCode,
+		AutoRelease:          true,
+		PenaltyPaymentMethod: "point",
+		Enabled:              true,
+	}
+	err := scanOutingRule(utils.DB.QueryRowContext(ctx,
+		"SELECT "+outingRuleColumns+" FROM outing_rule_table WHERE company_code = $1", companyCode), &rule)
+	if err != nil && err != sql.ErrNoRows {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if req.MaxOutingMinutes != nil {
+		rule.MaxOutingMinutes = *req.MaxOutingMinutes
+	}
+	if req.WarningBeforeMinutes != nil {
+		rule.WarningBeforeMinutes = *req.WarningBeforeMinutes
+	}
+	if req.AutoRelease != nil {
+		rule.AutoRelease = *req.AutoRelease
+	}
+	if req.PenaltyAmount != nil {
+		rule.PenaltyAmount = *req.PenaltyAmount
+	}
+	if req.PenaltyPaymentMethod != nil {
+		rule.PenaltyPaymentMethod = *req.PenaltyPaymentMethod
+	}
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+	if err := validateOutingRule(&rule); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	log.Printf("외출 규칙 저장 요청: %+v", rule)
+	err = scanOutingRule(utils.DB.QueryRowContext(ctx, `
+		INSERT INTO outing_rule_table
+		(company_code, max_outing_minutes, warning_before_minutes, auto_release, penalty_amount,
+		 penalty_payment_method, enabled, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		ON CONFLICT (company_code) DO UPDATE SET
+			max_outing_minutes = EXCLUDED.max_outing_minutes,
+			warning_before_minutes = EXCLUDED.warning_before_minutes,
+			auto_release = EXCLUDED.auto_release,
+			penalty_amount = EXCLUDED.penalty_amount,
+			penalty_payment_method = EXCLUDED.penalty_payment_method,
+			enabled = EXCLUDED.enabled,
+			updated_at = CURRENT_TIMESTAMP
+		RETURNING `+outingRuleColumns,
+		rule.CompanyCode, rule.MaxOutingMinutes, rule.WarningBeforeMinutes, rule.AutoRelease,
+		rule.PenaltyAmount, rule.PenaltyPaymentMethod, rule.Enabled), &rule)
+	if err != nil {
+		log.Printf("외출 규칙 저장 오류: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rule)
+}
+
+// DeleteOutingRule: 업체의 외출 규칙을 삭제합니다. 이후 외출 시간 제한이 적용되지 않습니다.
+func DeleteOutingRule(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	res, err := utils.DB.ExecContext(ctx, "DELETE FROM outing_rule_table WHERE company_code = $1", mux.Vars(r)["company_code"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		http.Error(w, "외출 규칙이 없습니다.", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetOutingEvents: 업체의 외출 경고/초과 이력을 조회합니다.
+// date 쿼리 파라미터(YYYY-MM-DD)가 없으면 오늘을 기준으로 합니다.
+func GetOutingEvents(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	date := r.URL.Query().Get("date")
+	if date == "" {
+		date = time.Now().Format("2006-01-02")
+	}
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		http.Error(w, "잘못된 date 형식입니다 (YYYY-MM-DD)", http.StatusBadRequest)
+		return
+	}
+
+	rows, err := utils.DB.QueryContext(ctx, `
+		SELECT serial_number, company_code, seat_code, outing_datetime, event_type, payment_id, created_at
+		FROM outing_event_table
+		WHERE company_code = $1 AND created_at >= $2::date AND created_at < $2::date + 1
+		ORDER BY created_at ASC, serial_number ASC`, mux.Vars(r)["company_code"], date)
+	if err != nil {
+		log.Printf("데이터베이스 쿼리 오류: %v", err)
+		http.Error(w, "데이터 조회 중 오류가 발생했습니다", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	result := []OutingEvent{}
+	for rows.Next() {
+		var e OutingEvent
+		if err := rows.Scan(&e.SerialNumber, &e.CompanyCode, &e.SeatCode, &e.OutingDatetime,
+			&e.EventType, &e.PaymentID, &e.CreatedAt); err != nil {
+			log.Printf("행 스캔 오류: %v", err)
+			http.Error(w, "데이터 처리 중 오류가 발생했습니다", http.StatusInternalServerError)
+			return
+		}
+		result = append(result, e)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,185 @@
+// password_reset.go
+package tables
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"narabackend/src/consts"
+	"narabackend/src/notifications"
+	"narabackend/src/utils"
+)
+
+// PASSWORD_RESET_TOKEN_BYTES는 비밀번호 재설정 코드의 난수 길이(바이트)입니다.
+const PASSWORD_RESET_TOKEN_BYTES = 24
+
+// passwordResetURL은 재설정 메일에 넣을 링크 주소입니다. 코드가 뒤에 붙으며, 비어 있으면 코드만 보냅니다.
+var passwordResetURL string
+
+// SetPasswordResetURL은 비밀번호 재설정 링크 주소를 설정합니다.
+func SetPasswordResetURL(baseURL string) {
+	passwordResetURL = baseURL
+}
+
+// PasswordResetRequest는 관리자 비밀번호 재설정 코드 발송 요청입니다. manager_id 또는 email 중 하나가 필요합니다.
+type PasswordResetRequest struct {
+	ManagerID string `json:"manager_id"`
+	Email     string `json:"email"`
+}
+
+// PasswordResetConfirmRequest는 재설정 코드로 새 비밀번호를 설정하는 요청입니다.
+type PasswordResetConfirmRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
+
+// RegisterPasswordResetRoutes는 관리자 비밀번호 재설정 엔드포인트를 등록합니다.
+func RegisterPasswordResetRoutes(r *mux.Router) {
+	r.HandleFunc("/managers/password-reset", RequestPasswordReset).Methods("POST")
+	r.HandleFunc("/managers/password-reset/confirm", ConfirmPasswordReset).Methods("POST")
+}
+
+// hashResetToken은 DB에 저장할 재설정 코드 해시를 계산합니다. 코드 자체는 저장하지 않습니다.
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// RequestPasswordReset: 관리자에게 비밀번호 재설정 코드를 이메일(없으면 문자)로 보냅니다.
+// 계정 존재 여부가 드러나지 않도록 관리자가 없어도 같은 202 응답을 반환합니다.
+// 같은 관리자에 대한 요청은 1분에 한 번만 처리하며, 새 코드를 보내면 이전 코드는 무효가 됩니다.
+func RequestPasswordReset(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	var req PasswordResetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "잘못된 요청 데이터", http.StatusBadRequest)
+		return
+	}
+	if req.ManagerID == "" && req.Email == "" {
+		http.Error(w, "필수 필드가 누락되었습니다 (manager_id 또는 email)", http.StatusBadRequest)
+		return
+	}
+
+	if err := requestPasswordReset(ctx, req); err != nil {
+		log.Printf("비밀번호 재설정 요청 처리 오류: %v", err)
+		http.Error(w, "비밀번호 재설정 요청을 처리할 수 없습니다", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "등록된 연락처로 비밀번호 재설정 코드를 보냈습니다",
+	})
+}
+
+// requestPasswordReset은 재설정 코드를 발급해 저장하고 알림을 등록합니다. 관리자가 없으면 아무것도 하지 않습니다.
+func requestPasswordReset(ctx context.Context, req PasswordResetRequest) error {
+	tx, err := utils.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	managerID := req.ManagerID
+	if managerID == "" {
+		err = tx.QueryRowContext(ctx, "SELECT manager_id FROM manager_table WHERE email = $1", req.Email).Scan(&managerID)
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	recipient, err := loadManagerRecipient(ctx, tx, managerID)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	token, err := randomSecret(PASSWORD_RESET_TOKEN_BYTES)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `
+		UPDATE manager_table SET
+			password_reset_token = $2, password_reset_expires = CURRENT_TIMESTAMP + make_interval(mins => $3)
+		WHERE manager_id = $1`, managerID, hashResetToken(token), consts.PASSWORD_RESET_TTL)
+	if err != nil {
+		return err
+	}
+
+	resetURL := ""
+	if passwordResetURL != "" {
+		resetURL = passwordResetURL + url.QueryEscape(token)
+	}
+	_, created, err := queueNotificationTx(ctx, tx, recipient, notificationRequest{
+		TemplateKey: notifications.TEMPLATE_PASSWORD_RESET,
+		Data: map[string]interface{}{
+			"Code":           token,
+			"ExpiresMinutes": consts.PASSWORD_RESET_TTL,
+			"ResetURL":       resetURL,
+		},
+		DedupKey: fmt.Sprintf("password-reset:%s:%d", managerID, time.Now().Unix()/60),
+	})
+	if err != nil {
+		return err
+	}
+	if !created {
+		// 1분 안에 다시 요청하면 이미 보낸 코드를 그대로 유지합니다.
+		return nil
+	}
+	log.Printf("비밀번호 재설정 코드 발급: manager_id=%s", managerID)
+	return tx.Commit()
+}
+
+// ConfirmPasswordReset: 재설정 코드를 확인하고 새 비밀번호를 설정합니다. 코드는 한 번만 사용할 수 있습니다.
+// 비밀번호 저장 방식은 UpdateManagerPassword와 같습니다.
+func ConfirmPasswordReset(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	var req PasswordResetConfirmRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "잘못된 요청 데이터", http.StatusBadRequest)
+		return
+	}
+	if req.Token == "" || req.NewPassword == "" {
+		http.Error(w, "필수 필드가 누락되었습니다 (token, new_password)", http.StatusBadRequest)
+		return
+	}
+
+	var managerID string
+	err := utils.DB.QueryRowContext(ctx, `
+		UPDATE manager_table SET
+			password = $2, password_reset_token = NULL, password_reset_expires = NULL,
+			last_password_change = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE password_reset_token = $1 AND password_reset_expires > CURRENT_TIMESTAMP
+		RETURNING manager_id`, hashResetToken(req.Token), req.NewPassword).Scan(&managerID)
+	if err == sql.ErrNoRows {
+		http.Error(w, "재설정 코드가 올바르지 않거나 만료되었습니다", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("비밀번호 재설정 오류: %v", err)
+		http.Error(w, "비밀번호 업데이트 실패", http.StatusInternalServerError)
+		return
+	}
+	log.Printf("비밀번호 재설정 완료: manager_id=%s", managerID)
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,511 @@
+// payment.go
+package tables
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"narabackend/src/consts"
+	"narabackend/src/notifications"
+	"narabackend/src/utils"
+)
+
+// 결제 구분 (naradesk PaymentType과 대응)
+const (
+	PAYMENT_TYPE_TIME_CHARGE = "time_charge" // 시간충전
+	PAYMENT_TYPE_MEMBERSHIP  = "membership"  // 멤버십
+	PAYMENT_TYPE_DEPOSIT     = "deposit"     // 보증금
+	PAYMENT_TYPE_PENALTY     = "penalty"     // 위약금
+	PAYMENT_TYPE_REFUND      = "refund"      // 환불
+)
+
+// 결제 상태 (원장에 저장하지 않고 환불 내역으로 계산합니다)
+const (
+	PAYMENT_STATUS_COMPLETED          = "completed"
+	PAYMENT_STATUS_PARTIALLY_REFUNDED = "partially_refunded"
+	PAYMENT_STATUS_REFUNDED           = "refunded"
+)
+
+// paymentTypes는 결제 생성 시 허용되는 구분입니다. refund는 환불 엔드포인트로만 생성합니다.
+var paymentTypes = map[string]bool{
+	PAYMENT_TYPE_TIME_CHARGE: true,
+	PAYMENT_TYPE_MEMBERSHIP:  true,
+	PAYMENT_TYPE_DEPOSIT:     true,
+	PAYMENT_TYPE_PENALTY:     true,
+}
+
+// paymentMethods는 허용되는 결제 수단입니다.
+var paymentMethods = map[string]bool{
+	"card":     true,
+	"cash":     true,
+	"transfer": true,
+	"point":    true,
+}
+
+// Payment 구조체는 payment_table의 각 컬럼과 환불 집계 값을 매핑합니다.
+type Payment struct {
+	SerialNumber      int64     `json:"serial_number" db:"serial_number"`
+	CompanyCode       string    `json:"company_code" db:"company_code"`
+	MemberID          *int64    `json:"member_id" db:"member_id"`
+	PassID            *int64    `json:"pass_id" db:"pass_id"`
+	PaymentType       string    `json:"payment_type" db:"payment_type"`
+	PaymentMethod     string    `json:"payment_method" db:"payment_method"`
+	Amount            int       `json:"amount" db:"amount"`
+	OriginalPaymentID *int64    `json:"original_payment_id" db:"original_payment_id"`
+	IdempotencyKey    *string   `json:"idempotency_key" db:"idempotency_key"`
+	Description       string    `json:"description" db:"description"`
+	CreatedBy         string    `json:"created_by" db:"created_by"`
+	CreatedAt         time.Time `json:"created_at" db:"created_at"`
+	RefundedAmount    int       `json:"refunded_amount"`
+	Status            string    `json:"status"`
+}
+
+// PaymentRequest는 결제 생성 요청 시 사용되는 구조체입니다.
+// idempotency_key는 본문 또는 Idempotency-Key 헤더로 전달할 수 있습니다.
+type PaymentRequest struct {
+	CompanyCode    string `json:"company_code"`
+	MemberID       *int64 `json:"member_id"`
+	PassID         *int64 `json:"pass_id"`
+	PaymentType    string `json:"payment_type"`
+	PaymentMethod  string `json:"payment_method"`
+	Amount         int    `json:"amount"`
+	IdempotencyKey string `json:"idempotency_key"`
+	Description    string `json:"description"`
+	CreatedBy      string `json:"created_by"`
+}
+
+// RefundRequest는 환불 요청 시 사용되는 구조체입니다.
+// amount가 0이면 남은 금액 전체를 환불합니다.
+type RefundRequest struct {
+	Amount         int    `json:"amount"`
+	PaymentMethod  string `json:"payment_method"`
+	IdempotencyKey string `json:"idempotency_key"`
+	Description    string `json:"description"`
+	CreatedBy      string `json:"created_by"`
+}
+
+// paymentColumns는 payment_table 조회 시 사용하는 컬럼 목록입니다.
+// refunded_amount는 원 결제를 참조하는 환불 행의 합계입니다.
+const paymentColumns = `p.serial_number, p.company_code, p.member_id, p.pass_id, p.payment_type,
+	p.payment_method, p.amount, p.original_payment_id, p.idempotency_key,
+	COALESCE(p.description, ''), COALESCE(p.created_by, ''), p.created_at,
+	COALESCE((SELECT SUM(r.amount) FROM payment_table r
+	          WHERE r.original_payment_id = p.serial_number AND r.payment_type = 'refund'), 0)`
+
+// scanPayment는 paymentColumns 순서로 조회된 행을 Payment 구조체로 변환합니다.
+func scanPayment(row interface{ Scan(...interface{}) error }, payment *Payment) error {
+	err := row.Scan(&payment.SerialNumber, &payment.CompanyCode, &payment.MemberID, &payment.PassID,
+		&payment.PaymentType, &payment.PaymentMethod, &payment.Amount, &payment.OriginalPaymentID,
+		&payment.IdempotencyKey, &payment.Description, &payment.CreatedBy, &payment.CreatedAt,
+		&payment.RefundedAmount)
+	if err != nil {
+		return err
+	}
+	switch {
+	case payment.PaymentType == PAYMENT_TYPE_REFUND || payment.RefundedAmount == 0:
+		payment.Status = PAYMENT_STATUS_COMPLETED
+	case payment.RefundedAmount >= payment.Amount:
+		payment.Status = PAYMENT_STATUS_REFUNDED
+	default:
+		payment.Status = PAYMENT_STATUS_PARTIALLY_REFUNDED
+	}
+	return nil
+}
+
+// errIdempotencyConflict는 같은 멱등 키로 다른 내용의 결제가 요청된 경우 반환됩니다.
+var errIdempotencyConflict = errors.New("같은 idempotency_key로 다른 결제가 이미 등록되었습니다")
+
+// errRefundExceeded는 환불 가능 금액을 초과한 경우 반환됩니다.
+var errRefundExceeded = errors.New("환불 가능 금액을 초과했습니다")
+
+// errRefundOfRefund는 환불 행을 다시 환불하려 할 때 반환됩니다.
+var errRefundOfRefund = errors.New("환불 행은 다시 환불할 수 없습니다")
+
+// nullableString은 빈 문자열을 NULL로 저장하기 위해 사용합니다.
+func nullableString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// insertPaymentTx는 트랜잭션 안에서 원장에 결제 행을 추가합니다.
+// 같은 업체에 같은 멱등 키가 이미 있으면 새 행을 만들지 않고 기존 행을 반환하며 created=false가 됩니다.
+// 새 행을 만들면 결제(payment.created) 또는 환불(payment.refunded) 웹훅을 같은 트랜잭션으로 등록합니다.
+func insertPaymentTx(ctx context.Context, tx *sql.Tx, p Payment) (Payment, bool, error) {
+	var saved Payment
+	if p.IdempotencyKey != nil {
+		err := scanPayment(tx.QueryRowContext(ctx,
+			"SELECT "+paymentColumns+" FROM payment_table p WHERE p.company_code = $1 AND p.idempotency_key = $2",
+			p.CompanyCode, *p.IdempotencyKey), &saved)
+		if err == nil {
+			if saved.Amount != p.Amount || saved.PaymentType != p.PaymentType {
+				return saved, false, errIdempotencyConflict
+			}
+			return saved, false, nil
+		}
+		if err != sql.ErrNoRows {
+			return saved, false, err
+		}
+	}
+
+	var id int64
+	err := tx.QueryRowContext(ctx, `
+		INSERT INTO payment_table
+		(company_code, member_id, pass_id, payment_type, payment_method, amount,
+		 original_payment_id, idempotency_key, description, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, CURRENT_TIMESTAMP)
+		RETURNING serial_number`,
+		p.CompanyCode, p.MemberID, p.PassID, p.PaymentType, p.PaymentMethod, p.Amount,
+		p.OriginalPaymentID, p.IdempotencyKey, p.Description, p.CreatedBy).Scan(&id)
+	if err != nil {
+		return saved, false, err
+	}
+	err = scanPayment(tx.QueryRowContext(ctx,
+		"SELECT "+paymentColumns+" FROM payment_table p WHERE p.serial_number = $1", id), &saved)
+	if err != nil {
+		return saved, true, err
+	}
+	webhookEvent := WEBHOOK_EVENT_PAYMENT_CREATED
+	if saved.PaymentType == PAYMENT_TYPE_REFUND {
+		webhookEvent = WEBHOOK_EVENT_PAYMENT_REFUNDED
+	}
+	if err := queueWebhookEventTx(ctx, tx, saved.CompanyCode, webhookEvent, saved); err != nil {
+		return saved, true, err
+	}
+	return saved, true, queuePaymentReceiptTx(ctx, tx, saved)
+}
+
+// queuePaymentReceiptTx는 회원에게 결제/환불 영수증 알림을 보냅니다. 결제 한 건에 한 번만 보냅니다.
+func queuePaymentReceiptTx(ctx context.Context, tx *sql.Tx, p Payment) error {
+	amount := p.Amount
+	if amount < 0 {
+		amount = -amount
+	}
+	return queueMemberNotificationTx(ctx, tx, p.MemberID, notificationRequest{
+		CompanyCode: p.CompanyCode,
+		TemplateKey: notifications.TEMPLATE_PAYMENT_RECEIPT,
+		Data: map[string]interface{}{
+			"PaymentID":     p.SerialNumber,
+			"Amount":        amount,
+			"PaymentMethod": p.PaymentMethod,
+			"Description":   p.Description,
+			"PaidAt":        p.CreatedAt.Format("2006-01-02 15:04"),
+			"IsRefund":      p.PaymentType == PAYMENT_TYPE_REFUND,
+		},
+		DedupKey: "payment-receipt:" + strconv.FormatInt(p.SerialNumber, 10),
+	})
+}
+
+// refundPaymentTx는 트랜잭션 안에서 원 결제를 잠그고 환불 행을 추가합니다.
+// 원 결제 행을 FOR UPDATE로 잠가 동시에 들어온 환불 요청이 합계를 초과하지 않도록 합니다.
+func refundPaymentTx(ctx context.Context, tx *sql.Tx, originalID int64, req RefundRequest) (Payment, bool, error) {
+	var original Payment
+	var lockedID int64
+	err := tx.QueryRowContext(ctx,
+		"SELECT serial_number FROM payment_table WHERE serial_number = $1 FOR UPDATE", originalID).Scan(&lockedID)
+	if err != nil {
+		return original, false, err
+	}
+	err = scanPayment(tx.QueryRowContext(ctx,
+		"SELECT "+paymentColumns+" FROM payment_table p WHERE p.serial_number = $1", originalID), &original)
+	if err != nil {
+		return original, false, err
+	}
+	if original.PaymentType == PAYMENT_TYPE_REFUND {
+		return original, false, errRefundOfRefund
+	}
+
+	amount := req.Amount
+	refundable := original.Amount - original.RefundedAmount
+	if amount == 0 {
+		amount = refundable
+	}
+
+	// 재시도된 요청은 잔액 검증보다 먼저 멱등 키로 기존 환불을 돌려줍니다.
+	refund := Payment{
+		CompanyCode:       original.CompanyCode,
+		MemberID:          original.MemberID,
+		PassID:            original.PassID,
+		PaymentType:       PAYMENT_TYPE_REFUND,
+		PaymentMethod:     original.PaymentMethod,
+		Amount:            amount,
+		OriginalPaymentID: &original.SerialNumber,
+		IdempotencyKey:    nullableString(req.IdempotencyKey),
+		Description:       req.Description,
+		CreatedBy:         req.CreatedBy,
+	}
+	if req.PaymentMethod != "" {
+		refund.PaymentMethod = req.PaymentMethod
+	}
+	if refund.IdempotencyKey != nil {
+		var existing Payment
+		err := scanPayment(tx.QueryRowContext(ctx,
+			"SELECT "+paymentColumns+" FROM payment_table p WHERE p.company_code = $1 AND p.idempotency_key = $2",
+			refund.CompanyCode, *refund.IdempotencyKey), &existing)
+		if err == nil {
+			if existing.OriginalPaymentID == nil || *existing.OriginalPaymentID != originalID {
+				return existing, false, errIdempotencyConflict
+			}
+			return existing, false, nil
+		}
+		if err != sql.ErrNoRows {
+			return existing, false, err
+		}
+	}
+
+	if amount <= 0 || amount > refundable {
+		return original, false, fmt.Errorf("%w (환불 가능: %d원)", errRefundExceeded, refundable)
+	}
+	return insertPaymentTx(ctx, tx, refund)
+}
+
+// RegisterPaymentRoutes는 payment_table 관련 엔드포인트를 등록합니다.
+// 원장은 추가 전용이므로 수정/삭제 엔드포인트는 제공하지 않습니다.
+func RegisterPaymentRoutes(r *mux.Router) {
+	r.HandleFunc("/payments", GetPayments).Methods("GET")
+	r.HandleFunc("/payments/{id:[0-9]+}", GetPayment).Methods("GET")
+	r.HandleFunc("/payments", CreatePayment).Methods("POST")
+	r.HandleFunc("/payments/{id:[0-9]+}/refunds", RefundPayment).Methods("POST")
+}
+
+// GetPayments: 결제 원장을 조회합니다.
+// company_code, member_id, pass_id, payment_type, payment_method 필터와 from/to(YYYY-MM-DD) 기간 조건을 지원합니다.
+func GetPayments(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	filters := []string{}
+	args := []interface{}{}
+	paramIdx := 1
+
+	filterParams := map[string]string{
+		"company_code":   "p.company_code",
+		"member_id":      "p.member_id",
+		"pass_id":        "p.pass_id",
+		"payment_type":   "p.payment_type",
+		"payment_method": "p.payment_method",
+	}
+	for param, dbField := range filterParams {
+		if value := r.URL.Query().Get(param); value != "" {
+			filters = append(filters, fmt.Sprintf("%s = $%d", dbField, paramIdx))
+			args = append(args, value)
+			paramIdx++
+		}
+	}
+	if from := r.URL.Query().Get("from"); from != "" {
+		filters = append(filters, fmt.Sprintf("p.created_at >= $%d::date", paramIdx))
+		args = append(args, from)
+		paramIdx++
+	}
+	if to := r.URL.Query().Get("to"); to != "" {
+		filters = append(filters, fmt.Sprintf("p.created_at < $%d::date + 1", paramIdx))
+		args = append(args, to)
+		paramIdx++
+	}
+
+	query := "SELECT " + paymentColumns + " FROM payment_table p"
+	if len(filters) > 0 {
+		query += " WHERE " + strings.Join(filters, " AND ")
+	}
+	query += " ORDER BY p.serial_number DESC"
+
+	rows, err := utils.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("데이터베이스 쿼리 오류: %v", err)
+		http.Error(w, "데이터 조회 중 오류가 발생했습니다", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	result := []Payment{}
+	for rows.Next() {
+		var payment Payment
+		if err := scanPayment(rows, &payment); err != nil {
+			log.Printf("행 스캔 오류: %v", err)
+			http.Error(w, "데이터 처리 중 오류가 발생했습니다", http.StatusInternalServerError)
+			return
+		}
+		result = append(result, payment)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// GetPayment: 단일 결제를 조회합니다.
+func GetPayment(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "잘못된 id", http.StatusBadRequest)
+		return
+	}
+
+	var payment Payment
+	err = scanPayment(utils.DB.QueryRowContext(ctx,
+		"SELECT "+paymentColumns+" FROM payment_table p WHERE p.serial_number = $1", id), &payment)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "결제를 찾을 수 없습니다.", http.StatusNotFound)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(payment)
+}
+
+// CreatePayment: 결제를 원장에 기록합니다.
+// 같은 멱등 키로 재요청하면 새 행을 만들지 않고 기존 결제를 200으로 반환합니다.
+func CreatePayment(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	var req PaymentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "잘못된 요청 데이터", http.StatusBadRequest)
+		return
+	}
+	if key := r.Header.Get("Idempotency-Key"); key != "" {
+		req.IdempotencyKey = key
+	}
+
+	// 필수 필드 검증
+	if req.CompanyCode == "" || req.PaymentType == "" || req.PaymentMethod == "" || req.Amount <= 0 {
+		http.Error(w, "필수 필드가 누락되었습니다 (company_code, payment_type, payment_method, amount)", http.StatusBadRequest)
+		return
+	}
+	if !paymentTypes[req.PaymentType] {
+		http.Error(w, "지원하지 않는 payment_type입니다", http.StatusBadRequest)
+		return
+	}
+	if !paymentMethods[req.PaymentMethod] {
+		http.Error(w, "지원하지 않는 payment_method입니다", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := utils.DB.BeginTx(ctx, nil)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	log.Printf("결제 기록 요청 시작: %+v", req)
+	payment, created, err := insertPaymentTx(ctx, tx, Payment{
+		CompanyCode:    req.CompanyCode,
+		MemberID:       req.MemberID,
+		PassID:         req.PassID,
+		PaymentType:    req.PaymentType,
+		PaymentMethod:  req.PaymentMethod,
+		Amount:         req.Amount,
+		IdempotencyKey: nullableString(req.IdempotencyKey),
+		Description:    req.Description,
+		CreatedBy:      req.CreatedBy,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, errIdempotencyConflict):
+			http.Error(w, err.Error(), http.StatusConflict)
+		case strings.Contains(err.Error(), "duplicate key"):
+			// 동시에 같은 키로 들어온 요청이 먼저 커밋된 경우
+			http.Error(w, "같은 idempotency_key의 결제가 처리 중입니다. 다시 조회해 주세요", http.StatusConflict)
+		case strings.Contains(err.Error(), "foreign key"):
+			http.Error(w, "존재하지 않는 회원 또는 이용권입니다", http.StatusBadRequest)
+		default:
+			log.Printf("결제 기록 오류: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if created {
+		w.WriteHeader(http.StatusCreated)
+	}
+	json.NewEncoder(w).Encode(payment)
+}
+
+// RefundPayment: 원 결제를 참조하는 환불(부분 환불 포함) 행을 추가합니다.
+func RefundPayment(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "잘못된 id", http.StatusBadRequest)
+		return
+	}
+
+	var req RefundRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "잘못된 요청 데이터", http.StatusBadRequest)
+		return
+	}
+	if key := r.Header.Get("Idempotency-Key"); key != "" {
+		req.IdempotencyKey = key
+	}
+	if req.Amount < 0 {
+		http.Error(w, "amount는 0 이상이어야 합니다", http.StatusBadRequest)
+		return
+	}
+	if req.PaymentMethod != "" && !paymentMethods[req.PaymentMethod] {
+		http.Error(w, "지원하지 않는 payment_method입니다", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := utils.DB.BeginTx(ctx, nil)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	refund, created, err := refundPaymentTx(ctx, tx, id, req)
+	if err != nil {
+		switch {
+		case err == sql.ErrNoRows:
+			http.Error(w, "결제를 찾을 수 없습니다.", http.StatusNotFound)
+		case errors.Is(err, errIdempotencyConflict), errors.Is(err, errRefundExceeded):
+			http.Error(w, err.Error(), http.StatusConflict)
+		case errors.Is(err, errRefundOfRefund):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			log.Printf("환불 처리 오류: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if created {
+		w.WriteHeader(http.StatusCreated)
+	}
+	json.NewEncoder(w).Encode(refund)
+}
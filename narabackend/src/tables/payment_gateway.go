@@ -0,0 +1,562 @@
+// payment_gateway.go
+package tables
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"narabackend/src/consts"
+	"narabackend/src/gateway"
+	"narabackend/src/utils"
+)
+
+// MAX_WEBHOOK_BODY_SIZE는 PG 웹훅 본문의 최대 크기입니다.
+const MAX_WEBHOOK_BODY_SIZE = 64 << 10
+
+// PGTransaction 구조체는 payment_gateway_transaction_table의 각 컬럼을 매핑합니다.
+type PGTransaction struct {
+	SerialNumber   int64     `json:"serial_number" db:"serial_number"`
+	CompanyCode    string    `json:"company_code" db:"company_code"`
+	Gateway        string    `json:"gateway" db:"gateway"`
+	TransactionID  string    `json:"transaction_id" db:"transaction_id"`
+	MemberID       *int64    `json:"member_id" db:"member_id"`
+	PassID         *int64    `json:"pass_id" db:"pass_id"`
+	PaymentType    string    `json:"payment_type" db:"payment_type"`
+	Amount         int       `json:"amount" db:"amount"`
+	RefundedAmount int       `json:"refunded_amount" db:"refunded_amount"`
+	Status         string    `json:"status" db:"status"`
+	PaymentID      *int64    `json:"payment_id" db:"payment_id"`
+	OrderID        string    `json:"order_id" db:"order_id"`
+	Message        string    `json:"message" db:"message"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// PGAuthorizeRequest는 카드 승인 요청 시 사용되는 구조체입니다.
+// capture가 true이면 승인 직후 매입까지 진행합니다.
+// order_id는 본문 또는 Idempotency-Key 헤더로 전달할 수 있습니다.
+type PGAuthorizeRequest struct {
+	CompanyCode string `json:"company_code"`
+	MemberID    *int64 `json:"member_id"`
+	PassID      *int64 `json:"pass_id"`
+	PaymentType string `json:"payment_type"`
+	Amount      int    `json:"amount"`
+	OrderID     string `json:"order_id"`
+	CardToken   string `json:"card_token"`
+	Description string `json:"description"`
+	Capture     bool   `json:"capture"`
+}
+
+// PGRefundRequest는 PG 환불 요청 시 사용되는 구조체입니다.
+// amount가 0이면 남은 금액 전체를 환불합니다.
+type PGRefundRequest struct {
+	Amount    int    `json:"amount"`
+	CreatedBy string `json:"created_by"`
+}
+
+// pgTransitions는 허용되는 거래 상태 전이입니다.
+// failed, cancelled, refunded는 종료 상태이므로 늦게 도착하거나 재전송된 웹훅이 종료된 거래를 바꾸지 못합니다.
+var pgTransitions = map[string]map[string]bool{
+	gateway.STATUS_AUTHORIZED: {
+		gateway.STATUS_CAPTURED:  true,
+		gateway.STATUS_CANCELLED: true,
+	},
+	gateway.STATUS_CAPTURED: {
+		gateway.STATUS_PARTIALLY_REFUNDED: true,
+		gateway.STATUS_REFUNDED:           true,
+	},
+	gateway.STATUS_PARTIALLY_REFUNDED: {
+		gateway.STATUS_REFUNDED: true,
+	},
+}
+
+// pgTransitionAllowed는 from 상태에서 to 상태로 바꿀 수 있는지 반환합니다. 같은 상태는 항상 허용합니다.
+func pgTransitionAllowed(from, to string) bool {
+	return from == to || pgTransitions[from][to]
+}
+
+// pgRefundable은 환불 건을 원장에 기록할 수 있는 상태인지 반환합니다.
+func pgRefundable(status string) bool {
+	return status == gateway.STATUS_PARTIALLY_REFUNDED || status == gateway.STATUS_REFUNDED
+}
+
+// pgTransactionColumns는 payment_gateway_transaction_table 조회 시 사용하는 컬럼 목록입니다.
+const pgTransactionColumns = `serial_number, company_code, gateway, transaction_id, member_id, pass_id,
+	payment_type, amount, refunded_amount, status, payment_id, order_id, COALESCE(message, ''),
+	created_at, updated_at`
+
+// scanPGTransaction은 한 행을 PGTransaction으로 읽습니다.
+func scanPGTransaction(row interface{ Scan(...interface{}) error }, t *PGTransaction) error {
+	return row.Scan(&t.SerialNumber, &t.CompanyCode, &t.Gateway, &t.TransactionID, &t.MemberID, &t.PassID,
+		&t.PaymentType, &t.Amount, &t.RefundedAmount, &t.Status, &t.PaymentID, &t.OrderID, &t.Message,
+		&t.CreatedAt, &t.UpdatedAt)
+}
+
+// pgUpdate는 PG 응답 또는 웹훅에서 얻은 거래 변경 내용입니다.
+// RefundID가 있으면 해당 환불 건을 원장에 한 번만 기록합니다.
+// 매입 금액은 요청으로 받지 않고 항상 저장된 거래 금액을 사용합니다.
+type pgUpdate struct {
+	Status       string
+	RefundID     string
+	RefundAmount int
+	Message      string
+	CreatedBy    string
+}
+
+// applyPGUpdateTx는 PG 거래 상태를 반영하고 매입/환불을 원장에 멱등하게 기록합니다.
+// 원장 행의 멱등 키는 pg:<gateway>:<transaction_id>:capture, pg:<gateway>:<transaction_id>:refund:<refund_id>이므로
+// 같은 결과를 API 응답과 웹훅으로 중복 수신해도 원장에는 한 번만 기록됩니다.
+// 허용되지 않는 상태 전이(pgTransitions)는 무시하고 기록만 남깁니다.
+func applyPGUpdateTx(ctx context.Context, tx *sql.Tx, id int64, update pgUpdate) (PGTransaction, error) {
+	var t PGTransaction
+	err := scanPGTransaction(tx.QueryRowContext(ctx,
+		"SELECT "+pgTransactionColumns+" FROM payment_gateway_transaction_table WHERE serial_number = $1 FOR UPDATE", id), &t)
+	if err != nil {
+		return t, err
+	}
+
+	status := t.Status
+	if update.Status != "" {
+		if pgTransitionAllowed(t.Status, update.Status) {
+			status = update.Status
+		} else {
+			log.Printf("PG 거래 상태 전이 무시: gateway=%s, transaction_id=%s, %s -> %s",
+				t.Gateway, t.TransactionID, t.Status, update.Status)
+		}
+	}
+
+	keyPrefix := fmt.Sprintf("pg:%s:%s", t.Gateway, t.TransactionID)
+	captured := status == gateway.STATUS_CAPTURED || status == gateway.STATUS_PARTIALLY_REFUNDED ||
+		status == gateway.STATUS_REFUNDED
+	if captured && t.PaymentID == nil {
+		payment, _, err := insertPaymentTx(ctx, tx, Payment{
+			CompanyCode:    t.CompanyCode,
+			MemberID:       t.MemberID,
+			PassID:         t.PassID,
+			PaymentType:    t.PaymentType,
+			PaymentMethod:  "card",
+			Amount:         t.Amount,
+			IdempotencyKey: nullableString(keyPrefix + ":capture"),
+			Description:    fmt.Sprintf("%s 카드 결제 %s", t.Gateway, t.TransactionID),
+			CreatedBy:      update.CreatedBy,
+		})
+		if err != nil {
+			return t, err
+		}
+		t.PaymentID = &payment.SerialNumber
+	}
+
+	if update.RefundID != "" && update.RefundAmount > 0 {
+		if t.PaymentID == nil || !pgRefundable(status) {
+			return t, fmt.Errorf("%w: 환불할 수 없는 거래입니다 (%s, %s)", gateway.ErrInvalidState, t.TransactionID, status)
+		}
+		_, _, err := refundPaymentTx(ctx, tx, *t.PaymentID, RefundRequest{
+			Amount:         update.RefundAmount,
+			IdempotencyKey: keyPrefix + ":refund:" + update.RefundID,
+			Description:    fmt.Sprintf("%s 카드 환불 %s", t.Gateway, update.RefundID),
+			CreatedBy:      update.CreatedBy,
+		})
+		if err != nil {
+			return t, err
+		}
+	}
+
+	message := t.Message
+	if update.Message != "" {
+		message = update.Message
+	}
+	err = scanPGTransaction(tx.QueryRowContext(ctx, `
+		UPDATE payment_gateway_transaction_table
+		SET status = $2, payment_id = $3, message = $4, updated_at = CURRENT_TIMESTAMP,
+		    refunded_amount = COALESCE((SELECT SUM(amount) FROM payment_table
+		                                WHERE original_payment_id = $3 AND payment_type = 'refund'), 0)
+		WHERE serial_number = $1
+		RETURNING `+pgTransactionColumns, t.SerialNumber, status, t.PaymentID, message), &t)
+	return t, err
+}
+
+// applyPGResult는 PG API 호출 결과를 새 트랜잭션에서 반영합니다.
+func applyPGResult(ctx context.Context, id int64, result gateway.Result, refundAmount int, createdBy string) (PGTransaction, error) {
+	tx, err := utils.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return PGTransaction{}, err
+	}
+	defer tx.Rollback()
+
+	t, err := applyPGUpdateTx(ctx, tx, id, pgUpdate{
+		Status:       result.Status,
+		RefundID:     result.RefundID,
+		RefundAmount: refundAmount,
+		Message:      result.Message,
+		CreatedBy:    createdBy,
+	})
+	if err != nil {
+		return t, err
+	}
+	return t, tx.Commit()
+}
+
+// loadPGTransaction은 id로 PG 거래와 연결된 어댑터를 조회합니다.
+func loadPGTransaction(ctx context.Context, id int64) (PGTransaction, gateway.PaymentGateway, error) {
+	var t PGTransaction
+	err := scanPGTransaction(utils.DB.QueryRowContext(ctx,
+		"SELECT "+pgTransactionColumns+" FROM payment_gateway_transaction_table WHERE serial_number = $1", id), &t)
+	if err != nil {
+		return t, nil, err
+	}
+	gw, err := gateway.Get(t.Gateway)
+	return t, gw, err
+}
+
+// writePGError는 PG/DB 오류를 HTTP 상태 코드로 변환합니다.
+func writePGError(w http.ResponseWriter, err error) {
+	switch {
+	case err == sql.ErrNoRows, errors.Is(err, gateway.ErrTransactionNotFound):
+		http.Error(w, "거래를 찾을 수 없습니다.", http.StatusNotFound)
+	case errors.Is(err, gateway.ErrUnknownGateway):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, gateway.ErrDeclined):
+		http.Error(w, err.Error(), http.StatusPaymentRequired)
+	case errors.Is(err, gateway.ErrInvalidState), errors.Is(err, errRefundExceeded), errors.Is(err, errIdempotencyConflict):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, gateway.ErrInvalidSignature):
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+	default:
+		log.Printf("PG 처리 오류: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// RegisterPaymentGatewayRoutes는 PG 결제 관련 엔드포인트를 등록합니다.
+func RegisterPaymentGatewayRoutes(r *mux.Router) {
+	r.HandleFunc("/payments/gateway", GetPaymentGateways).Methods("GET")
+	r.HandleFunc("/payments/gateway/{gateway}/authorize", AuthorizePGTransaction).Methods("POST")
+	r.HandleFunc("/payments/gateway/{gateway}/webhook", HandlePGWebhook).Methods("POST")
+	r.HandleFunc("/payments/gateway/transactions/{id:[0-9]+}", GetPGTransaction).Methods("GET")
+	r.HandleFunc("/payments/gateway/transactions/{id:[0-9]+}/capture", CapturePGTransaction).Methods("POST")
+	r.HandleFunc("/payments/gateway/transactions/{id:[0-9]+}/cancel", CancelPGTransaction).Methods("POST")
+	r.HandleFunc("/payments/gateway/transactions/{id:[0-9]+}/refund", RefundPGTransaction).Methods("POST")
+}
+
+// GetPaymentGateways: 등록된 PG 이름 목록을 반환합니다.
+func GetPaymentGateways(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(gateway.Names())
+}
+
+// AuthorizePGTransaction: PG에 카드 승인을 요청하고 거래를 기록합니다.
+// 같은 order_id로 재요청하면 PG와 DB 모두 기존 거래를 반환합니다.
+// 승인이 거절되면 failed 상태로 기록하고 402를 반환합니다.
+func AuthorizePGTransaction(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.LONG_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	gw, err := gateway.Get(mux.Vars(r)["gateway"])
+	if err != nil {
+		writePGError(w, err)
+		return
+	}
+
+	var req PGAuthorizeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "잘못된 요청 데이터", http.StatusBadRequest)
+		return
+	}
+	if key := r.Header.Get("Idempotency-Key"); key != "" {
+		req.OrderID = key
+	}
+	if req.CompanyCode == "" || req.PaymentType == "" || req.Amount <= 0 || req.OrderID == "" {
+		http.Error(w, "필수 필드가 누락되었습니다 (company_code, payment_type, amount, order_id)", http.StatusBadRequest)
+		return
+	}
+	if !paymentTypes[req.PaymentType] {
+		http.Error(w, "지원하지 않는 payment_type입니다", http.StatusBadRequest)
+		return
+	}
+
+	log.Printf("PG 승인 요청: gateway=%s, company_code=%s, order_id=%s, amount=%d",
+		gw.Name(), req.CompanyCode, req.OrderID, req.Amount)
+	result, authErr := gw.Authorize(ctx, gateway.AuthorizeRequest{
+		OrderID:     req.CompanyCode + ":" + req.OrderID,
+		Amount:      req.Amount,
+		CardToken:   req.CardToken,
+		Description: req.Description,
+	})
+	if authErr != nil && result.TransactionID == "" {
+		writePGError(w, authErr)
+		return
+	}
+
+	// 거절된 승인도 추적을 위해 기록합니다.
+	var t PGTransaction
+	_, err = utils.DB.ExecContext(ctx, `
+		INSERT INTO payment_gateway_transaction_table
+		(company_code, gateway, transaction_id, member_id, pass_id, payment_type, amount,
+		 status, order_id, message, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		ON CONFLICT (gateway, transaction_id) DO NOTHING`,
+		req.CompanyCode, gw.Name(), result.TransactionID, req.MemberID, req.PassID, req.PaymentType,
+		result.Amount, result.Status, req.OrderID, result.Message)
+	if err != nil {
+		if strings.Contains(err.Error(), "foreign key") {
+			http.Error(w, "존재하지 않는 회원 또는 이용권입니다", http.StatusBadRequest)
+		} else if strings.Contains(err.Error(), "duplicate key") {
+			http.Error(w, "같은 order_id의 다른 거래가 이미 있습니다", http.StatusConflict)
+		} else {
+			writePGError(w, err)
+		}
+		return
+	}
+	err = scanPGTransaction(utils.DB.QueryRowContext(ctx,
+		"SELECT "+pgTransactionColumns+" FROM payment_gateway_transaction_table WHERE gateway = $1 AND transaction_id = $2",
+		gw.Name(), result.TransactionID), &t)
+	if err != nil {
+		writePGError(w, err)
+		return
+	}
+	if authErr != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusPaymentRequired)
+		json.NewEncoder(w).Encode(t)
+		return
+	}
+
+	if req.Capture && t.Status == gateway.STATUS_AUTHORIZED {
+		result, err = gw.Capture(ctx, t.TransactionID)
+		if err != nil {
+			writePGError(w, err)
+			return
+		}
+		t, err = applyPGResult(ctx, t.SerialNumber, result, 0, "")
+		if err != nil {
+			writePGError(w, err)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(t)
+}
+
+// GetPGTransaction: PG 거래를 조회합니다.
+// sync=true이면 PG에 현재 상태를 조회하여 DB와 원장을 맞춥니다.
+func GetPGTransaction(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	id, _ := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	sync := r.URL.Query().Get("sync") == "true"
+	t, gw, err := loadPGTransaction(ctx, id)
+	// 어댑터가 제거된 PG의 거래도 DB 조회는 가능합니다.
+	if err != nil && (sync || !errors.Is(err, gateway.ErrUnknownGateway)) {
+		writePGError(w, err)
+		return
+	}
+
+	if sync {
+		result, err := gw.QueryStatus(ctx, t.TransactionID)
+		if err != nil {
+			writePGError(w, err)
+			return
+		}
+		t, err = applyPGResult(ctx, id, result, 0, "")
+		if err != nil {
+			writePGError(w, err)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(t)
+}
+
+// CapturePGTransaction: 승인된 거래를 매입하고 원장에 결제를 기록합니다.
+func CapturePGTransaction(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.LONG_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	id, _ := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	t, gw, err := loadPGTransaction(ctx, id)
+	if err != nil {
+		writePGError(w, err)
+		return
+	}
+
+	result, err := gw.Capture(ctx, t.TransactionID)
+	if err != nil {
+		writePGError(w, err)
+		return
+	}
+	t, err = applyPGResult(ctx, id, result, 0, "")
+	if err != nil {
+		writePGError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(t)
+}
+
+// CancelPGTransaction: 매입 전 승인을 취소합니다. 원장에는 기록하지 않습니다.
+func CancelPGTransaction(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.LONG_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	id, _ := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	t, gw, err := loadPGTransaction(ctx, id)
+	if err != nil {
+		writePGError(w, err)
+		return
+	}
+
+	result, err := gw.Cancel(ctx, t.TransactionID)
+	if err != nil {
+		writePGError(w, err)
+		return
+	}
+	t, err = applyPGResult(ctx, id, result, 0, "")
+	if err != nil {
+		writePGError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(t)
+}
+
+// RefundPGTransaction: 매입된 거래를 PG에 환불 요청하고 원장에 환불 행을 추가합니다.
+// PG 환불 후 DB 반영이 실패하더라도 같은 refund_id의 웹훅이 도착하면 원장이 맞춰집니다.
+func RefundPGTransaction(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.LONG_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	id, _ := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	var req PGRefundRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "잘못된 요청 데이터", http.StatusBadRequest)
+		return
+	}
+	if req.Amount < 0 {
+		http.Error(w, "amount는 0 이상이어야 합니다", http.StatusBadRequest)
+		return
+	}
+
+	t, gw, err := loadPGTransaction(ctx, id)
+	if err != nil {
+		writePGError(w, err)
+		return
+	}
+	if t.PaymentID == nil {
+		http.Error(w, "매입되지 않은 거래는 환불할 수 없습니다. 취소를 이용해 주세요", http.StatusConflict)
+		return
+	}
+	amount := req.Amount
+	if amount == 0 {
+		amount = t.Amount - t.RefundedAmount
+	}
+
+	log.Printf("PG 환불 요청: gateway=%s, transaction_id=%s, amount=%d", t.Gateway, t.TransactionID, amount)
+	result, err := gw.Refund(ctx, t.TransactionID, amount)
+	if err != nil {
+		writePGError(w, err)
+		return
+	}
+	t, err = applyPGResult(ctx, id, result, amount, req.CreatedBy)
+	if err != nil {
+		writePGError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(t)
+}
+
+// HandlePGWebhook: PG 콜백을 검증하고 거래 상태와 원장을 갱신합니다.
+// 같은 event_id는 한 번만 처리하며, 재전송된 이벤트에는 200을 반환합니다.
+func HandlePGWebhook(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	gw, err := gateway.Get(mux.Vars(r)["gateway"])
+	if err != nil {
+		writePGError(w, err)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, MAX_WEBHOOK_BODY_SIZE))
+	if err != nil {
+		http.Error(w, "잘못된 요청 데이터", http.StatusBadRequest)
+		return
+	}
+	event, err := gw.VerifyWebhook(r.Header, body)
+	if err != nil {
+		if errors.Is(err, gateway.ErrInvalidSignature) {
+			log.Printf("PG 웹훅 서명 검증 실패: gateway=%s", gw.Name())
+			writePGError(w, err)
+		} else {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+		return
+	}
+
+	tx, err := utils.DB.BeginTx(ctx, nil)
+	if err != nil {
+		writePGError(w, err)
+		return
+	}
+	defer tx.Rollback()
+
+	// 이벤트 기록과 상태 반영을 한 트랜잭션으로 처리하여, 반영이 실패하면 재전송 시 다시 처리합니다.
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO payment_gateway_event_table (gateway, event_id, transaction_id, payload, received_at)
+		VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP)
+		ON CONFLICT (gateway, event_id) DO NOTHING`,
+		gw.Name(), event.EventID, event.TransactionID, string(body))
+	if err != nil {
+		writePGError(w, err)
+		return
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		log.Printf("PG 웹훅 중복 수신: gateway=%s, event_id=%s", gw.Name(), event.EventID)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	var id int64
+	err = tx.QueryRowContext(ctx,
+		"SELECT serial_number FROM payment_gateway_transaction_table WHERE gateway = $1 AND transaction_id = $2",
+		gw.Name(), event.TransactionID).Scan(&id)
+	if err != nil {
+		writePGError(w, err)
+		return
+	}
+	_, err = applyPGUpdateTx(ctx, tx, id, pgUpdate{
+		Status:       event.Status,
+		RefundID:     event.RefundID,
+		RefundAmount: event.RefundAmount,
+	})
+	if err != nil {
+		writePGError(w, err)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		writePGError(w, err)
+		return
+	}
+	log.Printf("PG 웹훅 처리 완료: gateway=%s, event_id=%s, status=%s", gw.Name(), event.EventID, event.Status)
+	w.WriteHeader(http.StatusOK)
+}
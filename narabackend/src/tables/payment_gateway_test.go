@@ -0,0 +1,39 @@
+// payment_gateway_test.go
+package tables
+
+import (
+	"testing"
+
+	"narabackend/src/gateway"
+)
+
+func TestPGTransitionAllowed(t *testing.T) {
+	cases := []struct {
+		from, to string
+		want     bool
+	}{
+		{gateway.STATUS_AUTHORIZED, gateway.STATUS_CAPTURED, true},
+		{gateway.STATUS_AUTHORIZED, gateway.STATUS_CANCELLED, true},
+		{gateway.STATUS_CAPTURED, gateway.STATUS_REFUNDED, true},
+		{gateway.STATUS_CAPTURED, gateway.STATUS_PARTIALLY_REFUNDED, true},
+		{gateway.STATUS_PARTIALLY_REFUNDED, gateway.STATUS_REFUNDED, true},
+		{gateway.STATUS_CAPTURED, gateway.STATUS_CAPTURED, true},
+
+		// 종료 상태에서는 다른 상태로 바꿀 수 없습니다.
+		{gateway.STATUS_FAILED, gateway.STATUS_CAPTURED, false},
+		{gateway.STATUS_CANCELLED, gateway.STATUS_CAPTURED, false},
+		{gateway.STATUS_REFUNDED, gateway.STATUS_CAPTURED, false},
+		{gateway.STATUS_REFUNDED, gateway.STATUS_PARTIALLY_REFUNDED, false},
+
+		// 매입된 거래는 취소할 수 없고 환불만 가능합니다.
+		{gateway.STATUS_CAPTURED, gateway.STATUS_CANCELLED, false},
+		{gateway.STATUS_CAPTURED, gateway.STATUS_AUTHORIZED, false},
+		{gateway.STATUS_AUTHORIZED, gateway.STATUS_REFUNDED, false},
+		{gateway.STATUS_AUTHORIZED, "unknown", false},
+	}
+	for _, c := range cases {
+		if got := pgTransitionAllowed(c.from, c.to); got != c.want {
+			t.Errorf("pgTransitionAllowed(%s, %s) = %t, want %t", c.from, c.to, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,377 @@
+// plan.go
+package tables
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"narabackend/src/consts"
+	"narabackend/src/utils"
+)
+
+// 이용권 상품 구분
+const (
+	PLAN_TYPE_HOURLY        = "hourly"        // 시간제(1회 이용 시간 제공)
+	PLAN_TYPE_DAILY         = "daily"         // 당일권
+	PLAN_TYPE_FIXED_MONTHLY = "fixed_monthly" // 고정석 월정액
+	PLAN_TYPE_FREE_PERIOD   = "free_period"   // 자유석 기간권
+	PLAN_TYPE_TIME_BUNDLE   = "time_bundle"   // 시간 묶음(충전식)
+)
+
+// planTypes는 허용된 상품 구분 목록입니다.
+var planTypes = map[string]bool{
+	PLAN_TYPE_HOURLY:        true,
+	PLAN_TYPE_DAILY:         true,
+	PLAN_TYPE_FIXED_MONTHLY: true,
+	PLAN_TYPE_FREE_PERIOD:   true,
+	PLAN_TYPE_TIME_BUNDLE:   true,
+}
+
+// isTimeBasedPlan은 잔여 시간(분)을 차감하는 상품인지 확인합니다.
+// 그 외 상품은 유효 기간(valid_from ~ valid_until) 안에서 자유롭게 이용합니다.
+func isTimeBasedPlan(planType string) bool {
+	return planType == PLAN_TYPE_HOURLY || planType == PLAN_TYPE_TIME_BUNDLE
+}
+
+// Plan 구조체는 plan_table의 각 컬럼을 매핑합니다.
+type Plan struct {
+	SerialNumber    int64     `json:"serial_number" db:"serial_number"`
+	CompanyCode     string    `json:"company_code" db:"company_code"`
+	PlanName        string    `json:"plan_name" db:"plan_name"`
+	PlanType        string    `json:"plan_type" db:"plan_type"`
+	Price           int       `json:"price" db:"price"`
+	DurationMinutes *int      `json:"duration_minutes" db:"duration_minutes"`
+	ValidityDays    *int      `json:"validity_days" db:"validity_days"`
+	FixedSeat       bool      `json:"fixed_seat" db:"fixed_seat"`
+	Active          bool      `json:"active" db:"active"`
+	SortOrder       int       `json:"sort_order" db:"sort_order"`
+	Description     string    `json:"description" db:"description"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// PlanRequest는 요청 시 사용되는 구조체입니다.
+// 업데이트 시 포인터 필드가 nil이면 기존 값을 유지합니다.
+type PlanRequest struct {
+	CompanyCode     string `json:"company_code"`
+	PlanName        string `json:"plan_name"`
+	PlanType        string `json:"plan_type"`
+	Price           *int   `json:"price"`
+	DurationMinutes *int   `json:"duration_minutes"`
+	ValidityDays    *int   `json:"validity_days"`
+	FixedSeat       *bool  `json:"fixed_seat"`
+	Active          *bool  `json:"active"`
+	SortOrder       *int   `json:"sort_order"`
+	Description     string `json:"description"`
+}
+
+// planColumns는 plan_table 조회 시 사용하는 컬럼 목록입니다.
+const planColumns = `serial_number, company_code, plan_name, plan_type, price,
+	duration_minutes, validity_days, COALESCE(fixed_seat, FALSE), COALESCE(active, TRUE),
+	COALESCE(sort_order, 0), COALESCE(description, ''), created_at, updated_at`
+
+// scanPlan은 planColumns 순서로 조회된 행을 Plan 구조체로 변환합니다.
+func scanPlan(row interface{ Scan(...interface{}) error }, plan *Plan) error {
+	return row.Scan(&plan.SerialNumber, &plan.CompanyCode, &plan.PlanName, &plan.PlanType, &plan.Price,
+		&plan.DurationMinutes, &plan.ValidityDays, &plan.FixedSeat, &plan.Active,
+		&plan.SortOrder, &plan.Description, &plan.CreatedAt, &plan.UpdatedAt)
+}
+
+// validatePlan은 상품 구분에 따라 필요한 값이 채워졌는지 확인합니다.
+func validatePlan(plan *Plan) error {
+	if !planTypes[plan.PlanType] {
+		return fmt.Errorf("지원하지 않는 plan_type입니다: %s", plan.PlanType)
+	}
+	if plan.Price < 0 {
+		return fmt.Errorf("price는 0 이상이어야 합니다")
+	}
+	if isTimeBasedPlan(plan.PlanType) {
+		if plan.DurationMinutes == nil || *plan.DurationMinutes <= 0 {
+			return fmt.Errorf("%s 상품은 duration_minutes가 필요합니다", plan.PlanType)
+		}
+	} else if plan.PlanType != PLAN_TYPE_DAILY {
+		if plan.ValidityDays == nil || *plan.ValidityDays <= 0 {
+			return fmt.Errorf("%s 상품은 validity_days가 필요합니다", plan.PlanType)
+		}
+	}
+	return nil
+}
+
+// RegisterPlanRoutes는 plan_table 관련 엔드포인트를 등록합니다.
+func RegisterPlanRoutes(r *mux.Router) {
+	r.HandleFunc("/plans", GetPlans).Methods("GET")
+	r.HandleFunc("/plans/{id}", GetPlan).Methods("GET")
+	r.HandleFunc("/plans", CreatePlan).Methods("POST")
+	r.HandleFunc("/plans/{id}", UpdatePlan).Methods("PUT", "PATCH")
+	r.HandleFunc("/plans/{id}", DeletePlan).Methods("DELETE")
+}
+
+// GetPlans: 업체별 이용권 상품 목록을 조회합니다.
+// company_code, plan_type, active 쿼리 파라미터로 필터링합니다.
+func GetPlans(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	// 필터링 조건 처리
+	filters := []string{}
+	args := []interface{}{}
+	paramIdx := 1
+
+	filterParams := map[string]string{
+		"company_code": "company_code",
+		"plan_type":    "plan_type",
+		"active":       "active",
+	}
+	for param, dbField := range filterParams {
+		if value := r.URL.Query().Get(param); value != "" {
+			filters = append(filters, fmt.Sprintf("%s = $%d", dbField, paramIdx))
+			args = append(args, value)
+			paramIdx++
+		}
+	}
+
+	query := "SELECT " + planColumns + " FROM plan_table"
+	if len(filters) > 0 {
+		query += " WHERE " + strings.Join(filters, " AND ")
+	}
+	query += " ORDER BY company_code ASC, sort_order ASC, serial_number ASC"
+
+	rows, err := utils.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("데이터베이스 쿼리 오류: %v", err)
+		http.Error(w, "데이터 조회 중 오류가 발생했습니다", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	result := []Plan{}
+	for rows.Next() {
+		var plan Plan
+		if err := scanPlan(rows, &plan); err != nil {
+			log.Printf("행 스캔 오류: %v", err)
+			http.Error(w, "데이터 처리 중 오류가 발생했습니다", http.StatusInternalServerError)
+			return
+		}
+		result = append(result, plan)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// GetPlan: 단일 이용권 상품을 조회합니다.
+func GetPlan(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "잘못된 id", http.StatusBadRequest)
+		return
+	}
+
+	var plan Plan
+	err = scanPlan(utils.DB.QueryRowContext(ctx,
+		"SELECT "+planColumns+" FROM plan_table WHERE serial_number = $1", id), &plan)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Plan을 찾을 수 없습니다.", http.StatusNotFound)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(plan)
+}
+
+// CreatePlan: 새로운 이용권 상품을 등록합니다.
+func CreatePlan(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	var req PlanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "잘못된 요청 데이터", http.StatusBadRequest)
+		return
+	}
+
+	// 필수 필드 검증
+	if req.CompanyCode == "" || req.PlanName == "" || req.PlanType == "" || req.Price == nil {
+		http.Error(w, "필수 필드가 누락되었습니다 (company_code, plan_name, plan_type, price)", http.StatusBadRequest)
+		return
+	}
+
+	plan := Plan{
+		CompanyCode:     req.CompanyCode,
+		PlanName:        req.PlanName,
+		PlanType:        req.PlanType,
+		Price:           *req.Price,
+		DurationMinutes: req.DurationMinutes,
+		ValidityDays:    req.ValidityDays,
+		Active:          true,
+		Description:     req.Description,
+	}
+	if req.FixedSeat != nil {
+		plan.FixedSeat = *req.FixedSeat
+	}
+	if req.Active != nil {
+		plan.Active = *req.Active
+	}
+	if req.SortOrder != nil {
+		plan.SortOrder = *req.SortOrder
+	}
+	if err := validatePlan(&plan); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	log.Printf("Plan 생성 요청 시작: %+v", plan)
+	err := scanPlan(utils.DB.QueryRowContext(ctx, `
+		INSERT INTO plan_table
+		(company_code, plan_name, plan_type, price, duration_minutes, validity_days,
+		 fixed_seat, active, sort_order, description, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		RETURNING `+planColumns,
+		plan.CompanyCode, plan.PlanName, plan.PlanType, plan.Price, plan.DurationMinutes, plan.ValidityDays,
+		plan.FixedSeat, plan.Active, plan.SortOrder, plan.Description), &plan)
+	if err != nil {
+		log.Printf("DB 오류: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(plan)
+}
+
+// UpdatePlan: 이용권 상품 정보를 업데이트합니다.
+// 이미 판매된 이용권(member_pass_table)은 구매 시점의 조건을 유지합니다.
+func UpdatePlan(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "잘못된 id", http.StatusBadRequest)
+		return
+	}
+
+	var req PlanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "잘못된 요청 데이터", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := utils.DB.BeginTx(ctx, nil)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	// 기존 값을 읽어 요청 값과 병합한 뒤 검증합니다.
+	var plan Plan
+	err = scanPlan(tx.QueryRowContext(ctx,
+		"SELECT "+planColumns+" FROM plan_table WHERE serial_number = $1 FOR UPDATE", id), &plan)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Plan을 찾을 수 없습니다.", http.StatusNotFound)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	if req.PlanName != "" {
+		plan.PlanName = req.PlanName
+	}
+	if req.PlanType != "" {
+		plan.PlanType = req.PlanType
+	}
+	if req.Price != nil {
+		plan.Price = *req.Price
+	}
+	if req.DurationMinutes != nil {
+		plan.DurationMinutes = req.DurationMinutes
+	}
+	if req.ValidityDays != nil {
+		plan.ValidityDays = req.ValidityDays
+	}
+	if req.FixedSeat != nil {
+		plan.FixedSeat = *req.FixedSeat
+	}
+	if req.Active != nil {
+		plan.Active = *req.Active
+	}
+	if req.SortOrder != nil {
+		plan.SortOrder = *req.SortOrder
+	}
+	if req.Description != "" {
+		plan.Description = req.Description
+	}
+	if err := validatePlan(&plan); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = scanPlan(tx.QueryRowContext(ctx, `
+		UPDATE plan_table SET
+			plan_name = $2, plan_type = $3, price = $4, duration_minutes = $5, validity_days = $6,
+			fixed_seat = $7, active = $8, sort_order = $9, description = $10,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE serial_number = $1
+		RETURNING `+planColumns,
+		id, plan.PlanName, plan.PlanType, plan.Price, plan.DurationMinutes, plan.ValidityDays,
+		plan.FixedSeat, plan.Active, plan.SortOrder, plan.Description), &plan)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(plan)
+}
+
+// DeletePlan: 이용권 상품을 판매 중지합니다.
+// 판매된 이용권이 상품을 참조하므로 행을 삭제하지 않고 active를 false로 변경합니다.
+func DeletePlan(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "잘못된 id", http.StatusBadRequest)
+		return
+	}
+
+	result, err := utils.DB.ExecContext(ctx,
+		"UPDATE plan_table SET active = FALSE, updated_at = CURRENT_TIMESTAMP WHERE serial_number = $1", id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		http.Error(w, "Plan을 찾을 수 없습니다.", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
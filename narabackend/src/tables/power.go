@@ -0,0 +1,880 @@
+// power.go
+package tables
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"narabackend/src/consts"
+	"narabackend/src/utils"
+)
+
+// 전원 제어 대상
+const (
+	POWER_TARGET_ROOM = "room" // 열람실 차단기 (room_table.breaker_number)
+	POWER_TARGET_SEAT = "seat" // 좌석 전원 (seat_table.power_number)
+)
+
+// 전원 제어 발생 원인
+const (
+	POWER_SOURCE_SCHEDULE  = "schedule"  // 열람실 전원 시간표
+	POWER_SOURCE_OCCUPANCY = "occupancy" // 입실/퇴실
+	POWER_SOURCE_RELEASE   = "release"   // 좌석 자동 해제
+	POWER_SOURCE_MANUAL    = "manual"    // 관리자 즉시 제어
+)
+
+// 전원 제어 이벤트 상태
+const (
+	POWER_STATUS_PENDING      = "pending"      // 기록 후 전송 전
+	POWER_STATUS_SENT         = "sent"         // naracontrol 전송 완료, 장치 응답 대기
+	POWER_STATUS_NO_RECIPIENT = "no_recipient" // 연결된 장치 없음
+	POWER_STATUS_FAILED       = "failed"       // naracontrol 전송 실패
+	POWER_STATUS_ACKNOWLEDGED = "acknowledged" // 장치가 처리 완료를 응답
+	POWER_STATUS_REJECTED     = "rejected"     // 장치가 처리 실패를 응답
+)
+
+// POWER_EVENT_ID_PREFIX는 naracontrol 명령 이벤트ID에서 전원 제어 이벤트를 구분하는 접두어입니다.
+const POWER_EVENT_ID_PREFIX = "power-"
+
+// PowerScheduleWindow는 열람실의 요일별 차단기 켜짐 시간대입니다. 시각은 HH:MM 형식입니다.
+// 끄는 시각이 켜는 시각보다 이르면 다음 날 끄는 시각까지, 같으면 하루 종일 켜 둡니다.
+type PowerScheduleWindow struct {
+	RoomCode   int    `json:"room_code"`
+	DayOfWeek  int    `json:"day_of_week"`
+	PowerOnAt  string `json:"power_on_at"`
+	PowerOffAt string `json:"power_off_at"`
+	Enabled    bool   `json:"enabled"`
+}
+
+// PowerScheduleRequest는 전원 시간표 저장 요청 시 사용되는 구조체입니다. 기존 시간표를 모두 대체합니다.
+type PowerScheduleRequest struct {
+	Windows []PowerScheduleWindow `json:"windows"`
+}
+
+// PowerRule 구조체는 power_rule_table의 각 컬럼을 매핑합니다.
+type PowerRule struct {
+	SerialNumber          int64     `json:"serial_number" db:"serial_number"`
+	CompanyCode           string    `json:"company_code" db:"company_code"`
+	SeatPowerOnCheckIn    bool      `json:"seat_power_on_check_in" db:"seat_power_on_check_in"`
+	SeatPowerOffOnRelease bool      `json:"seat_power_off_on_release" db:"seat_power_off_on_release"`
+	CreatedAt             time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt             time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// PowerRuleRequest는 좌석 전원 규칙 저장 요청 시 사용되는 구조체입니다.
+// 포인터 필드는 생략 시 기존 값(없으면 기본값)을 유지합니다.
+type PowerRuleRequest struct {
+	SeatPowerOnCheckIn    *bool `json:"seat_power_on_check_in"`
+	SeatPowerOffOnRelease *bool `json:"seat_power_off_on_release"`
+}
+
+// PowerEvent 구조체는 power_event_table의 각 컬럼을 매핑합니다.
+type PowerEvent struct {
+	SerialNumber int64      `json:"serial_number" db:"serial_number"`
+	CompanyCode  string     `json:"company_code" db:"company_code"`
+	TargetType   string     `json:"target_type" db:"target_type"`
+	RoomCode     *int       `json:"room_code" db:"room_code"`
+	SeatCode     *int       `json:"seat_code" db:"seat_code"`
+	PowerNumber  *int       `json:"power_number" db:"power_number"`
+	Command      string     `json:"command" db:"command"`
+	Source       string     `json:"source" db:"source"`
+	RequestedBy  *string    `json:"requested_by" db:"requested_by"`
+	Status       string     `json:"status" db:"status"`
+	Recipients   int        `json:"recipients" db:"recipients"`
+	ErrorMessage *string    `json:"error_message" db:"error_message"`
+	AckStatus    *string    `json:"ack_status" db:"ack_status"`
+	AckMessage   *string    `json:"ack_message" db:"ack_message"`
+	AckedAt      *time.Time `json:"acked_at" db:"acked_at"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+}
+
+// PowerCommandRequest는 열람실/좌석 전원 즉시 제어 요청입니다.
+type PowerCommandRequest struct {
+	Command     string `json:"command"` // power_on 또는 power_off
+	RequestedBy string `json:"requested_by"`
+}
+
+// powerTarget은 전원 명령을 보낼 열람실 차단기 또는 좌석 전원입니다.
+type powerTarget struct {
+	CompanyCode string
+	TargetType  string
+	RoomCode    *int
+	SeatCode    *int
+	SeatNumber  *int
+	PowerNumber *int
+}
+
+// powerRuleColumns는 power_rule_table 조회 시 사용하는 컬럼 목록입니다.
+const powerRuleColumns = `serial_number, company_code, seat_power_on_check_in, seat_power_off_on_release,
+	created_at, updated_at`
+
+// powerEventColumns는 power_event_table 조회 시 사용하는 컬럼 목록입니다.
+const powerEventColumns = `serial_number, company_code, target_type, room_code, seat_code, power_number,
+	command, source, requested_by, status, recipients, error_message, ack_status, ack_message, acked_at, created_at`
+
+// scanPowerRule은 한 행을 PowerRule로 읽습니다.
+func scanPowerRule(row interface{ Scan(...interface{}) error }, rule *PowerRule) error {
+	return row.Scan(&rule.SerialNumber, &rule.CompanyCode, &rule.SeatPowerOnCheckIn, &rule.SeatPowerOffOnRelease,
+		&rule.CreatedAt, &rule.UpdatedAt)
+}
+
+// scanPowerEvent는 한 행을 PowerEvent로 읽습니다.
+func scanPowerEvent(row interface{ Scan(...interface{}) error }, event *PowerEvent) error {
+	return row.Scan(&event.SerialNumber, &event.CompanyCode, &event.TargetType, &event.RoomCode, &event.SeatCode,
+		&event.PowerNumber, &event.Command, &event.Source, &event.RequestedBy, &event.Status, &event.Recipients,
+		&event.ErrorMessage, &event.AckStatus, &event.AckMessage, &event.AckedAt, &event.CreatedAt)
+}
+
+var (
+	errInvalidPowerCommand  = errors.New("command는 power_on 또는 power_off여야 합니다")
+	errPowerControlDisabled = errors.New("전원 제어를 사용하지 않는 열람실입니다")
+	errNoPowerNumber        = errors.New("차단기 번호 또는 전원 번호가 설정되지 않았습니다")
+)
+
+// validPowerCommand는 전원 명령이 power_on 또는 power_off인지 확인합니다.
+func validPowerCommand(command string) bool {
+	return command == utils.CONTROL_COMMAND_POWER_ON || command == utils.CONTROL_COMMAND_POWER_OFF
+}
+
+// loadRoomPowerTarget은 열람실 차단기 정보를 조회합니다.
+// room_table의 company_code는 숫자 컬럼이므로 문자열로 바꿔 비교합니다.
+func loadRoomPowerTarget(ctx context.Context, companyCode string, roomCode int) (powerTarget, error) {
+	target := powerTarget{CompanyCode: companyCode, TargetType: POWER_TARGET_ROOM, RoomCode: &roomCode}
+	var powerControl int
+	err := utils.DB.QueryRowContext(ctx,
+		"SELECT COALESCE(power_control, 0), breaker_number FROM room_table WHERE room_code = $1 AND company_code::text = $2 LIMIT 1",
+		roomCode, companyCode).Scan(&powerControl, &target.PowerNumber)
+	if err != nil {
+		return target, err
+	}
+	if powerControl == 0 {
+		return target, errPowerControlDisabled
+	}
+	if target.PowerNumber == nil {
+		return target, errNoPowerNumber
+	}
+	return target, nil
+}
+
+// loadSeatPowerTarget은 좌석 전원 정보를 조회합니다.
+func loadSeatPowerTarget(ctx context.Context, companyCode string, seatCode int) (powerTarget, error) {
+	target := powerTarget{CompanyCode: companyCode, TargetType: POWER_TARGET_SEAT, SeatCode: &seatCode}
+	err := utils.DB.QueryRowContext(ctx,
+		"SELECT room_code, seat_number, power_number FROM seat_table WHERE company_code = $1 AND seat_code = $2",
+		companyCode, seatCode).Scan(&target.RoomCode, &target.SeatNumber, &target.PowerNumber)
+	if err != nil {
+		return target, err
+	}
+	if target.PowerNumber == nil {
+		return target, errNoPowerNumber
+	}
+	return target, nil
+}
+
+// sendPowerCommand는 전원 제어 이벤트를 기록한 뒤 naracontrol로 명령을 보내고 전송 결과를 기록합니다.
+// 장치의 처리 결과는 이벤트ID로 /internal/command-acks를 통해 나중에 기록됩니다.
+func sendPowerCommand(ctx context.Context, target powerTarget, command, source, requestedBy string) (PowerEvent, error) {
+	var event PowerEvent
+	err := scanPowerEvent(utils.DB.QueryRowContext(ctx, `
+		INSERT INTO power_event_table
+		(company_code, target_type, room_code, seat_code, power_number, command, source, requested_by, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, CURRENT_TIMESTAMP)
+		RETURNING `+powerEventColumns,
+		target.CompanyCode, target.TargetType, target.RoomCode, target.SeatCode, target.PowerNumber,
+		command, source, nullableString(requestedBy), POWER_STATUS_PENDING), &event)
+	if err != nil {
+		return event, err
+	}
+
+	// 장치/데스크는 seat_table의 좌석 번호를 사용하고, 없으면 seat_code로 대신합니다.
+	seatNumber := utils.ControlNumberString(target.SeatNumber)
+	if seatNumber == "" {
+		seatNumber = utils.ControlNumberString(target.SeatCode)
+	}
+	recipients, sendErr := utils.SendControlCommand(ctx, utils.ControlCommand{
+		Command: command,
+		Target:  utils.CONTROL_TARGET_DEVICE,
+		EventID: POWER_EVENT_ID_PREFIX + strconv.FormatInt(event.SerialNumber, 10),
+		Message: utils.ControlMessage{
+			CompanyCode: target.CompanyCode,
+			RoomCode:    utils.ControlNumberString(target.RoomCode),
+			SeatNumber:  seatNumber,
+			PowerNumber: utils.ControlNumberString(target.PowerNumber),
+		},
+	})
+
+	status := POWER_STATUS_SENT
+	var errorMessage *string
+	switch {
+	case sendErr != nil:
+		status = POWER_STATUS_FAILED
+		errorMessage = nullableString(sendErr.Error())
+	case recipients == 0:
+		status = POWER_STATUS_NO_RECIPIENT
+	}
+
+	// 장치 응답이 전송 결과 기록보다 먼저 도착했으면 응답 상태를 유지합니다.
+	err = scanPowerEvent(utils.DB.QueryRowContext(ctx, `
+		UPDATE power_event_table SET
+			status = CASE WHEN status = $2 THEN $3 ELSE status END,
+			recipients = $4, error_message = $5
+		WHERE serial_number = $1
+		RETURNING `+powerEventColumns,
+		event.SerialNumber, POWER_STATUS_PENDING, status, recipients, errorMessage), &event)
+	if err != nil {
+		return event, err
+	}
+	if sendErr != nil {
+		log.Printf("전원 명령 전송 실패: company_code=%s, event=%d, 오류: %v", target.CompanyCode, event.SerialNumber, sendErr)
+	}
+	return event, nil
+}
+
+// loadPowerRule은 업체의 좌석 전원 규칙을 조회합니다. 규칙이 없으면 found=false입니다.
+func loadPowerRule(ctx context.Context, companyCode string) (rule PowerRule, found bool, err error) {
+	err = scanPowerRule(utils.DB.QueryRowContext(ctx,
+		"SELECT "+powerRuleColumns+" FROM power_rule_table WHERE company_code = $1", companyCode), &rule)
+	if err == sql.ErrNoRows {
+		return rule, false, nil
+	}
+	return rule, err == nil, err
+}
+
+// applySeatOccupancyPower는 입실/퇴실에 맞춰 좌석 전원 규칙대로 좌석 전원을 켜거나 끕니다.
+// 규칙이 없거나 전원 번호가 없는 좌석은 아무것도 하지 않으며, 실패해도 로그만 남깁니다.
+func applySeatOccupancyPower(ctx context.Context, companyCode string, seatCode int, occupied bool) {
+	rule, found, err := loadPowerRule(ctx, companyCode)
+	if err != nil {
+		log.Printf("좌석 전원 규칙 조회 실패: company_code=%s, 오류: %v", companyCode, err)
+		return
+	}
+	if !found {
+		return
+	}
+	command := utils.CONTROL_COMMAND_POWER_OFF
+	if occupied {
+		if !rule.SeatPowerOnCheckIn {
+			return
+		}
+		command = utils.CONTROL_COMMAND_POWER_ON
+	} else if !rule.SeatPowerOffOnRelease {
+		return
+	}
+
+	target, err := loadSeatPowerTarget(ctx, companyCode, seatCode)
+	if err != nil {
+		if err != sql.ErrNoRows && err != errNoPowerNumber {
+			log.Printf("좌석 전원 정보 조회 실패: company_code=%s, seat_code=%d, 오류: %v", companyCode, seatCode, err)
+		}
+		return
+	}
+	if _, err := sendPowerCommand(ctx, target, command, POWER_SOURCE_OCCUPANCY, ""); err != nil {
+		log.Printf("좌석 전원 이벤트 기록 실패: company_code=%s, seat_code=%d, 오류: %v", companyCode, seatCode, err)
+	}
+}
+
+// releaseSeatPowerOff는 자동 해제된 좌석의 전원 차단 여부를 판단합니다.
+// 스케줄러 설정(SEAT_EXPIRATION_POWER_OFF)이 켜져 있거나 업체 좌석 전원 규칙이 해제 시 차단이면 끕니다.
+func releaseSeatPowerOff(ctx context.Context, companyCode string, configured bool) bool {
+	if configured {
+		return true
+	}
+	rule, found, err := loadPowerRule(ctx, companyCode)
+	if err != nil {
+		log.Printf("좌석 전원 규칙 조회 실패: company_code=%s, 오류: %v", companyCode, err)
+		return false
+	}
+	return found && rule.SeatPowerOffOnRelease
+}
+
+// loadPowerSchedule은 업체의 열람실 전원 시간표를 조회합니다. roomCode가 있으면 해당 열람실만 조회합니다.
+func loadPowerSchedule(ctx context.Context, companyCode string, roomCode *int) ([]PowerScheduleWindow, error) {
+	query := `
+		SELECT room_code, day_of_week, to_char(power_on_at, 'HH24:MI'), to_char(power_off_at, 'HH24:MI'), enabled
+		FROM power_schedule_table WHERE company_code = $1`
+	args := []interface{}{companyCode}
+	if roomCode != nil {
+		query += " AND room_code = $2"
+		args = append(args, *roomCode)
+	}
+	query += " ORDER BY room_code, day_of_week, power_on_at"
+
+	rows, err := utils.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	windows := []PowerScheduleWindow{}
+	for rows.Next() {
+		var w PowerScheduleWindow
+		if err := rows.Scan(&w.RoomCode, &w.DayOfWeek, &w.PowerOnAt, &w.PowerOffAt, &w.Enabled); err != nil {
+			return nil, err
+		}
+		windows = append(windows, w)
+	}
+	return windows, rows.Err()
+}
+
+// StartPowerScheduler는 주기적으로 열람실 전원 시간표를 확인하여 차단기를 켜고 끄는 백그라운드 작업을 시작합니다.
+func StartPowerScheduler(interval time.Duration) {
+	if interval <= 0 {
+		log.Printf("전원 시간표 스케줄러 비활성화 (interval=%s)", interval)
+		return
+	}
+	log.Printf("전원 시간표 스케줄러 시작 (interval=%s)", interval)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			runPowerSchedule()
+			<-ticker.C
+		}
+	}()
+}
+
+// runPowerSchedule은 사용 중인 시간표가 있는 열람실마다 지금 켜져 있어야 하는지 판단하고,
+// 시간표가 마지막으로 보낸 명령과 다를 때만 차단기 명령을 보냅니다.
+// 따라서 관리자가 즉시 제어로 바꾼 상태는 다음 시간표 전환 시각까지 유지됩니다.
+// 여러 서버가 같은 명령을 중복으로 보내지 않도록 advisory lock을 잡은 서버 하나만 실행합니다.
+func runPowerSchedule() {
+	timeout := time.Duration(consts.LONG_WORK_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// advisory lock은 세션 단위이므로 잠금과 해제를 같은 연결에서 합니다.
+	conn, err := utils.DB.Conn(ctx)
+	if err != nil {
+		log.Printf("전원 시간표 DB 연결 실패: %v", err)
+		return
+	}
+	defer conn.Close()
+	var locked bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", consts.POWER_SCHEDULER_LOCK_KEY).Scan(&locked); err != nil || !locked {
+		if err != nil {
+			log.Printf("전원 시간표 잠금 확인 실패: %v", err)
+		}
+		return
+	}
+	defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", consts.POWER_SCHEDULER_LOCK_KEY)
+
+	rows, err := utils.DB.QueryContext(ctx, `
+		SELECT company_code, room_code, day_of_week, to_char(power_on_at, 'HH24:MI'), to_char(power_off_at, 'HH24:MI')
+		FROM power_schedule_table WHERE enabled
+		ORDER BY company_code, room_code`)
+	if err != nil {
+		log.Printf("전원 시간표 조회 오류: %v", err)
+		return
+	}
+	type roomKey struct {
+		companyCode string
+		roomCode    int
+	}
+	schedules := map[roomKey][]OperatingWindow{}
+	keys := []roomKey{}
+	for rows.Next() {
+		var key roomKey
+		var w OperatingWindow
+		if err := rows.Scan(&key.companyCode, &key.roomCode, &w.DayOfWeek, &w.StaffedFrom, &w.StaffedUntil); err != nil {
+			rows.Close()
+			log.Printf("전원 시간표 조회 오류: %v", err)
+			return
+		}
+		if _, ok := schedules[key]; !ok {
+			keys = append(keys, key)
+		}
+		schedules[key] = append(schedules[key], w)
+	}
+	rows.Close()
+
+	now := time.Now()
+	sent := 0
+	for _, key := range keys {
+		// 전원 시간대 판정은 운영 시간표와 같은 규칙(자정 넘김, 하루 종일)을 사용합니다.
+		command := utils.CONTROL_COMMAND_POWER_OFF
+		if _, on := staffedWindow(schedules[key], now); on {
+			command = utils.CONTROL_COMMAND_POWER_ON
+		}
+
+		var lastCommand string
+		err := utils.DB.QueryRowContext(ctx, `
+			SELECT command FROM power_event_table
+			WHERE company_code = $1 AND target_type = $2 AND room_code = $3 AND source = $4 AND status <> $5
+			ORDER BY created_at DESC, serial_number DESC LIMIT 1`,
+			key.companyCode, POWER_TARGET_ROOM, key.roomCode, POWER_SOURCE_SCHEDULE, POWER_STATUS_FAILED).Scan(&lastCommand)
+		if err != nil && err != sql.ErrNoRows {
+			log.Printf("전원 이벤트 조회 오류: %v", err)
+			continue
+		}
+		if lastCommand == command {
+			continue
+		}
+
+		target, err := loadRoomPowerTarget(ctx, key.companyCode, key.roomCode)
+		if err != nil {
+			log.Printf("열람실 차단기 정보 조회 실패: company_code=%s, room_code=%d, 오류: %v", key.companyCode, key.roomCode, err)
+			continue
+		}
+		if _, err := sendPowerCommand(ctx, target, command, POWER_SOURCE_SCHEDULE, ""); err != nil {
+			log.Printf("전원 이벤트 기록 실패: company_code=%s, room_code=%d, 오류: %v", key.companyCode, key.roomCode, err)
+			continue
+		}
+		sent++
+	}
+	if sent > 0 {
+		log.Printf("전원 시간표 명령 전송 완료: %d건", sent)
+	}
+}
+
+// RegisterPowerRoutes는 전원 시간표, 좌석 전원 규칙, 전원 즉시 제어, 전원 이벤트 엔드포인트를 등록합니다.
+func RegisterPowerRoutes(r *mux.Router) {
+	r.HandleFunc("/companies/{company_code}/power-schedules", GetPowerSchedules).Methods("GET")
+	r.HandleFunc("/companies/{company_code}/power-schedules", SavePowerSchedules).Methods("PUT")
+	r.HandleFunc("/companies/{company_code}/power-rule", GetPowerRule).Methods("GET")
+	r.HandleFunc("/companies/{company_code}/power-rule", SavePowerRule).Methods("PUT", "PATCH")
+	r.HandleFunc("/companies/{company_code}/power-rule", DeletePowerRule).Methods("DELETE")
+	r.HandleFunc("/companies/{company_code}/rooms/{room_code:[0-9]+}/power", SendRoomPowerCommand).Methods("POST")
+	r.HandleFunc("/companies/{company_code}/seats/{seat_code:[0-9]+}/power", SendSeatPowerCommand).Methods("POST")
+	r.HandleFunc("/companies/{company_code}/power-events", GetPowerEvents).Methods("GET")
+	r.HandleFunc("/power-events/{id:[0-9]+}", GetPowerEvent).Methods("GET")
+	r.HandleFunc("/internal/command-acks", ReceiveCommandAck).Methods("POST")
+}
+
+// GetPowerSchedules: 업체의 열람실 전원 시간표를 조회합니다. room_code로 열람실을 지정할 수 있습니다.
+func GetPowerSchedules(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	var roomCode *int
+	if v := r.URL.Query().Get("room_code"); v != "" {
+		code, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "잘못된 room_code", http.StatusBadRequest)
+			return
+		}
+		roomCode = &code
+	}
+	windows, err := loadPowerSchedule(ctx, mux.Vars(r)["company_code"], roomCode)
+	if err != nil {
+		log.Printf("데이터베이스 쿼리 오류: %v", err)
+		http.Error(w, "데이터 조회 중 오류가 발생했습니다", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(PowerScheduleRequest{Windows: windows})
+}
+
+// SavePowerSchedules: 업체의 열람실 전원 시간표를 요청 내용으로 대체합니다.
+// 시간표가 없는 열람실은 스케줄러가 차단기를 제어하지 않습니다.
+func SavePowerSchedules(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	companyCode := mux.Vars(r)["company_code"]
+	var req PowerScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "잘못된 요청 데이터", http.StatusBadRequest)
+		return
+	}
+	for _, window := range req.Windows {
+		if window.RoomCode == 0 {
+			http.Error(w, "필수 필드가 누락되었습니다 (room_code)", http.StatusBadRequest)
+			return
+		}
+		if window.DayOfWeek < 0 || window.DayOfWeek > 6 {
+			http.Error(w, "day_of_week는 0(일요일)~6(토요일)이어야 합니다", http.StatusBadRequest)
+			return
+		}
+		if _, err := parseClock(window.PowerOnAt); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if _, err := parseClock(window.PowerOffAt); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	tx, err := utils.DB.BeginTx(ctx, nil)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM power_schedule_table WHERE company_code = $1", companyCode); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	for _, window := range req.Windows {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO power_schedule_table (company_code, room_code, day_of_week, power_on_at, power_off_at, enabled, created_at)
+			VALUES ($1, $2, $3, $4::time, $5::time, $6, CURRENT_TIMESTAMP)`,
+			companyCode, window.RoomCode, window.DayOfWeek, window.PowerOnAt, window.PowerOffAt, window.Enabled)
+		if err != nil {
+			log.Printf("전원 시간표 저장 오류: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	windows, err := loadPowerSchedule(ctx, companyCode, nil)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(PowerScheduleRequest{Windows: windows})
+}
+
+// GetPowerRule: 업체의 좌석 전원 규칙을 조회합니다.
+func GetPowerRule(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	rule, found, err := loadPowerRule(ctx, mux.Vars(r)["company_code"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, "좌석 전원 규칙이 없습니다.", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rule)
+}
+
+// SavePowerRule: 업체의 좌석 전원 규칙을 생성하거나 수정합니다.
+func SavePowerRule(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	companyCode := mux.Vars(r)["company_code"]
+	var req PowerRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "잘못된 요청 데이터", http.StatusBadRequest)
+		return
+	}
+
+	rule, found, err := loadPowerRule(ctx, companyCode)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		rule = PowerRule{CompanyCode: companyCode, SeatPowerOnCheckIn: true, SeatPowerOffOnRelease: true}
+	}
+	if req.SeatPowerOnCheckIn != nil {
+		rule.SeatPowerOnCheckIn = *req.SeatPowerOnCheckIn
+	}
+	if req.SeatPowerOffOnRelease != nil {
+		rule.SeatPowerOffOnRelease = *req.SeatPowerOffOnRelease
+	}
+
+	log.Printf("좌석 전원 규칙 저장 요청: %+v", rule)
+	err = scanPowerRule(utils.DB.QueryRowContext(ctx, `
+		INSERT INTO power_rule_table
+		(company_code, seat_power_on_check_in, seat_power_off_on_release, created_at, updated_at)
+		VALUES ($1, $2, $3, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		ON CONFLICT (company_code) DO UPDATE SET
+			seat_power_on_check_in = EXCLUDED.seat_power_on_check_in,
+			seat_power_off_on_release = EXCLUDED.seat_power_off_on_release,
+			updated_at = CURRENT_TIMESTAMP
+		RETURNING `+powerRuleColumns,
+		rule.CompanyCode, rule.SeatPowerOnCheckIn, rule.SeatPowerOffOnRelease), &rule)
+	if err != nil {
+		log.Printf("좌석 전원 규칙 저장 오류: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rule)
+}
+
+// DeletePowerRule: 업체의 좌석 전원 규칙을 삭제합니다. 이후 입실/퇴실 시 좌석 전원을 제어하지 않습니다.
+func DeletePowerRule(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	res, err := utils.DB.ExecContext(ctx, "DELETE FROM power_rule_table WHERE company_code = $1", mux.Vars(r)["company_code"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		http.Error(w, "좌석 전원 규칙이 없습니다.", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SendRoomPowerCommand: 열람실 차단기를 즉시 켜거나 끕니다.
+func SendRoomPowerCommand(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	vars := mux.Vars(r)
+	roomCode, _ := strconv.Atoi(vars["room_code"])
+	req, ok := decodePowerCommandRequest(w, r)
+	if !ok {
+		return
+	}
+	target, err := loadRoomPowerTarget(ctx, vars["company_code"], roomCode)
+	if err != nil {
+		writePowerTargetError(w, err, "열람실을 찾을 수 없습니다.")
+		return
+	}
+	writePowerCommand(ctx, w, target, req)
+}
+
+// SendSeatPowerCommand: 좌석 전원을 즉시 켜거나 끕니다.
+func SendSeatPowerCommand(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	vars := mux.Vars(r)
+	seatCode, _ := strconv.Atoi(vars["seat_code"])
+	req, ok := decodePowerCommandRequest(w, r)
+	if !ok {
+		return
+	}
+	target, err := loadSeatPowerTarget(ctx, vars["company_code"], seatCode)
+	if err != nil {
+		writePowerTargetError(w, err, "좌석을 찾을 수 없습니다.")
+		return
+	}
+	writePowerCommand(ctx, w, target, req)
+}
+
+// decodePowerCommandRequest는 전원 즉시 제어 요청을 읽고 검증합니다. 실패하면 응답을 쓰고 false를 반환합니다.
+func decodePowerCommandRequest(w http.ResponseWriter, r *http.Request) (PowerCommandRequest, bool) {
+	var req PowerCommandRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "잘못된 요청 데이터", http.StatusBadRequest)
+		return req, false
+	}
+	if !validPowerCommand(req.Command) {
+		http.Error(w, errInvalidPowerCommand.Error(), http.StatusBadRequest)
+		return req, false
+	}
+	return req, true
+}
+
+// writePowerTargetError는 전원 제어 대상 조회 오류를 HTTP 응답으로 변환합니다.
+func writePowerTargetError(w http.ResponseWriter, err error, notFound string) {
+	switch {
+	case err == sql.ErrNoRows:
+		http.Error(w, notFound, http.StatusNotFound)
+	case errors.Is(err, errPowerControlDisabled), errors.Is(err, errNoPowerNumber):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// writePowerCommand는 전원 명령을 보내고 기록된 이벤트를 응답합니다.
+// 장치 응답은 비동기로 기록되므로 전송에 성공하면 202로 응답합니다.
+func writePowerCommand(ctx context.Context, w http.ResponseWriter, target powerTarget, req PowerCommandRequest) {
+	event, err := sendPowerCommand(ctx, target, req.Command, POWER_SOURCE_MANUAL, req.RequestedBy)
+	if err != nil {
+		log.Printf("전원 이벤트 기록 오류: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	status := http.StatusAccepted
+	if event.Status == POWER_STATUS_FAILED {
+		status = http.StatusBadGateway
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(event)
+}
+
+// GetPowerEvents: 업체의 전원 제어 이벤트를 최신순으로 조회합니다.
+// target_type, room_code, seat_code, source, status와 from/to(YYYY-MM-DD, created_at 기준)로 거를 수 있습니다.
+func GetPowerEvents(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	filters := []string{"company_code = $1"}
+	args := []interface{}{mux.Vars(r)["company_code"]}
+	paramIdx := 2
+
+	filterParams := map[string]string{
+		"target_type": "target_type",
+		"room_code":   "room_code",
+		"seat_code":   "seat_code",
+		"source":      "source",
+		"status":      "status",
+	}
+	for param, dbField := range filterParams {
+		if value := r.URL.Query().Get(param); value != "" {
+			filters = append(filters, fmt.Sprintf("%s = $%d", dbField, paramIdx))
+			args = append(args, value)
+			paramIdx++
+		}
+	}
+	if from := r.URL.Query().Get("from"); from != "" {
+		filters = append(filters, fmt.Sprintf("created_at >= $%d::date", paramIdx))
+		args = append(args, from)
+		paramIdx++
+	}
+	if to := r.URL.Query().Get("to"); to != "" {
+		filters = append(filters, fmt.Sprintf("created_at < $%d::date + 1", paramIdx))
+		args = append(args, to)
+		paramIdx++
+	}
+
+	page, pageSize, err := parsePagination(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	query := "SELECT " + powerEventColumns + " FROM power_event_table WHERE " + strings.Join(filters, " AND ") +
+		fmt.Sprintf(" ORDER BY created_at DESC, serial_number DESC LIMIT $%d OFFSET $%d", paramIdx, paramIdx+1)
+	args = append(args, pageSize, (page-1)*pageSize)
+
+	rows, err := utils.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("데이터베이스 쿼리 오류: %v", err)
+		http.Error(w, "데이터 조회 중 오류가 발생했습니다", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	result := []PowerEvent{}
+	for rows.Next() {
+		var event PowerEvent
+		if err := scanPowerEvent(rows, &event); err != nil {
+			log.Printf("행 스캔 오류: %v", err)
+			http.Error(w, "데이터 처리 중 오류가 발생했습니다", http.StatusInternalServerError)
+			return
+		}
+		result = append(result, event)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// GetPowerEvent: 전원 제어 이벤트 하나를 조회합니다.
+func GetPowerEvent(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	id, _ := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	var event PowerEvent
+	err := scanPowerEvent(utils.DB.QueryRowContext(ctx,
+		"SELECT "+powerEventColumns+" FROM power_event_table WHERE serial_number = $1", id), &event)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "전원 이벤트를 찾을 수 없습니다.", http.StatusNotFound)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(event)
+}
+
+// ReceiveCommandAck: naracontrol이 전달한 장치의 명령 처리 결과를 전원 이벤트에 기록합니다.
+// X-Internal-Key 헤더가 naracontrol.api_key(NARACONTROL_API_KEY)와 같아야 하며, 키가 설정되지 않으면 모든 요청을 거부합니다.
+func ReceiveCommandAck(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	if !utils.ValidNaracontrolKey(r.Header.Get("X-Internal-Key")) {
+		log.Printf("내부 API 인증 실패 - 원격 주소: %s", r.RemoteAddr)
+		http.Error(w, "인증에 실패했습니다", http.StatusUnauthorized)
+		return
+	}
+
+	var ack utils.ControlAck
+	if err := json.NewDecoder(r.Body).Decode(&ack); err != nil {
+		http.Error(w, "잘못된 요청 데이터", http.StatusBadRequest)
+		return
+	}
+	if !strings.HasPrefix(ack.EventID, POWER_EVENT_ID_PREFIX) {
+		http.Error(w, "알 수 없는 이벤트ID입니다", http.StatusBadRequest)
+		return
+	}
+	id, err := strconv.ParseInt(strings.TrimPrefix(ack.EventID, POWER_EVENT_ID_PREFIX), 10, 64)
+	if err != nil {
+		http.Error(w, "알 수 없는 이벤트ID입니다", http.StatusBadRequest)
+		return
+	}
+
+	status := POWER_STATUS_ACKNOWLEDGED
+	if ack.Status != utils.CONTROL_ACK_OK {
+		status = POWER_STATUS_REJECTED
+	}
+
+	tx, err := utils.DB.BeginTx(ctx, nil)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	// 같은 명령을 여러 장치가 받을 수 있으므로 첫 응답만 기록합니다.
+	// 처리 완료 응답이면 바뀐 전원 상태를 같은 트랜잭션으로 outbox에 기록하여 데스크에 알립니다.
+	var event PowerEvent
+	err = scanPowerEvent(tx.QueryRowContext(ctx, `
+		UPDATE power_event_table SET status = $3, ack_status = $4, ack_message = $5, acked_at = CURRENT_TIMESTAMP
+		WHERE serial_number = $1 AND company_code = $2 AND acked_at IS NULL
+		RETURNING `+powerEventColumns,
+		id, ack.Message.CompanyCode, status, ack.Status, nullableString(ack.Detail)), &event)
+	if err == nil && status == POWER_STATUS_ACKNOWLEDGED {
+		err = writePowerStateChangeTx(ctx, tx, event)
+	}
+	if err == sql.ErrNoRows {
+		err = scanPowerEvent(tx.QueryRowContext(ctx,
+			"SELECT "+powerEventColumns+" FROM power_event_table WHERE serial_number = $1 AND company_code = $2",
+			id, ack.Message.CompanyCode), &event)
+	}
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "전원 이벤트를 찾을 수 없습니다.", http.StatusNotFound)
+		} else {
+			log.Printf("장치 응답 기록 오류: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	log.Printf("전원 명령 장치 응답: company_code=%s, event=%d, ack=%s", event.CompanyCode, event.SerialNumber, ack.Status)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(event)
+}
@@ -0,0 +1,467 @@
+// report.go
+package tables
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"narabackend/src/consts"
+	"narabackend/src/reports"
+	"narabackend/src/utils"
+)
+
+// 보고서 생성 상태
+const (
+	REPORT_STATUS_QUEUED    = "queued"    // 생성 대기
+	REPORT_STATUS_RUNNING   = "running"   // 생성 중
+	REPORT_STATUS_COMPLETED = "completed" // 생성 완료(다운로드 가능)
+	REPORT_STATUS_FAILED    = "failed"    // 생성 실패
+)
+
+// REPORT_SYNC_MAX_DAYS보다 긴 기간은 요청 즉시 생성하지 않고 비동기로 생성합니다.
+const REPORT_SYNC_MAX_DAYS = 31
+
+// REPORT_MAX_RANGE_DAYS는 보고서 한 건에서 조회할 수 있는 최대 일수입니다.
+const REPORT_MAX_RANGE_DAYS = 731
+
+// ReportRun 구조체는 report_run_table의 각 컬럼(파일 내용 제외)을 매핑합니다.
+type ReportRun struct {
+	SerialNumber int64      `json:"serial_number" db:"serial_number"`
+	CompanyCode  string     `json:"company_code" db:"company_code"`
+	ReportName   string     `json:"report_name" db:"report_name"`
+	Format       string     `json:"format" db:"format"`
+	RangeFrom    string     `json:"range_from" db:"range_from"`
+	RangeTo      string     `json:"range_to" db:"range_to"`
+	Status       string     `json:"status" db:"status"`
+	RowCount     *int       `json:"row_count" db:"row_count"`
+	FileName     string     `json:"file_name" db:"file_name"`
+	ErrorMessage string     `json:"error_message" db:"error_message"`
+	RequestedBy  string     `json:"requested_by" db:"requested_by"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	StartedAt    *time.Time `json:"started_at" db:"started_at"`
+	FinishedAt   *time.Time `json:"finished_at" db:"finished_at"`
+}
+
+// ReportRunRequest는 비동기 보고서 생성 요청 시 사용되는 구조체입니다.
+// month(YYYY-MM)를 지정하면 from/to 대신 해당 월 전체를 사용합니다.
+type ReportRunRequest struct {
+	From        string `json:"from"`
+	To          string `json:"to"`
+	Month       string `json:"month"`
+	Format      string `json:"format"`
+	RequestedBy string `json:"requested_by"`
+}
+
+// reportRunColumns는 report_run_table 조회 시 사용하는 컬럼 목록입니다.
+const reportRunColumns = `serial_number, company_code, report_name, format,
+	to_char(range_from, 'YYYY-MM-DD'), to_char(range_to, 'YYYY-MM-DD'), status, row_count,
+	COALESCE(file_name, ''), COALESCE(error_message, ''), COALESCE(requested_by, ''),
+	created_at, started_at, finished_at`
+
+// scanReportRun은 한 행을 ReportRun으로 읽습니다.
+func scanReportRun(row interface{ Scan(...interface{}) error }, run *ReportRun) error {
+	return row.Scan(&run.SerialNumber, &run.CompanyCode, &run.ReportName, &run.Format,
+		&run.RangeFrom, &run.RangeTo, &run.Status, &run.RowCount,
+		&run.FileName, &run.ErrorMessage, &run.RequestedBy,
+		&run.CreatedAt, &run.StartedAt, &run.FinishedAt)
+}
+
+// parseReportParams는 보고서 기간과 형식을 검증합니다.
+// month(YYYY-MM)가 있으면 해당 월 전체, from/to가 모두 없으면 이번 달을 사용합니다.
+func parseReportParams(companyCode, from, to, month, format string) (reports.Params, string, string) {
+	p := reports.Params{CompanyCode: companyCode}
+	if format == "" {
+		format = reports.FORMAT_JSON
+	}
+	if _, err := reports.ContentType(format); err != nil {
+		return p, format, "format은 json, csv, xlsx 중 하나여야 합니다"
+	}
+
+	switch {
+	case month != "":
+		t, err := time.ParseInLocation("2006-01", month, time.Local)
+		if err != nil {
+			return p, format, "잘못된 month 형식입니다 (YYYY-MM)"
+		}
+		p.From, p.To = t, t.AddDate(0, 1, -1)
+	case from == "" && to == "":
+		y, m, _ := time.Now().Date()
+		p.From = time.Date(y, m, 1, 0, 0, 0, 0, time.Local)
+		p.To = p.From.AddDate(0, 1, -1)
+	default:
+		var err error
+		if p.From, err = time.ParseInLocation("2006-01-02", from, time.Local); err != nil {
+			return p, format, "잘못된 from 형식입니다 (YYYY-MM-DD)"
+		}
+		if p.To, err = time.ParseInLocation("2006-01-02", to, time.Local); err != nil {
+			return p, format, "잘못된 to 형식입니다 (YYYY-MM-DD)"
+		}
+	}
+	if p.From.After(p.To) {
+		return p, format, "from은 to보다 늦을 수 없습니다"
+	}
+	if p.Days() > REPORT_MAX_RANGE_DAYS {
+		return p, format, "조회 기간이 너무 깁니다"
+	}
+	return p, format, ""
+}
+
+// writeReport는 보고서 결과를 요청한 형식으로 씁니다.
+func writeReport(w io.Writer, d reports.Definition, p reports.Params, table reports.Table, format string) error {
+	switch format {
+	case reports.FORMAT_CSV:
+		return reports.WriteCSV(w, table)
+	case reports.FORMAT_XLSX:
+		return reports.WriteXLSX(w, d.Title, table)
+	}
+	return json.NewEncoder(w).Encode(map[string]interface{}{
+		"report":       d.Name,
+		"title":        d.Title,
+		"company_code": p.CompanyCode,
+		"from":         p.From.Format("2006-01-02"),
+		"to":           p.To.Format("2006-01-02"),
+		"columns":      table.Columns,
+		"rows":         table.Rows,
+	})
+}
+
+// enqueueReportRun은 보고서 생성 요청을 저장하고 같은 트랜잭션에서 생성 작업(GenerateReport)을 큐에 넣습니다.
+func enqueueReportRun(ctx context.Context, d reports.Definition, p reports.Params, format, requestedBy string) (ReportRun, error) {
+	var run ReportRun
+	tx, err := utils.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return run, err
+	}
+	defer tx.Rollback()
+
+	err = scanReportRun(tx.QueryRowContext(ctx, `
+		INSERT INTO report_run_table (company_code, report_name, format, range_from, range_to, status, requested_by, created_at)
+		VALUES ($1, $2, $3, $4::date, $5::date, $6, $7, CURRENT_TIMESTAMP)
+		RETURNING `+reportRunColumns,
+		p.CompanyCode, d.Name, format, p.From.Format("2006-01-02"), p.To.Format("2006-01-02"),
+		REPORT_STATUS_QUEUED, nullableString(requestedBy)), &run)
+	if err != nil {
+		return run, err
+	}
+	if _, err = utils.EnqueueJobTx(ctx, tx, reportRunJob(run.SerialNumber)); err != nil {
+		return run, err
+	}
+	return run, tx.Commit()
+}
+
+// reportRunJob은 보고서 요청 하나를 생성하는 작업입니다. 요청마다 한 번만 큐에 들어가도록 멱등 키를 둡니다.
+func reportRunJob(id int64) utils.Job {
+	return utils.Job{
+		Name:           JOB_GENERATE_REPORT,
+		Payload:        generateReportPayload{ReportRunID: id},
+		IdempotencyKey: fmt.Sprintf("report-run-%d", id),
+	}
+}
+
+// generateReportRun은 보고서 한 건을 생성하여 결과 파일을 저장합니다. GenerateReport 작업 처리기에서 호출합니다.
+// 이미 완료되었거나 실패한 요청이면 아무것도 하지 않습니다. 생성 중이던 요청(워커 중단 후 재시도)은 다시 생성합니다.
+// 보고서 자체의 오류는 실패로 기록하고 nil을 반환하며, DB 오류는 반환하여 작업을 재시도하게 합니다.
+func generateReportRun(ctx context.Context, id int64) error {
+	var run ReportRun
+	err := scanReportRun(utils.DB.QueryRowContext(ctx, `
+		UPDATE report_run_table SET status = $2, started_at = CURRENT_TIMESTAMP
+		WHERE serial_number = $1 AND status IN ($2, $3)
+		RETURNING `+reportRunColumns,
+		id, REPORT_STATUS_RUNNING, REPORT_STATUS_QUEUED), &run)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("보고서 생성 시작 오류 (id=%d): %w", id, err)
+	}
+
+	content, rowCount, fileName, err := buildReportRun(ctx, run)
+	if err != nil {
+		log.Printf("보고서 생성 실패 (id=%d, report=%s): %v", id, run.ReportName, err)
+		_, err = utils.DB.ExecContext(context.Background(), `
+			UPDATE report_run_table SET status = $2, error_message = $3, finished_at = CURRENT_TIMESTAMP
+			WHERE serial_number = $1`, id, REPORT_STATUS_FAILED, err.Error())
+		if err != nil {
+			return fmt.Errorf("보고서 실패 기록 오류 (id=%d): %w", id, err)
+		}
+		return nil
+	}
+
+	contentType, _ := reports.ContentType(run.Format)
+	_, err = utils.DB.ExecContext(ctx, `
+		UPDATE report_run_table
+		SET status = $2, row_count = $3, content = $4, content_type = $5, file_name = $6, finished_at = CURRENT_TIMESTAMP
+		WHERE serial_number = $1`,
+		id, REPORT_STATUS_COMPLETED, rowCount, content, contentType, fileName)
+	if err != nil {
+		return fmt.Errorf("보고서 저장 오류 (id=%d): %w", id, err)
+	}
+	log.Printf("보고서 생성 완료 (id=%d, report=%s, company_code=%s, rows=%d)", id, run.ReportName, run.CompanyCode, rowCount)
+	return nil
+}
+
+// buildReportRun은 저장된 요청 조건으로 보고서를 실행하여 파일 내용을 만듭니다.
+func buildReportRun(ctx context.Context, run ReportRun) ([]byte, int, string, error) {
+	d, err := reports.Get(run.ReportName)
+	if err != nil {
+		return nil, 0, "", err
+	}
+	p, format, msg := parseReportParams(run.CompanyCode, run.RangeFrom, run.RangeTo, "", run.Format)
+	if msg != "" {
+		return nil, 0, "", fmt.Errorf("%s", msg)
+	}
+	table, err := d.Run(ctx, utils.DB, p)
+	if err != nil {
+		return nil, 0, "", err
+	}
+	var buf bytes.Buffer
+	if err := writeReport(&buf, d, p, table, format); err != nil {
+		return nil, 0, "", err
+	}
+	return buf.Bytes(), len(table.Rows), reports.FileName(d, p, format), nil
+}
+
+// ResumeReportRuns는 작업 큐 도입 전에 저장되어 생성 작업이 없는 보고서 요청(대기/생성 중)을 큐에 넣습니다.
+// 이미 작업이 있는 요청은 멱등 키로 걸러지므로 서버를 시작할 때마다 호출해도 됩니다.
+func ResumeReportRuns() {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	rows, err := utils.DB.QueryContext(ctx, `
+		SELECT serial_number FROM report_run_table WHERE status IN ($1, $2)`,
+		REPORT_STATUS_QUEUED, REPORT_STATUS_RUNNING)
+	if err != nil {
+		log.Printf("중단된 보고서 조회 오류: %v", err)
+		return
+	}
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			log.Printf("행 스캔 오류: %v", err)
+			return
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+
+	for _, id := range ids {
+		if _, err := utils.EnqueueJobContext(ctx, reportRunJob(id)); err != nil {
+			log.Printf("보고서 생성 작업 등록 오류 (id=%d): %v", id, err)
+		}
+	}
+}
+
+// RegisterReportRoutes는 보고서 관련 엔드포인트를 등록합니다.
+// 모든 보고서와 생성 결과는 경로의 company_code로 범위가 제한됩니다.
+func RegisterReportRoutes(r *mux.Router) {
+	r.HandleFunc("/reports", GetReportDefinitions).Methods("GET")
+	r.HandleFunc("/companies/{company_code}/reports/{name}", GetReport).Methods("GET")
+	r.HandleFunc("/companies/{company_code}/reports/{name}/runs", CreateReportRun).Methods("POST")
+	r.HandleFunc("/companies/{company_code}/report-runs", GetReportRuns).Methods("GET")
+	r.HandleFunc("/companies/{company_code}/report-runs/{id:[0-9]+}", GetReportRun).Methods("GET")
+	r.HandleFunc("/companies/{company_code}/report-runs/{id:[0-9]+}/download", DownloadReportRun).Methods("GET")
+}
+
+// GetReportDefinitions: 사용할 수 있는 보고서 목록을 조회합니다.
+func GetReportDefinitions(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(reports.List())
+}
+
+// GetReport: 보고서를 바로 생성하여 반환합니다.
+// from/to(YYYY-MM-DD) 또는 month(YYYY-MM), format(json, csv, xlsx) 쿼리 파라미터를 사용합니다.
+// 기간이 REPORT_SYNC_MAX_DAYS보다 길거나 async=true이면 비동기로 생성하고 202와 요청 정보를 반환합니다.
+func GetReport(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.LONG_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	vars := mux.Vars(r)
+	d, err := reports.Get(vars["name"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	q := r.URL.Query()
+	p, format, msg := parseReportParams(vars["company_code"], q.Get("from"), q.Get("to"), q.Get("month"), q.Get("format"))
+	if msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+
+	if p.Days() > REPORT_SYNC_MAX_DAYS || q.Get("async") == "true" {
+		run, err := enqueueReportRun(ctx, d, p, format, q.Get("requested_by"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeReportRunAccepted(w, run)
+		return
+	}
+
+	table, err := d.Run(ctx, utils.DB, p)
+	if err != nil {
+		log.Printf("보고서 조회 오류 (report=%s): %v", d.Name, err)
+		http.Error(w, "데이터 조회 중 오류가 발생했습니다", http.StatusInternalServerError)
+		return
+	}
+	contentType, _ := reports.ContentType(format)
+	w.Header().Set("Content-Type", contentType)
+	if format != reports.FORMAT_JSON {
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, reports.FileName(d, p, format)))
+	}
+	if err := writeReport(w, d, p, table, format); err != nil {
+		log.Printf("보고서 출력 오류 (report=%s): %v", d.Name, err)
+	}
+}
+
+// CreateReportRun: 보고서 비동기 생성을 요청합니다. 생성 상태는 report-runs로 조회합니다.
+func CreateReportRun(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	vars := mux.Vars(r)
+	d, err := reports.Get(vars["name"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	var req ReportRunRequest
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "잘못된 요청 데이터", http.StatusBadRequest)
+			return
+		}
+	}
+	p, format, msg := parseReportParams(vars["company_code"], req.From, req.To, req.Month, req.Format)
+	if msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+
+	run, err := enqueueReportRun(ctx, d, p, format, req.RequestedBy)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeReportRunAccepted(w, run)
+}
+
+// writeReportRunAccepted는 비동기 생성 요청 결과를 202로 응답합니다.
+func writeReportRunAccepted(w http.ResponseWriter, run ReportRun) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", fmt.Sprintf("/companies/%s/report-runs/%d", run.CompanyCode, run.SerialNumber))
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(run)
+}
+
+// GetReportRuns: 업체의 보고서 생성 요청 목록을 조회합니다. status, report_name으로 필터링합니다.
+func GetReportRuns(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	query := "SELECT " + reportRunColumns + " FROM report_run_table WHERE company_code = $1"
+	args := []interface{}{mux.Vars(r)["company_code"]}
+	for _, param := range []string{"status", "report_name"} {
+		if value := r.URL.Query().Get(param); value != "" {
+			args = append(args, value)
+			query += fmt.Sprintf(" AND %s = $%d", param, len(args))
+		}
+	}
+	query += " ORDER BY serial_number DESC LIMIT 100"
+
+	rows, err := utils.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("데이터베이스 쿼리 오류: %v", err)
+		http.Error(w, "데이터 조회 중 오류가 발생했습니다", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	result := []ReportRun{}
+	for rows.Next() {
+		var run ReportRun
+		if err := scanReportRun(rows, &run); err != nil {
+			log.Printf("행 스캔 오류: %v", err)
+			http.Error(w, "데이터 처리 중 오류가 발생했습니다", http.StatusInternalServerError)
+			return
+		}
+		result = append(result, run)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// GetReportRun: 보고서 생성 요청의 상태를 조회합니다.
+func GetReportRun(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	vars := mux.Vars(r)
+	id, _ := strconv.ParseInt(vars["id"], 10, 64)
+	var run ReportRun
+	err := scanReportRun(utils.DB.QueryRowContext(ctx,
+		"SELECT "+reportRunColumns+" FROM report_run_table WHERE serial_number = $1 AND company_code = $2",
+		id, vars["company_code"]), &run)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "보고서 요청을 찾을 수 없습니다.", http.StatusNotFound)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(run)
+}
+
+// DownloadReportRun: 생성이 완료된 보고서 파일을 내려받습니다.
+func DownloadReportRun(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.LONG_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	vars := mux.Vars(r)
+	id, _ := strconv.ParseInt(vars["id"], 10, 64)
+	var status string
+	var content []byte
+	var contentType, fileName sql.NullString
+	err := utils.DB.QueryRowContext(ctx, `
+		SELECT status, content, content_type, file_name
+		FROM report_run_table WHERE serial_number = $1 AND company_code = $2`,
+		id, vars["company_code"]).Scan(&status, &content, &contentType, &fileName)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "보고서 요청을 찾을 수 없습니다.", http.StatusNotFound)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	if status != REPORT_STATUS_COMPLETED {
+		http.Error(w, fmt.Sprintf("아직 내려받을 수 없는 보고서입니다: 상태=%s", status), http.StatusConflict)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType.String)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, fileName.String))
+	w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+	w.Write(content)
+}
@@ -0,0 +1,453 @@
+// reservation.go
+package tables
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/lib/pq"
+
+	"narabackend/src/consts"
+	"narabackend/src/utils"
+)
+
+// 예약 대상 구분
+const (
+	RESOURCE_TYPE_SEAT = "seat" // 좌석 (seat_code)
+	RESOURCE_TYPE_ROOM = "room" // 스터디룸/회의실 (room_code)
+)
+
+// 예약 상태 (naradesk SeatStatus.reserved와 대응)
+const (
+	RESERVATION_STATUS_RESERVED   = "reserved"
+	RESERVATION_STATUS_CHECKED_IN = "checked_in"
+	RESERVATION_STATUS_COMPLETED  = "completed"
+	RESERVATION_STATUS_CANCELLED  = "cancelled"
+	RESERVATION_STATUS_NO_SHOW    = "no_show"
+)
+
+// Reservation 구조체는 reservation_table의 각 컬럼을 매핑합니다.
+type Reservation struct {
+	SerialNumber  int64      `json:"serial_number" db:"serial_number"`
+	CompanyCode   string     `json:"company_code" db:"company_code"`
+	ResourceType  string     `json:"resource_type" db:"resource_type"`
+	ResourceCode  int        `json:"resource_code" db:"resource_code"`
+	MemberID      *int64     `json:"member_id" db:"member_id"`
+	StartAt       time.Time  `json:"start_at" db:"start_at"`
+	EndAt         time.Time  `json:"end_at" db:"end_at"`
+	Status        string     `json:"status" db:"status"`
+	CheckedInAt   *time.Time `json:"checked_in_at" db:"checked_in_at"`
+	SeatSessionID *int64     `json:"seat_session_id" db:"seat_session_id"`
+	CancelledAt   *time.Time `json:"cancelled_at" db:"cancelled_at"`
+	CancelReason  string     `json:"cancel_reason" db:"cancel_reason"`
+	Memo          string     `json:"memo" db:"memo"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// ReservationRequest는 예약 생성 요청 시 사용되는 구조체입니다.
+type ReservationRequest struct {
+	CompanyCode  string    `json:"company_code"`
+	ResourceType string    `json:"resource_type"`
+	ResourceCode int       `json:"resource_code"`
+	MemberID     *int64    `json:"member_id"`
+	StartAt      time.Time `json:"start_at"`
+	EndAt        time.Time `json:"end_at"`
+	Memo         string    `json:"memo"`
+}
+
+// ReservationCheckInRequest는 예약 입실 확인 요청 시 사용되는 구조체입니다.
+// 좌석 예약은 예약 회원의 이용권(pass_id)으로 좌석 세션을 함께 시작합니다.
+type ReservationCheckInRequest struct {
+	PassID int64 `json:"pass_id"`
+}
+
+// CancelReservationRequest는 예약 취소 요청 시 사용되는 구조체입니다.
+type CancelReservationRequest struct {
+	Reason string `json:"reason"`
+}
+
+// reservationColumns는 reservation_table 조회 시 사용하는 컬럼 목록입니다.
+const reservationColumns = `serial_number, company_code, resource_type, resource_code, member_id,
+	start_at, end_at, status, checked_in_at, seat_session_id, cancelled_at, COALESCE(cancel_reason, ''), COALESCE(memo, ''),
+	created_at, updated_at`
+
+// scanReservation은 한 행을 Reservation으로 읽습니다.
+func scanReservation(row interface{ Scan(...interface{}) error }, rv *Reservation) error {
+	return row.Scan(&rv.SerialNumber, &rv.CompanyCode, &rv.ResourceType, &rv.ResourceCode, &rv.MemberID,
+		&rv.StartAt, &rv.EndAt, &rv.Status, &rv.CheckedInAt, &rv.SeatSessionID, &rv.CancelledAt, &rv.CancelReason, &rv.Memo,
+		&rv.CreatedAt, &rv.UpdatedAt)
+}
+
+// isReservationOverlap은 exclusion constraint(reservation_no_overlap) 위반(23P01) 여부를 확인합니다.
+func isReservationOverlap(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23P01"
+}
+
+// cancelNoShowReservations는 시작 후 유예 시간이 지나도 입실하지 않은 예약을 no_show로 취소합니다.
+// 좌석 예약이 취소되면 해당 좌석을 열람실 대기열에 제안합니다.
+func cancelNoShowReservations(ctx context.Context, now time.Time) error {
+	grace := time.Duration(consts.RESERVATION_NO_SHOW_GRACE_MINUTES) * time.Minute
+	rows, err := utils.DB.QueryContext(ctx, `
+		UPDATE reservation_table
+		SET status = $1, cancelled_at = $2, cancel_reason = 'no_show', updated_at = CURRENT_TIMESTAMP
+		WHERE status = $3 AND start_at <= $4
+		RETURNING company_code, resource_type, resource_code`,
+		RESERVATION_STATUS_NO_SHOW, now, RESERVATION_STATUS_RESERVED, now.Add(-grace))
+	if err != nil {
+		return err
+	}
+	type freedSeat struct {
+		companyCode string
+		seatCode    int
+	}
+	var freed []freedSeat
+	for rows.Next() {
+		var companyCode, resourceType string
+		var resourceCode int
+		if err := rows.Scan(&companyCode, &resourceType, &resourceCode); err != nil {
+			rows.Close()
+			return err
+		}
+		log.Printf("예약 노쇼 자동 취소: company_code=%s, %s=%d", companyCode, resourceType, resourceCode)
+		if resourceType == RESOURCE_TYPE_SEAT {
+			freed = append(freed, freedSeat{companyCode, resourceCode})
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, seat := range freed {
+		offerFreedSeat(ctx, seat.companyCode, seat.seatCode, now)
+	}
+	return nil
+}
+
+// runReservationMaintenance는 스케줄러에서 호출되어 노쇼 취소, 종료된 예약 완료 처리, 대기열 제안 만료를 수행합니다.
+func runReservationMaintenance(ctx context.Context, now time.Time) error {
+	if err := cancelNoShowReservations(ctx, now); err != nil {
+		return err
+	}
+	_, err := utils.DB.ExecContext(ctx, `
+		UPDATE reservation_table SET status = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE status = $2 AND end_at <= $3`,
+		RESERVATION_STATUS_COMPLETED, RESERVATION_STATUS_CHECKED_IN, now)
+	if err != nil {
+		return err
+	}
+	return expireWaitingOffers(ctx, now)
+}
+
+// RegisterReservationRoutes는 reservation_table 관련 엔드포인트를 등록합니다.
+func RegisterReservationRoutes(r *mux.Router) {
+	r.HandleFunc("/reservations", GetReservations).Methods("GET")
+	r.HandleFunc("/reservations/{id:[0-9]+}", GetReservation).Methods("GET")
+	r.HandleFunc("/reservations", CreateReservation).Methods("POST")
+	r.HandleFunc("/reservations/{id:[0-9]+}/check-in", CheckInReservation).Methods("POST")
+	r.HandleFunc("/reservations/{id:[0-9]+}/cancel", CancelReservation).Methods("POST")
+}
+
+// GetReservations: 예약 목록을 조회합니다.
+// company_code, resource_type, resource_code, member_id, status 쿼리 파라미터로 필터링하며,
+// from/to(RFC3339)를 주면 해당 구간과 겹치는 예약만 반환합니다.
+func GetReservations(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	// 필터링 조건 처리
+	filters := []string{}
+	args := []interface{}{}
+	paramIdx := 1
+
+	filterParams := map[string]string{
+		"company_code":  "company_code",
+		"resource_type": "resource_type",
+		"resource_code": "resource_code",
+		"member_id":     "member_id",
+		"status":        "status",
+	}
+	for param, dbField := range filterParams {
+		if value := r.URL.Query().Get(param); value != "" {
+			filters = append(filters, fmt.Sprintf("%s = $%d", dbField, paramIdx))
+			args = append(args, value)
+			paramIdx++
+		}
+	}
+	if from := r.URL.Query().Get("from"); from != "" {
+		t, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			http.Error(w, "잘못된 from 형식입니다 (RFC3339)", http.StatusBadRequest)
+			return
+		}
+		filters = append(filters, fmt.Sprintf("end_at > $%d", paramIdx))
+		args = append(args, t)
+		paramIdx++
+	}
+	if to := r.URL.Query().Get("to"); to != "" {
+		t, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			http.Error(w, "잘못된 to 형식입니다 (RFC3339)", http.StatusBadRequest)
+			return
+		}
+		filters = append(filters, fmt.Sprintf("start_at < $%d", paramIdx))
+		args = append(args, t)
+		paramIdx++
+	}
+
+	query := "SELECT " + reservationColumns + " FROM reservation_table"
+	if len(filters) > 0 {
+		query += " WHERE " + strings.Join(filters, " AND ")
+	}
+	query += " ORDER BY start_at ASC, serial_number ASC"
+
+	rows, err := utils.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("데이터베이스 쿼리 오류: %v", err)
+		http.Error(w, "데이터 조회 중 오류가 발생했습니다", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	result := []Reservation{}
+	for rows.Next() {
+		var rv Reservation
+		if err := scanReservation(rows, &rv); err != nil {
+			log.Printf("행 스캔 오류: %v", err)
+			http.Error(w, "데이터 처리 중 오류가 발생했습니다", http.StatusInternalServerError)
+			return
+		}
+		result = append(result, rv)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// GetReservation: 단일 예약을 조회합니다.
+func GetReservation(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	id, _ := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	var rv Reservation
+	err := scanReservation(utils.DB.QueryRowContext(ctx,
+		"SELECT "+reservationColumns+" FROM reservation_table WHERE serial_number = $1", id), &rv)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "예약을 찾을 수 없습니다.", http.StatusNotFound)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rv)
+}
+
+// CreateReservation: 좌석/룸의 시간대 예약을 생성합니다.
+// 같은 대상의 유효한 예약과 시간대가 겹치면 DB 제약조건에 의해 409를 반환합니다.
+func CreateReservation(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	var req ReservationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "잘못된 요청 데이터", http.StatusBadRequest)
+		return
+	}
+
+	// 필수 필드 검증
+	if req.CompanyCode == "" || req.ResourceCode == 0 || req.StartAt.IsZero() || req.EndAt.IsZero() {
+		http.Error(w, "필수 필드가 누락되었습니다 (company_code, resource_type, resource_code, start_at, end_at)", http.StatusBadRequest)
+		return
+	}
+	if req.ResourceType != RESOURCE_TYPE_SEAT && req.ResourceType != RESOURCE_TYPE_ROOM {
+		http.Error(w, "resource_type은 seat 또는 room이어야 합니다", http.StatusBadRequest)
+		return
+	}
+	if !req.EndAt.After(req.StartAt) {
+		http.Error(w, "end_at은 start_at 이후여야 합니다", http.StatusBadRequest)
+		return
+	}
+	if req.EndAt.Before(time.Now()) {
+		http.Error(w, "이미 지난 시간대는 예약할 수 없습니다", http.StatusBadRequest)
+		return
+	}
+
+	log.Printf("예약 생성 요청 시작: %+v", req)
+	var rv Reservation
+	err := scanReservation(utils.DB.QueryRowContext(ctx, `
+		INSERT INTO reservation_table
+		(company_code, resource_type, resource_code, member_id, start_at, end_at, status, memo, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		RETURNING `+reservationColumns,
+		req.CompanyCode, req.ResourceType, req.ResourceCode, req.MemberID, req.StartAt, req.EndAt,
+		RESERVATION_STATUS_RESERVED, req.Memo), &rv)
+	if err != nil {
+		switch {
+		case isReservationOverlap(err):
+			http.Error(w, "이미 예약된 시간대입니다", http.StatusConflict)
+		case strings.Contains(err.Error(), "foreign key"):
+			http.Error(w, "존재하지 않는 회원입니다", http.StatusBadRequest)
+		default:
+			log.Printf("예약 생성 오류: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(rv)
+}
+
+// CheckInReservation: 예약 회원의 입실을 확인합니다. 예약 시작 RESERVATION_CHECK_IN_EARLY_MINUTES분 전부터
+// 종료 전까지, 노쇼 처리 전에만 가능합니다. 좌석 예약은 예약 회원의 이용권(pass_id)으로 좌석 세션을 함께 시작하고
+// 예약에 세션 번호를 기록합니다.
+func CheckInReservation(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	id, _ := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	var req ReservationCheckInRequest
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "잘못된 요청 데이터", http.StatusBadRequest)
+			return
+		}
+	}
+
+	tx, err := utils.DB.BeginTx(ctx, nil)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	var rv Reservation
+	err = scanReservation(tx.QueryRowContext(ctx,
+		"SELECT "+reservationColumns+" FROM reservation_table WHERE serial_number = $1 AND status = $2 FOR UPDATE",
+		id, RESERVATION_STATUS_RESERVED), &rv)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "입실 확인할 수 있는 예약이 없습니다 (없거나 이미 처리됨)", http.StatusConflict)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	now := time.Now()
+	early := time.Duration(consts.RESERVATION_CHECK_IN_EARLY_MINUTES) * time.Minute
+	if now.Before(rv.StartAt.Add(-early)) || !now.Before(rv.EndAt) {
+		http.Error(w, fmt.Sprintf("예약 시간(%s ~ %s)에만 입실할 수 있습니다",
+			rv.StartAt.Format("2006-01-02 15:04"), rv.EndAt.Format("2006-01-02 15:04")), http.StatusConflict)
+		return
+	}
+
+	var sessionID *int64
+	var session SeatSession
+	if rv.ResourceType == RESOURCE_TYPE_SEAT {
+		if req.PassID == 0 {
+			http.Error(w, "좌석 예약은 입실할 이용권(pass_id)이 필요합니다", http.StatusBadRequest)
+			return
+		}
+		var passMemberID int64
+		err := tx.QueryRowContext(ctx, "SELECT member_id FROM member_pass_table WHERE serial_number = $1", req.PassID).
+			Scan(&passMemberID)
+		if err != nil {
+			writeCheckInError(w, err)
+			return
+		}
+		if rv.MemberID == nil || *rv.MemberID != passMemberID {
+			http.Error(w, "예약 회원의 이용권으로만 입실할 수 있습니다", http.StatusBadRequest)
+			return
+		}
+		session, err = checkInTx(ctx, tx, req.PassID, rv.ResourceCode, now)
+		if err != nil {
+			writeCheckInError(w, err)
+			return
+		}
+		sessionID = &session.SerialNumber
+	}
+
+	err = scanReservation(tx.QueryRowContext(ctx, `
+		UPDATE reservation_table
+		SET status = $2, checked_in_at = $3, seat_session_id = $4, updated_at = CURRENT_TIMESTAMP
+		WHERE serial_number = $1
+		RETURNING `+reservationColumns,
+		id, RESERVATION_STATUS_CHECKED_IN, now, sessionID), &rv)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if sessionID != nil {
+		// 좌석 전원 규칙에 따라 좌석 전원을 켭니다.
+		applySeatOccupancyPower(ctx, session.CompanyCode, session.SeatCode, true)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rv)
+}
+
+// CancelReservation: 예약을 취소합니다. 좌석 예약이면 해당 좌석을 열람실 대기열에 제안합니다.
+func CancelReservation(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	id, _ := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	var req CancelReservationRequest
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "잘못된 요청 데이터", http.StatusBadRequest)
+			return
+		}
+	}
+
+	var rv Reservation
+	err := scanReservation(utils.DB.QueryRowContext(ctx, `
+		UPDATE reservation_table
+		SET status = $2, cancelled_at = CURRENT_TIMESTAMP, cancel_reason = $3, updated_at = CURRENT_TIMESTAMP
+		WHERE serial_number = $1 AND status IN ($4, $5)
+		RETURNING `+reservationColumns,
+		id, RESERVATION_STATUS_CANCELLED, req.Reason, RESERVATION_STATUS_RESERVED, RESERVATION_STATUS_CHECKED_IN), &rv)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "취소할 수 있는 예약이 없습니다 (없거나 이미 처리됨)", http.StatusConflict)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	// 이용 중인 시간대의 좌석 예약이 취소된 경우에만 좌석이 비게 됩니다.
+	now := time.Now()
+	if rv.ResourceType == RESOURCE_TYPE_SEAT && !rv.StartAt.After(now) && rv.EndAt.After(now) {
+		offerFreedSeat(ctx, rv.CompanyCode, rv.ResourceCode, now)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rv)
+}
@@ -0,0 +1,26 @@
+// reservation_test.go
+package tables
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestIsReservationOverlap(t *testing.T) {
+	overlap := &pq.Error{Code: "23P01", Message: "conflicting key value violates exclusion constraint"}
+	if !isReservationOverlap(overlap) || !isReservationOverlap(fmt.Errorf("예약 생성: %w", overlap)) {
+		t.Fatal("23P01 오류가 예약 중복으로 판정되지 않았습니다")
+	}
+	for _, err := range []error{
+		nil,
+		errors.New("exclusion constraint"),
+		&pq.Error{Code: "23505", Message: "duplicate key value violates unique constraint"},
+	} {
+		if isReservationOverlap(err) {
+			t.Errorf("%v가 예약 중복으로 판정되었습니다", err)
+		}
+	}
+}
@@ -317,27 +317,23 @@ func UpdateRoom(w http.ResponseWriter, r *http.Request) {
 
 	query := "UPDATE room_table SET " + strings.Join(updates, ", ") + " WHERE room_code = $" + strconv.Itoa(idx)
 	args = append(args, roomCode)
-	_, err = utils.DB.ExecContext(ctx, query, args...)
+	// 변경과 변경 이벤트(outbox)를 같은 트랜잭션으로 기록합니다.
+	tx, err := utils.DB.BeginTx(ctx, nil)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	defer tx.Rollback()
 
-	// 업데이트 후 비동기 작업 큐에 작업을 넣어 (예: room 업데이트 알림) 백그라운드 처리를 수행합니다.
-	job := utils.Job{
-		Name: "RoomUpdated",
-		Data: map[string]interface{}{
-			"room_code": roomCode,
-			"time":      time.Now(),
-		},
-	}
-	if utils.EnqueueJobHandler != nil {
-		utils.EnqueueJobHandler(job)
+	_, err = tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
 
 	// 업데이트된 room을 조회하여 반환합니다.
 	var room Room
-	err = utils.DB.QueryRowContext(ctx, `
+	err = tx.QueryRowContext(ctx, `
 		SELECT auto_increment, company_code, room_code, room_title,
 		       title_background_color, title_text_color, room_background_color,
 		       room_top, room_left, room_width, room_height,
@@ -355,6 +351,27 @@ func UpdateRoom(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	if err := writeRoomChangeTx(ctx, tx, strconv.Itoa(room.CompanyCode), room.RoomCode); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// 업데이트 후 비동기 작업 큐에 작업을 넣어 (예: room 업데이트 알림) 백그라운드 처리를 수행합니다.
+	job := utils.Job{
+		Name: JOB_ROOM_UPDATED,
+		Data: map[string]interface{}{
+			"room_code": roomCode,
+			"time":      time.Now(),
+		},
+	}
+	if utils.EnqueueJobHandler != nil {
+		utils.EnqueueJobHandler(job)
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(room)
 }
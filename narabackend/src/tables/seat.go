@@ -403,27 +403,23 @@ func UpdateSeat(w http.ResponseWriter, r *http.Request) {
 
 	query := "UPDATE seat_table SET " + strings.Join(updates, ", ") + " WHERE seat_code = $" + strconv.Itoa(idx)
 	args = append(args, seatCode)
-	_, err = utils.DB.ExecContext(ctx, query, args...)
+	// 변경과 변경 이벤트(outbox)를 같은 트랜잭션으로 기록합니다.
+	tx, err := utils.DB.BeginTx(ctx, nil)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	defer tx.Rollback()
 
-	// 업데이트 후 비동기 작업 큐에 작업을 넣어 (예: seat 업데이트 알림) 백그라운드 처리를 수행합니다.
-	job := utils.Job{
-		Name: "SeatUpdated",
-		Data: map[string]interface{}{
-			"seat_code": seatCode,
-			"time":      time.Now(),
-		},
-	}
-	if utils.EnqueueJobHandler != nil {
-		utils.EnqueueJobHandler(job)
+	_, err = tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
 
 	// 업데이트된 seat을 조회하여 반환합니다.
 	var seat Seat
-	err = utils.DB.QueryRowContext(ctx, `
+	err = tx.QueryRowContext(ctx, `
         SELECT auto_increment, company_code, seat_code, seat_title,
                title_background_color, title_text_color, seat_background_color,
                seat_top, seat_left, seat_width, seat_height,
@@ -441,6 +437,27 @@ func UpdateSeat(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	if err := writeSeatChangeTx(ctx, tx, utils.CONTROL_EVENT_SEAT_UPDATED, changeTargetsAll, strconv.Itoa(seat.CompanyCode), seat.SeatCode, ""); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// 업데이트 후 비동기 작업 큐에 작업을 넣어 (예: seat 업데이트 알림) 백그라운드 처리를 수행합니다.
+	job := utils.Job{
+		Name: JOB_SEAT_UPDATED,
+		Data: map[string]interface{}{
+			"seat_code": seatCode,
+			"time":      time.Now(),
+		},
+	}
+	if utils.EnqueueJobHandler != nil {
+		utils.EnqueueJobHandler(job)
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(seat)
 }
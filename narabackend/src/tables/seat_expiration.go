@@ -0,0 +1,338 @@
+// seat_expiration.go
+package tables
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"narabackend/src/consts"
+	"narabackend/src/utils"
+)
+
+// SeatExpirationConfig는 좌석 자동 해제 스케줄러 설정입니다.
+type SeatExpirationConfig struct {
+	Interval time.Duration // 점검 주기
+	PowerOff bool          // 해제된 좌석의 전원을 naracontrol로 끌지 여부
+}
+
+// seatRelease는 자동 해제 대상 좌석입니다.
+// SessionID가 있으면 이용권 만료로 찾은 세션이고, 없으면 seat_table 기준으로 찾은 좌석입니다.
+type seatRelease struct {
+	CompanyCode string
+	SeatCode    int
+	SessionID   *int64
+	Reason      string
+}
+
+// releasedSeat는 해제가 완료된 좌석과 알림에 필요한 정보입니다.
+type releasedSeat struct {
+	seatRelease
+	RoomCode    *int
+	SeatNumber  *int
+	PowerNumber *int
+}
+
+// seatTableExpiredCondition은 seat_table에서 만료일시 또는 해제 예정일시(외출 복귀 기한)가 지난 좌석 조건입니다.
+const seatTableExpiredCondition = `member_id IS NOT NULL AND company_code IS NOT NULL AND seat_code IS NOT NULL AND (
+		(expiration_date IS NOT NULL AND expiration_date + COALESCE(expiration_time, TIME '23:59:59') <= $1)
+		OR (seat_release_datetime IS NOT NULL AND seat_release_datetime <= $1))`
+
+// StartSeatExpirationScheduler는 주기적으로 만료/외출 초과 좌석을 해제하는 백그라운드 작업을 시작합니다.
+func StartSeatExpirationScheduler(cfg SeatExpirationConfig) {
+	if cfg.Interval <= 0 {
+		log.Printf("좌석 자동 해제 스케줄러 비활성화 (interval=%s)", cfg.Interval)
+		return
+	}
+	log.Printf("좌석 자동 해제 스케줄러 시작 (interval=%s, power_off=%v)", cfg.Interval, cfg.PowerOff)
+
+	go func() {
+		ticker := time.NewTicker(cfg.Interval)
+		defer ticker.Stop()
+		for {
+			runSeatExpiration(cfg)
+			<-ticker.C
+		}
+	}()
+}
+
+// runSeatExpiration은 한 번의 점검(외출 규칙 평가, 예약/대기열 정리 포함)을 실행하고,
+// 해제된 좌석을 장치/데스크에 알린 뒤 열람실 대기열에 제안합니다.
+// 여러 서버가 같은 좌석을 중복으로 해제·알림하지 않도록 advisory lock을 잡은 서버 하나만 실행합니다.
+func runSeatExpiration(cfg SeatExpirationConfig) {
+	timeout := time.Duration(consts.LONG_WORK_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// advisory lock은 세션 단위이므로 잠금과 해제를 같은 연결에서 합니다.
+	conn, err := utils.DB.Conn(ctx)
+	if err != nil {
+		log.Printf("좌석 자동 해제 DB 연결 실패: %v", err)
+		return
+	}
+	defer conn.Close()
+	var locked bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", consts.SEAT_EXPIRATION_LOCK_KEY).Scan(&locked); err != nil || !locked {
+		if err != nil {
+			log.Printf("좌석 자동 해제 잠금 확인 실패: %v", err)
+		}
+		return
+	}
+	defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", consts.SEAT_EXPIRATION_LOCK_KEY)
+
+	now := time.Now()
+
+	// 외출 규칙을 먼저 평가하여 초과 좌석의 해제 기한을 설정합니다.
+	if err := evaluateOutingRules(ctx, now); err != nil {
+		log.Printf("외출 규칙 평가 오류: %v", err)
+	}
+
+	released, err := releaseExpiredSeats(ctx, now)
+	if err != nil {
+		log.Printf("좌석 자동 해제 오류: %v", err)
+	}
+	for _, seat := range released {
+		notifySeatReleased(ctx, seat, cfg.PowerOff)
+		pushCleaningLight(ctx, seat.CompanyCode, seat.SeatCode)
+		offerFreedSeat(ctx, seat.CompanyCode, seat.SeatCode, now)
+	}
+
+	// 예약 노쇼 취소와 대기열 제안 만료를 처리합니다.
+	if err := runReservationMaintenance(ctx, now); err != nil {
+		log.Printf("예약/대기열 정리 오류: %v", err)
+	}
+
+	// 만료된 사물함 대여를 정리하고 데스크와 웹훅으로 알립니다.
+	lockers, err := expireLockerRentals(ctx, now)
+	if err != nil {
+		log.Printf("사물함 대여 만료 처리 오류: %v", err)
+	}
+	for _, locker := range lockers {
+		notifyLockerExpired(ctx, locker)
+		queueWebhookEvent(ctx, locker.CompanyCode, WEBHOOK_EVENT_LOCKER_EXPIRED, locker)
+	}
+	if len(released) > 0 {
+		log.Printf("좌석 자동 해제 완료: %d석", len(released))
+	}
+}
+
+// releaseExpiredSeats는 이용권이 만료된 열린 세션과 seat_table 기준 만료 좌석을 찾아 해제합니다.
+// 좌석 하나의 해제가 실패해도 나머지 좌석은 계속 처리하며, 마지막 오류를 반환합니다.
+func releaseExpiredSeats(ctx context.Context, now time.Time) ([]releasedSeat, error) {
+	candidates, err := findExpiredSessions(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+	seatCandidates, err := findExpiredSeatRows(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+
+	// 같은 좌석이 양쪽에서 발견되면 세션 기준 항목을 우선합니다.
+	seen := make(map[string]bool)
+	for _, c := range candidates {
+		seen[fmt.Sprintf("%s:%d", c.CompanyCode, c.SeatCode)] = true
+	}
+	for _, c := range seatCandidates {
+		if !seen[fmt.Sprintf("%s:%d", c.CompanyCode, c.SeatCode)] {
+			candidates = append(candidates, c)
+		}
+	}
+
+	var released []releasedSeat
+	var lastErr error
+	for _, c := range candidates {
+		seat, ok, err := releaseSeat(ctx, c, now)
+		if err != nil {
+			log.Printf("좌석 해제 실패: company_code=%s, seat_code=%d, 오류: %v", c.CompanyCode, c.SeatCode, err)
+			lastErr = err
+			continue
+		}
+		if ok {
+			released = append(released, seat)
+		}
+	}
+	return released, lastErr
+}
+
+// findExpiredSessions는 이용권 잔여 시간 또는 유효기간이 지난 열린 세션을 찾습니다.
+func findExpiredSessions(ctx context.Context, now time.Time) ([]seatRelease, error) {
+	rows, err := utils.DB.QueryContext(ctx, `
+		SELECT s.serial_number, s.company_code, s.seat_code, s.check_in_at, p.remaining_minutes, p.valid_until
+		FROM seat_session_table s
+		JOIN member_pass_table p ON p.serial_number = s.pass_id
+		WHERE s.check_out_at IS NULL`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []seatRelease
+	for rows.Next() {
+		var c seatRelease
+		var sessionID int64
+		var checkInAt time.Time
+		var pass MemberPass
+		if err := rows.Scan(&sessionID, &c.CompanyCode, &c.SeatCode, &checkInAt,
+			&pass.RemainingMinutes, &pass.ValidUntil); err != nil {
+			return nil, err
+		}
+		if deadline := passDeadline(pass, checkInAt); deadline != nil && !deadline.After(now) {
+			c.SessionID = &sessionID
+			c.Reason = RELEASE_REASON_EXPIRED
+			result = append(result, c)
+		}
+	}
+	return result, rows.Err()
+}
+
+// findExpiredSeatRows는 seat_table의 만료일시 또는 해제 예정일시가 지난 사용 중 좌석을 찾습니다.
+func findExpiredSeatRows(ctx context.Context, now time.Time) ([]seatRelease, error) {
+	rows, err := utils.DB.QueryContext(ctx, `
+		SELECT company_code, seat_code, outing_datetime IS NOT NULL
+		FROM seat_table
+		WHERE `+seatTableExpiredCondition, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []seatRelease
+	for rows.Next() {
+		var c seatRelease
+		var onOuting bool
+		if err := rows.Scan(&c.CompanyCode, &c.SeatCode, &onOuting); err != nil {
+			return nil, err
+		}
+		c.Reason = RELEASE_REASON_EXPIRED
+		if onOuting {
+			c.Reason = RELEASE_REASON_OUTING_TIMEOUT
+		}
+		result = append(result, c)
+	}
+	return result, rows.Err()
+}
+
+// releaseSeat는 한 좌석을 하나의 트랜잭션에서 해제합니다.
+// 조회 이후 연장/퇴실된 좌석은 잠금 후 다시 확인하여 건너뜁니다(ok=false).
+func releaseSeat(ctx context.Context, c seatRelease, now time.Time) (releasedSeat, bool, error) {
+	seat := releasedSeat{seatRelease: c}
+
+	tx, err := utils.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return seat, false, err
+	}
+	defer tx.Rollback()
+
+	sessionID := c.SessionID
+	if sessionID != nil {
+		// 이용권 만료 여부를 잠금 상태에서 다시 확인합니다.
+		var checkInAt time.Time
+		var pass MemberPass
+		err = tx.QueryRowContext(ctx, `
+			SELECT s.check_in_at, p.remaining_minutes, p.valid_until
+			FROM seat_session_table s
+			JOIN member_pass_table p ON p.serial_number = s.pass_id
+			WHERE s.serial_number = $1 AND s.check_out_at IS NULL
+			FOR UPDATE OF s, p`, *sessionID).Scan(&checkInAt, &pass.RemainingMinutes, &pass.ValidUntil)
+		if err == sql.ErrNoRows {
+			return seat, false, nil
+		}
+		if err != nil {
+			return seat, false, err
+		}
+		if deadline := passDeadline(pass, checkInAt); deadline == nil || deadline.After(now) {
+			return seat, false, nil
+		}
+	} else {
+		// seat_table 기준 만료 여부를 잠금 상태에서 다시 확인합니다.
+		var locked bool
+		err = tx.QueryRowContext(ctx, `
+			SELECT true FROM seat_table
+			WHERE company_code = $2 AND seat_code = $3 AND `+seatTableExpiredCondition+`
+			LIMIT 1 FOR UPDATE`, now, c.CompanyCode, c.SeatCode).Scan(&locked)
+		if err == sql.ErrNoRows {
+			return seat, false, nil
+		}
+		if err != nil {
+			return seat, false, err
+		}
+
+		var openID int64
+		err = tx.QueryRowContext(ctx,
+			"SELECT serial_number FROM seat_session_table WHERE company_code = $1 AND seat_code = $2 AND check_out_at IS NULL",
+			c.CompanyCode, c.SeatCode).Scan(&openID)
+		if err == nil {
+			sessionID = &openID
+		} else if err != sql.ErrNoRows {
+			return seat, false, err
+		}
+	}
+
+	if sessionID != nil {
+		if _, err := checkOutTx(ctx, tx, *sessionID, c.Reason, now); err != nil {
+			return seat, false, err
+		}
+	}
+
+	// seat_table의 점유 정보를 비웁니다. seat_table에 행이 없는 좌석도 세션은 해제됩니다.
+	err = tx.QueryRowContext(ctx, `
+		UPDATE seat_table SET member_id = NULL, member_name = NULL, check_in_time = NULL,
+			outing_datetime = NULL, seat_release_datetime = NULL
+		WHERE company_code = $1 AND seat_code = $2
+		RETURNING room_code, seat_number, power_number`,
+		c.CompanyCode, c.SeatCode).Scan(&seat.RoomCode, &seat.SeatNumber, &seat.PowerNumber)
+	if err != nil && err != sql.ErrNoRows {
+		return seat, false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return seat, false, err
+	}
+	log.Printf("좌석 자동 해제: company_code=%s, seat_code=%d, reason=%s", c.CompanyCode, c.SeatCode, c.Reason)
+	return seat, true, nil
+}
+
+// notifySeatReleased는 해제된 좌석의 전원을 끄고(설정 또는 좌석 전원 규칙에 따라) 데스크에 해제 사실을 알립니다.
+// naracontrol 전송 실패는 해제 결과에 영향을 주지 않고 로그만 남깁니다.
+func notifySeatReleased(ctx context.Context, seat releasedSeat, powerOff bool) {
+	// 장치/데스크는 seat_table의 좌석 번호를 사용하고, 없으면 seat_code로 대신합니다.
+	seatNumber := strconv.Itoa(seat.SeatCode)
+	if seat.SeatNumber != nil {
+		seatNumber = strconv.Itoa(*seat.SeatNumber)
+	}
+	message := utils.ControlMessage{
+		CompanyCode: seat.CompanyCode,
+		RoomCode:    utils.ControlNumberString(seat.RoomCode),
+		SeatNumber:  seatNumber,
+		PowerNumber: utils.ControlNumberString(seat.PowerNumber),
+	}
+
+	// 전원 차단은 전원 제어 이벤트로 기록하여 장치 응답을 추적합니다.
+	if seat.PowerNumber != nil && releaseSeatPowerOff(ctx, seat.CompanyCode, powerOff) {
+		seatCode := seat.SeatCode
+		target := powerTarget{
+			CompanyCode: seat.CompanyCode,
+			TargetType:  POWER_TARGET_SEAT,
+			RoomCode:    seat.RoomCode,
+			SeatCode:    &seatCode,
+			SeatNumber:  seat.SeatNumber,
+			PowerNumber: seat.PowerNumber,
+		}
+		if _, err := sendPowerCommand(ctx, target, utils.CONTROL_COMMAND_POWER_OFF, POWER_SOURCE_RELEASE, ""); err != nil {
+			log.Printf("좌석 전원 차단 명령 실패: company_code=%s, seat_code=%d, 오류: %v", seat.CompanyCode, seat.SeatCode, err)
+		}
+	}
+
+	_, err := utils.SendControlCommand(ctx, utils.ControlCommand{
+		Command: utils.CONTROL_COMMAND_SEAT_RELEASED,
+		Target:  utils.CONTROL_TARGET_DESK,
+		Message: message,
+	})
+	if err != nil {
+		log.Printf("데스크 좌석 해제 알림 실패: company_code=%s, seat_code=%d, 오류: %v", seat.CompanyCode, seat.SeatCode, err)
+	}
+}
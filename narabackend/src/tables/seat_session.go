@@ -0,0 +1,412 @@
+// seat_session.go
+package tables
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"narabackend/src/consts"
+	"narabackend/src/utils"
+)
+
+// 좌석 세션 종료 사유
+const (
+	RELEASE_REASON_CHECKOUT       = "checkout"       // 정상 퇴실
+	RELEASE_REASON_EXPIRED        = "expired"        // 이용권 만료로 자동 해제
+	RELEASE_REASON_MANUAL         = "manual"         // 관리자 해제
+	RELEASE_REASON_OUTING_TIMEOUT = "outing_timeout" // 외출 복귀 시간 초과로 자동 해제
+)
+
+// SeatSession 구조체는 seat_session_table의 각 컬럼을 매핑합니다.
+type SeatSession struct {
+	SerialNumber  int64      `json:"serial_number" db:"serial_number"`
+	CompanyCode   string     `json:"company_code" db:"company_code"`
+	SeatCode      int        `json:"seat_code" db:"seat_code"`
+	MemberID      int64      `json:"member_id" db:"member_id"`
+	PassID        int64      `json:"pass_id" db:"pass_id"`
+	CheckInAt     time.Time  `json:"check_in_at" db:"check_in_at"`
+	CheckOutAt    *time.Time `json:"check_out_at" db:"check_out_at"`
+	UsedMinutes   int        `json:"used_minutes" db:"used_minutes"`
+	ReleaseReason *string    `json:"release_reason" db:"release_reason"`
+}
+
+// CheckInRequest는 입실 요청 시 사용되는 구조체입니다.
+type CheckInRequest struct {
+	PassID   int64 `json:"pass_id"`
+	SeatCode int   `json:"seat_code"`
+}
+
+// CheckOutRequest는 퇴실 요청 시 사용되는 구조체입니다.
+// 요청으로 지정할 수 있는 사유는 checkout, manual뿐이며 expired, outing_timeout은 스케줄러만 기록합니다.
+type CheckOutRequest struct {
+	Reason string `json:"reason"`
+}
+
+// seatSessionColumns는 seat_session_table 조회 시 사용하는 컬럼 목록입니다.
+const seatSessionColumns = `serial_number, company_code, seat_code, member_id, pass_id,
+	check_in_at, check_out_at, used_minutes, release_reason`
+
+// scanSeatSession은 seatSessionColumns 순서로 조회된 행을 SeatSession 구조체로 변환합니다.
+func scanSeatSession(row interface{ Scan(...interface{}) error }, session *SeatSession) error {
+	return row.Scan(&session.SerialNumber, &session.CompanyCode, &session.SeatCode, &session.MemberID,
+		&session.PassID, &session.CheckInAt, &session.CheckOutAt, &session.UsedMinutes, &session.ReleaseReason)
+}
+
+// errSessionClosed는 이미 종료된 세션을 다시 종료하려 할 때 반환됩니다.
+var errSessionClosed = errors.New("이미 종료된 세션입니다")
+
+// errSeatHeld는 다른 회원의 진행 중 예약이나 대기열 제안이 걸린 좌석에 입실하려 할 때 반환됩니다.
+var errSeatHeld = errors.New("다른 회원이 예약했거나 대기열에서 제안받은 좌석입니다")
+
+// usedMinutesBetween은 입실~퇴실 사이의 이용 시간을 분 단위로 올림 계산합니다.
+func usedMinutesBetween(from, to time.Time) int {
+	if !to.After(from) {
+		return 0
+	}
+	return int((to.Sub(from) + time.Minute - time.Nanosecond) / time.Minute)
+}
+
+// checkInTx는 트랜잭션 안에서 이용권으로 좌석에 입실시킵니다.
+// 이용권 행을 잠가 동시 입실을 막고, 좌석/이용권별 부분 유니크 인덱스가 중복 세션을 최종적으로 차단합니다.
+// 다른 회원의 진행 중 예약(reserved, checked_in)이나 대기열 제안(offered, 수락 후 제안 기한 동안의 accepted)이 있는 좌석은 거부합니다.
+// 입실 이벤트(seat_occupied)는 같은 트랜잭션으로 outbox에 기록되어 커밋 후 데스크에 전달되며, 입실 웹훅도 함께 등록됩니다.
+func checkInTx(ctx context.Context, tx *sql.Tx, passID int64, seatCode int, at time.Time) (SeatSession, error) {
+	var session SeatSession
+
+	var pass MemberPass
+	err := scanMemberPass(tx.QueryRowContext(ctx,
+		"SELECT "+memberPassColumns+" FROM member_pass_table WHERE serial_number = $1 FOR UPDATE", passID), &pass)
+	if err != nil {
+		return session, err
+	}
+	if err := checkPassUsable(pass, at); err != nil {
+		return session, err
+	}
+	if pass.SeatCode != nil && *pass.SeatCode != seatCode {
+		return session, fmt.Errorf("%w: 고정석(%d) 이외의 좌석은 사용할 수 없습니다", errPassNotUsable, *pass.SeatCode)
+	}
+	blocked, err := seatAwaitingCleaning(ctx, tx, pass.CompanyCode, seatCode)
+	if err != nil {
+		return session, err
+	}
+	if blocked {
+		return session, errSeatNeedsCleaning
+	}
+	held, err := seatHeldByOther(ctx, tx, pass.CompanyCode, seatCode, pass.MemberID, at)
+	if err != nil {
+		return session, err
+	}
+	if held {
+		return session, errSeatHeld
+	}
+
+	err = scanSeatSession(tx.QueryRowContext(ctx, `
+		INSERT INTO seat_session_table (company_code, seat_code, member_id, pass_id, check_in_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING `+seatSessionColumns,
+		pass.CompanyCode, seatCode, pass.MemberID, pass.SerialNumber, at), &session)
+	if err != nil {
+		return session, err
+	}
+	err = writeSeatChangeTx(ctx, tx, utils.CONTROL_EVENT_SEAT_OCCUPIED, changeTargetsDesk,
+		session.CompanyCode, session.SeatCode, strconv.FormatInt(session.MemberID, 10))
+	if err != nil {
+		return session, err
+	}
+	return session, queueWebhookEventTx(ctx, tx, session.CompanyCode, WEBHOOK_EVENT_CHECKED_IN, session)
+}
+
+// seatHeldByOther는 좌석에 memberID 이외 회원의 진행 중 예약이나 대기열 제안이 있는지 확인합니다.
+// 수락된 제안은 수락 후 제안 수락 기한만큼 좌석을 잡아 둡니다.
+// memberID가 0이면 모든 회원의 예약과 제안을 셉니다 (대기열 제안 전 빈 좌석 확인).
+func seatHeldByOther(ctx context.Context, tx *sql.Tx, companyCode string, seatCode int, memberID int64, at time.Time) (bool, error) {
+	acceptedSince := at.Add(-time.Duration(consts.WAITING_OFFER_TIMEOUT_MINUTES) * time.Minute)
+	var held bool
+	err := tx.QueryRowContext(ctx, `
+		SELECT EXISTS(SELECT 1 FROM reservation_table
+		              WHERE company_code = $1 AND resource_type = $3 AND resource_code = $2
+		                AND status IN ($4, $5) AND start_at <= $6 AND end_at > $6
+		                AND member_id IS DISTINCT FROM $7)
+		    OR EXISTS(SELECT 1 FROM waiting_list_table
+		              WHERE company_code = $1 AND offered_seat_code = $2 AND member_id <> $7
+		                AND ((status = $8 AND offer_expires_at > $6) OR (status = $9 AND responded_at > $10)))`,
+		companyCode, seatCode, RESOURCE_TYPE_SEAT, RESERVATION_STATUS_RESERVED, RESERVATION_STATUS_CHECKED_IN,
+		at, memberID, WAITING_STATUS_OFFERED, WAITING_STATUS_ACCEPTED, acceptedSince).Scan(&held)
+	return held, err
+}
+
+// checkOutTx는 트랜잭션 안에서 세션을 종료하고 이용권을 차감합니다.
+// 시간제 이용권은 사용 시간만큼 잔여 시간을 줄이고, 0 이하가 되면 exhausted로 변경합니다.
+// 비게 된 좌석은 청소 대기 작업으로 등록되며, 청소등 전송은 커밋 후 호출자가 pushCleaningLight로 처리합니다.
+// 만료 스케줄러 등 HTTP 요청 이외의 경로에서도 이 함수를 통해 좌석을 해제하며, 퇴실 이벤트(seat_vacated)를 outbox에 기록하고
+// 퇴실 웹훅(이용권 만료 해제는 session.expired)을 등록합니다.
+func checkOutTx(ctx context.Context, tx *sql.Tx, sessionID int64, reason string, at time.Time) (SeatSession, error) {
+	var session SeatSession
+	err := scanSeatSession(tx.QueryRowContext(ctx,
+		"SELECT "+seatSessionColumns+" FROM seat_session_table WHERE serial_number = $1 FOR UPDATE", sessionID), &session)
+	if err != nil {
+		return session, err
+	}
+	if session.CheckOutAt != nil {
+		return session, errSessionClosed
+	}
+
+	usedMinutes := usedMinutesBetween(session.CheckInAt, at)
+	err = scanSeatSession(tx.QueryRowContext(ctx, `
+		UPDATE seat_session_table SET check_out_at = $2, used_minutes = $3, release_reason = $4
+		WHERE serial_number = $1
+		RETURNING `+seatSessionColumns,
+		sessionID, at, usedMinutes, reason), &session)
+	if err != nil {
+		return session, err
+	}
+
+	// 시간제 이용권 차감 (기간권은 remaining_minutes가 NULL이므로 영향 없음)
+	_, err = tx.ExecContext(ctx, `
+		UPDATE member_pass_table SET
+			remaining_minutes = GREATEST(remaining_minutes - $2, 0),
+			status = CASE WHEN remaining_minutes - $2 <= 0 AND status = $4 THEN $3 ELSE status END,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE serial_number = $1 AND remaining_minutes IS NOT NULL`,
+		session.PassID, usedMinutes, PASS_STATUS_EXHAUSTED, PASS_STATUS_ACTIVE)
+	if err != nil {
+		return session, err
+	}
+
+	err = writeSeatChangeTx(ctx, tx, utils.CONTROL_EVENT_SEAT_VACATED, changeTargetsDesk,
+		session.CompanyCode, session.SeatCode, strconv.FormatInt(session.MemberID, 10))
+	if err != nil {
+		return session, err
+	}
+	webhookEvent := WEBHOOK_EVENT_CHECKED_OUT
+	if reason == RELEASE_REASON_EXPIRED {
+		webhookEvent = WEBHOOK_EVENT_SESSION_EXPIRED
+	}
+	if err := queueWebhookEventTx(ctx, tx, session.CompanyCode, webhookEvent, session); err != nil {
+		return session, err
+	}
+
+	return session, enqueueCheckoutCleaningTx(ctx, tx, session, at)
+}
+
+// RegisterSeatSessionRoutes는 seat_session_table 관련 엔드포인트를 등록합니다.
+func RegisterSeatSessionRoutes(r *mux.Router) {
+	r.HandleFunc("/seat-sessions", GetSeatSessions).Methods("GET")
+	r.HandleFunc("/seat-sessions/{id}", GetSeatSession).Methods("GET")
+	r.HandleFunc("/seat-sessions/check-in", CheckInSeatSession).Methods("POST")
+	r.HandleFunc("/seat-sessions/{id}/check-out", CheckOutSeatSession).Methods("POST")
+}
+
+// GetSeatSessions: 좌석 이용 기록을 조회합니다.
+// company_code, seat_code, member_id, pass_id 필터와 open=true(이용 중만) 옵션을 지원합니다.
+func GetSeatSessions(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	filters := []string{}
+	args := []interface{}{}
+	paramIdx := 1
+
+	filterParams := map[string]string{
+		"company_code": "company_code",
+		"seat_code":    "seat_code",
+		"member_id":    "member_id",
+		"pass_id":      "pass_id",
+	}
+	for param, dbField := range filterParams {
+		if value := r.URL.Query().Get(param); value != "" {
+			filters = append(filters, fmt.Sprintf("%s = $%d", dbField, paramIdx))
+			args = append(args, value)
+			paramIdx++
+		}
+	}
+	if r.URL.Query().Get("open") == "true" {
+		filters = append(filters, "check_out_at IS NULL")
+	}
+
+	query := "SELECT " + seatSessionColumns + " FROM seat_session_table"
+	if len(filters) > 0 {
+		query += " WHERE " + strings.Join(filters, " AND ")
+	}
+	query += " ORDER BY check_in_at DESC"
+
+	rows, err := utils.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("데이터베이스 쿼리 오류: %v", err)
+		http.Error(w, "데이터 조회 중 오류가 발생했습니다", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	result := []SeatSession{}
+	for rows.Next() {
+		var session SeatSession
+		if err := scanSeatSession(rows, &session); err != nil {
+			log.Printf("행 스캔 오류: %v", err)
+			http.Error(w, "데이터 처리 중 오류가 발생했습니다", http.StatusInternalServerError)
+			return
+		}
+		result = append(result, session)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// GetSeatSession: 단일 좌석 이용 기록을 조회합니다.
+func GetSeatSession(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "잘못된 id", http.StatusBadRequest)
+		return
+	}
+
+	var session SeatSession
+	err = scanSeatSession(utils.DB.QueryRowContext(ctx,
+		"SELECT "+seatSessionColumns+" FROM seat_session_table WHERE serial_number = $1", id), &session)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "세션을 찾을 수 없습니다.", http.StatusNotFound)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(session)
+}
+
+// CheckInSeatSession: 이용권으로 좌석에 입실합니다.
+func CheckInSeatSession(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	var req CheckInRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "잘못된 요청 데이터", http.StatusBadRequest)
+		return
+	}
+	if req.PassID == 0 || req.SeatCode == 0 {
+		http.Error(w, "필수 필드가 누락되었습니다 (pass_id, seat_code)", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := utils.DB.BeginTx(ctx, nil)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	session, err := checkInTx(ctx, tx, req.PassID, req.SeatCode, time.Now())
+	if err != nil {
+		writeCheckInError(w, err)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// 좌석 전원 규칙에 따라 좌석 전원을 켭니다.
+	applySeatOccupancyPower(ctx, session.CompanyCode, session.SeatCode, true)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(session)
+}
+
+// writeCheckInError는 checkInTx 오류를 HTTP 응답으로 변환합니다.
+func writeCheckInError(w http.ResponseWriter, err error) {
+	switch {
+	case err == sql.ErrNoRows:
+		http.Error(w, "이용권을 찾을 수 없습니다.", http.StatusNotFound)
+	case errors.Is(err, errPassNotUsable):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, errSeatNeedsCleaning), errors.Is(err, errSeatHeld):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case strings.Contains(err.Error(), "duplicate key"):
+		http.Error(w, "이미 사용 중인 좌석이거나 이용 중인 이용권입니다", http.StatusConflict)
+	default:
+		log.Printf("입실 처리 오류: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// CheckOutSeatSession: 세션을 종료(퇴실)하고 이용권을 차감합니다.
+func CheckOutSeatSession(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "잘못된 id", http.StatusBadRequest)
+		return
+	}
+
+	// 본문은 선택 사항입니다. 사유가 없으면 정상 퇴실로 기록합니다.
+	var req CheckOutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		http.Error(w, "잘못된 요청 데이터", http.StatusBadRequest)
+		return
+	}
+	switch req.Reason {
+	case "":
+		req.Reason = RELEASE_REASON_CHECKOUT
+	case RELEASE_REASON_CHECKOUT, RELEASE_REASON_MANUAL:
+	default:
+		http.Error(w, "퇴실 사유는 checkout 또는 manual이어야 합니다.", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := utils.DB.BeginTx(ctx, nil)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	session, err := checkOutTx(ctx, tx, id, req.Reason, time.Now())
+	if err != nil {
+		switch {
+		case err == sql.ErrNoRows:
+			http.Error(w, "세션을 찾을 수 없습니다.", http.StatusNotFound)
+		case errors.Is(err, errSessionClosed):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			log.Printf("퇴실 처리 오류: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// 좌석 전원 규칙에 따라 좌석 전원을 끄고, 청소등을 켜고, 비게 된 좌석을 열람실 대기열에 제안합니다.
+	applySeatOccupancyPower(ctx, session.CompanyCode, session.SeatCode, false)
+	pushCleaningLight(ctx, session.CompanyCode, session.SeatCode)
+	offerFreedSeat(ctx, session.CompanyCode, session.SeatCode, time.Now())
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(session)
+}
@@ -0,0 +1,256 @@
+// settlement.go
+package tables
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"narabackend/src/consts"
+	"narabackend/src/utils"
+)
+
+// SettlementLine은 결제 수단별 일일 마감 금액입니다.
+type SettlementLine struct {
+	PaymentMethod string `json:"payment_method" db:"payment_method"`
+	PaymentCount  int    `json:"payment_count" db:"payment_count"`
+	GrossAmount   int64  `json:"gross_amount" db:"gross_amount"`
+	RefundCount   int    `json:"refund_count" db:"refund_count"`
+	RefundAmount  int64  `json:"refund_amount" db:"refund_amount"`
+	NetAmount     int64  `json:"net_amount" db:"net_amount"`
+}
+
+// SettlementReport는 업체의 일일 마감 보고서입니다.
+// Closed가 true이면 settlement_table에 저장된 마감 값을, false이면 원장에서 계산한 현재 값을 담습니다.
+type SettlementReport struct {
+	CompanyCode    string           `json:"company_code"`
+	SettlementDate string           `json:"settlement_date"`
+	Closed         bool             `json:"closed"`
+	ClosedBy       string           `json:"closed_by,omitempty"`
+	ClosedAt       *time.Time       `json:"closed_at,omitempty"`
+	Lines          []SettlementLine `json:"lines"`
+	TotalGross     int64            `json:"total_gross"`
+	TotalRefund    int64            `json:"total_refund"`
+	TotalNet       int64            `json:"total_net"`
+}
+
+// SettlementRequest는 마감 요청 시 사용되는 구조체입니다.
+type SettlementRequest struct {
+	SettlementDate string `json:"settlement_date"`
+	ClosedBy       string `json:"closed_by"`
+}
+
+// errAlreadySettled는 이미 마감된 일자를 다시 마감하려 할 때 반환됩니다.
+var errAlreadySettled = errors.New("이미 마감된 일자입니다")
+
+// settlementQuerier는 *sql.DB와 *sql.Tx에 공통된 조회 메서드입니다.
+type settlementQuerier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// computeSettlementLines는 원장에서 업체/일자의 결제 수단별 합계를 계산합니다.
+// 환불은 환불이 발생한 날짜(환불 행의 created_at)에 차감됩니다.
+func computeSettlementLines(ctx context.Context, q settlementQuerier, companyCode, date string) ([]SettlementLine, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT payment_method,
+		       COUNT(*) FILTER (WHERE payment_type <> 'refund'),
+		       COALESCE(SUM(amount) FILTER (WHERE payment_type <> 'refund'), 0),
+		       COUNT(*) FILTER (WHERE payment_type = 'refund'),
+		       COALESCE(SUM(amount) FILTER (WHERE payment_type = 'refund'), 0)
+		FROM payment_table
+		WHERE company_code = $1 AND created_at >= $2::date AND created_at < $2::date + 1
+		GROUP BY payment_method
+		ORDER BY payment_method`, companyCode, date)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lines := []SettlementLine{}
+	for rows.Next() {
+		var line SettlementLine
+		if err := rows.Scan(&line.PaymentMethod, &line.PaymentCount, &line.GrossAmount,
+			&line.RefundCount, &line.RefundAmount); err != nil {
+			return nil, err
+		}
+		line.NetAmount = line.GrossAmount - line.RefundAmount
+		lines = append(lines, line)
+	}
+	return lines, rows.Err()
+}
+
+// loadSettlementReport는 마감된 일자면 저장된 값을, 아니면 원장에서 계산한 값을 반환합니다.
+func loadSettlementReport(ctx context.Context, companyCode, date string) (SettlementReport, error) {
+	report := SettlementReport{CompanyCode: companyCode, SettlementDate: date, Lines: []SettlementLine{}}
+
+	rows, err := utils.DB.QueryContext(ctx, `
+		SELECT payment_method, payment_count, gross_amount, refund_count, refund_amount, net_amount,
+		       COALESCE(closed_by, ''), closed_at
+		FROM settlement_table
+		WHERE company_code = $1 AND settlement_date = $2::date
+		ORDER BY payment_method`, companyCode, date)
+	if err != nil {
+		return report, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var line SettlementLine
+		var closedAt time.Time
+		if err := rows.Scan(&line.PaymentMethod, &line.PaymentCount, &line.GrossAmount, &line.RefundCount,
+			&line.RefundAmount, &line.NetAmount, &report.ClosedBy, &closedAt); err != nil {
+			return report, err
+		}
+		report.Closed = true
+		report.ClosedAt = &closedAt
+		report.Lines = append(report.Lines, line)
+	}
+	if err := rows.Err(); err != nil {
+		return report, err
+	}
+
+	if !report.Closed {
+		report.Lines, err = computeSettlementLines(ctx, utils.DB, companyCode, date)
+		if err != nil {
+			return report, err
+		}
+	}
+	for _, line := range report.Lines {
+		report.TotalGross += line.GrossAmount
+		report.TotalRefund += line.RefundAmount
+		report.TotalNet += line.NetAmount
+	}
+	return report, nil
+}
+
+// closeSettlement는 업체/일자의 결제 수단별 합계를 settlement_table에 저장하여 마감합니다.
+// 결제가 없는 날도 마감 기록을 남기기 위해 수단이 없으면 'none' 행을 저장합니다.
+// 스케줄러 등 HTTP 이외의 경로에서도 호출할 수 있습니다.
+func closeSettlement(ctx context.Context, companyCode, date, closedBy string) error {
+	tx, err := utils.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// 같은 업체의 동시 마감 요청을 직렬화합니다.
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock(hashtext('settlement:' || $1))", companyCode); err != nil {
+		return err
+	}
+
+	var exists bool
+	err = tx.QueryRowContext(ctx,
+		"SELECT EXISTS(SELECT 1 FROM settlement_table WHERE company_code = $1 AND settlement_date = $2::date)",
+		companyCode, date).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return errAlreadySettled
+	}
+
+	lines, err := computeSettlementLines(ctx, tx, companyCode, date)
+	if err != nil {
+		return err
+	}
+	if len(lines) == 0 {
+		lines = append(lines, SettlementLine{PaymentMethod: "none"})
+	}
+	for _, line := range lines {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO settlement_table
+			(company_code, settlement_date, payment_method, payment_count, gross_amount,
+			 refund_count, refund_amount, net_amount, closed_by, closed_at)
+			VALUES ($1, $2::date, $3, $4, $5, $6, $7, $8, $9, CURRENT_TIMESTAMP)`,
+			companyCode, date, line.PaymentMethod, line.PaymentCount, line.GrossAmount,
+			line.RefundCount, line.RefundAmount, line.NetAmount, closedBy)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// RegisterSettlementRoutes는 settlement_table 관련 엔드포인트를 등록합니다.
+func RegisterSettlementRoutes(r *mux.Router) {
+	r.HandleFunc("/companies/{company_code}/settlements", GetSettlement).Methods("GET")
+	r.HandleFunc("/companies/{company_code}/settlements", CreateSettlement).Methods("POST")
+}
+
+// GetSettlement: 업체의 일일 마감 보고서를 조회합니다.
+// date 쿼리 파라미터(YYYY-MM-DD)가 없으면 오늘을 기준으로 합니다.
+func GetSettlement(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.LONG_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	companyCode := mux.Vars(r)["company_code"]
+	date := r.URL.Query().Get("date")
+	if date == "" {
+		date = time.Now().Format("2006-01-02")
+	}
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		http.Error(w, "잘못된 date 형식입니다 (YYYY-MM-DD)", http.StatusBadRequest)
+		return
+	}
+
+	report, err := loadSettlementReport(ctx, companyCode, date)
+	if err != nil {
+		log.Printf("마감 보고서 조회 오류: %v", err)
+		http.Error(w, "데이터 조회 중 오류가 발생했습니다", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// CreateSettlement: 업체의 특정 일자를 마감합니다.
+// 마감 이후 해당 일자의 보고서는 저장된 값으로 고정됩니다.
+func CreateSettlement(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.LONG_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	companyCode := mux.Vars(r)["company_code"]
+
+	var req SettlementRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "잘못된 요청 데이터", http.StatusBadRequest)
+		return
+	}
+	if req.SettlementDate == "" {
+		req.SettlementDate = time.Now().Format("2006-01-02")
+	}
+	if _, err := time.Parse("2006-01-02", req.SettlementDate); err != nil {
+		http.Error(w, "잘못된 settlement_date 형식입니다 (YYYY-MM-DD)", http.StatusBadRequest)
+		return
+	}
+
+	log.Printf("일일 마감 요청: company_code=%s, date=%s, closed_by=%s", companyCode, req.SettlementDate, req.ClosedBy)
+	if err := closeSettlement(ctx, companyCode, req.SettlementDate, req.ClosedBy); err != nil {
+		if errors.Is(err, errAlreadySettled) {
+			http.Error(w, err.Error(), http.StatusConflict)
+		} else if strings.Contains(err.Error(), "duplicate key") {
+			http.Error(w, errAlreadySettled.Error(), http.StatusConflict)
+		} else {
+			log.Printf("일일 마감 오류: %v", err)
+			http.Error(w, "마감 처리 중 오류가 발생했습니다", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	report, err := loadSettlementReport(ctx, companyCode, req.SettlementDate)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,615 @@
+// unmanned.go
+package tables
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/lib/pq"
+
+	"narabackend/src/consts"
+	"narabackend/src/utils"
+)
+
+// 운영 형태
+const (
+	OPERATING_MODE_STAFFED  = "staffed"  // 유인 운영
+	OPERATING_MODE_UNMANNED = "unmanned" // 무인 운영
+)
+
+// 키오스크 판정 사유
+const (
+	KIOSK_REASON_ALLOWED              = "allowed"                // 허용
+	KIOSK_REASON_MEMBER_INACTIVE      = "member_inactive"        // 활성 회원이 아님
+	KIOSK_REASON_SEAT_NOT_FOUND       = "seat_not_found"         // 좌석 없음
+	KIOSK_REASON_KIOSK_DISABLED       = "kiosk_disabled"         // 열람실 키오스크 사용 안 함
+	KIOSK_REASON_SEAT_UNMANNED_DENIED = "seat_unmanned_disabled" // 무인 시간 사용 불가 좌석
+	KIOSK_REASON_CHECK_IN_DENIED      = "check_in_not_allowed"   // 무인 시간 입실 불가
+	KIOSK_REASON_PURCHASE_DENIED      = "purchase_not_allowed"   // 무인 시간 구매 불가
+	KIOSK_REASON_GRADE_DENIED         = "grade_not_allowed"      // 무인 등급 미달
+	KIOSK_REASON_GRADE_MISSING        = "grade_missing"          // 무인 등급 없음
+)
+
+// kioskReasonMessages는 판정 사유별 키오스크 안내 문구입니다.
+var kioskReasonMessages = map[string]string{
+	KIOSK_REASON_ALLOWED:              "이용할 수 있습니다",
+	KIOSK_REASON_MEMBER_INACTIVE:      "이용이 정지되었거나 비활성 회원입니다. 관리자에게 문의하세요",
+	KIOSK_REASON_SEAT_NOT_FOUND:       "좌석을 찾을 수 없습니다",
+	KIOSK_REASON_KIOSK_DISABLED:       "이 열람실은 키오스크에서 입실할 수 없습니다",
+	KIOSK_REASON_SEAT_UNMANNED_DENIED: "무인 운영 시간에는 사용할 수 없는 좌석입니다",
+	KIOSK_REASON_CHECK_IN_DENIED:      "무인 운영 시간에는 입실할 수 없습니다",
+	KIOSK_REASON_PURCHASE_DENIED:      "무인 운영 시간에는 이용권을 구매할 수 없습니다",
+	KIOSK_REASON_GRADE_DENIED:         "무인 운영 시간에 허용되지 않는 회원 등급입니다",
+	KIOSK_REASON_GRADE_MISSING:        "무인 운영 등급이 없어 무인 시간에 이용할 수 없습니다",
+}
+
+// OperatingWindow는 요일별 유인 운영 시간대입니다. 시각은 HH:MM 형식입니다.
+// 종료 시각이 시작 시각보다 이르면 다음 날 종료 시각까지, 같으면 하루 종일입니다.
+type OperatingWindow struct {
+	DayOfWeek    int    `json:"day_of_week"`
+	StaffedFrom  string `json:"staffed_from"`
+	StaffedUntil string `json:"staffed_until"`
+}
+
+// OperatingScheduleRequest는 운영 시간표 저장 요청 시 사용되는 구조체입니다. 기존 시간표를 모두 대체합니다.
+type OperatingScheduleRequest struct {
+	Windows []OperatingWindow `json:"windows"`
+}
+
+// UnmannedRule 구조체는 unmanned_rule_table의 각 컬럼을 매핑합니다.
+type UnmannedRule struct {
+	SerialNumber  int64     `json:"serial_number" db:"serial_number"`
+	CompanyCode   string    `json:"company_code" db:"company_code"`
+	EntryGrades   []int64   `json:"entry_grades" db:"entry_grades"`
+	CheckInGrades []int64   `json:"check_in_grades" db:"check_in_grades"`
+	AllowUngraded bool      `json:"allow_ungraded" db:"allow_ungraded"`
+	AllowCheckIn  bool      `json:"allow_check_in" db:"allow_check_in"`
+	AllowPurchase bool      `json:"allow_purchase" db:"allow_purchase"`
+	Enabled       bool      `json:"enabled" db:"enabled"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// UnmannedRuleRequest는 무인 운영 규칙 저장 요청 시 사용되는 구조체입니다.
+// 포인터 필드는 생략 시 기존 값(없으면 기본값)을 유지합니다.
+type UnmannedRuleRequest struct {
+	EntryGrades   *[]int64 `json:"entry_grades"`
+	CheckInGrades *[]int64 `json:"check_in_grades"`
+	AllowUngraded *bool    `json:"allow_ungraded"`
+	AllowCheckIn  *bool    `json:"allow_check_in"`
+	AllowPurchase *bool    `json:"allow_purchase"`
+	Enabled       *bool    `json:"enabled"`
+}
+
+// OperatingMode는 특정 시각의 업체 운영 형태입니다.
+type OperatingMode struct {
+	CompanyCode string           `json:"company_code"`
+	At          time.Time        `json:"at"`
+	Mode        string           `json:"mode"`
+	Window      *OperatingWindow `json:"window"`
+}
+
+// KioskEvaluateRequest는 키오스크 동작 허용 여부 판정 요청입니다.
+// check_in은 seat_code를 함께 보내면 좌석/열람실 설정도 확인합니다.
+type KioskEvaluateRequest struct {
+	Action   string `json:"action"`
+	MemberID int64  `json:"member_id"`
+	SeatCode *int   `json:"seat_code"`
+}
+
+// KioskDecision은 키오스크 동작 허용 여부 판정 결과입니다.
+type KioskDecision struct {
+	Action     string `json:"action"`
+	Allowed    bool   `json:"allowed"`
+	Mode       string `json:"mode"`
+	ReasonCode string `json:"reason_code"`
+	Reason     string `json:"reason"`
+}
+
+// unmannedRuleColumns는 unmanned_rule_table 조회 시 사용하는 컬럼 목록입니다.
+const unmannedRuleColumns = `serial_number, company_code, entry_grades, check_in_grades, allow_ungraded,
+	allow_check_in, allow_purchase, enabled, created_at, updated_at`
+
+// scanUnmannedRule은 한 행을 UnmannedRule로 읽습니다.
+func scanUnmannedRule(row interface{ Scan(...interface{}) error }, rule *UnmannedRule) error {
+	return row.Scan(&rule.SerialNumber, &rule.CompanyCode, (*pq.Int64Array)(&rule.EntryGrades),
+		(*pq.Int64Array)(&rule.CheckInGrades), &rule.AllowUngraded, &rule.AllowCheckIn, &rule.AllowPurchase,
+		&rule.Enabled, &rule.CreatedAt, &rule.UpdatedAt)
+}
+
+var errInvalidKioskAction = errors.New("action은 entry, check_in, check_out, purchase 중 하나여야 합니다")
+
+// parseClock은 HH:MM 형식의 시각을 자정부터의 분으로 바꿉니다.
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("잘못된 시각 형식입니다 (HH:MM): %s", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// loadOperatingSchedule은 업체의 요일별 유인 운영 시간대를 조회합니다.
+func loadOperatingSchedule(ctx context.Context, companyCode string) ([]OperatingWindow, error) {
+	rows, err := utils.DB.QueryContext(ctx, `
+		SELECT day_of_week, to_char(staffed_from, 'HH24:MI'), to_char(staffed_until, 'HH24:MI')
+		FROM operating_schedule_table WHERE company_code = $1
+		ORDER BY day_of_week, staffed_from`, companyCode)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	windows := []OperatingWindow{}
+	for rows.Next() {
+		var w OperatingWindow
+		if err := rows.Scan(&w.DayOfWeek, &w.StaffedFrom, &w.StaffedUntil); err != nil {
+			return nil, err
+		}
+		windows = append(windows, w)
+	}
+	return windows, rows.Err()
+}
+
+// staffedWindow는 시각이 속한 유인 운영 시간대를 찾습니다.
+// 전날 시작하여 자정을 넘긴 시간대도 확인합니다.
+func staffedWindow(windows []OperatingWindow, t time.Time) (*OperatingWindow, bool) {
+	day := int(t.Weekday())
+	prevDay := (day + 6) % 7
+	minute := t.Hour()*60 + t.Minute()
+	for i := range windows {
+		w := &windows[i]
+		from, err1 := parseClock(w.StaffedFrom)
+		until, err2 := parseClock(w.StaffedUntil)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		switch {
+		case from == until:
+			if w.DayOfWeek == day {
+				return w, true
+			}
+		case from < until:
+			if w.DayOfWeek == day && minute >= from && minute < until {
+				return w, true
+			}
+		default:
+			if (w.DayOfWeek == day && minute >= from) || (w.DayOfWeek == prevDay && minute < until) {
+				return w, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// operatingModeAt은 업체의 운영 형태를 판정합니다. 운영 시간표가 없으면 항상 유인 운영입니다.
+func operatingModeAt(ctx context.Context, companyCode string, t time.Time) (OperatingMode, error) {
+	mode := OperatingMode{CompanyCode: companyCode, At: t, Mode: OPERATING_MODE_STAFFED}
+	windows, err := loadOperatingSchedule(ctx, companyCode)
+	if err != nil || len(windows) == 0 {
+		return mode, err
+	}
+	window, staffed := staffedWindow(windows, t)
+	mode.Window = window
+	if !staffed {
+		mode.Mode = OPERATING_MODE_UNMANNED
+	}
+	return mode, nil
+}
+
+// gradeAllowed는 회원의 무인 등급이 허용 등급 목록에 있는지 확인합니다. 목록이 비어 있으면 모든 등급을 허용합니다.
+func gradeAllowed(grades []int64, grade *int, allowUngraded bool) (bool, string) {
+	if len(grades) == 0 {
+		return true, KIOSK_REASON_ALLOWED
+	}
+	if grade == nil {
+		if allowUngraded {
+			return true, KIOSK_REASON_ALLOWED
+		}
+		return false, KIOSK_REASON_GRADE_MISSING
+	}
+	for _, g := range grades {
+		if g == int64(*grade) {
+			return true, KIOSK_REASON_ALLOWED
+		}
+	}
+	return false, KIOSK_REASON_GRADE_DENIED
+}
+
+// newKioskDecision은 판정 사유로 결과를 만듭니다.
+func newKioskDecision(action, mode, reasonCode string) KioskDecision {
+	return KioskDecision{
+		Action:     action,
+		Allowed:    reasonCode == KIOSK_REASON_ALLOWED,
+		Mode:       mode,
+		ReasonCode: reasonCode,
+		Reason:     kioskReasonMessages[reasonCode],
+	}
+}
+
+// evaluateKioskAction은 운영 시간표와 무인 운영 규칙으로 키오스크 동작 허용 여부를 판정합니다.
+// 퇴실은 항상 허용하고, 유인 운영 시간이거나 규칙이 없으면 회원 상태와 좌석 설정만 확인합니다.
+func evaluateKioskAction(ctx context.Context, companyCode, action string, member Member, seatCode *int, mode OperatingMode) (KioskDecision, error) {
+	switch action {
+	case KIOSK_ACTION_ENTRY, KIOSK_ACTION_CHECK_IN, KIOSK_ACTION_PURCHASE:
+	case KIOSK_ACTION_CHECK_OUT:
+		return newKioskDecision(action, mode.Mode, KIOSK_REASON_ALLOWED), nil
+	default:
+		return KioskDecision{}, errInvalidKioskAction
+	}
+	if member.Status != MEMBER_STATUS_ACTIVE {
+		return newKioskDecision(action, mode.Mode, KIOSK_REASON_MEMBER_INACTIVE), nil
+	}
+
+	// 좌석과 열람실 설정: 열람실 키오스크 사용 안 함은 운영 형태와 무관하게 적용합니다.
+	var seatUnmannedDisabled bool
+	if action == KIOSK_ACTION_CHECK_IN && seatCode != nil {
+		var kioskDisabled int
+		err := utils.DB.QueryRowContext(ctx, `
+			SELECT COALESCE(st.unmanned_disabled, FALSE),
+			       COALESCE((SELECT rt.kiosk_disabled FROM room_table rt
+			                  WHERE rt.room_code = st.room_code AND rt.company_code::text = st.company_code LIMIT 1), 0)
+			FROM seat_table st WHERE st.company_code = $1 AND st.seat_code = $2`,
+			companyCode, *seatCode).Scan(&seatUnmannedDisabled, &kioskDisabled)
+		if err == sql.ErrNoRows {
+			return newKioskDecision(action, mode.Mode, KIOSK_REASON_SEAT_NOT_FOUND), nil
+		}
+		if err != nil {
+			return KioskDecision{}, err
+		}
+		if kioskDisabled != 0 {
+			return newKioskDecision(action, mode.Mode, KIOSK_REASON_KIOSK_DISABLED), nil
+		}
+	}
+
+	if mode.Mode != OPERATING_MODE_UNMANNED {
+		return newKioskDecision(action, mode.Mode, KIOSK_REASON_ALLOWED), nil
+	}
+	var rule UnmannedRule
+	err := scanUnmannedRule(utils.DB.QueryRowContext(ctx,
+		"SELECT "+unmannedRuleColumns+" FROM unmanned_rule_table WHERE company_code = $1", companyCode), &rule)
+	if err == sql.ErrNoRows || (err == nil && !rule.Enabled) {
+		return newKioskDecision(action, mode.Mode, KIOSK_REASON_ALLOWED), nil
+	}
+	if err != nil {
+		return KioskDecision{}, err
+	}
+
+	var grade *int
+	if member.Grade != nil {
+		grade = member.Grade.UnmannedGrade
+	}
+	switch action {
+	case KIOSK_ACTION_PURCHASE:
+		if !rule.AllowPurchase {
+			return newKioskDecision(action, mode.Mode, KIOSK_REASON_PURCHASE_DENIED), nil
+		}
+	case KIOSK_ACTION_ENTRY:
+		if ok, reason := gradeAllowed(rule.EntryGrades, grade, rule.AllowUngraded); !ok {
+			return newKioskDecision(action, mode.Mode, reason), nil
+		}
+	case KIOSK_ACTION_CHECK_IN:
+		if !rule.AllowCheckIn {
+			return newKioskDecision(action, mode.Mode, KIOSK_REASON_CHECK_IN_DENIED), nil
+		}
+		if seatUnmannedDisabled {
+			return newKioskDecision(action, mode.Mode, KIOSK_REASON_SEAT_UNMANNED_DENIED), nil
+		}
+		if ok, reason := gradeAllowed(rule.CheckInGrades, grade, rule.AllowUngraded); !ok {
+			return newKioskDecision(action, mode.Mode, reason), nil
+		}
+	}
+	return newKioskDecision(action, mode.Mode, KIOSK_REASON_ALLOWED), nil
+}
+
+// RegisterUnmannedRoutes는 운영 시간표, 무인 운영 규칙, 키오스크 판정 엔드포인트를 등록합니다.
+func RegisterUnmannedRoutes(r *mux.Router) {
+	r.HandleFunc("/companies/{company_code}/operating-schedule", GetOperatingSchedule).Methods("GET")
+	r.HandleFunc("/companies/{company_code}/operating-schedule", SaveOperatingSchedule).Methods("PUT")
+	r.HandleFunc("/companies/{company_code}/operating-mode", GetOperatingMode).Methods("GET")
+	r.HandleFunc("/companies/{company_code}/unmanned-rule", GetUnmannedRule).Methods("GET")
+	r.HandleFunc("/companies/{company_code}/unmanned-rule", SaveUnmannedRule).Methods("PUT", "PATCH")
+	r.HandleFunc("/companies/{company_code}/unmanned-rule", DeleteUnmannedRule).Methods("DELETE")
+	r.HandleFunc("/companies/{company_code}/kiosk/evaluate", EvaluateKioskAction).Methods("POST")
+}
+
+// GetOperatingSchedule: 업체의 요일별 유인 운영 시간대를 조회합니다.
+func GetOperatingSchedule(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	windows, err := loadOperatingSchedule(ctx, mux.Vars(r)["company_code"])
+	if err != nil {
+		log.Printf("데이터베이스 쿼리 오류: %v", err)
+		http.Error(w, "데이터 조회 중 오류가 발생했습니다", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(OperatingScheduleRequest{Windows: windows})
+}
+
+// SaveOperatingSchedule: 업체의 운영 시간표를 요청 내용으로 대체합니다.
+// 빈 목록을 저장하면 운영 시간표가 없어져 항상 유인 운영으로 봅니다.
+func SaveOperatingSchedule(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	companyCode := mux.Vars(r)["company_code"]
+	var req OperatingScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "잘못된 요청 데이터", http.StatusBadRequest)
+		return
+	}
+	for _, window := range req.Windows {
+		if window.DayOfWeek < 0 || window.DayOfWeek > 6 {
+			http.Error(w, "day_of_week는 0(일요일)~6(토요일)이어야 합니다", http.StatusBadRequest)
+			return
+		}
+		if _, err := parseClock(window.StaffedFrom); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if _, err := parseClock(window.StaffedUntil); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	tx, err := utils.DB.BeginTx(ctx, nil)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM operating_schedule_table WHERE company_code = $1", companyCode); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	for _, window := range req.Windows {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO operating_schedule_table (company_code, day_of_week, staffed_from, staffed_until, created_at)
+			VALUES ($1, $2, $3::time, $4::time, CURRENT_TIMESTAMP)`,
+			companyCode, window.DayOfWeek, window.StaffedFrom, window.StaffedUntil)
+		if err != nil {
+			log.Printf("운영 시간표 저장 오류: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	windows, err := loadOperatingSchedule(ctx, companyCode)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(OperatingScheduleRequest{Windows: windows})
+}
+
+// GetOperatingMode: 업체의 현재(또는 at 쿼리 파라미터 시각의) 운영 형태를 조회합니다.
+// at은 RFC3339 형식입니다.
+func GetOperatingMode(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	at := time.Now()
+	if v := r.URL.Query().Get("at"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			http.Error(w, "잘못된 at 형식입니다 (RFC3339)", http.StatusBadRequest)
+			return
+		}
+		at = t.In(time.Local)
+	}
+	mode, err := operatingModeAt(ctx, mux.Vars(r)["company_code"], at)
+	if err != nil {
+		log.Printf("데이터베이스 쿼리 오류: %v", err)
+		http.Error(w, "데이터 조회 중 오류가 발생했습니다", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(mode)
+}
+
+// GetUnmannedRule: 업체의 무인 운영 규칙을 조회합니다.
+func GetUnmannedRule(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	var rule UnmannedRule
+	err := scanUnmannedRule(utils.DB.QueryRowContext(ctx,
+		"SELECT "+unmannedRuleColumns+" FROM unmanned_rule_table WHERE company_code = $1",
+		mux.Vars(r)["company_code"]), &rule)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "무인 운영 규칙이 없습니다.", http.StatusNotFound)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rule)
+}
+
+// SaveUnmannedRule: 업체의 무인 운영 규칙을 생성하거나 수정합니다.
+func SaveUnmannedRule(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	companyCode := mux.Vars(r)["company_code"]
+	var req UnmannedRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "잘못된 요청 데이터", http.StatusBadRequest)
+		return
+	}
+
+	rule := UnmannedRule{
+		CompanyCode:   companyCode,
+		EntryGrades:   []int64{},
+		CheckInGrades: []int64{},
+		AllowCheckIn:  true,
+		AllowPurchase: true,
+		Enabled:       true,
+	}
+	err := scanUnmannedRule(utils.DB.QueryRowContext(ctx,
+		"SELECT "+unmannedRuleColumns+" FROM unmanned_rule_table WHERE company_code = $1", companyCode), &rule)
+	if err != nil && err != sql.ErrNoRows {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if req.EntryGrades != nil {
+		rule.EntryGrades = *req.EntryGrades
+	}
+	if req.CheckInGrades != nil {
+		rule.CheckInGrades = *req.CheckInGrades
+	}
+	if req.AllowUngraded != nil {
+		rule.AllowUngraded = *req.AllowUngraded
+	}
+	if req.AllowCheckIn != nil {
+		rule.AllowCheckIn = *req.AllowCheckIn
+	}
+	if req.AllowPurchase != nil {
+		rule.AllowPurchase = *req.AllowPurchase
+	}
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+	if rule.EntryGrades == nil {
+		rule.EntryGrades = []int64{}
+	}
+	if rule.CheckInGrades == nil {
+		rule.CheckInGrades = []int64{}
+	}
+
+	log.Printf("무인 운영 규칙 저장 요청: %+v", rule)
+	err = scanUnmannedRule(utils.DB.QueryRowContext(ctx, `
+		INSERT INTO unmanned_rule_table
+		(company_code, entry_grades, check_in_grades, allow_ungraded, allow_check_in, allow_purchase,
+		 enabled, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		ON CONFLICT (company_code) DO UPDATE SET
+			entry_grades = EXCLUDED.entry_grades,
+			check_in_grades = EXCLUDED.check_in_grades,
+			allow_ungraded = EXCLUDED.allow_ungraded,
+			allow_check_in = EXCLUDED.allow_check_in,
+			allow_purchase = EXCLUDED.allow_purchase,
+			enabled = EXCLUDED.enabled,
+			updated_at = CURRENT_TIMESTAMP
+		RETURNING `+unmannedRuleColumns,
+		rule.CompanyCode, pq.Int64Array(rule.EntryGrades), pq.Int64Array(rule.CheckInGrades), rule.AllowUngraded,
+		rule.AllowCheckIn, rule.AllowPurchase, rule.Enabled), &rule)
+	if err != nil {
+		log.Printf("무인 운영 규칙 저장 오류: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rule)
+}
+
+// DeleteUnmannedRule: 업체의 무인 운영 규칙을 삭제합니다. 이후 무인 시간에도 제한이 적용되지 않습니다.
+func DeleteUnmannedRule(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	res, err := utils.DB.ExecContext(ctx, "DELETE FROM unmanned_rule_table WHERE company_code = $1", mux.Vars(r)["company_code"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		http.Error(w, "무인 운영 규칙이 없습니다.", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// EvaluateKioskAction: 키오스크에서 회원의 동작(entry, check_in, check_out, purchase) 허용 여부를 판정합니다.
+// 거부되어도 200으로 응답하며, allowed와 reason_code/reason으로 결과를 알립니다.
+func EvaluateKioskAction(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	companyCode := mux.Vars(r)["company_code"]
+	var req KioskEvaluateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "잘못된 요청 데이터", http.StatusBadRequest)
+		return
+	}
+	if req.MemberID <= 0 {
+		http.Error(w, "member_id는 필수입니다", http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	member, err := loadMember(ctx, companyCode, req.MemberID, now)
+	if err != nil && err != errNotCompanyMember {
+		if err == sql.ErrNoRows {
+			http.Error(w, "회원을 찾을 수 없습니다.", http.StatusNotFound)
+			return
+		}
+		log.Printf("회원 조회 오류: %v", err)
+		http.Error(w, "데이터 조회 중 오류가 발생했습니다", http.StatusInternalServerError)
+		return
+	}
+	mode, err := operatingModeAt(ctx, companyCode, now)
+	if err != nil {
+		log.Printf("운영 형태 조회 오류: %v", err)
+		http.Error(w, "데이터 조회 중 오류가 발생했습니다", http.StatusInternalServerError)
+		return
+	}
+	decision, err := evaluateKioskAction(ctx, companyCode, strings.TrimSpace(req.Action), member, req.SeatCode, mode)
+	if err != nil {
+		if err == errInvalidKioskAction {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("키오스크 판정 오류: %v", err)
+		http.Error(w, "데이터 조회 중 오류가 발생했습니다", http.StatusInternalServerError)
+		return
+	}
+	if !decision.Allowed {
+		log.Printf("키오스크 동작 거부: company_code=%s, member_id=%d, action=%s, reason=%s",
+			companyCode, req.MemberID, decision.Action, decision.ReasonCode)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(decision)
+}
+
+// filterKioskActions는 운영 형태와 무인 운영 규칙으로 허용되지 않는 동작을 걸러냅니다.
+func filterKioskActions(ctx context.Context, companyCode string, member Member, actions []string, mode OperatingMode) ([]string, error) {
+	allowed := []string{}
+	for _, action := range actions {
+		decision, err := evaluateKioskAction(ctx, companyCode, action, member, nil, mode)
+		if err != nil {
+			return nil, err
+		}
+		if decision.Allowed {
+			allowed = append(allowed, action)
+		}
+	}
+	return allowed, nil
+}
@@ -0,0 +1,371 @@
+// waiting_list.go
+package tables
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"narabackend/src/consts"
+	"narabackend/src/utils"
+)
+
+// 대기열 상태
+const (
+	WAITING_STATUS_WAITING   = "waiting"   // 대기 중
+	WAITING_STATUS_OFFERED   = "offered"   // 좌석 제안됨(수락 대기)
+	WAITING_STATUS_ACCEPTED  = "accepted"  // 제안 수락
+	WAITING_STATUS_EXPIRED   = "expired"   // 수락 기한 초과
+	WAITING_STATUS_CANCELLED = "cancelled" // 대기 취소/제안 거절
+)
+
+// WaitingEntry 구조체는 waiting_list_table의 각 컬럼을 매핑합니다.
+type WaitingEntry struct {
+	SerialNumber    int64      `json:"serial_number" db:"serial_number"`
+	CompanyCode     string     `json:"company_code" db:"company_code"`
+	RoomCode        int        `json:"room_code" db:"room_code"`
+	MemberID        int64      `json:"member_id" db:"member_id"`
+	Status          string     `json:"status" db:"status"`
+	OfferedSeatCode *int       `json:"offered_seat_code" db:"offered_seat_code"`
+	OfferedAt       *time.Time `json:"offered_at" db:"offered_at"`
+	OfferExpiresAt  *time.Time `json:"offer_expires_at" db:"offer_expires_at"`
+	RespondedAt     *time.Time `json:"responded_at" db:"responded_at"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	Position        *int       `json:"position,omitempty"` // 대기 순번 (waiting 상태에서만)
+}
+
+// WaitingEntryRequest는 대기열 등록 요청 시 사용되는 구조체입니다.
+type WaitingEntryRequest struct {
+	MemberID int64 `json:"member_id"`
+}
+
+// waitingEntryColumns는 waiting_list_table 조회 시 사용하는 컬럼 목록입니다.
+const waitingEntryColumns = `serial_number, company_code, room_code, member_id, status, offered_seat_code,
+	offered_at, offer_expires_at, responded_at, created_at`
+
+// scanWaitingEntry는 한 행을 WaitingEntry로 읽습니다.
+func scanWaitingEntry(row interface{ Scan(...interface{}) error }, e *WaitingEntry) error {
+	return row.Scan(&e.SerialNumber, &e.CompanyCode, &e.RoomCode, &e.MemberID, &e.Status, &e.OfferedSeatCode,
+		&e.OfferedAt, &e.OfferExpiresAt, &e.RespondedAt, &e.CreatedAt)
+}
+
+// offerFreedSeat는 비게 된 좌석을 해당 열람실 대기열의 첫 번째 대기자에게 제안합니다.
+// 좌석이 실제로 비어 있지 않거나 대기자가 없으면 아무것도 하지 않습니다. 실패는 로그만 남깁니다.
+func offerFreedSeat(ctx context.Context, companyCode string, seatCode int, now time.Time) {
+	entry, ok, err := offerFreedSeatTx(ctx, companyCode, seatCode, now)
+	if err != nil {
+		log.Printf("대기열 좌석 제안 실패: company_code=%s, seat_code=%d, 오류: %v", companyCode, seatCode, err)
+		return
+	}
+	if !ok {
+		return
+	}
+	log.Printf("대기열 좌석 제안: company_code=%s, room_code=%d, seat_code=%d, member_id=%d",
+		companyCode, entry.RoomCode, seatCode, entry.MemberID)
+
+	_, err = utils.SendControlCommand(ctx, utils.ControlCommand{
+		Command: utils.CONTROL_COMMAND_WAITING_OFFER,
+		Target:  utils.CONTROL_TARGET_DESK,
+		Message: utils.ControlMessage{
+			CompanyCode: companyCode,
+			UserCode:    strconv.FormatInt(entry.MemberID, 10),
+			RoomCode:    strconv.Itoa(entry.RoomCode),
+			SeatNumber:  strconv.Itoa(seatCode),
+		},
+	})
+	if err != nil {
+		log.Printf("대기열 제안 알림 실패: company_code=%s, seat_code=%d, 오류: %v", companyCode, seatCode, err)
+	}
+}
+
+// offerFreedSeatTx는 열람실 대기열을 잠그고 첫 번째 대기자에게 좌석을 제안합니다.
+func offerFreedSeatTx(ctx context.Context, companyCode string, seatCode int, now time.Time) (WaitingEntry, bool, error) {
+	var entry WaitingEntry
+
+	// 좌석이 속한 열람실을 찾습니다. seat_table에 없으면 대기열을 적용할 수 없습니다.
+	var roomCode int
+	err := utils.DB.QueryRowContext(ctx,
+		"SELECT room_code FROM seat_table WHERE company_code = $1 AND seat_code = $2",
+		companyCode, seatCode).Scan(&roomCode)
+	if err == sql.ErrNoRows {
+		return entry, false, nil
+	}
+	if err != nil {
+		return entry, false, err
+	}
+
+	tx, err := utils.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return entry, false, err
+	}
+	defer tx.Rollback()
+
+	// 같은 열람실의 제안을 직렬화하여 한 대기자에게 두 좌석이 제안되지 않도록 합니다.
+	_, err = tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock(hashtext('waiting:' || $1 || ':' || $2))",
+		companyCode, strconv.Itoa(roomCode))
+	if err != nil {
+		return entry, false, err
+	}
+
+	// 좌석이 실제로 비어 있는지 확인합니다 (열린 세션이 없고, 입실 때와 같은 기준으로 진행 중 예약이나 제안이 없어야 함).
+	// 청소 완료 전 배정을 막는 업체에서는 청소 대기 좌석도 제안하지 않습니다.
+	var busy bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT EXISTS(SELECT 1 FROM seat_session_table
+		              WHERE company_code = $1 AND seat_code = $2 AND check_out_at IS NULL)`,
+		companyCode, seatCode).Scan(&busy)
+	if err != nil {
+		return entry, false, err
+	}
+	if !busy {
+		busy, err = seatHeldByOther(ctx, tx, companyCode, seatCode, 0, now)
+		if err != nil {
+			return entry, false, err
+		}
+	}
+	if !busy {
+		busy, err = seatAwaitingCleaning(ctx, tx, companyCode, seatCode)
+		if err != nil {
+			return entry, false, err
+		}
+	}
+	if busy {
+		return entry, false, nil
+	}
+
+	expiresAt := now.Add(time.Duration(consts.WAITING_OFFER_TIMEOUT_MINUTES) * time.Minute)
+	err = scanWaitingEntry(tx.QueryRowContext(ctx, `
+		UPDATE waiting_list_table
+		SET status = $1, offered_seat_code = $2, offered_at = $3, offer_expires_at = $4
+		WHERE serial_number = (
+			SELECT serial_number FROM waiting_list_table
+			WHERE company_code = $5 AND room_code = $6 AND status = $7
+			ORDER BY created_at ASC, serial_number ASC
+			LIMIT 1
+		)
+		RETURNING `+waitingEntryColumns,
+		WAITING_STATUS_OFFERED, seatCode, now, expiresAt, companyCode, roomCode, WAITING_STATUS_WAITING), &entry)
+	if err == sql.ErrNoRows {
+		return entry, false, nil
+	}
+	if err != nil {
+		return entry, false, err
+	}
+	return entry, true, tx.Commit()
+}
+
+// expireWaitingOffers는 수락 기한이 지난 제안을 만료시키고, 해당 좌석을 다음 대기자에게 다시 제안합니다.
+func expireWaitingOffers(ctx context.Context, now time.Time) error {
+	rows, err := utils.DB.QueryContext(ctx, `
+		UPDATE waiting_list_table SET status = $1, responded_at = $2
+		WHERE status = $3 AND offer_expires_at <= $2
+		RETURNING company_code, offered_seat_code`,
+		WAITING_STATUS_EXPIRED, now, WAITING_STATUS_OFFERED)
+	if err != nil {
+		return err
+	}
+	type expiredOffer struct {
+		companyCode string
+		seatCode    int
+	}
+	var expired []expiredOffer
+	for rows.Next() {
+		var o expiredOffer
+		if err := rows.Scan(&o.companyCode, &o.seatCode); err != nil {
+			rows.Close()
+			return err
+		}
+		expired = append(expired, o)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, o := range expired {
+		log.Printf("대기열 제안 만료: company_code=%s, seat_code=%d", o.companyCode, o.seatCode)
+		offerFreedSeat(ctx, o.companyCode, o.seatCode, now)
+	}
+	return nil
+}
+
+// RegisterWaitingListRoutes는 waiting_list_table 관련 엔드포인트를 등록합니다.
+func RegisterWaitingListRoutes(r *mux.Router) {
+	r.HandleFunc("/companies/{company_code}/rooms/{room_code:[0-9]+}/waiting-list", GetWaitingList).Methods("GET")
+	r.HandleFunc("/companies/{company_code}/rooms/{room_code:[0-9]+}/waiting-list", JoinWaitingList).Methods("POST")
+	r.HandleFunc("/waiting-list/{id:[0-9]+}/accept", AcceptWaitingOffer).Methods("POST")
+	r.HandleFunc("/waiting-list/{id:[0-9]+}/decline", DeclineWaitingOffer).Methods("POST")
+	r.HandleFunc("/waiting-list/{id:[0-9]+}", CancelWaitingEntry).Methods("DELETE")
+}
+
+// GetWaitingList: 열람실의 진행 중인 대기열(waiting, offered)을 순번대로 조회합니다.
+func GetWaitingList(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	vars := mux.Vars(r)
+	rows, err := utils.DB.QueryContext(ctx, `
+		SELECT `+waitingEntryColumns+` FROM waiting_list_table
+		WHERE company_code = $1 AND room_code = $2 AND status IN ($3, $4)
+		ORDER BY created_at ASC, serial_number ASC`,
+		vars["company_code"], vars["room_code"], WAITING_STATUS_OFFERED, WAITING_STATUS_WAITING)
+	if err != nil {
+		log.Printf("데이터베이스 쿼리 오류: %v", err)
+		http.Error(w, "데이터 조회 중 오류가 발생했습니다", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	result := []WaitingEntry{}
+	position := 0
+	for rows.Next() {
+		var e WaitingEntry
+		if err := scanWaitingEntry(rows, &e); err != nil {
+			log.Printf("행 스캔 오류: %v", err)
+			http.Error(w, "데이터 처리 중 오류가 발생했습니다", http.StatusInternalServerError)
+			return
+		}
+		if e.Status == WAITING_STATUS_WAITING {
+			position++
+			p := position
+			e.Position = &p
+		}
+		result = append(result, e)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// JoinWaitingList: 회원을 열람실 대기열의 마지막에 등록합니다.
+func JoinWaitingList(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	vars := mux.Vars(r)
+	var req WaitingEntryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "잘못된 요청 데이터", http.StatusBadRequest)
+		return
+	}
+	if req.MemberID == 0 {
+		http.Error(w, "필수 필드가 누락되었습니다 (member_id)", http.StatusBadRequest)
+		return
+	}
+
+	var e WaitingEntry
+	err := scanWaitingEntry(utils.DB.QueryRowContext(ctx, `
+		INSERT INTO waiting_list_table (company_code, room_code, member_id, status, created_at)
+		VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP)
+		RETURNING `+waitingEntryColumns,
+		vars["company_code"], vars["room_code"], req.MemberID, WAITING_STATUS_WAITING), &e)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "duplicate key"):
+			http.Error(w, "이미 대기열에 등록된 회원입니다", http.StatusConflict)
+		case strings.Contains(err.Error(), "foreign key"):
+			http.Error(w, "존재하지 않는 회원입니다", http.StatusBadRequest)
+		default:
+			log.Printf("대기열 등록 오류: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(e)
+}
+
+// AcceptWaitingOffer: 제안된 좌석을 수락합니다. 수락 기한이 지났으면 409를 반환합니다.
+// 수락 후 입실은 /seat-sessions/check-in으로 진행합니다.
+func AcceptWaitingOffer(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	id, _ := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	var e WaitingEntry
+	err := scanWaitingEntry(utils.DB.QueryRowContext(ctx, `
+		UPDATE waiting_list_table SET status = $2, responded_at = CURRENT_TIMESTAMP
+		WHERE serial_number = $1 AND status = $3 AND offer_expires_at > CURRENT_TIMESTAMP
+		RETURNING `+waitingEntryColumns,
+		id, WAITING_STATUS_ACCEPTED, WAITING_STATUS_OFFERED), &e)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "수락할 수 있는 좌석 제안이 없습니다 (없거나 기한 초과)", http.StatusConflict)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(e)
+}
+
+// DeclineWaitingOffer: 제안된 좌석을 거절하고 다음 대기자에게 제안합니다.
+func DeclineWaitingOffer(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	id, _ := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	var e WaitingEntry
+	err := scanWaitingEntry(utils.DB.QueryRowContext(ctx, `
+		UPDATE waiting_list_table SET status = $2, responded_at = CURRENT_TIMESTAMP
+		WHERE serial_number = $1 AND status = $3
+		RETURNING `+waitingEntryColumns,
+		id, WAITING_STATUS_CANCELLED, WAITING_STATUS_OFFERED), &e)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "거절할 수 있는 좌석 제안이 없습니다", http.StatusConflict)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if e.OfferedSeatCode != nil {
+		offerFreedSeat(ctx, e.CompanyCode, *e.OfferedSeatCode, time.Now())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(e)
+}
+
+// CancelWaitingEntry: 대기열 등록을 취소합니다. 제안 중이던 좌석은 다음 대기자에게 제안합니다.
+func CancelWaitingEntry(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	id, _ := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	var e WaitingEntry
+	err := scanWaitingEntry(utils.DB.QueryRowContext(ctx, `
+		UPDATE waiting_list_table SET status = $2, responded_at = CURRENT_TIMESTAMP
+		WHERE serial_number = $1 AND status IN ($3, $4)
+		RETURNING `+waitingEntryColumns,
+		id, WAITING_STATUS_CANCELLED, WAITING_STATUS_WAITING, WAITING_STATUS_OFFERED), &e)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "취소할 수 있는 대기 등록이 없습니다", http.StatusNotFound)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if e.OfferedSeatCode != nil {
+		offerFreedSeat(ctx, e.CompanyCode, *e.OfferedSeatCode, time.Now())
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
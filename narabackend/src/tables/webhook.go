@@ -0,0 +1,453 @@
+// webhook.go
+package tables
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/lib/pq"
+
+	"narabackend/src/consts"
+	"narabackend/src/utils"
+)
+
+// Webhook 구조체는 webhook_table의 각 컬럼을 매핑합니다.
+// 서명 비밀키(secret)는 생성 응답과 비밀키를 바꾼 수정 응답에서만 반환합니다.
+type Webhook struct {
+	SerialNumber int64     `json:"serial_number" db:"serial_number"`
+	CompanyCode  string    `json:"company_code" db:"company_code"`
+	URL          string    `json:"url" db:"url"`
+	Secret       string    `json:"secret,omitempty" db:"secret"`
+	EventTypes   []string  `json:"event_types" db:"event_types"` // 비어 있으면 전체 이벤트
+	Enabled      bool      `json:"enabled" db:"enabled"`
+	Description  *string   `json:"description" db:"description"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// WebhookRequest는 웹훅 생성/수정 요청 시 사용되는 구조체입니다.
+// 수정 시 포인터 필드는 생략하면 기존 값을 유지합니다. 생성 시 secret을 생략하면 임의로 만듭니다.
+type WebhookRequest struct {
+	URL         *string   `json:"url"`
+	Secret      *string   `json:"secret"`
+	EventTypes  *[]string `json:"event_types"`
+	Enabled     *bool     `json:"enabled"`
+	Description *string   `json:"description"`
+}
+
+// WebhookDelivery 구조체는 webhook_delivery_table의 각 컬럼을 매핑합니다.
+type WebhookDelivery struct {
+	SerialNumber int64           `json:"serial_number" db:"serial_number"`
+	WebhookID    int64           `json:"webhook_id" db:"webhook_id"`
+	CompanyCode  string          `json:"company_code" db:"company_code"`
+	EventType    string          `json:"event_type" db:"event_type"`
+	Payload      json.RawMessage `json:"payload" db:"payload"`
+	Status       string          `json:"status" db:"status"`
+	Attempts     int             `json:"attempts" db:"attempts"`
+	ResponseCode *int            `json:"response_code" db:"response_code"`
+	ResponseBody *string         `json:"response_body" db:"response_body"`
+	LastError    *string         `json:"last_error" db:"last_error"`
+	DurationMs   *int            `json:"duration_ms" db:"duration_ms"`
+	CreatedAt    time.Time       `json:"created_at" db:"created_at"`
+	AttemptedAt  *time.Time      `json:"attempted_at" db:"attempted_at"`
+	DeliveredAt  *time.Time      `json:"delivered_at" db:"delivered_at"`
+}
+
+// webhookColumns는 webhook_table 조회 시 사용하는 컬럼 목록입니다.
+const webhookColumns = `serial_number, company_code, url, secret, event_types, enabled, description, created_at, updated_at`
+
+// scanWebhook은 webhookColumns 순서로 조회된 행을 Webhook 구조체로 변환합니다.
+func scanWebhook(row interface{ Scan(...interface{}) error }, h *Webhook) error {
+	var eventTypes pq.StringArray
+	err := row.Scan(&h.SerialNumber, &h.CompanyCode, &h.URL, &h.Secret, &eventTypes, &h.Enabled,
+		&h.Description, &h.CreatedAt, &h.UpdatedAt)
+	h.EventTypes = []string(eventTypes)
+	if h.EventTypes == nil {
+		h.EventTypes = []string{}
+	}
+	return err
+}
+
+// webhookDeliveryColumns는 webhook_delivery_table 조회 시 사용하는 컬럼 목록입니다.
+const webhookDeliveryColumns = `serial_number, webhook_id, company_code, event_type, payload, status, attempts,
+	response_code, response_body, last_error, duration_ms, created_at, attempted_at, delivered_at`
+
+// scanWebhookDelivery는 webhookDeliveryColumns 순서로 조회된 행을 WebhookDelivery 구조체로 변환합니다.
+func scanWebhookDelivery(row interface{ Scan(...interface{}) error }, d *WebhookDelivery) error {
+	var payload []byte
+	err := row.Scan(&d.SerialNumber, &d.WebhookID, &d.CompanyCode, &d.EventType, &payload, &d.Status, &d.Attempts,
+		&d.ResponseCode, &d.ResponseBody, &d.LastError, &d.DurationMs, &d.CreatedAt, &d.AttemptedAt, &d.DeliveredAt)
+	d.Payload = payload
+	return err
+}
+
+// loadWebhook은 회사의 웹훅을 조회합니다. 없으면 sql.ErrNoRows입니다.
+func loadWebhook(ctx context.Context, companyCode string, id int64) (Webhook, error) {
+	var h Webhook
+	err := scanWebhook(utils.DB.QueryRowContext(ctx,
+		"SELECT "+webhookColumns+" FROM webhook_table WHERE company_code = $1 AND serial_number = $2",
+		companyCode, id), &h)
+	return h, err
+}
+
+// validateWebhook은 수신 URL과 구독 이벤트 목록을 확인합니다.
+// localhost와 내부 IP 주소는 SetWebhookAllowPrivate로 허용한 경우에만 등록할 수 있습니다.
+// 도메인이 내부 주소로 해석되는 경우는 전송 시 접속 주소 검사(webhookDialControl)에서 막습니다.
+func validateWebhook(rawURL string, eventTypes []string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url은 http 또는 https 주소여야 합니다")
+	}
+	if !webhookAllowPrivate {
+		host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+		if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+			return errWebhookForbiddenAddress
+		}
+		if ip, err := netip.ParseAddr(host); err == nil && !webhookAddressAllowed(ip) {
+			return errWebhookForbiddenAddress
+		}
+	}
+	for _, eventType := range eventTypes {
+		if !webhookEventTypes[eventType] {
+			return fmt.Errorf("알 수 없는 이벤트 종류입니다: %s", eventType)
+		}
+	}
+	return nil
+}
+
+// RegisterWebhookRoutes는 업체 웹훅 구독과 전송 기록 관련 엔드포인트를 등록합니다.
+func RegisterWebhookRoutes(r *mux.Router) {
+	r.HandleFunc("/companies/{company_code}/webhooks", GetWebhooks).Methods("GET")
+	r.HandleFunc("/companies/{company_code}/webhooks", CreateWebhook).Methods("POST")
+	r.HandleFunc("/companies/{company_code}/webhooks/{webhook_id:[0-9]+}", GetWebhook).Methods("GET")
+	r.HandleFunc("/companies/{company_code}/webhooks/{webhook_id:[0-9]+}", UpdateWebhook).Methods("PUT", "PATCH")
+	r.HandleFunc("/companies/{company_code}/webhooks/{webhook_id:[0-9]+}", DeleteWebhook).Methods("DELETE")
+	r.HandleFunc("/companies/{company_code}/webhooks/{webhook_id:[0-9]+}/test", TestWebhook).Methods("POST")
+	r.HandleFunc("/companies/{company_code}/webhooks/{webhook_id:[0-9]+}/deliveries", GetWebhookDeliveries).Methods("GET")
+}
+
+// GetWebhooks: 회사의 웹훅 목록을 조회합니다.
+func GetWebhooks(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	rows, err := utils.DB.QueryContext(ctx,
+		"SELECT "+webhookColumns+" FROM webhook_table WHERE company_code = $1 ORDER BY serial_number",
+		mux.Vars(r)["company_code"])
+	if err != nil {
+		log.Printf("데이터베이스 쿼리 오류: %v", err)
+		http.Error(w, "데이터 조회 중 오류가 발생했습니다", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	result := []Webhook{}
+	for rows.Next() {
+		var h Webhook
+		if err := scanWebhook(rows, &h); err != nil {
+			log.Printf("행 스캔 오류: %v", err)
+			http.Error(w, "데이터 처리 중 오류가 발생했습니다", http.StatusInternalServerError)
+			return
+		}
+		h.Secret = ""
+		result = append(result, h)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// GetWebhook: 단일 웹훅을 조회합니다.
+func GetWebhook(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	vars := mux.Vars(r)
+	id, _ := strconv.ParseInt(vars["webhook_id"], 10, 64)
+	h, err := loadWebhook(ctx, vars["company_code"], id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "웹훅을 찾을 수 없습니다.", http.StatusNotFound)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	h.Secret = ""
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h)
+}
+
+// CreateWebhook: 웹훅을 등록합니다. 응답의 secret으로 X-Nara-Signature를 검증해야 합니다.
+func CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	companyCode := mux.Vars(r)["company_code"]
+	var req WebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "잘못된 요청 데이터", http.StatusBadRequest)
+		return
+	}
+	if req.URL == nil || *req.URL == "" {
+		http.Error(w, "필수 필드가 누락되었습니다 (url)", http.StatusBadRequest)
+		return
+	}
+	eventTypes := []string{}
+	if req.EventTypes != nil {
+		eventTypes = *req.EventTypes
+	}
+	if err := validateWebhook(*req.URL, eventTypes); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	secret := ""
+	if req.Secret != nil {
+		secret = *req.Secret
+	}
+	if secret == "" {
+		var err error
+		if secret, err = randomSecret(32); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+	description := ""
+	if req.Description != nil {
+		description = *req.Description
+	}
+
+	var h Webhook
+	err := scanWebhook(utils.DB.QueryRowContext(ctx, `
+		INSERT INTO webhook_table (company_code, url, secret, event_types, enabled, description, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		RETURNING `+webhookColumns,
+		companyCode, *req.URL, secret, pq.StringArray(eventTypes), enabled, nullableString(description)), &h)
+	if err != nil {
+		log.Printf("웹훅 저장 오류: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	log.Printf("웹훅 등록: company_code=%s, id=%d, url=%s, events=%v", companyCode, h.SerialNumber, utils.MaskSensitiveURL(h.URL), h.EventTypes)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(h)
+}
+
+// UpdateWebhook: 웹훅의 URL, 비밀키, 구독 이벤트, 사용 여부를 수정합니다.
+func UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	vars := mux.Vars(r)
+	companyCode := vars["company_code"]
+	id, _ := strconv.ParseInt(vars["webhook_id"], 10, 64)
+	var req WebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "잘못된 요청 데이터", http.StatusBadRequest)
+		return
+	}
+
+	h, err := loadWebhook(ctx, companyCode, id)
+	if err == sql.ErrNoRows {
+		http.Error(w, "웹훅을 찾을 수 없습니다.", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if req.URL != nil {
+		h.URL = *req.URL
+	}
+	secretChanged := req.Secret != nil && *req.Secret != "" && *req.Secret != h.Secret
+	if secretChanged {
+		h.Secret = *req.Secret
+	}
+	if req.EventTypes != nil {
+		h.EventTypes = *req.EventTypes
+	}
+	if req.Enabled != nil {
+		h.Enabled = *req.Enabled
+	}
+	if req.Description != nil {
+		h.Description = nullableString(*req.Description)
+	}
+	if err := validateWebhook(h.URL, h.EventTypes); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = scanWebhook(utils.DB.QueryRowContext(ctx, `
+		UPDATE webhook_table
+		SET url = $3, secret = $4, event_types = $5, enabled = $6, description = $7, updated_at = CURRENT_TIMESTAMP
+		WHERE company_code = $1 AND serial_number = $2
+		RETURNING `+webhookColumns,
+		companyCode, id, h.URL, h.Secret, pq.StringArray(h.EventTypes), h.Enabled, h.Description), &h)
+	if err != nil {
+		log.Printf("웹훅 수정 오류: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !secretChanged {
+		h.Secret = ""
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h)
+}
+
+// DeleteWebhook: 웹훅과 전송 기록을 삭제합니다. 대기 중인 전송 작업은 실행될 때 건너뜁니다.
+func DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	vars := mux.Vars(r)
+	id, _ := strconv.ParseInt(vars["webhook_id"], 10, 64)
+	res, err := utils.DB.ExecContext(ctx,
+		"DELETE FROM webhook_table WHERE company_code = $1 AND serial_number = $2", vars["company_code"], id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		http.Error(w, "웹훅을 찾을 수 없습니다.", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// TestWebhook: webhook.test 이벤트를 즉시 한 번 전송하고 결과(전송 기록)를 반환합니다.
+// 구독 이벤트와 사용 여부에 관계없이 전송하며, 실패해도 재시도하지 않습니다.
+// 수신 서버의 응답 본문은 반환하지 않고 응답 코드와 오류만 돌려줍니다.
+func TestWebhook(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT+consts.WEBHOOK_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	vars := mux.Vars(r)
+	companyCode := vars["company_code"]
+	id, _ := strconv.ParseInt(vars["webhook_id"], 10, 64)
+	h, err := loadWebhook(ctx, companyCode, id)
+	if err == sql.ErrNoRows {
+		http.Error(w, "웹훅을 찾을 수 없습니다.", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	tx, err := utils.DB.BeginTx(ctx, nil)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+	deliveryID, body, err := createWebhookDeliveryTx(ctx, tx, h.SerialNumber, companyCode, WEBHOOK_EVENT_TEST,
+		map[string]interface{}{"webhook_id": h.SerialNumber, "message": "narabackend 웹훅 테스트"})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	attempt := sendWebhook(ctx, h.URL, h.Secret, WEBHOOK_EVENT_TEST, deliveryID, body)
+	status := WEBHOOK_DELIVERY_SUCCEEDED
+	if attempt.Err != nil {
+		status = WEBHOOK_DELIVERY_FAILED
+	}
+	if err := recordWebhookAttempt(ctx, deliveryID, attempt, status); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var d WebhookDelivery
+	err = scanWebhookDelivery(utils.DB.QueryRowContext(ctx,
+		"SELECT "+webhookDeliveryColumns+" FROM webhook_delivery_table WHERE serial_number = $1", deliveryID), &d)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	d.ResponseBody = nil
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(d)
+}
+
+// GetWebhookDeliveries: 웹훅의 전송 기록을 최신순으로 조회합니다. status, event_type으로 거를 수 있습니다.
+func GetWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	vars := mux.Vars(r)
+	id, _ := strconv.ParseInt(vars["webhook_id"], 10, 64)
+	filters := []string{"company_code = $1", "webhook_id = $2"}
+	args := []interface{}{vars["company_code"], id}
+	paramIdx := 3
+
+	filterParams := map[string]string{
+		"status":     "status",
+		"event_type": "event_type",
+	}
+	for param, dbField := range filterParams {
+		if value := r.URL.Query().Get(param); value != "" {
+			filters = append(filters, fmt.Sprintf("%s = $%d", dbField, paramIdx))
+			args = append(args, value)
+			paramIdx++
+		}
+	}
+
+	page, pageSize, err := parsePagination(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	query := "SELECT " + webhookDeliveryColumns + " FROM webhook_delivery_table WHERE " + strings.Join(filters, " AND ") +
+		fmt.Sprintf(" ORDER BY created_at DESC, serial_number DESC LIMIT $%d OFFSET $%d", paramIdx, paramIdx+1)
+	args = append(args, pageSize, (page-1)*pageSize)
+
+	rows, err := utils.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("데이터베이스 쿼리 오류: %v", err)
+		http.Error(w, "데이터 조회 중 오류가 발생했습니다", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	result := []WebhookDelivery{}
+	for rows.Next() {
+		var d WebhookDelivery
+		if err := scanWebhookDelivery(rows, &d); err != nil {
+			log.Printf("행 스캔 오류: %v", err)
+			http.Error(w, "데이터 처리 중 오류가 발생했습니다", http.StatusInternalServerError)
+			return
+		}
+		result = append(result, d)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,363 @@
+// webhook_delivery.go
+package tables
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/lib/pq"
+
+	"narabackend/src/consts"
+	"narabackend/src/utils"
+)
+
+// 웹훅 이벤트 종류
+const (
+	WEBHOOK_EVENT_CHECKED_IN       = "session.checked_in"  // 입실
+	WEBHOOK_EVENT_CHECKED_OUT      = "session.checked_out" // 퇴실 (관리자 해제, 외출 초과 해제 포함)
+	WEBHOOK_EVENT_SESSION_EXPIRED  = "session.expired"     // 이용권 만료로 좌석 자동 해제
+	WEBHOOK_EVENT_PAYMENT_CREATED  = "payment.created"     // 결제
+	WEBHOOK_EVENT_PAYMENT_REFUNDED = "payment.refunded"    // 환불
+	WEBHOOK_EVENT_LOCKER_EXPIRED   = "locker.expired"      // 사물함 대여 만료
+	WEBHOOK_EVENT_TEST             = "webhook.test"        // 테스트 전송 (구독 여부와 관계없이 전송)
+)
+
+// webhookEventTypes는 구독할 수 있는 이벤트 종류입니다.
+var webhookEventTypes = map[string]bool{
+	WEBHOOK_EVENT_CHECKED_IN:       true,
+	WEBHOOK_EVENT_CHECKED_OUT:      true,
+	WEBHOOK_EVENT_SESSION_EXPIRED:  true,
+	WEBHOOK_EVENT_PAYMENT_CREATED:  true,
+	WEBHOOK_EVENT_PAYMENT_REFUNDED: true,
+	WEBHOOK_EVENT_LOCKER_EXPIRED:   true,
+}
+
+// 웹훅 전송 상태
+const (
+	WEBHOOK_DELIVERY_PENDING   = "pending"   // 전송 대기 (재시도 대기 포함)
+	WEBHOOK_DELIVERY_SUCCEEDED = "succeeded" // 2xx 응답
+	WEBHOOK_DELIVERY_FAILED    = "failed"    // 최대 시도 횟수 초과 또는 웹훅 비활성화
+)
+
+// 웹훅 요청 헤더
+const (
+	WEBHOOK_HEADER_EVENT     = "X-Nara-Event"
+	WEBHOOK_HEADER_DELIVERY  = "X-Nara-Delivery"
+	WEBHOOK_HEADER_TIMESTAMP = "X-Nara-Timestamp"
+	WEBHOOK_HEADER_SIGNATURE = "X-Nara-Signature"
+)
+
+// webhookResponseBodyLimit는 전송 기록에 저장하는 응답 본문의 최대 길이(바이트)입니다.
+const webhookResponseBodyLimit = 1024
+
+// webhookAllowPrivate가 true이면 내부 주소(루프백, 사설망, 링크 로컬)로도 웹훅을 보냅니다. 기본은 차단합니다.
+var webhookAllowPrivate bool
+
+// SetWebhookAllowPrivate는 내부 주소로의 웹훅 전송 허용 여부를 설정합니다 (설정 features.webhook_private_targets).
+// 업체가 임의의 URL을 등록할 수 있으므로 운영 환경에서는 켜지 않아야 합니다.
+func SetWebhookAllowPrivate(allow bool) {
+	webhookAllowPrivate = allow
+}
+
+// errWebhookForbiddenAddress는 웹훅 URL이 내부 주소를 가리키는 경우입니다.
+var errWebhookForbiddenAddress = errors.New("내부 주소로는 웹훅을 보낼 수 없습니다")
+
+// webhookBlockedPrefixes는 공인 유니캐스트 범위 중 추가로 막는 주소입니다.
+// 100.64.0.0/10에는 일부 클라우드의 메타데이터 주소가 있습니다.
+var webhookBlockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+}
+
+// webhookAddressAllowed는 웹훅을 보낼 수 있는 IP인지 반환합니다.
+// 루프백, 사설망, 링크 로컬(169.254.169.254 메타데이터 포함), 멀티캐스트, 미지정 주소는 막습니다.
+func webhookAddressAllowed(ip netip.Addr) bool {
+	if webhookAllowPrivate {
+		return true
+	}
+	ip = ip.Unmap()
+	if !ip.IsGlobalUnicast() || ip.IsPrivate() {
+		return false
+	}
+	for _, prefix := range webhookBlockedPrefixes {
+		if prefix.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// webhookDialControl은 DNS 조회가 끝난 실제 접속 주소를 검사합니다.
+// URL 등록 시점이 아니라 접속 직전에 확인하므로 DNS 리바인딩으로 내부 주소에 접속할 수 없습니다.
+func webhookDialControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil || !webhookAddressAllowed(ip) {
+		return fmt.Errorf("%w: %s", errWebhookForbiddenAddress, host)
+	}
+	return nil
+}
+
+// webhookHTTPClient는 웹훅 전송에 사용하는 HTTP 클라이언트입니다. 리다이렉트는 따라가지 않습니다.
+// 프록시를 거치면 접속 주소 검사를 우회하므로 환경 변수의 프록시 설정은 사용하지 않습니다.
+var webhookHTTPClient = &http.Client{
+	Timeout: time.Duration(consts.WEBHOOK_TIMEOUT) * time.Second,
+	Transport: &http.Transport{
+		Proxy: nil,
+		DialContext: (&net.Dialer{
+			Timeout: time.Duration(consts.WEBHOOK_TIMEOUT) * time.Second,
+			Control: webhookDialControl,
+		}).DialContext,
+		TLSHandshakeTimeout: time.Duration(consts.WEBHOOK_TIMEOUT) * time.Second,
+		MaxIdleConnsPerHost: 2,
+		IdleConnTimeout:     90 * time.Second,
+	},
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// deliverWebhookPayload는 DeliverWebhook 작업 데이터입니다.
+type deliverWebhookPayload struct {
+	DeliveryID int64 `json:"delivery_id"`
+}
+
+// WebhookEnvelope는 웹훅 요청 본문입니다.
+type WebhookEnvelope struct {
+	ID          int64       `json:"id"` // 전송ID (재시도해도 같음, 수신 측 중복 제거용)
+	Event       string      `json:"event"`
+	CompanyCode string      `json:"company_code"`
+	CreatedAt   time.Time   `json:"created_at"`
+	Data        interface{} `json:"data"`
+}
+
+// signWebhook은 "타임스탬프.본문"의 HMAC-SHA256 서명을 "sha256=<hex>" 형식으로 만듭니다.
+// 수신 측은 같은 방식으로 계산한 값과 X-Nara-Signature를 비교하고, 타임스탬프가 너무 오래되었으면 거부해야 합니다.
+func signWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// queueWebhookEventTx는 이벤트를 구독 중인 회사의 웹훅마다 전송 기록을 만들고 전송 작업을 작업 큐에 넣습니다.
+// 데이터 변경과 같은 트랜잭션에서 호출하므로, 롤백되면 웹훅도 전송되지 않습니다.
+func queueWebhookEventTx(ctx context.Context, tx *sql.Tx, companyCode, eventType string, data interface{}) error {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT serial_number FROM webhook_table
+		WHERE company_code = $1 AND enabled AND (cardinality(event_types) = 0 OR $2 = ANY(event_types))`,
+		companyCode, eventType)
+	if err != nil {
+		return err
+	}
+	var webhookIDs []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		webhookIDs = append(webhookIDs, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, webhookID := range webhookIDs {
+		if _, err := insertWebhookDeliveryTx(ctx, tx, webhookID, companyCode, eventType, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// queueWebhookEvent는 트랜잭션 밖의 변경(예: 사물함 만료)에 대한 웹훅 전송을 등록합니다. 실패하면 로그만 남깁니다.
+func queueWebhookEvent(ctx context.Context, companyCode, eventType string, data interface{}) {
+	tx, err := utils.DB.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("웹훅 등록 실패: company_code=%s, event=%s, 오류: %v", companyCode, eventType, err)
+		return
+	}
+	defer tx.Rollback()
+	if err := queueWebhookEventTx(ctx, tx, companyCode, eventType, data); err != nil {
+		log.Printf("웹훅 등록 실패: company_code=%s, event=%s, 오류: %v", companyCode, eventType, err)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("웹훅 등록 실패: company_code=%s, event=%s, 오류: %v", companyCode, eventType, err)
+	}
+}
+
+// insertWebhookDeliveryTx는 전송 기록을 만들고 DeliverWebhook 작업을 작업 큐에 넣습니다.
+func insertWebhookDeliveryTx(ctx context.Context, tx *sql.Tx, webhookID int64, companyCode, eventType string, data interface{}) (int64, error) {
+	id, _, err := createWebhookDeliveryTx(ctx, tx, webhookID, companyCode, eventType, data)
+	if err != nil {
+		return 0, err
+	}
+	_, err = utils.EnqueueJobTx(ctx, tx, utils.Job{
+		Name:           JOB_DELIVER_WEBHOOK,
+		Payload:        deliverWebhookPayload{DeliveryID: id},
+		IdempotencyKey: "webhook-delivery-" + strconv.FormatInt(id, 10),
+	})
+	return id, err
+}
+
+// createWebhookDeliveryTx는 pending 상태의 전송 기록을 만들고 전송ID와 요청 본문을 반환합니다.
+func createWebhookDeliveryTx(ctx context.Context, tx *sql.Tx, webhookID int64, companyCode, eventType string, data interface{}) (int64, []byte, error) {
+	var id int64
+	var createdAt time.Time
+	err := tx.QueryRowContext(ctx, `
+		INSERT INTO webhook_delivery_table (webhook_id, company_code, event_type, created_at)
+		VALUES ($1, $2, $3, CURRENT_TIMESTAMP)
+		RETURNING serial_number, created_at`,
+		webhookID, companyCode, eventType).Scan(&id, &createdAt)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	// 본문은 재시도해도 같아야 하므로 전송ID를 넣어 미리 만들어 둡니다.
+	payload, err := json.Marshal(WebhookEnvelope{ID: id, Event: eventType, CompanyCode: companyCode, CreatedAt: createdAt, Data: data})
+	if err != nil {
+		return 0, nil, err
+	}
+	_, err = tx.ExecContext(ctx, "UPDATE webhook_delivery_table SET payload = $2 WHERE serial_number = $1", id, payload)
+	return id, payload, err
+}
+
+// webhookAttempt는 웹훅 전송 한 번의 결과입니다.
+type webhookAttempt struct {
+	ResponseCode *int
+	ResponseBody string
+	Duration     time.Duration
+	Err          error
+}
+
+// sendWebhook은 서명한 본문을 웹훅 URL로 POST합니다. 2xx가 아닌 응답은 오류로 처리합니다.
+func sendWebhook(ctx context.Context, url, secret, eventType string, deliveryID int64, body []byte) (result webhookAttempt) {
+	start := time.Now()
+	defer func() { result.Duration = time.Since(start) }()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "narabackend-webhook")
+	req.Header.Set(WEBHOOK_HEADER_EVENT, eventType)
+	req.Header.Set(WEBHOOK_HEADER_DELIVERY, strconv.FormatInt(deliveryID, 10))
+	req.Header.Set(WEBHOOK_HEADER_TIMESTAMP, timestamp)
+	req.Header.Set(WEBHOOK_HEADER_SIGNATURE, signWebhook(secret, timestamp, body))
+
+	resp, err := webhookHTTPClient.Do(req)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	defer resp.Body.Close()
+	code := resp.StatusCode
+	result.ResponseCode = &code
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, webhookResponseBodyLimit))
+	// 잘린 멀티바이트 문자는 TEXT 컬럼에 저장할 수 없으므로 제거합니다.
+	result.ResponseBody = strings.ToValidUTF8(string(snippet), "")
+	if code < 200 || code >= 300 {
+		result.Err = fmt.Errorf("웹훅 응답 오류: %s", resp.Status)
+	}
+	return result
+}
+
+// recordWebhookAttempt는 전송 시도 결과와 상태를 전송 기록에 저장합니다.
+func recordWebhookAttempt(ctx context.Context, deliveryID int64, attempt webhookAttempt, status string) error {
+	var lastError *string
+	if attempt.Err != nil {
+		msg := attempt.Err.Error()
+		lastError = &msg
+	}
+	_, err := utils.DB.ExecContext(ctx, `
+		UPDATE webhook_delivery_table SET
+			status = $2, attempts = attempts + 1, response_code = $3, response_body = $4, last_error = $5,
+			duration_ms = $6, attempted_at = CURRENT_TIMESTAMP,
+			delivered_at = CASE WHEN $2 = $7 THEN CURRENT_TIMESTAMP ELSE delivered_at END
+		WHERE serial_number = $1`,
+		deliveryID, status, attempt.ResponseCode, nullableString(attempt.ResponseBody), lastError,
+		attempt.Duration.Milliseconds(), WEBHOOK_DELIVERY_SUCCEEDED)
+	return err
+}
+
+// errWebhookDisabled는 전송 전에 웹훅이 비활성화된 경우입니다.
+var errWebhookDisabled = errors.New("웹훅이 비활성화되었습니다")
+
+// deliverWebhook은 DeliverWebhook 작업을 처리합니다. 실패하면 작업 큐가 백오프로 재시도하며,
+// 마지막 시도까지 실패하면 전송 기록을 failed로 둡니다.
+func deliverWebhook(ctx context.Context, job utils.Job, payload deliverWebhookPayload) error {
+	var url, secret, eventType, status string
+	var enabled bool
+	var body []byte
+	err := utils.DB.QueryRowContext(ctx, `
+		SELECT w.url, w.secret, w.enabled, d.event_type, d.status, d.payload
+		FROM webhook_delivery_table d JOIN webhook_table w ON w.serial_number = d.webhook_id
+		WHERE d.serial_number = $1`, payload.DeliveryID).Scan(&url, &secret, &enabled, &eventType, &status, &body)
+	if err == sql.ErrNoRows {
+		// 웹훅이 삭제되면 전송 기록도 함께 삭제됩니다.
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if status != WEBHOOK_DELIVERY_PENDING {
+		return nil
+	}
+	if !enabled {
+		return recordWebhookAttempt(ctx, payload.DeliveryID, webhookAttempt{Err: errWebhookDisabled}, WEBHOOK_DELIVERY_FAILED)
+	}
+
+	attempt := sendWebhook(ctx, url, secret, eventType, payload.DeliveryID, body)
+	status = WEBHOOK_DELIVERY_SUCCEEDED
+	forbidden := errors.Is(attempt.Err, errWebhookForbiddenAddress)
+	if attempt.Err != nil {
+		status = WEBHOOK_DELIVERY_PENDING
+		if job.Attempt >= job.MaxAttempts || forbidden {
+			status = WEBHOOK_DELIVERY_FAILED
+		}
+	}
+	if err := recordWebhookAttempt(ctx, payload.DeliveryID, attempt, status); err != nil {
+		log.Printf("웹훅 전송 기록 실패 (delivery=%d): %v", payload.DeliveryID, err)
+	}
+	if forbidden {
+		// 내부 주소는 재시도해도 보낼 수 없습니다.
+		return utils.PermanentJobError(attempt.Err)
+	}
+	return attempt.Err
+}
+
+// PurgeWebhookDeliveries는 before 이전에 생성된 완료(succeeded, failed) 전송 기록을 삭제하고 삭제한 개수를 반환합니다.
+func PurgeWebhookDeliveries(ctx context.Context, before time.Time) (int64, error) {
+	res, err := utils.DB.ExecContext(ctx, `
+		DELETE FROM webhook_delivery_table WHERE status = ANY($1) AND created_at < $2`,
+		pq.StringArray{WEBHOOK_DELIVERY_SUCCEEDED, WEBHOOK_DELIVERY_FAILED}, before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
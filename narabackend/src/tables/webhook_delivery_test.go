@@ -0,0 +1,147 @@
+// webhook_delivery_test.go
+package tables
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+// allowPrivateWebhooks는 테스트 동안 내부 주소 전송 허용 여부를 바꾸고 끝나면 되돌립니다.
+func allowPrivateWebhooks(t *testing.T, allow bool) {
+	prev := webhookAllowPrivate
+	SetWebhookAllowPrivate(allow)
+	t.Cleanup(func() { SetWebhookAllowPrivate(prev) })
+}
+
+func TestSignWebhook(t *testing.T) {
+	body := []byte(`{"id":1}`)
+	sig := signWebhook("secret", "1700000000", body)
+	if sig != signWebhook("secret", "1700000000", body) {
+		t.Fatal("같은 입력의 서명이 다릅니다")
+	}
+	if len(sig) != len("sha256=")+64 || sig[:7] != "sha256=" {
+		t.Fatalf("서명 형식이 다릅니다: %s", sig)
+	}
+	for _, other := range []string{
+		signWebhook("other", "1700000000", body),
+		signWebhook("secret", "1700000001", body),
+		signWebhook("secret", "1700000000", []byte(`{"id":2}`)),
+	} {
+		if other == sig {
+			t.Fatal("secret, 타임스탬프, 본문 중 하나가 달라도 서명이 같습니다")
+		}
+	}
+}
+
+func TestSendWebhookDelivers(t *testing.T) {
+	allowPrivateWebhooks(t, true)
+
+	var received *http.Request
+	var receivedBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		receivedBody, _ = io.ReadAll(r.Body)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	body := []byte(`{"id":42,"event":"webhook.test"}`)
+	attempt := sendWebhook(context.Background(), server.URL, "secret", WEBHOOK_EVENT_TEST, 42, body)
+	if attempt.Err != nil {
+		t.Fatalf("sendWebhook: %v", attempt.Err)
+	}
+	if attempt.ResponseCode == nil || *attempt.ResponseCode != http.StatusOK || attempt.ResponseBody != "ok" {
+		t.Fatalf("응답 기록이 다릅니다: %+v", attempt)
+	}
+	if string(receivedBody) != string(body) {
+		t.Fatalf("본문이 다릅니다: %s", receivedBody)
+	}
+	if received.Header.Get(WEBHOOK_HEADER_EVENT) != WEBHOOK_EVENT_TEST || received.Header.Get(WEBHOOK_HEADER_DELIVERY) != "42" {
+		t.Fatalf("이벤트 헤더가 다릅니다: %v", received.Header)
+	}
+	timestamp := received.Header.Get(WEBHOOK_HEADER_TIMESTAMP)
+	if got, want := received.Header.Get(WEBHOOK_HEADER_SIGNATURE), signWebhook("secret", timestamp, body); got != want {
+		t.Fatalf("서명이 다릅니다: %s, want %s", got, want)
+	}
+}
+
+func TestSendWebhookNon2xxFails(t *testing.T) {
+	allowPrivateWebhooks(t, true)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://169.254.169.254/", http.StatusFound)
+	}))
+	defer server.Close()
+
+	// 리다이렉트는 따라가지 않고 실패로 기록합니다.
+	attempt := sendWebhook(context.Background(), server.URL, "secret", WEBHOOK_EVENT_TEST, 1, []byte(`{}`))
+	if attempt.Err == nil || attempt.ResponseCode == nil || *attempt.ResponseCode != http.StatusFound {
+		t.Fatalf("3xx 응답이 실패로 기록되지 않았습니다: %+v", attempt)
+	}
+}
+
+func TestSendWebhookBlocksPrivateAddress(t *testing.T) {
+	allowPrivateWebhooks(t, false)
+
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	attempt := sendWebhook(context.Background(), server.URL, "secret", WEBHOOK_EVENT_TEST, 1, []byte(`{}`))
+	if !errors.Is(attempt.Err, errWebhookForbiddenAddress) || called {
+		t.Fatalf("루프백 주소 전송이 차단되지 않았습니다: %v", attempt.Err)
+	}
+}
+
+func TestWebhookAddressAllowed(t *testing.T) {
+	allowPrivateWebhooks(t, false)
+
+	blocked := []string{
+		"127.0.0.1", "10.0.0.1", "172.16.0.1", "192.168.1.1", "169.254.169.254",
+		"100.100.100.200", "0.0.0.0", "::1", "fe80::1", "fd00:ec2::254", "::ffff:127.0.0.1", "224.0.0.1",
+	}
+	for _, addr := range blocked {
+		if webhookAddressAllowed(netip.MustParseAddr(addr)) {
+			t.Errorf("%s가 허용되었습니다", addr)
+		}
+	}
+	for _, addr := range []string{"8.8.8.8", "203.0.113.10", "2001:4860:4860::8888"} {
+		if !webhookAddressAllowed(netip.MustParseAddr(addr)) {
+			t.Errorf("%s가 차단되었습니다", addr)
+		}
+	}
+}
+
+func TestValidateWebhookURL(t *testing.T) {
+	allowPrivateWebhooks(t, false)
+
+	for _, rawURL := range []string{
+		"http://localhost:8080/hook", "http://127.0.0.1/hook", "http://[::1]/hook",
+		"http://169.254.169.254/latest/meta-data", "http://10.1.2.3/hook",
+	} {
+		if err := validateWebhook(rawURL, nil); !errors.Is(err, errWebhookForbiddenAddress) {
+			t.Errorf("%s: 내부 주소가 등록되었습니다 (%v)", rawURL, err)
+		}
+	}
+	if err := validateWebhook("https://hooks.example.com/nara", []string{WEBHOOK_EVENT_CHECKED_IN}); err != nil {
+		t.Errorf("공개 URL이 거부되었습니다: %v", err)
+	}
+	if err := validateWebhook("ftp://example.com", nil); err == nil {
+		t.Error("http(s)가 아닌 URL이 허용되었습니다")
+	}
+	if err := validateWebhook("https://example.com", []string{"unknown.event"}); err == nil {
+		t.Error("알 수 없는 이벤트가 허용되었습니다")
+	}
+
+	allowPrivateWebhooks(t, true)
+	if err := validateWebhook("http://localhost:8080/hook", nil); err != nil {
+		t.Errorf("내부 주소 허용 설정에서 거부되었습니다: %v", err)
+	}
+}
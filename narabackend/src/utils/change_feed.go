@@ -0,0 +1,161 @@
+// change_feed.go
+package utils
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// CHANGE_FEED_NOTIFY_CHANNEL은 change_event_table 트리거가 "회사코드:이벤트ID"를 알리는 Postgres NOTIFY 채널입니다.
+const CHANGE_FEED_NOTIFY_CHANNEL = "change_event"
+
+// ChangeEvent는 change_event_table에 기록된 열람실/좌석/세션/결제 변경 이벤트입니다.
+type ChangeEvent struct {
+	ID          int64           `json:"id"`
+	CompanyCode string          `json:"company_code"`
+	Entity      string          `json:"entity"`    // room, seat, seat_session, payment
+	Action      string          `json:"action"`    // created, updated, deleted
+	EntityID    *string         `json:"entity_id"` // room_code, seat_code, serial_number
+	Data        json.RawMessage `json:"data"`      // 변경 후 행 (삭제는 삭제 전 행)
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+// changeFeedSubscribers는 회사별로 새 변경 이벤트를 기다리는 구독자입니다.
+// 알림은 "새 이벤트가 있다"는 신호일 뿐이며, 구독자가 마지막 이벤트ID 이후를 DB에서 다시 조회합니다.
+var changeFeedSubscribers = struct {
+	sync.Mutex
+	byCompany map[string]map[chan struct{}]struct{}
+}{byCompany: map[string]map[chan struct{}]struct{}{}}
+
+// SubscribeChanges는 회사의 변경 알림 채널을 등록합니다. 반환한 함수로 구독을 해제해야 합니다.
+func SubscribeChanges(companyCode string) (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+	changeFeedSubscribers.Lock()
+	subs := changeFeedSubscribers.byCompany[companyCode]
+	if subs == nil {
+		subs = map[chan struct{}]struct{}{}
+		changeFeedSubscribers.byCompany[companyCode] = subs
+	}
+	subs[ch] = struct{}{}
+	changeFeedSubscribers.Unlock()
+
+	return ch, func() {
+		changeFeedSubscribers.Lock()
+		defer changeFeedSubscribers.Unlock()
+		delete(subs, ch)
+		if len(subs) == 0 {
+			delete(changeFeedSubscribers.byCompany, companyCode)
+		}
+	}
+}
+
+// wakeChangeSubscribers는 회사의 구독자를 깨웁니다. companyCode가 비어 있으면 모든 구독자를 깨웁니다.
+func wakeChangeSubscribers(companyCode string) {
+	changeFeedSubscribers.Lock()
+	defer changeFeedSubscribers.Unlock()
+	for company, subs := range changeFeedSubscribers.byCompany {
+		if companyCode != "" && company != companyCode {
+			continue
+		}
+		for ch := range subs {
+			select {
+			case ch <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+// StartChangeFeed는 change_event 채널을 LISTEN하여 변경 이벤트가 커밋되면 해당 회사의 구독자를 깨웁니다.
+// 어느 서버에서 변경되었든 모든 서버의 구독자가 알림을 받습니다.
+// LISTEN 연결이 끊어졌다 복구되면 놓친 알림이 있을 수 있으므로 모든 구독자를 깨웁니다.
+func StartChangeFeed(databaseURL string) {
+	listener := pq.NewListener(databaseURL, time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("변경 피드 LISTEN 연결 오류: %v", err)
+		}
+	})
+	if err := listener.Listen(CHANGE_FEED_NOTIFY_CHANNEL); err != nil {
+		// 연결이 복구되면 pq.Listener가 다시 LISTEN하며, 그 전까지 구독자는 주기 확인으로 이벤트를 받습니다.
+		log.Printf("변경 피드 LISTEN 실패, 주기 확인으로 전달합니다: %v", err)
+	}
+	log.Printf("변경 피드 시작 (channel=%s)", CHANGE_FEED_NOTIFY_CHANNEL)
+
+	go func() {
+		for n := range listener.Notify {
+			if n == nil {
+				wakeChangeSubscribers("")
+				continue
+			}
+			companyCode := n.Extra
+			if i := strings.LastIndex(companyCode, ":"); i >= 0 {
+				companyCode = companyCode[:i]
+			}
+			wakeChangeSubscribers(companyCode)
+		}
+	}()
+}
+
+// ChangeEventsAfter는 회사의 afterID 이후 변경 이벤트를 이벤트ID 순으로 최대 limit개 조회합니다.
+func ChangeEventsAfter(ctx context.Context, companyCode string, afterID int64, limit int) ([]ChangeEvent, error) {
+	rows, err := DB.QueryContext(ctx, `
+		SELECT serial_number, company_code, entity, action, entity_id, data, created_at
+		FROM change_event_table
+		WHERE company_code = $1 AND serial_number > $2
+		ORDER BY serial_number
+		LIMIT $3`, companyCode, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []ChangeEvent{}
+	for rows.Next() {
+		var ev ChangeEvent
+		var data []byte
+		if err := rows.Scan(&ev.ID, &ev.CompanyCode, &ev.Entity, &ev.Action, &ev.EntityID, &data, &ev.CreatedAt); err != nil {
+			return nil, err
+		}
+		ev.Data = data
+		events = append(events, ev)
+	}
+	return events, rows.Err()
+}
+
+// LatestChangeEventID는 회사의 마지막 변경 이벤트ID를 반환합니다. 이벤트가 없으면 0입니다.
+func LatestChangeEventID(ctx context.Context, companyCode string) (int64, error) {
+	var id int64
+	err := DB.QueryRowContext(ctx,
+		"SELECT COALESCE(MAX(serial_number), 0) FROM change_event_table WHERE company_code = $1", companyCode).Scan(&id)
+	return id, err
+}
+
+// ChangeEventsPurgedAfter는 afterID 이후의 이벤트 중 보관 기간이 지나 삭제된 것이 있을 수 있는지 반환합니다.
+// true이면 클라이언트는 이어 받기 대신 전체 데이터를 다시 조회해야 합니다.
+func ChangeEventsPurgedAfter(ctx context.Context, afterID int64) (bool, error) {
+	var oldest sql.NullInt64
+	err := DB.QueryRowContext(ctx, "SELECT MIN(serial_number) FROM change_event_table").Scan(&oldest)
+	if err != nil {
+		return false, err
+	}
+	return oldest.Valid && afterID < oldest.Int64-1, nil
+}
+
+// PurgeChangeEvents는 before 이전에 생성된 변경 이벤트를 삭제하고 삭제한 개수를 반환합니다.
+// 삭제 여부를 판단할 수 있도록(ChangeEventsPurgedAfter) 가장 최근 이벤트 하나는 남겨 둡니다.
+func PurgeChangeEvents(ctx context.Context, before time.Time) (int64, error) {
+	res, err := DB.ExecContext(ctx, `
+		DELETE FROM change_event_table
+		WHERE created_at < $1 AND serial_number < (SELECT MAX(serial_number) FROM change_event_table)`, before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
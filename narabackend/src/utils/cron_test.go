@@ -0,0 +1,88 @@
+// cron_test.go
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	at := func(year int, month time.Month, day, hour, minute int) time.Time {
+		return time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+	}
+	// 2026-03-10은 화요일, 2026-03-13은 금요일입니다.
+	tests := []struct {
+		name  string
+		expr  string
+		after time.Time
+		want  time.Time
+	}{
+		{"간격", "*/15 * * * *", at(2026, 3, 10, 10, 7), at(2026, 3, 10, 10, 15)},
+		{"after 자신은 제외", "*/15 * * * *", at(2026, 3, 10, 10, 15), at(2026, 3, 10, 10, 30)},
+		{"초 단위는 버림", "*/15 * * * *", at(2026, 3, 10, 10, 14).Add(59 * time.Second), at(2026, 3, 10, 10, 15)},
+		{"범위 간격", "0-10/5 * * * *", at(2026, 3, 10, 10, 7), at(2026, 3, 10, 10, 10)},
+		{"범위 간격 끝 이후", "0-10/5 * * * *", at(2026, 3, 10, 10, 10), at(2026, 3, 10, 11, 0)},
+		{"시작 값 간격", "5/20 * * * *", at(2026, 3, 10, 10, 30), at(2026, 3, 10, 10, 45)},
+		{"목록", "0 8,12,18 * * *", at(2026, 3, 10, 12, 0), at(2026, 3, 10, 18, 0)},
+		{"시 범위 다음 날", "30 9-17 * * *", at(2026, 3, 10, 17, 31), at(2026, 3, 11, 9, 30)},
+		{"요일 이름 범위", "0 9 * * MON-FRI", at(2026, 3, 13, 10, 0), at(2026, 3, 16, 9, 0)},
+		{"월 이름", "0 0 1 JAN *", at(2026, 3, 10, 0, 0), at(2027, 1, 1, 0, 0)},
+		{"소문자 이름", "0 0 1 jan *", at(2026, 3, 10, 0, 0), at(2027, 1, 1, 0, 0)},
+		{"0은 일요일", "0 12 * * 0", at(2026, 3, 10, 0, 0), at(2026, 3, 15, 12, 0)},
+		{"7도 일요일", "0 12 * * 7", at(2026, 3, 10, 0, 0), at(2026, 3, 15, 12, 0)},
+		{"일과 요일은 하나만 맞아도 실행", "0 0 13 * FRI", at(2026, 3, 1, 0, 0), at(2026, 3, 6, 0, 0)},
+		{"일과 요일 중 일이 먼저", "0 0 13 * FRI", at(2026, 3, 6, 0, 0), at(2026, 3, 13, 0, 0)},
+		{"요일만 지정", "0 0 * * FRI", at(2026, 3, 6, 0, 0), at(2026, 3, 13, 0, 0)},
+		{"없는 날은 다음 달로", "0 0 31 * *", at(2026, 4, 1, 0, 0), at(2026, 5, 31, 0, 0)},
+		{"연말 넘김", "* * * * *", at(2026, 12, 31, 23, 59), at(2027, 1, 1, 0, 0)},
+		{"윤년 2월 29일", "0 0 29 2 *", at(2026, 3, 1, 0, 0), at(2028, 2, 29, 0, 0)},
+		{"별칭", "@daily", at(2026, 3, 10, 10, 0), at(2026, 3, 11, 0, 0)},
+		{"2월 30일은 없음", "0 0 30 2 *", at(2026, 1, 1, 0, 0), time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := ParseCron(tt.expr)
+			if err != nil {
+				t.Fatalf("cron 식 %q 해석 실패: %v", tt.expr, err)
+			}
+			if got := s.Next(tt.after); !got.Equal(tt.want) {
+				t.Errorf("%q의 %s 다음 실행 시각이 %s입니다 (기대값 %s)", tt.expr, tt.after, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCronNextKeepsLocation(t *testing.T) {
+	seoul := time.FixedZone("KST", 9*60*60)
+	s, err := ParseCron("0 9 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := s.Next(time.Date(2026, 3, 10, 9, 0, 0, 0, seoul))
+	want := time.Date(2026, 3, 11, 9, 0, 0, 0, seoul)
+	if !got.Equal(want) || got.Location() != seoul {
+		t.Fatalf("다음 실행 시각이 %s입니다 (기대값 %s)", got, want)
+	}
+}
+
+func TestParseCronRejectsInvalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"* * * FOO *",
+		"* * * * MON-",
+		"a * * * *",
+	} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("잘못된 cron 식 %q가 허용되었습니다", expr)
+		}
+	}
+}
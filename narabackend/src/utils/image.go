@@ -0,0 +1,119 @@
+// image.go
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/jpeg"
+	"net/http"
+
+	_ "image/gif" // GIF 디코더 등록
+	_ "image/png" // PNG 디코더 등록
+)
+
+// 업로드 이미지 처리 한도
+const (
+	IMAGE_MAX_DIMENSION     = 8000 // 원본 가로/세로 최대 픽셀 (압축 폭탄 방지)
+	THUMBNAIL_MAX_DIMENSION = 320  // 썸네일 가로/세로 최대 픽셀
+	THUMBNAIL_JPEG_QUALITY  = 80
+)
+
+// 허용하는 이미지 MIME 타입과 저장 확장자
+var ImageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+var (
+	// ErrUnsupportedImage는 허용하지 않는 형식이거나 해석할 수 없는 이미지인 경우 반환됩니다.
+	ErrUnsupportedImage = errors.New("지원하지 않는 이미지 형식입니다 (jpeg, png, gif)")
+	// ErrImageTooLarge는 이미지 해상도가 한도를 넘는 경우 반환됩니다.
+	ErrImageTooLarge = errors.New("이미지 해상도가 너무 큽니다")
+)
+
+// ImageInfo는 검증된 업로드 이미지 정보입니다.
+type ImageInfo struct {
+	ContentType string
+	Extension   string
+	Width       int
+	Height      int
+}
+
+// InspectImage는 파일 내용으로 이미지 형식을 판별하고 해상도를 확인합니다.
+// 클라이언트가 보낸 Content-Type은 믿지 않습니다.
+func InspectImage(data []byte) (ImageInfo, error) {
+	var info ImageInfo
+	info.ContentType = http.DetectContentType(data)
+	ext, ok := ImageExtensions[info.ContentType]
+	if !ok {
+		return info, ErrUnsupportedImage
+	}
+	info.Extension = ext
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return info, ErrUnsupportedImage
+	}
+	if cfg.Width > IMAGE_MAX_DIMENSION || cfg.Height > IMAGE_MAX_DIMENSION {
+		return info, ErrImageTooLarge
+	}
+	info.Width, info.Height = cfg.Width, cfg.Height
+	return info, nil
+}
+
+// MakeThumbnail은 이미지를 비율을 유지하며 THUMBNAIL_MAX_DIMENSION 안으로 줄여 JPEG로 인코딩합니다.
+// 각 썸네일 픽셀은 대응하는 원본 영역의 평균이며, 투명한 부분은 흰 배경으로 채웁니다.
+func MakeThumbnail(data []byte) ([]byte, error) {
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+	bounds := src.Bounds()
+	sw, sh := bounds.Dx(), bounds.Dy()
+	if sw == 0 || sh == 0 {
+		return nil, ErrUnsupportedImage
+	}
+
+	dw, dh := sw, sh
+	if sw > THUMBNAIL_MAX_DIMENSION || sh > THUMBNAIL_MAX_DIMENSION {
+		if sw >= sh {
+			dw, dh = THUMBNAIL_MAX_DIMENSION, max(1, sh*THUMBNAIL_MAX_DIMENSION/sw)
+		} else {
+			dw, dh = max(1, sw*THUMBNAIL_MAX_DIMENSION/sh), THUMBNAIL_MAX_DIMENSION
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		y0 := bounds.Min.Y + y*sh/dh
+		y1 := max(y0+1, bounds.Min.Y+(y+1)*sh/dh)
+		for x := 0; x < dw; x++ {
+			x0 := bounds.Min.X + x*sw/dw
+			x1 := max(x0+1, bounds.Min.X+(x+1)*sw/dw)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := src.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(pr), g+uint64(pg), b+uint64(pb), a+uint64(pa)
+					n++
+				}
+			}
+			// 미리 곱해진(premultiplied) 색을 흰 배경 위에 합성합니다.
+			white := (0xffff*n - a)
+			i := dst.PixOffset(x, y)
+			dst.Pix[i+0] = uint8((r + white) / n >> 8)
+			dst.Pix[i+1] = uint8((g + white) / n >> 8)
+			dst.Pix[i+2] = uint8((b + white) / n >> 8)
+			dst.Pix[i+3] = 0xff
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: THUMBNAIL_JPEG_QUALITY}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,100 @@
+// job_admin.go
+package utils
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	ErrJobNotFound     = errors.New("작업을 찾을 수 없습니다")
+	ErrJobNotRetryable = errors.New("대기, dead, 취소 상태의 작업만 재시도할 수 있습니다")
+	ErrJobNotQueued    = errors.New("실행 대기 중인 작업만 취소할 수 있습니다")
+)
+
+// jobStatus는 작업의 현재 상태를 조회합니다.
+func jobStatus(ctx context.Context, id int64) (string, error) {
+	var status string
+	err := DB.QueryRowContext(ctx, "SELECT status FROM job_table WHERE serial_number = $1", id).Scan(&status)
+	if err == sql.ErrNoRows {
+		return "", ErrJobNotFound
+	}
+	return status, err
+}
+
+// RetryJob은 dead 또는 취소된 작업을 시도 횟수를 초기화하여 즉시 다시 실행하게 합니다.
+// 재시도 대기 중인 작업은 대기 시간 없이 바로 실행합니다. 실패 이력(error_history)은 유지합니다.
+func RetryJob(ctx context.Context, id int64) error {
+	res, err := DB.ExecContext(ctx, `
+		UPDATE job_table SET
+			status = $2,
+			attempts = CASE WHEN status = $2 THEN attempts ELSE 0 END,
+			run_at = CURRENT_TIMESTAMP, finished_at = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE serial_number = $1 AND status IN ($2, $3, $4)`,
+		id, JOB_STATUS_QUEUED, JOB_STATUS_DEAD, JOB_STATUS_CANCELLED)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		if _, err := jobStatus(ctx, id); err != nil {
+			return err
+		}
+		return ErrJobNotRetryable
+	}
+	atomic.AddInt64(&jobMetrics.retried, 1)
+	return nil
+}
+
+// CancelJob은 실행 대기 중인 작업을 취소합니다. 이미 실행 중인 작업은 다른 서버에서 실행될 수 있으므로 취소할 수 없습니다.
+func CancelJob(ctx context.Context, id int64) error {
+	res, err := DB.ExecContext(ctx, `
+		UPDATE job_table SET status = $2, finished_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE serial_number = $1 AND status = $3`,
+		id, JOB_STATUS_CANCELLED, JOB_STATUS_QUEUED)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		if _, err := jobStatus(ctx, id); err != nil {
+			return err
+		}
+		return ErrJobNotQueued
+	}
+	atomic.AddInt64(&jobMetrics.cancelled, 1)
+	return nil
+}
+
+// PurgeFinishedJobs는 before 이전에 완료(succeeded)되거나 취소(cancelled)된 작업을 삭제하고 삭제한 개수를 반환합니다.
+// dead 작업은 원인 확인 후 재시도할 수 있도록 남겨 둡니다.
+func PurgeFinishedJobs(ctx context.Context, before time.Time) (int64, error) {
+	res, err := DB.ExecContext(ctx, `
+		DELETE FROM job_table WHERE status IN ($1, $2) AND finished_at < $3`,
+		JOB_STATUS_SUCCEEDED, JOB_STATUS_CANCELLED, before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// PurgeDeadJobs는 dead 작업(dead-letter)을 삭제하고 삭제한 개수를 반환합니다.
+// name이 있으면 해당 작업만, before가 있으면 그 이전에 dead가 된 작업만 삭제합니다.
+func PurgeDeadJobs(ctx context.Context, name string, before *time.Time) (int64, error) {
+	var nameArg, beforeArg interface{}
+	if name != "" {
+		nameArg = name
+	}
+	if before != nil {
+		beforeArg = *before
+	}
+	res, err := DB.ExecContext(ctx, `
+		DELETE FROM job_table
+		WHERE status = $1 AND ($2::text IS NULL OR name = $2) AND ($3::timestamp IS NULL OR finished_at < $3)`,
+		JOB_STATUS_DEAD, nameArg, beforeArg)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
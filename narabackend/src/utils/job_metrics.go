@@ -0,0 +1,72 @@
+// job_metrics.go
+package utils
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// JobMetrics는 이 서버가 시작된 뒤의 작업 큐 처리 통계입니다.
+// 여러 서버를 운영하면 서버마다 따로 집계되며, 재시작하면 0부터 다시 셉니다.
+type JobMetrics struct {
+	Since     time.Time `json:"since"`
+	Enqueued  int64     `json:"enqueued"`  // 큐에 새로 넣은 작업 (멱등 키로 걸러진 작업 제외, 트랜잭션 롤백 포함)
+	Succeeded int64     `json:"succeeded"` // 성공한 실행
+	Failed    int64     `json:"failed"`    // 실패한 실행 (재시도 예정과 dead 모두 포함)
+	Retried   int64     `json:"retried"`   // 실패 후 재시도 대기열에 다시 넣은 횟수 (관리자 재시도 포함)
+	Dead      int64     `json:"dead"`      // dead가 된 작업
+	Cancelled int64     `json:"cancelled"` // 관리자가 취소한 작업
+
+	// 큐 대기 시간: 실행 가능 시각(run_at)부터 워커가 가져갈 때까지
+	LatencyCount     int64   `json:"latency_count"`
+	LatencyAvgMillis float64 `json:"latency_avg_ms"`
+	LatencyMaxMillis int64   `json:"latency_max_ms"`
+}
+
+var jobMetrics struct {
+	enqueued, succeeded, failed, retried, dead, cancelled int64
+
+	latencyMu    sync.Mutex
+	latencyCount int64
+	latencySum   time.Duration
+	latencyMax   time.Duration
+}
+
+var jobMetricsSince = time.Now()
+
+// recordJobLatency는 작업 하나의 큐 대기 시간을 기록합니다.
+func recordJobLatency(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	jobMetrics.latencyMu.Lock()
+	defer jobMetrics.latencyMu.Unlock()
+	jobMetrics.latencyCount++
+	jobMetrics.latencySum += d
+	if d > jobMetrics.latencyMax {
+		jobMetrics.latencyMax = d
+	}
+}
+
+// JobMetricsSnapshot은 현재까지의 작업 큐 처리 통계를 반환합니다.
+func JobMetricsSnapshot() JobMetrics {
+	m := JobMetrics{
+		Since:     jobMetricsSince,
+		Enqueued:  atomic.LoadInt64(&jobMetrics.enqueued),
+		Succeeded: atomic.LoadInt64(&jobMetrics.succeeded),
+		Failed:    atomic.LoadInt64(&jobMetrics.failed),
+		Retried:   atomic.LoadInt64(&jobMetrics.retried),
+		Dead:      atomic.LoadInt64(&jobMetrics.dead),
+		Cancelled: atomic.LoadInt64(&jobMetrics.cancelled),
+	}
+
+	jobMetrics.latencyMu.Lock()
+	defer jobMetrics.latencyMu.Unlock()
+	m.LatencyCount = jobMetrics.latencyCount
+	if jobMetrics.latencyCount > 0 {
+		m.LatencyAvgMillis = float64(jobMetrics.latencySum.Milliseconds()) / float64(jobMetrics.latencyCount)
+	}
+	m.LatencyMaxMillis = jobMetrics.latencyMax.Milliseconds()
+	return m
+}
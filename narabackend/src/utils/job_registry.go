@@ -0,0 +1,159 @@
+// job_registry.go
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// JobHandlerFunc는 작업 이름별로 등록하는 작업 처리 함수입니다.
+// 오류를 반환하면 재시도하며, PermanentJobError로 감싼 오류는 재시도하지 않고 dead로 둡니다.
+type JobHandlerFunc func(ctx context.Context, job Job) error
+
+// JobHandlerOptions는 작업 처리기의 실행 옵션입니다.
+type JobHandlerOptions struct {
+	Timeout     time.Duration // 한 번 실행의 제한 시간 (0이면 consts.DEFAULT_WORK_TIMEOUT)
+	Concurrency int           // 이 서버에서 동시에 실행할 수 있는 최대 개수 (0이면 제한 없음)
+	MaxAttempts int           // 큐에 넣을 때 Job.MaxAttempts가 없으면 사용할 최대 시도 횟수 (0이면 consts.JOB_MAX_ATTEMPTS)
+}
+
+// jobHandler는 등록된 작업 처리기와 현재 실행 중인 개수입니다.
+type jobHandler struct {
+	name    string
+	options JobHandlerOptions
+	fn      JobHandlerFunc
+	running int
+}
+
+var (
+	jobHandlersMu sync.Mutex
+	jobHandlers   = map[string]*jobHandler{}
+)
+
+// RegisterJobHandler는 작업 이름에 처리기를 등록합니다. 같은 이름이면 교체됩니다.
+// 워커를 시작하기 전에 등록해야 합니다.
+func RegisterJobHandler(name string, options JobHandlerOptions, fn JobHandlerFunc) {
+	jobHandlersMu.Lock()
+	defer jobHandlersMu.Unlock()
+	jobHandlers[name] = &jobHandler{name: name, options: options, fn: fn}
+	log.Printf("작업 처리기 등록: %s (timeout=%s, concurrency=%d)", name, options.Timeout, options.Concurrency)
+}
+
+// RegisterTypedJobHandler는 작업 데이터를 T로 해석하여 전달하는 처리기를 등록합니다.
+// 데이터를 T로 해석할 수 없는 작업은 재시도해도 소용없으므로 바로 dead로 둡니다.
+func RegisterTypedJobHandler[T any](name string, options JobHandlerOptions, fn func(ctx context.Context, job Job, payload T) error) {
+	RegisterJobHandler(name, options, func(ctx context.Context, job Job) error {
+		var payload T
+		if err := DecodeJobData(job, &payload); err != nil {
+			return PermanentJobError(fmt.Errorf("작업 데이터 해석 실패: %w", err))
+		}
+		return fn(ctx, job, payload)
+	})
+}
+
+// JobHandlerNames는 등록된 작업 이름 목록을 정렬하여 반환합니다.
+func JobHandlerNames() []string {
+	jobHandlersMu.Lock()
+	defer jobHandlersMu.Unlock()
+	names := make([]string, 0, len(jobHandlers))
+	for name := range jobHandlers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// HasJobHandler는 작업 이름에 처리기가 등록되어 있는지 확인합니다.
+func HasJobHandler(name string) bool {
+	jobHandlersMu.Lock()
+	defer jobHandlersMu.Unlock()
+	_, ok := jobHandlers[name]
+	return ok
+}
+
+// jobHandlerOptions는 등록된 처리기의 옵션을 반환합니다.
+func jobHandlerOptions(name string) (JobHandlerOptions, bool) {
+	jobHandlersMu.Lock()
+	defer jobHandlersMu.Unlock()
+	h, ok := jobHandlers[name]
+	if !ok {
+		return JobHandlerOptions{}, false
+	}
+	return h.options, true
+}
+
+// saturatedJobNames는 동시 실행 한도에 도달한 작업 이름 목록입니다. 워커는 이 작업들을 가져가지 않습니다.
+func saturatedJobNames() []string {
+	jobHandlersMu.Lock()
+	defer jobHandlersMu.Unlock()
+	names := []string{}
+	for name, h := range jobHandlers {
+		if h.options.Concurrency > 0 && h.running >= h.options.Concurrency {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// acquireJobHandler는 처리기 실행 자리를 확보합니다.
+// 처리기가 없으면 found=false이고, 동시 실행 한도에 도달했으면 ok=false입니다.
+func acquireJobHandler(name string) (h *jobHandler, found bool, ok bool) {
+	jobHandlersMu.Lock()
+	defer jobHandlersMu.Unlock()
+	h, found = jobHandlers[name]
+	if !found {
+		return nil, false, false
+	}
+	if h.options.Concurrency > 0 && h.running >= h.options.Concurrency {
+		return h, true, false
+	}
+	h.running++
+	return h, true, true
+}
+
+// releaseJobHandler는 acquireJobHandler로 확보한 실행 자리를 반납합니다.
+func releaseJobHandler(h *jobHandler) {
+	jobHandlersMu.Lock()
+	defer jobHandlersMu.Unlock()
+	h.running--
+}
+
+// DecodeJobData는 작업 데이터를 v(구조체 포인터 등)로 해석합니다.
+func DecodeJobData(job Job, v interface{}) error {
+	raw := job.raw
+	if raw == nil {
+		var err error
+		if raw, err = json.Marshal(job.Data); err != nil {
+			return err
+		}
+	}
+	return json.Unmarshal(raw, v)
+}
+
+// permanentJobError는 재시도하지 않을 작업 오류입니다.
+type permanentJobError struct {
+	err error
+}
+
+func (e *permanentJobError) Error() string { return e.err.Error() }
+func (e *permanentJobError) Unwrap() error { return e.err }
+
+// PermanentJobError는 재시도해도 성공할 수 없는 오류임을 표시합니다. 작업은 바로 dead가 됩니다.
+func PermanentJobError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentJobError{err: err}
+}
+
+// isPermanentJobError는 오류가 PermanentJobError로 표시되었는지 확인합니다.
+func isPermanentJobError(err error) bool {
+	var p *permanentJobError
+	return errors.As(err, &p)
+}
@@ -3,10 +3,19 @@ package utils
 import (
 	"log"
 	"net/http"
-	"os"
+	"strconv"
+	"strings"
 	"time"
 )
 
+// debugLogging이 true이면 요청 헤더도 로깅합니다. 시작 시 SetDebugLogging으로 설정합니다.
+var debugLogging bool
+
+// SetDebugLogging은 요청 헤더 로깅 여부를 설정합니다 (설정 features.debug, 환경 변수 DEBUG).
+func SetDebugLogging(enabled bool) {
+	debugLogging = enabled
+}
+
 // LoggingMiddleware: 모든 HTTP 요청을 로깅하는 미들웨어
 func LoggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -29,7 +38,7 @@ func LoggingMiddleware(next http.Handler) http.Handler {
 		log.Printf("[요청] %s %s FROM %s", r.Method, r.URL.Path, clientIP)
 
 		// 요청 헤더 로깅 (디버깅 목적)
-		if os.Getenv("DEBUG") == "true" {
+		if debugLogging {
 			for name, values := range r.Header {
 				log.Printf("[헤더] %s: %s", name, values)
 			}
@@ -66,17 +75,101 @@ func (rw *responseWrapper) Write(b []byte) (int, error) {
 	return size, err
 }
 
-// CorsMiddleware: CORS 관련 헤더를 추가하는 미들웨어
-func CorsMiddleware(h http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// 실제 운영환경에서는 허용할 도메인을 제한하세요.
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, X-Fields")
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
-			return
+// Flush는 원래 ResponseWriter가 지원하면 버퍼의 응답을 바로 전송합니다 (Server-Sent Events용).
+func (rw *responseWrapper) Flush() {
+	if f, ok := rw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// CorsOptions는 CorsMiddleware의 교차 출처 요청 정책입니다.
+type CorsOptions struct {
+	AllowedOrigins   []string // 허용할 출처 (scheme://host[:port]). "*"이면 모든 출처 허용
+	AllowedMethods   []string
+	AllowedHeaders   []string // "*"이면 preflight에서 요청한 헤더를 모두 허용
+	ExposedHeaders   []string // 브라우저 스크립트에서 읽을 수 있게 할 응답 헤더
+	AllowCredentials bool     // 쿠키, Authorization 등 인증 정보를 포함한 요청 허용
+	MaxAge           int      // preflight 결과 캐시 시간(초). 0이면 보내지 않음
+}
+
+// CorsMiddleware: 허용 목록에 있는 출처에만 CORS 헤더를 추가하는 미들웨어
+// 허용되지 않은 출처의 preflight(OPTIONS) 요청은 403으로 거부하고, 일반 요청은 CORS 헤더 없이 처리하여 브라우저가 응답을 차단하게 합니다.
+// AllowCredentials이면 "*" 대신 요청 Origin을 그대로 돌려줍니다.
+func CorsMiddleware(opts CorsOptions) func(http.Handler) http.Handler {
+	allowAll := false
+	allowed := make(map[string]bool, len(opts.AllowedOrigins))
+	for _, origin := range opts.AllowedOrigins {
+		if origin == "*" {
+			allowAll = true
 		}
-		h.ServeHTTP(w, r)
-	})
+		allowed[strings.ToLower(origin)] = true
+	}
+	allowAnyHeader := false
+	for _, header := range opts.AllowedHeaders {
+		if header == "*" {
+			allowAnyHeader = true
+		}
+	}
+	methods := strings.Join(opts.AllowedMethods, ", ")
+	headers := strings.Join(opts.AllowedHeaders, ", ")
+	exposed := strings.Join(opts.ExposedHeaders, ", ")
+	maxAge := ""
+	if opts.MaxAge > 0 {
+		maxAge = strconv.Itoa(opts.MaxAge)
+	}
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+			if !allowAll || opts.AllowCredentials {
+				// 출처마다 응답이 달라지므로 캐시가 출처별로 저장하도록 합니다.
+				w.Header().Add("Vary", "Origin")
+			}
+
+			if origin != "" && !allowAll && !allowed[strings.ToLower(origin)] {
+				if preflight {
+					http.Error(w, "허용되지 않은 출처입니다", http.StatusForbidden)
+					return
+				}
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			if origin != "" {
+				if allowAll && !opts.AllowCredentials {
+					w.Header().Set("Access-Control-Allow-Origin", "*")
+				} else {
+					w.Header().Set("Access-Control-Allow-Origin", origin)
+				}
+				if opts.AllowCredentials {
+					w.Header().Set("Access-Control-Allow-Credentials", "true")
+				}
+				if exposed != "" && !preflight {
+					w.Header().Set("Access-Control-Expose-Headers", exposed)
+				}
+			}
+
+			if r.Method != http.MethodOptions {
+				h.ServeHTTP(w, r)
+				return
+			}
+			if preflight {
+				w.Header().Add("Vary", "Access-Control-Request-Method")
+				w.Header().Add("Vary", "Access-Control-Request-Headers")
+				w.Header().Set("Access-Control-Allow-Methods", methods)
+				if allowAnyHeader {
+					w.Header().Set("Access-Control-Allow-Headers", r.Header.Get("Access-Control-Request-Headers"))
+				} else {
+					w.Header().Set("Access-Control-Allow-Headers", headers)
+				}
+				if maxAge != "" {
+					w.Header().Set("Access-Control-Max-Age", maxAge)
+				}
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		})
+	}
 }
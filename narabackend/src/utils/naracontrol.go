@@ -0,0 +1,210 @@
+// naracontrol.go
+package utils
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// naracontrol 제어 명령 (naracontrol config 패키지의 명령 상수와 동일해야 합니다)
+const (
+	CONTROL_COMMAND_POWER_ON           = "power_on"
+	CONTROL_COMMAND_POWER_OFF          = "power_off"
+	CONTROL_COMMAND_SEAT_RELEASED      = "seat_released"
+	CONTROL_COMMAND_OUTING_WARNING     = "outing_warning"
+	CONTROL_COMMAND_OUTING_OVERRUN     = "outing_overrun"
+	CONTROL_COMMAND_WAITING_OFFER      = "waiting_offer"
+	CONTROL_COMMAND_LOCKER_EXPIRED     = "locker_expired"
+	CONTROL_COMMAND_CLEANING_LIGHT_ON  = "cleaning_light_on"
+	CONTROL_COMMAND_CLEANING_LIGHT_OFF = "cleaning_light_off"
+)
+
+// naracontrol 변경 이벤트 (naracontrol config 패키지의 이벤트 상수와 동일해야 합니다)
+const (
+	CONTROL_EVENT_ROOM_UPDATED    = "room_updated"
+	CONTROL_EVENT_SEAT_UPDATED    = "seat_updated"
+	CONTROL_EVENT_SEAT_OCCUPIED   = "seat_occupied"
+	CONTROL_EVENT_SEAT_VACATED    = "seat_vacated"
+	CONTROL_EVENT_POWER_STATE_ON  = "power_state_on"
+	CONTROL_EVENT_POWER_STATE_OFF = "power_state_off"
+)
+
+// 제어 명령 수신 대상 클라이언트 타입
+const (
+	CONTROL_TARGET_DESK   = "naradesk"
+	CONTROL_TARGET_DEVICE = "naradevice"
+)
+
+// ControlMessage는 naracontrol 메시지의 좌석 정보입니다.
+type ControlMessage struct {
+	CompanyCode string `json:"companyCode"`
+	UserCode    string `json:"userCode"`
+	RoomCode    string `json:"roomCode"`
+	SeatNumber  string `json:"seatNumber"`
+	PowerNumber string `json:"powerNumber"`
+}
+
+// ControlCommand는 naracontrol 내부 API(/internal/commands)로 보내는 명령입니다.
+// EventID가 있으면 장치가 처리 결과(Ack)를 같은 이벤트ID로 /internal/command-acks에 돌려줍니다.
+type ControlCommand struct {
+	Command string         `json:"command"`
+	Target  string         `json:"target"`
+	EventID string         `json:"eventId,omitempty"`
+	Message ControlMessage `json:"message"`
+}
+
+// ControlEvent는 naracontrol 내부 API(/internal/events)로 보내는 변경 이벤트입니다.
+// naracontrol은 같은 EventID를 한 번만 전달하므로 릴레이가 다시 보내도 클라이언트는 한 번만 받습니다.
+type ControlEvent struct {
+	EventID string         `json:"eventId"`
+	Event   string         `json:"event"`
+	Targets []string       `json:"targets"`
+	Message ControlMessage `json:"message"`
+}
+
+// ControlAck는 naracontrol이 전달하는 장치의 명령 처리 결과입니다.
+type ControlAck struct {
+	EventID string         `json:"eventId"`
+	Status  string         `json:"status"` // ok 또는 error
+	Detail  string         `json:"detail"`
+	Message ControlMessage `json:"message"`
+}
+
+// 장치 명령 처리 결과 상태
+const (
+	CONTROL_ACK_OK    = "ok"
+	CONTROL_ACK_ERROR = "error"
+)
+
+// controlHTTPClient는 naracontrol 호출에 사용하는 HTTP 클라이언트입니다.
+var controlHTTPClient = &http.Client{Timeout: 5 * time.Second}
+
+// naracontrol 연동 설정 (SetNaracontrol로 설정)
+var (
+	naracontrolURL    string
+	naracontrolAPIKey string
+)
+
+// SetNaracontrol은 naracontrol 주소와 내부 API 키를 설정합니다. 명령이나 이벤트를 보내기 전에 호출해야 합니다.
+func SetNaracontrol(baseURL, apiKey string) {
+	naracontrolURL = strings.TrimRight(baseURL, "/")
+	naracontrolAPIKey = apiKey
+}
+
+// NaracontrolEnabled는 naracontrol 주소가 설정되어 명령 전송이 가능한지 반환합니다.
+func NaracontrolEnabled() bool {
+	return naracontrolURL != ""
+}
+
+// ValidNaracontrolKey는 naracontrol이 보낸 X-Internal-Key 값이 내부 API 키와 같은지 확인합니다.
+// 키가 설정되지 않았으면 항상 false입니다.
+func ValidNaracontrolKey(provided string) bool {
+	return naracontrolAPIKey != "" && subtle.ConstantTimeCompare([]byte(provided), []byte(naracontrolAPIKey)) == 1
+}
+
+// SendControlCommand는 naracontrol에 제어 명령을 보내고 수신한 클라이언트 수를 반환합니다.
+// naracontrol 주소가 없으면 아무것도 하지 않고 0을 반환합니다.
+func SendControlCommand(ctx context.Context, cmd ControlCommand) (int, error) {
+	baseURL := naracontrolURL
+	if baseURL == "" {
+		return 0, nil
+	}
+
+	body, err := json.Marshal(cmd)
+	if err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+"/internal/commands", bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Internal-Key", naracontrolAPIKey)
+
+	resp, err := controlHTTPClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("naracontrol 응답 오류: %s", resp.Status)
+	}
+
+	var result struct {
+		Recipients int `json:"recipients"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, err
+	}
+	log.Printf("naracontrol 명령 전송: %s -> %s (회사코드: %s, 좌석: %s, 전원: %s, 수신자: %d)",
+		cmd.Command, cmd.Target, cmd.Message.CompanyCode, cmd.Message.SeatNumber, cmd.Message.PowerNumber, result.Recipients)
+	return result.Recipients, nil
+}
+
+// SendControlEvent는 naracontrol에 변경 이벤트를 보내고 수신한 클라이언트 수를 반환합니다.
+// naracontrol 주소가 없으면 아무것도 하지 않고 0을 반환합니다.
+func SendControlEvent(ctx context.Context, ev ControlEvent) (int, error) {
+	baseURL := naracontrolURL
+	if baseURL == "" {
+		return 0, nil
+	}
+
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+"/internal/events", bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Internal-Key", naracontrolAPIKey)
+
+	resp, err := controlHTTPClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, &controlStatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+
+	var result struct {
+		Recipients int  `json:"recipients"`
+		Duplicate  bool `json:"duplicate"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, err
+	}
+	if !result.Duplicate {
+		log.Printf("naracontrol 이벤트 전송: %s -> %v (회사코드: %s, 방: %s, 좌석: %s, 수신자: %d)",
+			ev.Event, ev.Targets, ev.Message.CompanyCode, ev.Message.RoomCode, ev.Message.SeatNumber, result.Recipients)
+	}
+	return result.Recipients, nil
+}
+
+// controlStatusError는 naracontrol이 200이 아닌 응답을 돌려준 경우의 오류입니다.
+type controlStatusError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e *controlStatusError) Error() string {
+	return fmt.Sprintf("naracontrol 응답 오류: %s", e.Status)
+}
+
+// ControlNumberString은 NULL 가능한 방/좌석/전원 번호를 메시지 형식 문자열로 변환합니다.
+func ControlNumberString(number *int) string {
+	if number == nil {
+		return ""
+	}
+	return strconv.Itoa(*number)
+}
@@ -0,0 +1,254 @@
+// outbox.go
+package utils
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/lib/pq"
+
+	"narabackend/src/consts"
+)
+
+// 이벤트 상태 (outbox_table.status)
+const (
+	OUTBOX_STATUS_PENDING   = "pending"   // 전달 대기 (재시도 대기 포함)
+	OUTBOX_STATUS_DELIVERED = "delivered" // 전달 완료
+	OUTBOX_STATUS_FAILED    = "failed"    // 최대 시도 횟수 초과 또는 naracontrol이 거부한 이벤트
+)
+
+// OUTBOX_NOTIFY_CHANNEL은 outbox에 이벤트가 기록되면 릴레이를 깨우는 Postgres NOTIFY 채널입니다.
+const OUTBOX_NOTIFY_CHANNEL = "outbox_event"
+
+// OutboxEvent는 데이터 변경과 함께 기록하여 naracontrol로 전달할 변경 이벤트입니다.
+type OutboxEvent struct {
+	Event   string         // CONTROL_EVENT_*
+	Targets []string       // CONTROL_TARGET_*
+	Message ControlMessage // Message.CompanyCode 회사의 클라이언트에게 전달됩니다
+}
+
+// WriteOutboxTx는 변경 이벤트를 데이터 변경과 같은 트랜잭션으로 outbox에 기록합니다.
+// 트랜잭션이 롤백되면 이벤트도 남지 않고, 커밋되면 NOTIFY로 릴레이를 깨웁니다.
+// NARACONTROL_URL이 없으면 전달할 곳이 없으므로 기록하지 않습니다.
+func WriteOutboxTx(ctx context.Context, tx *sql.Tx, ev OutboxEvent) error {
+	if !NaracontrolEnabled() {
+		return nil
+	}
+	message, err := json.Marshal(ev.Message)
+	if err != nil {
+		return err
+	}
+	var id int64
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO outbox_table (company_code, event, targets, message)
+		VALUES ($1, $2, $3, $4)
+		RETURNING serial_number`,
+		ev.Message.CompanyCode, ev.Event, pq.StringArray(ev.Targets), message).Scan(&id)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, "SELECT pg_notify($1, $2)", OUTBOX_NOTIFY_CHANNEL, fmt.Sprint(id))
+	return err
+}
+
+// outboxRow는 릴레이가 가져온 전달 대기 이벤트입니다.
+type outboxRow struct {
+	id        int64
+	event     ControlEvent
+	attempts  int
+	decodeErr error // 메시지를 해석할 수 없으면 오류
+}
+
+// StartOutboxRelay는 outbox의 변경 이벤트를 naracontrol로 전달하는 백그라운드 작업을 시작합니다.
+// 이벤트가 기록되면 LISTEN/NOTIFY로 바로 전달하고, 알림을 놓친 경우(연결 끊김, 재시도 대기)를 위해 interval마다 다시 확인합니다.
+// 여러 서버가 실행되어도 SKIP LOCKED와 임대(next_attempt_at)로 같은 이벤트를 동시에 전달하지 않으며,
+// 다시 전달되더라도 naracontrol이 이벤트ID(outbox-<serial_number>)로 중복을 걸러냅니다.
+func StartOutboxRelay(databaseURL string, interval time.Duration) {
+	if !NaracontrolEnabled() {
+		log.Printf("outbox 릴레이 비활성화 (NARACONTROL_URL 없음)")
+		return
+	}
+	if interval <= 0 {
+		log.Printf("outbox 릴레이 비활성화 (interval=%s)", interval)
+		return
+	}
+
+	listener := pq.NewListener(databaseURL, time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("outbox LISTEN 연결 오류: %v", err)
+		}
+	})
+	if err := listener.Listen(OUTBOX_NOTIFY_CHANNEL); err != nil {
+		// 연결이 복구되면 pq.Listener가 다시 LISTEN하며, 그 전까지는 주기 확인으로 전달합니다.
+		log.Printf("outbox LISTEN 실패, 주기 확인으로 전달합니다: %v", err)
+	}
+	log.Printf("outbox 릴레이 시작 (interval=%s)", interval)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			for relayOutboxBatch() {
+			}
+			select {
+			case <-listener.Notify:
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// relayOutboxBatch는 전달 대기 이벤트를 생성 순서대로 한 묶음 가져와 naracontrol로 전달합니다.
+// 묶음을 모두 전달하여 남은 이벤트가 더 있을 수 있으면 true를 반환합니다.
+// naracontrol에 연결할 수 없으면 뒤의 이벤트도 실패하므로 해당 이벤트만 재시도 대기로 두고 멈춥니다.
+// 가져온 이벤트는 next_attempt_at을 묶음 전달 시간만큼 미뤄(임대) 다른 서버가 가져가지 않게 하고,
+// naracontrol 호출은 트랜잭션 밖에서 합니다. 서버가 전달 중 중단되면 임대가 끝난 뒤 다시 전달됩니다.
+func relayOutboxBatch() bool {
+	if DB == nil {
+		return false
+	}
+	// 이벤트 하나당 naracontrol 호출 제한 시간(controlHTTPClient)과 DB 작업 여유를 더한 시간
+	timeout := time.Duration(consts.OUTBOX_BATCH_SIZE)*controlHTTPClient.Timeout + time.Duration(consts.DEFAULT_QUERY_TIMEOUT)*time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	batch, err := claimOutboxBatch(ctx, timeout)
+	if err != nil {
+		log.Printf("outbox 조회 실패: %v", err)
+		return false
+	}
+	if len(batch) == 0 {
+		return false
+	}
+
+	stopped := false
+	for i, row := range batch {
+		if row.decodeErr != nil {
+			// 메시지를 해석할 수 없는 이벤트는 다시 시도해도 같으므로 보내지 않고 failed로 둡니다.
+			_, err = DB.ExecContext(ctx, `
+				UPDATE outbox_table SET status = $2, last_error = $3
+				WHERE serial_number = $1`, row.id, OUTBOX_STATUS_FAILED, row.decodeErr.Error())
+			log.Printf("❌ outbox 메시지 해석 실패: %s (id=%d), 오류: %v", row.event.Event, row.id, row.decodeErr)
+			if err != nil {
+				log.Printf("outbox 결과 기록 실패 (id=%d): %v", row.id, err)
+				return false
+			}
+			continue
+		}
+
+		recipients, sendErr := SendControlEvent(ctx, row.event)
+		if sendErr == nil {
+			_, err = DB.ExecContext(ctx, `
+				UPDATE outbox_table SET status = $2, attempts = attempts + 1, recipients = $3,
+					last_error = NULL, delivered_at = CURRENT_TIMESTAMP
+				WHERE serial_number = $1`, row.id, OUTBOX_STATUS_DELIVERED, recipients)
+		} else {
+			// naracontrol이 요청 자체를 거부(400)한 이벤트는 다시 보내도 실패하므로 바로 failed로 둡니다.
+			var statusErr *controlStatusError
+			rejected := errors.As(sendErr, &statusErr) && statusErr.StatusCode == http.StatusBadRequest
+			attempt := row.attempts + 1
+			if rejected || attempt >= consts.OUTBOX_MAX_ATTEMPTS {
+				_, err = DB.ExecContext(ctx, `
+					UPDATE outbox_table SET status = $2, attempts = attempts + 1, last_error = $3
+					WHERE serial_number = $1`, row.id, OUTBOX_STATUS_FAILED, sendErr.Error())
+				log.Printf("❌ outbox 이벤트 전달 실패: %s (id=%d, 시도 %d/%d), 오류: %v",
+					row.event.Event, row.id, attempt, consts.OUTBOX_MAX_ATTEMPTS, sendErr)
+			} else {
+				delay := retryDelay(attempt)
+				_, err = DB.ExecContext(ctx, `
+					UPDATE outbox_table SET attempts = attempts + 1, last_error = $2,
+						next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $3)
+					WHERE serial_number = $1`, row.id, sendErr.Error(), delay.Seconds())
+				log.Printf("outbox 이벤트 전달 실패, %s 후 재시도: %s (id=%d, 시도 %d/%d), 오류: %v",
+					delay.Round(time.Second), row.event.Event, row.id, attempt, consts.OUTBOX_MAX_ATTEMPTS, sendErr)
+			}
+			stopped = !rejected
+		}
+		if err != nil {
+			log.Printf("outbox 결과 기록 실패 (id=%d): %v", row.id, err)
+			return false
+		}
+		if stopped {
+			releaseOutboxRows(ctx, batch[i+1:])
+			break
+		}
+	}
+	return !stopped && len(batch) == consts.OUTBOX_BATCH_SIZE
+}
+
+// claimOutboxBatch는 전달 대기 이벤트를 한 묶음 가져오면서 next_attempt_at을 lease만큼 미룹니다.
+// 여러 서버가 동시에 가져가도 SKIP LOCKED로 같은 이벤트를 두 번 가져가지 않으며, 잠금은 이 문장이 끝나면 풀립니다.
+// 메시지를 해석할 수 없는 이벤트는 decodeErr에 오류를 담아 반환합니다.
+func claimOutboxBatch(ctx context.Context, lease time.Duration) ([]outboxRow, error) {
+	rows, err := DB.QueryContext(ctx, `
+		UPDATE outbox_table SET next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $3)
+		WHERE serial_number IN (
+			SELECT serial_number FROM outbox_table
+			WHERE status = $1 AND next_attempt_at <= CURRENT_TIMESTAMP
+			ORDER BY serial_number
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED)
+		RETURNING serial_number, event, targets, message, attempts`,
+		OUTBOX_STATUS_PENDING, consts.OUTBOX_BATCH_SIZE, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var batch []outboxRow
+	for rows.Next() {
+		var row outboxRow
+		var targets pq.StringArray
+		var message []byte
+		if err := rows.Scan(&row.id, &row.event.Event, &targets, &message, &row.attempts); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(message, &row.event.Message); err != nil {
+			row.decodeErr = fmt.Errorf("메시지 해석 실패: %w", err)
+		}
+		row.event.EventID = fmt.Sprintf("outbox-%d", row.id)
+		row.event.Targets = targets
+		batch = append(batch, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	// RETURNING은 순서를 보장하지 않으므로 생성 순서로 다시 정렬합니다.
+	sort.Slice(batch, func(i, j int) bool { return batch[i].id < batch[j].id })
+	return batch, nil
+}
+
+// releaseOutboxRows는 전달을 멈춰 보내지 않은 이벤트의 임대를 풀어 다음 확인 때 바로 다시 가져가게 합니다.
+func releaseOutboxRows(ctx context.Context, rows []outboxRow) {
+	if len(rows) == 0 {
+		return
+	}
+	ids := make([]int64, len(rows))
+	for i, row := range rows {
+		ids[i] = row.id
+	}
+	_, err := DB.ExecContext(ctx, `
+		UPDATE outbox_table SET next_attempt_at = CURRENT_TIMESTAMP
+		WHERE serial_number = ANY($1) AND status = $2`, pq.Int64Array(ids), OUTBOX_STATUS_PENDING)
+	if err != nil {
+		log.Printf("outbox 임대 해제 실패: %v", err)
+	}
+}
+
+// PurgeOutbox는 before 이전에 생성된 전달 완료 및 실패 이벤트를 삭제하고 삭제한 개수를 반환합니다.
+func PurgeOutbox(ctx context.Context, before time.Time) (int64, error) {
+	res, err := DB.ExecContext(ctx, `
+		DELETE FROM outbox_table WHERE status IN ($1, $2) AND created_at < $3`,
+		OUTBOX_STATUS_DELIVERED, OUTBOX_STATUS_FAILED, before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
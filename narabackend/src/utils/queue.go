@@ -4,9 +4,18 @@ package utils
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
+	"math/rand"
+	"os"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/lib/pq"
+
 	"narabackend/src/consts"
 )
 
@@ -16,11 +25,29 @@ var DB *sql.DB
 // EnqueueJobFunc는 작업을 큐에 추가하는 함수의 타입입니다.
 type EnqueueJobFunc func(job Job)
 
+// 작업 상태 (job_table.status)
+const (
+	JOB_STATUS_QUEUED    = "queued"    // 실행 대기 (재시도 대기 포함)
+	JOB_STATUS_RUNNING   = "running"   // 워커가 실행 중
+	JOB_STATUS_SUCCEEDED = "succeeded" // 완료
+	JOB_STATUS_DEAD      = "dead"      // 최대 시도 횟수를 넘겨 더 이상 실행하지 않음 (dead-letter)
+	JOB_STATUS_CANCELLED = "cancelled" // 관리자가 실행 전에 취소
+)
+
 // Job 구조체는 비동기 작업을 표현합니다.
+// ID, Attempt는 워커가 실행할 때 채워집니다. 큐에 넣을 때 Payload가 있으면 Data 대신 Payload를 JSON으로 저장합니다.
 type Job struct {
-	Name     string
-	Data     map[string]interface{}
-	Priority int // 높을수록 우선순위 높음
+	ID             int64
+	Name           string
+	Data           map[string]interface{}
+	Payload        interface{} // 구조체 데이터 (처리기에서 RegisterTypedJobHandler의 T로 받음)
+	Priority       int         // 높을수록 우선순위 높음
+	IdempotencyKey string      // 같은 Name과 키의 작업이 이미 있으면 새로 넣지 않음
+	Attempt        int         // 이번 실행이 몇 번째 시도인지 (1부터)
+	MaxAttempts    int         // 0이면 처리기 옵션, 없으면 consts.JOB_MAX_ATTEMPTS
+	RunAt          time.Time   // 이 시각 이후에 실행 (0이면 즉시)
+
+	raw []byte // 큐에서 읽은 원본 JSON 데이터
 }
 
 // 작업 큐에 추가하기 위한 함수 참조
@@ -31,58 +58,377 @@ func SetEnqueueJobFunc(fn EnqueueJobFunc) {
 	EnqueueJobHandler = fn
 }
 
-// jobQueue는 버퍼링된 채널로, 최대 100개의 작업을 저장할 수 있습니다.
-var jobQueue = make(chan Job, 100)
+// execer는 *sql.DB와 *sql.Tx가 함께 구현하는 쿼리 인터페이스입니다.
+type execer interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// insertJob은 job_table에 작업을 추가하고 작업 번호를 반환합니다.
+// 멱등 키가 같은 작업이 이미 있으면 새로 넣지 않고 기존 작업 번호를 반환합니다.
+func insertJob(ctx context.Context, q execer, job Job) (int64, error) {
+	var data interface{} = job.Data
+	if job.Payload != nil {
+		data = job.Payload
+	} else if job.Data == nil {
+		data = map[string]interface{}{}
+	}
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return 0, fmt.Errorf("작업 데이터 인코딩 실패: %w", err)
+	}
+	maxAttempts := job.MaxAttempts
+	if maxAttempts <= 0 {
+		if options, ok := jobHandlerOptions(job.Name); ok && options.MaxAttempts > 0 {
+			maxAttempts = options.MaxAttempts
+		} else {
+			maxAttempts = consts.JOB_MAX_ATTEMPTS
+		}
+	}
+	var idempotencyKey *string
+	if job.IdempotencyKey != "" {
+		idempotencyKey = &job.IdempotencyKey
+	}
+	var runAt *time.Time
+	if !job.RunAt.IsZero() {
+		runAt = &job.RunAt
+	}
+
+	var id int64
+	err = q.QueryRowContext(ctx, `
+		INSERT INTO job_table (name, payload, priority, idempotency_key, status, max_attempts, run_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, COALESCE($7, CURRENT_TIMESTAMP), CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		ON CONFLICT (name, idempotency_key) WHERE idempotency_key IS NOT NULL DO NOTHING
+		RETURNING serial_number`,
+		job.Name, payload, job.Priority, idempotencyKey, JOB_STATUS_QUEUED, maxAttempts, runAt).Scan(&id)
+	if err == nil {
+		atomic.AddInt64(&jobMetrics.enqueued, 1)
+	}
+	if err == sql.ErrNoRows && idempotencyKey != nil {
+		err = q.QueryRowContext(ctx,
+			"SELECT serial_number FROM job_table WHERE name = $1 AND idempotency_key = $2",
+			job.Name, job.IdempotencyKey).Scan(&id)
+	}
+	return id, err
+}
+
+// EnqueueJobContext는 작업을 job_table에 저장하고 작업 번호를 반환합니다.
+func EnqueueJobContext(ctx context.Context, job Job) (int64, error) {
+	if DB == nil {
+		return 0, errors.New("데이터베이스가 연결되지 않았습니다")
+	}
+	return insertJob(ctx, DB, job)
+}
 
-// EnqueueJob은 작업을 큐에 추가합니다.
+// EnqueueJobTx는 데이터 변경과 같은 트랜잭션 안에서 작업을 저장합니다.
+// 트랜잭션이 롤백되면 작업도 함께 취소됩니다.
+func EnqueueJobTx(ctx context.Context, tx *sql.Tx, job Job) (int64, error) {
+	return insertJob(ctx, tx, job)
+}
+
+// EnqueueJob은 작업을 큐에 추가합니다. 저장에 실패하면 작업 내용과 함께 오류를 기록합니다.
 func EnqueueJob(job Job) {
-	select {
-	case jobQueue <- job:
-		log.Printf("Job enqueued: %s", job.Name)
-	default:
-		log.Printf("Job queue full, dropping job: %s", job.Name)
+	timeout := time.Duration(consts.SHORT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	id, err := EnqueueJobContext(ctx, job)
+	if err != nil {
+		log.Printf("❌ 작업 저장 실패: %s (데이터: %v), 오류: %v", job.Name, job.Data, err)
+		return
 	}
+	log.Printf("Job enqueued: %s (id=%d, priority=%d)", job.Name, id, job.Priority)
 }
 
 // StartJobWorker는 백그라운드에서 큐의 작업을 처리하는 워커를 시작합니다.
 func StartJobWorker() {
-	go func() {
-		for job := range jobQueue {
-			processJob(job)
-		}
-	}()
+	StartJobWorkers(1)
 }
 
+// reaperOnce는 여러 번 워커를 시작해도 잠금 만료 작업 회수기를 하나만 실행하도록 합니다.
+var reaperOnce sync.Once
+
 // 워커 수를 구성 가능하게 만듦
+// 워커마다 job_table에서 실행 가능한 작업을 우선순위 순으로 하나씩 가져와 처리합니다.
 func StartJobWorkers(workerCount int) {
+	hostname, _ := os.Hostname()
 	for i := 0; i < workerCount; i++ {
-		go func(id int) {
-			log.Printf("Worker %d started", id)
-			for job := range jobQueue {
-				processJob(job)
+		workerID := fmt.Sprintf("%s-%d-%d", hostname, os.Getpid(), i)
+		go func(id string) {
+			log.Printf("Worker %s started", id)
+			pollInterval := time.Duration(consts.JOB_POLL_INTERVAL) * time.Second
+			for {
+				processed, err := runNextJob(id)
+				if err != nil {
+					log.Printf("작업 가져오기 오류 (worker=%s): %v", id, err)
+				}
+				if !processed {
+					time.Sleep(pollInterval)
+				}
+			}
+		}(workerID)
+	}
+
+	reaperOnce.Do(func() {
+		go func() {
+			interval := time.Duration(consts.JOB_VISIBILITY_TIMEOUT) * time.Second / 4
+			for {
+				reapExpiredJobs()
+				time.Sleep(interval)
+			}
+		}()
+	})
+}
+
+// claimJob은 실행 가능한 작업 하나를 잠그고 running으로 바꿉니다. 없으면 sql.ErrNoRows입니다.
+// 여러 워커와 여러 서버가 동시에 가져가도 SKIP LOCKED로 같은 작업을 두 번 가져가지 않습니다.
+// excluded 작업 이름(동시 실행 한도 도달)은 가져가지 않습니다.
+func claimJob(ctx context.Context, workerID string, excluded []string) (Job, error) {
+	var job Job
+	var payload []byte
+	var waitSeconds float64
+	err := DB.QueryRowContext(ctx, `
+		UPDATE job_table SET
+			status = $1, attempts = attempts + 1, locked_by = $2,
+			locked_until = CURRENT_TIMESTAMP + make_interval(secs => $3),
+			started_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE serial_number = (
+			SELECT serial_number FROM job_table
+			WHERE status = $4 AND run_at <= CURRENT_TIMESTAMP AND NOT (name = ANY($5))
+			ORDER BY priority DESC, run_at, serial_number
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED)
+		RETURNING serial_number, name, payload, priority, attempts, max_attempts,
+			EXTRACT(EPOCH FROM started_at - run_at)::float8`,
+		JOB_STATUS_RUNNING, workerID, consts.JOB_VISIBILITY_TIMEOUT, JOB_STATUS_QUEUED, pq.StringArray(excluded)).
+		Scan(&job.ID, &job.Name, &payload, &job.Priority, &job.Attempt, &job.MaxAttempts, &waitSeconds)
+	if err != nil {
+		return job, err
+	}
+	recordJobLatency(time.Duration(waitSeconds * float64(time.Second)))
+	job.raw = payload
+	if err := json.Unmarshal(payload, &job.Data); err != nil {
+		return job, fmt.Errorf("작업 데이터 해석 실패 (id=%d): %w", job.ID, err)
+	}
+	return job, nil
+}
+
+// runNextJob은 작업 하나를 가져와 처리하고 결과를 기록합니다. 처리할 작업이 없으면 processed=false입니다.
+func runNextJob(workerID string) (processed bool, err error) {
+	timeout := time.Duration(consts.SHORT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	job, err := claimJob(ctx, workerID, saturatedJobNames())
+	cancel()
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		if job.ID == 0 {
+			return false, err
+		}
+		// 데이터를 해석할 수 없는 작업은 재시도해도 소용없으므로 바로 dead로 보냅니다.
+		finishJob(job, err, true)
+		return true, nil
+	}
+
+	handler, found, ok := acquireJobHandler(job.Name)
+	if !found {
+		// 처리기를 등록한 새 버전 서버가 처리할 수 있도록 재시도 대상으로 둡니다.
+		finishJob(job, fmt.Errorf("등록된 작업 처리기가 없습니다: %s", job.Name), false)
+		return true, nil
+	}
+	if !ok {
+		// 가져오는 사이에 다른 워커가 동시 실행 한도를 채웠습니다. 시도 횟수를 되돌리고 돌려놓습니다.
+		returnJob(job)
+		return false, nil
+	}
+	defer releaseJobHandler(handler)
+
+	// 실행 중에는 잠금 시간을 주기적으로 연장하여 오래 걸리는 작업이 다른 워커에게 넘어가지 않게 합니다.
+	stop := make(chan struct{})
+	go extendJobLock(job.ID, workerID, stop)
+	jobErr := processJob(handler, job)
+	close(stop)
+
+	finishJob(job, jobErr, isPermanentJobError(jobErr))
+	return true, nil
+}
+
+// returnJob은 실행하지 않은 작업을 시도 횟수 증가 없이 대기열로 돌려놓습니다.
+func returnJob(job Job) {
+	timeout := time.Duration(consts.SHORT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	_, err := DB.ExecContext(ctx, `
+		UPDATE job_table SET status = $2, attempts = attempts - 1, locked_by = NULL, locked_until = NULL,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE serial_number = $1`, job.ID, JOB_STATUS_QUEUED)
+	if err != nil {
+		log.Printf("작업 반환 실패 (id=%d): %v", job.ID, err)
+	}
+}
+
+// extendJobLock은 stop이 닫힐 때까지 실행 중인 작업의 잠금 만료 시각을 연장합니다.
+func extendJobLock(jobID int64, workerID string, stop <-chan struct{}) {
+	ticker := time.NewTicker(time.Duration(consts.JOB_VISIBILITY_TIMEOUT) * time.Second / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			timeout := time.Duration(consts.SHORT_QUERY_TIMEOUT) * time.Second
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			_, err := DB.ExecContext(ctx, `
+				UPDATE job_table SET locked_until = CURRENT_TIMESTAMP + make_interval(secs => $3)
+				WHERE serial_number = $1 AND locked_by = $2 AND status = 'running'`,
+				jobID, workerID, consts.JOB_VISIBILITY_TIMEOUT)
+			cancel()
+			if err != nil {
+				log.Printf("작업 잠금 연장 실패 (id=%d): %v", jobID, err)
 			}
-		}(i)
+		}
+	}
+}
+
+// retryDelay는 attempt번째 실패 후 재시도까지의 대기 시간입니다 (지수 백오프, 최대 25% 지터).
+func retryDelay(attempt int) time.Duration {
+	delay := time.Duration(consts.JOB_RETRY_BASE_DELAY) * time.Second
+	maxDelay := time.Duration(consts.JOB_RETRY_MAX_DELAY) * time.Second
+	for i := 1; i < attempt && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	return delay + time.Duration(rand.Int63n(int64(delay)/4+1))
+}
+
+// appendJobErrorHistory는 error_history에 이번 시도의 실패를 덧붙이는 SET 절입니다. errExpr은 오류 문구 SQL 식입니다.
+// 같은 UPDATE에서 locked_by를 지워도 SET 절의 locked_by는 변경 전 값(실패한 워커)입니다.
+func appendJobErrorHistory(errExpr string) string {
+	return "error_history = error_history || jsonb_build_array(jsonb_build_object(" +
+		"'attempt', attempts, 'error', " + errExpr + ", 'worker', locked_by, 'at', CURRENT_TIMESTAMP))"
+}
+
+// finishJob은 처리 결과를 기록합니다.
+// 실패하면 최대 시도 횟수 전까지 지수 백오프로 다시 대기열에 넣고, 넘으면 dead로 둡니다.
+func finishJob(job Job, jobErr error, permanent bool) {
+	timeout := time.Duration(consts.SHORT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var err error
+	switch {
+	case jobErr == nil:
+		_, err = DB.ExecContext(ctx, `
+			UPDATE job_table SET status = $2, locked_by = NULL, locked_until = NULL, last_error = NULL,
+				finished_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+			WHERE serial_number = $1`, job.ID, JOB_STATUS_SUCCEEDED)
+		if err == nil {
+			atomic.AddInt64(&jobMetrics.succeeded, 1)
+			log.Printf("Job processed: %s (id=%d)", job.Name, job.ID)
+		}
+	case permanent || job.Attempt >= job.MaxAttempts:
+		_, err = DB.ExecContext(ctx, `
+			UPDATE job_table SET status = $2, locked_by = NULL, locked_until = NULL, last_error = $3,
+				`+appendJobErrorHistory("$3::text")+`,
+				finished_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+			WHERE serial_number = $1`, job.ID, JOB_STATUS_DEAD, jobErr.Error())
+		if err == nil {
+			atomic.AddInt64(&jobMetrics.failed, 1)
+			atomic.AddInt64(&jobMetrics.dead, 1)
+			log.Printf("❌ Job dead: %s (id=%d, 시도 %d/%d), 오류: %v", job.Name, job.ID, job.Attempt, job.MaxAttempts, jobErr)
+		}
+	default:
+		delay := retryDelay(job.Attempt)
+		_, err = DB.ExecContext(ctx, `
+			UPDATE job_table SET status = $2, locked_by = NULL, locked_until = NULL, last_error = $3,
+				`+appendJobErrorHistory("$3::text")+`,
+				run_at = CURRENT_TIMESTAMP + make_interval(secs => $4), updated_at = CURRENT_TIMESTAMP
+			WHERE serial_number = $1`, job.ID, JOB_STATUS_QUEUED, jobErr.Error(), delay.Seconds())
+		if err == nil {
+			atomic.AddInt64(&jobMetrics.failed, 1)
+			atomic.AddInt64(&jobMetrics.retried, 1)
+			log.Printf("Job failed, retry in %s: %s (id=%d, 시도 %d/%d), 오류: %v",
+				delay.Round(time.Second), job.Name, job.ID, job.Attempt, job.MaxAttempts, jobErr)
+		}
+	}
+	if err != nil {
+		// 기록에 실패해도 잠금이 만료되면 다시 실행됩니다.
+		log.Printf("작업 결과 기록 실패 (id=%d): %v", job.ID, err)
+	}
+}
+
+// reapExpiredJobs는 잠금 시간이 지난 running 작업(중단된 워커의 작업)을 다시 대기열에 넣거나 dead로 둡니다.
+func reapExpiredJobs() {
+	if DB == nil {
+		return
+	}
+	timeout := time.Duration(consts.SHORT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	rows, err := DB.QueryContext(ctx, `
+		UPDATE job_table SET
+			status = CASE WHEN attempts >= max_attempts THEN $1 ELSE $2 END,
+			finished_at = CASE WHEN attempts >= max_attempts THEN CURRENT_TIMESTAMP ELSE finished_at END,
+			last_error = '실행 잠금 시간 초과 (worker: ' || COALESCE(locked_by, '') || ')',
+			`+appendJobErrorHistory("'실행 잠금 시간 초과'")+`,
+			locked_by = NULL, locked_until = NULL, run_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE status = $3 AND locked_until < CURRENT_TIMESTAMP
+		RETURNING status`,
+		JOB_STATUS_DEAD, JOB_STATUS_QUEUED, JOB_STATUS_RUNNING)
+	if err != nil {
+		log.Printf("잠금 만료 작업 회수 오류: %v", err)
+		return
+	}
+	defer rows.Close()
+	var n int
+	for rows.Next() {
+		var status string
+		if err := rows.Scan(&status); err != nil {
+			log.Printf("잠금 만료 작업 회수 오류: %v", err)
+			return
+		}
+		n++
+		atomic.AddInt64(&jobMetrics.failed, 1)
+		if status == JOB_STATUS_DEAD {
+			atomic.AddInt64(&jobMetrics.dead, 1)
+		} else {
+			atomic.AddInt64(&jobMetrics.retried, 1)
+		}
+	}
+	if n > 0 {
+		log.Printf("잠금 만료 작업 회수: %d건", n)
 	}
 }
 
-// processJob은 작업을 처리합니다. (여기서는 단순 로그 출력과 1초 Sleep으로 시뮬레이션)
-func processJob(job Job) {
-	timeout := time.Duration(consts.DEFAULT_WORK_TIMEOUT) * time.Second
+// processJob은 등록된 처리기로 작업을 처리합니다. 오류를 반환하면 재시도 대상이 됩니다.
+// 제한 시간은 처리기 옵션(없으면 consts.DEFAULT_WORK_TIMEOUT)을 따르며, 시간이 지나면 ctx가 취소됩니다.
+func processJob(handler *jobHandler, job Job) error {
+	timeout := handler.options.Timeout
+	if timeout <= 0 {
+		timeout = time.Duration(consts.DEFAULT_WORK_TIMEOUT) * time.Second
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
-	done := make(chan bool)
+	done := make(chan error, 1)
 	go func() {
-		// 실제 작업 처리
-		log.Printf("Processing job: %s", job.Name)
-		// ... 작업 로직
-		done <- true
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("작업 처리 중 panic: %v", r)
+			}
+		}()
+		log.Printf("Processing job: %s (id=%d, 시도 %d)", job.Name, job.ID, job.Attempt)
+		done <- handler.fn(ctx, job)
 	}()
 
 	select {
-	case <-done:
-		log.Printf("Job processed: %s", job.Name)
+	case err := <-done:
+		return err
 	case <-ctx.Done():
-		log.Printf("Job timed out: %s", job.Name)
+		return fmt.Errorf("작업 시간 초과 (%s)", timeout)
 	}
 }
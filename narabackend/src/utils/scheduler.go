@@ -0,0 +1,284 @@
+// scheduler.go
+package utils
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"narabackend/src/consts"
+)
+
+// JobScheduleDefinition은 코드에서 정의하는 전체 공통 반복 작업 일정입니다.
+// 서버 시작 시 job_schedule_table에 없으면 추가하며, 이미 있으면 관리자가 바꾼 설정(cron 식, 사용 여부 등)을 유지합니다.
+type JobScheduleDefinition struct {
+	Key         string      // 일정 키 (schedule_key)
+	CronExpr    string      // cron 식 (분 시 일 월 요일)
+	Timezone    string      // cron 식을 해석할 시간대 (빈 값이면 서버 시간대)
+	JobName     string      // 넣을 작업 이름
+	Payload     interface{} // 작업 데이터 (nil이면 빈 객체)
+	Description string
+}
+
+var (
+	jobScheduleDefinitionsMu sync.Mutex
+	jobScheduleDefinitions   []JobScheduleDefinition
+)
+
+// DefineJobSchedule은 전체 공통 반복 작업 일정을 정의합니다. StartJobScheduler 전에 호출해야 합니다.
+func DefineJobSchedule(def JobScheduleDefinition) {
+	jobScheduleDefinitionsMu.Lock()
+	defer jobScheduleDefinitionsMu.Unlock()
+	jobScheduleDefinitions = append(jobScheduleDefinitions, def)
+}
+
+// NextScheduleRun은 cron 식과 시간대로 after 이후의 다음 실행 시각을 계산합니다.
+// 반환 시각은 DB(TIMESTAMP)에 저장할 수 있도록 서버 시간대로 바꿉니다.
+func NextScheduleRun(cronExpr, timezone string, after time.Time) (time.Time, error) {
+	schedule, err := ParseCron(cronExpr)
+	if err != nil {
+		return time.Time{}, err
+	}
+	loc := time.Local
+	if timezone != "" {
+		if loc, err = time.LoadLocation(timezone); err != nil {
+			return time.Time{}, fmt.Errorf("잘못된 시간대: %s", timezone)
+		}
+	}
+	next := schedule.Next(after.In(loc))
+	if next.IsZero() {
+		return next, fmt.Errorf("cron 식에 맞는 실행 시각이 없습니다: %s", cronExpr)
+	}
+	return next.In(time.Local), nil
+}
+
+// StartJobScheduler는 반복 작업 일정을 확인하여 실행 시각이 된 작업을 작업 큐에 넣는 백그라운드 작업을 시작합니다.
+// 여러 서버가 실행되어도 Postgres advisory lock을 잡은 서버 하나(리더)만 작업을 넣습니다.
+// 리더의 DB 연결이 끊어지면 잠금이 풀리고 다른 서버가 다음 확인 때 리더가 됩니다.
+func StartJobScheduler(interval time.Duration) {
+	if interval <= 0 {
+		log.Printf("반복 작업 스케줄러 비활성화 (interval=%s)", interval)
+		return
+	}
+	syncJobScheduleDefinitions()
+	log.Printf("반복 작업 스케줄러 시작 (interval=%s)", interval)
+
+	go func() {
+		var leader *sql.Conn
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			leader = ensureSchedulerLeader(leader)
+			if leader != nil {
+				runDueJobSchedules()
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// syncJobScheduleDefinitions는 코드에서 정의한 일정 중 테이블에 없는 것을 추가합니다.
+func syncJobScheduleDefinitions() {
+	jobScheduleDefinitionsMu.Lock()
+	defs := append([]JobScheduleDefinition(nil), jobScheduleDefinitions...)
+	jobScheduleDefinitionsMu.Unlock()
+
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	for _, def := range defs {
+		next, err := NextScheduleRun(def.CronExpr, def.Timezone, time.Now())
+		if err != nil {
+			log.Printf("반복 작업 일정 정의 오류 (%s): %v", def.Key, err)
+			continue
+		}
+		var payload interface{} = def.Payload
+		if payload == nil {
+			payload = map[string]interface{}{}
+		}
+		data, err := json.Marshal(payload)
+		if err != nil {
+			log.Printf("반복 작업 일정 정의 오류 (%s): %v", def.Key, err)
+			continue
+		}
+		var timezone *string
+		if def.Timezone != "" {
+			timezone = &def.Timezone
+		}
+		_, err = DB.ExecContext(ctx, `
+			INSERT INTO job_schedule_table
+			(schedule_key, company_code, cron_expr, timezone, job_name, payload, description, enabled, next_run_at, created_at, updated_at)
+			VALUES ($1, '', $2, $3, $4, $5, $6, TRUE, $7, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+			ON CONFLICT (schedule_key, company_code) DO NOTHING`,
+			def.Key, def.CronExpr, timezone, def.JobName, data, def.Description, next)
+		if err != nil {
+			log.Printf("반복 작업 일정 등록 오류 (%s): %v", def.Key, err)
+		}
+	}
+}
+
+// ensureSchedulerLeader는 리더 잠금을 확인합니다. 잡고 있는 연결이 살아 있으면 그대로 반환하고,
+// 없으면 새 연결에서 잠금을 시도합니다. 잠금을 잡지 못하면 nil입니다.
+// advisory lock은 세션 단위이므로 리더인 동안 같은 연결을 계속 보관합니다.
+func ensureSchedulerLeader(conn *sql.Conn) *sql.Conn {
+	timeout := time.Duration(consts.SHORT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if conn != nil {
+		err := conn.PingContext(ctx)
+		if err == nil {
+			return conn
+		}
+		log.Printf("반복 작업 스케줄러 리더 연결 끊김: %v", err)
+		// 연결이 살아 있는데 응답만 늦었던 경우를 위해 잠금을 풀고 연결을 반환합니다.
+		conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", consts.JOB_SCHEDULER_LOCK_KEY)
+		conn.Close()
+	}
+
+	conn, err := DB.Conn(ctx)
+	if err != nil {
+		log.Printf("반복 작업 스케줄러 DB 연결 실패: %v", err)
+		return nil
+	}
+	var locked bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", consts.JOB_SCHEDULER_LOCK_KEY).Scan(&locked); err != nil || !locked {
+		if err != nil {
+			log.Printf("반복 작업 스케줄러 잠금 확인 실패: %v", err)
+		}
+		conn.Close()
+		return nil
+	}
+	hostname, _ := os.Hostname()
+	log.Printf("반복 작업 스케줄러 리더가 되었습니다 (%s-%d)", hostname, os.Getpid())
+	return conn
+}
+
+// dueJobSchedule은 실행 시각이 된 일정입니다.
+type dueJobSchedule struct {
+	id          int64
+	companyCode string
+	cronExpr    string
+	timezone    string
+	jobName     string
+	payload     []byte
+	nextRunAt   sql.NullTime
+}
+
+// runDueJobSchedules는 실행 시각이 지난 일정마다 작업을 넣고 다음 실행 시각을 계산합니다.
+// 서버가 멈춰 여러 번 놓친 일정도 한 번만 실행합니다. 다음 실행 시각이 없는 일정(새로 추가·수정)은 시각만 계산합니다.
+func runDueJobSchedules() {
+	timeout := time.Duration(consts.DEFAULT_QUERY_TIMEOUT) * time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	now := time.Now()
+	rows, err := DB.QueryContext(ctx, `
+		SELECT serial_number, company_code, cron_expr, COALESCE(timezone, ''), job_name, payload, next_run_at
+		FROM job_schedule_table
+		WHERE enabled AND (next_run_at <= $1 OR (next_run_at IS NULL AND last_error IS NULL))
+		ORDER BY next_run_at NULLS FIRST, serial_number`, now)
+	if err != nil {
+		log.Printf("반복 작업 일정 조회 오류: %v", err)
+		return
+	}
+	var due []dueJobSchedule
+	for rows.Next() {
+		var s dueJobSchedule
+		if err := rows.Scan(&s.id, &s.companyCode, &s.cronExpr, &s.timezone, &s.jobName, &s.payload, &s.nextRunAt); err != nil {
+			rows.Close()
+			log.Printf("반복 작업 일정 조회 오류: %v", err)
+			return
+		}
+		due = append(due, s)
+	}
+	rows.Close()
+
+	for _, s := range due {
+		if err := fireJobSchedule(ctx, s, now); err != nil {
+			log.Printf("반복 작업 일정 실행 오류 (id=%d, job=%s): %v", s.id, s.jobName, err)
+		}
+	}
+}
+
+// fireJobSchedule은 일정 하나의 작업을 넣고 다음 실행 시각을 같은 트랜잭션에서 기록합니다.
+// 조회한 next_run_at이 그대로인 경우에만 실행 차례를 가져가므로 리더가 바뀌는 중에 두 서버가 실행해도 작업은 한 번만 들어갑니다.
+// 작업 멱등 키에도 일정 시각을 넣어 같은 차례의 작업이 다시 들어가지 않게 합니다.
+func fireJobSchedule(ctx context.Context, s dueJobSchedule, now time.Time) error {
+	next, err := NextScheduleRun(s.cronExpr, s.timezone, now)
+	if err != nil {
+		_, dbErr := DB.ExecContext(ctx, `
+			UPDATE job_schedule_table SET next_run_at = NULL, last_error = $2 WHERE serial_number = $1`,
+			s.id, err.Error())
+		if dbErr != nil {
+			return dbErr
+		}
+		return err
+	}
+	if !s.nextRunAt.Valid {
+		_, err := DB.ExecContext(ctx,
+			"UPDATE job_schedule_table SET next_run_at = $2, last_error = NULL WHERE serial_number = $1", s.id, next)
+		return err
+	}
+
+	scheduledAt := s.nextRunAt.Time
+	job := Job{
+		Name:           s.jobName,
+		Payload:        scheduleJobPayload(s.payload, s.companyCode),
+		IdempotencyKey: fmt.Sprintf("schedule-%d-%s", s.id, scheduledAt.Format("200601021504")),
+	}
+
+	tx, err := DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// 다른 서버가 먼저 실행했으면 next_run_at이 바뀌어 있으므로 아무것도 하지 않습니다.
+	res, err := tx.ExecContext(ctx, `
+		UPDATE job_schedule_table SET last_run_at = $2, next_run_at = $3, last_error = NULL
+		WHERE serial_number = $1 AND next_run_at = $4`, s.id, scheduledAt, next, scheduledAt)
+	if err != nil {
+		return err
+	}
+	claimed, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if claimed == 0 {
+		return nil
+	}
+
+	jobID, err := EnqueueJobTx(ctx, tx, job)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx,
+		"UPDATE job_schedule_table SET last_job_id = $2 WHERE serial_number = $1", s.id, jobID); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	log.Printf("반복 작업 등록: %s (schedule=%d, company_code=%q, job=%d, next=%s)",
+		s.jobName, s.id, s.companyCode, jobID, next.Format(time.RFC3339))
+	return nil
+}
+
+// scheduleJobPayload는 일정의 작업 데이터를 만듭니다. 업체 일정이면 데이터 객체에 company_code를 넣습니다.
+func scheduleJobPayload(raw []byte, companyCode string) interface{} {
+	var data map[string]interface{}
+	if err := json.Unmarshal(raw, &data); err != nil || data == nil {
+		return json.RawMessage(raw)
+	}
+	if companyCode != "" {
+		data["company_code"] = companyCode
+	}
+	return data
+}
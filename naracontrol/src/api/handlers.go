@@ -1,21 +1,34 @@
 package api
 
 import (
+	"crypto/subtle"
+	"encoding/json"
 	"log"
 	"net/http"
+	"os"
+	"time"
 
+	"github.com/soinfree/naracontrol/src/config"
+	"github.com/soinfree/naracontrol/src/message"
+	"github.com/soinfree/naracontrol/src/models"
 	"github.com/soinfree/naracontrol/src/websocket"
 )
 
 // SetupHTTPHandlers는 HTTP 핸들러를 설정합니다.
-func SetupHTTPHandlers(wsServer *websocket.WebSocketServer) {
+func SetupHTTPHandlers(wsServer *websocket.WebSocketServer, serverInstance message.ServerInterface) {
 	// 헬스체크 엔드포인트
 	http.HandleFunc("/health", healthCheckHandler)
 
 	// WebSocket 핸들러 등록
 	http.HandleFunc("/ws", wsServer.HandleWebSocket)
 
-	log.Printf("HTTP 핸들러 설정 완료: /health, /ws")
+	// 내부 제어 명령 엔드포인트 (narabackend 전용)
+	http.HandleFunc("/internal/commands", commandHandler(serverInstance))
+
+	// 내부 변경 이벤트 엔드포인트 (narabackend outbox 전용)
+	http.HandleFunc("/internal/events", eventHandler(serverInstance))
+
+	log.Printf("HTTP 핸들러 설정 완료: /health, /ws, /internal/commands, /internal/events")
 }
 
 // healthCheckHandler는 서버 상태 확인을 위한 헬스체크 핸들러입니다.
@@ -23,3 +36,101 @@ func healthCheckHandler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte("OK"))
 }
+
+// authorizeInternal은 내부 API 요청의 메서드(POST)와 인증 키를 확인합니다. 실패하면 응답을 쓰고 false를 반환합니다.
+// NARACONTROL_API_KEY가 설정되지 않으면 모든 요청을 거부합니다.
+func authorizeInternal(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method != http.MethodPost {
+		http.Error(w, "허용되지 않는 메서드입니다", http.StatusMethodNotAllowed)
+		return false
+	}
+
+	apiKey := os.Getenv(config.InternalAPIKeyEnv)
+	provided := r.Header.Get(config.InternalAPIKeyHeader)
+	if apiKey == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(apiKey)) != 1 {
+		log.Printf("내부 API 인증 실패 - 원격 주소: %s", r.RemoteAddr)
+		http.Error(w, "인증에 실패했습니다", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+// validTarget은 수신 클라이언트 타입이 naradesk 또는 naradevice인지 확인합니다.
+func validTarget(target string) bool {
+	return target == config.ClientTypeNaradesk || target == config.ClientTypeNaradevice
+}
+
+// commandHandler는 narabackend가 보낸 제어 명령을 회사의 대상 클라이언트에게 전달합니다.
+func commandHandler(serverInstance message.ServerInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !authorizeInternal(w, r) {
+			return
+		}
+
+		var req models.CommandRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, config.MaxMessageSize)).Decode(&req); err != nil {
+			http.Error(w, "잘못된 요청 데이터", http.StatusBadRequest)
+			return
+		}
+		if req.Command == "" || req.Message.CompanyCode == "" {
+			http.Error(w, "필수 필드가 누락되었습니다 (command, message.companyCode)", http.StatusBadRequest)
+			return
+		}
+		if !validTarget(req.Target) {
+			http.Error(w, "target은 naradesk 또는 naradevice여야 합니다", http.StatusBadRequest)
+			return
+		}
+		req.Message.Source = "narabackend"
+		if req.Message.Timestamp == "" {
+			req.Message.Timestamp = time.Now().Format(time.RFC3339)
+		}
+
+		recipients := message.SendCommand(serverInstance, req)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]int{"recipients": recipients})
+	}
+}
+
+// eventHandler는 narabackend outbox가 보낸 변경 이벤트(열람실/좌석/전원 변경)를 회사의 대상 클라이언트들에게 전달합니다.
+// 이미 전달한 이벤트ID를 다시 받으면 전달하지 않고 duplicate=true로 응답합니다.
+func eventHandler(serverInstance message.ServerInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !authorizeInternal(w, r) {
+			return
+		}
+
+		var req models.EventRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, config.MaxMessageSize)).Decode(&req); err != nil {
+			http.Error(w, "잘못된 요청 데이터", http.StatusBadRequest)
+			return
+		}
+		if req.EventID == "" || req.Message.CompanyCode == "" {
+			http.Error(w, "필수 필드가 누락되었습니다 (eventId, message.companyCode)", http.StatusBadRequest)
+			return
+		}
+		if !message.IsChangeEvent(req.Event) {
+			http.Error(w, "알 수 없는 이벤트입니다", http.StatusBadRequest)
+			return
+		}
+		if len(req.Targets) == 0 {
+			http.Error(w, "필수 필드가 누락되었습니다 (targets)", http.StatusBadRequest)
+			return
+		}
+		for _, target := range req.Targets {
+			if !validTarget(target) {
+				http.Error(w, "targets는 naradesk 또는 naradevice여야 합니다", http.StatusBadRequest)
+				return
+			}
+		}
+		req.Message.Source = "narabackend"
+		if req.Message.Timestamp == "" {
+			req.Message.Timestamp = time.Now().Format(time.RFC3339)
+		}
+
+		recipients, duplicate := message.DeliverEvent(serverInstance, req)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"recipients": recipients, "duplicate": duplicate})
+	}
+}
@@ -43,6 +43,45 @@ const (
 	MessageTypeWelcome = "welcome"
 )
 
+// 제어 명령 상수 (BinaryMessageTypeCommand)
+const (
+	CommandPowerOn          = "power_on"           // 좌석 전원 켜기
+	CommandPowerOff         = "power_off"          // 좌석 전원 끄기
+	CommandSeatReleased     = "seat_released"      // 좌석 자동 해제 알림 (naradesk)
+	CommandOutingWarning    = "outing_warning"     // 외출 시간 임박 경고 (naradesk)
+	CommandOutingOverrun    = "outing_overrun"     // 외출 시간 초과 알림 (naradesk)
+	CommandWaitingOffer     = "waiting_offer"      // 대기열 좌석 제안 알림 (naradesk)
+	CommandLockerExpired    = "locker_expired"     // 사물함 대여 만료 알림 (naradesk, seatNumber에 사물함 번호)
+	CommandCleaningLightOn  = "cleaning_light_on"  // 좌석 청소등 켜기 (naradevice)
+	CommandCleaningLightOff = "cleaning_light_off" // 좌석 청소등 끄기 (naradevice)
+)
+
+// 변경 이벤트 상수 (narabackend 변경 알림, BinaryMessageTypeCommand의 명령 자리로 전달)
+const (
+	EventRoomUpdated   = "room_updated"    // 열람실 정보 변경
+	EventSeatUpdated   = "seat_updated"    // 좌석 정보 변경
+	EventSeatOccupied  = "seat_occupied"   // 좌석 입실
+	EventSeatVacated   = "seat_vacated"    // 좌석 퇴실/해제
+	EventPowerStateOn  = "power_state_on"  // 장치가 전원 켜짐을 확인
+	EventPowerStateOff = "power_state_off" // 장치가 전원 꺼짐을 확인
+)
+
+// 내부 API 상수
+const (
+	// 내부 API 인증 키 환경변수 이름 (설정되지 않으면 내부 API 비활성화)
+	InternalAPIKeyEnv = "NARACONTROL_API_KEY"
+	// 내부 API 인증 헤더
+	InternalAPIKeyHeader = "X-Internal-Key"
+	// 명령 응답(Ack)을 전달할 narabackend 주소 환경변수 이름 (설정되지 않으면 응답을 전달하지 않음)
+	BackendURLEnv = "NARABACKEND_URL"
+	// narabackend 명령 응답 수신 경로
+	BackendAckPath = "/internal/command-acks"
+	// narabackend 요청 제한 시간
+	BackendRequestTimeout = 5 * time.Second
+	// 변경 이벤트 중복 전달 방지 기간 (narabackend가 재전송한 같은 이벤트ID는 이 기간 동안 무시)
+	EventDedupWindow = 10 * time.Minute
+)
+
 // 기타 상수
 const (
 	// 샤드 수
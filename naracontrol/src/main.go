@@ -38,7 +38,7 @@ func main() {
 	go serverInstance.Run()
 
 	// HTTP 핸들러 설정
-	api.SetupHTTPHandlers(wsServer)
+	api.SetupHTTPHandlers(wsServer, serverInstance)
 
 	// HTTP 서버 설정
 	httpServer := &http.Server{
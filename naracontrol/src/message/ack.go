@@ -0,0 +1,72 @@
+package message
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/soinfree/naracontrol/src/config"
+	"github.com/soinfree/naracontrol/src/models"
+)
+
+var ackHTTPClient = &http.Client{Timeout: config.BackendRequestTimeout}
+
+// ForwardAck는 장치가 보낸 명령 응답(Ack)을 narabackend로 전달합니다.
+// 응답의 회사코드는 장치가 보낸 값과 관계없이 발신 클라이언트의 회사코드로 덮어쓰며, 전달은 비동기로 처리합니다.
+func ForwardAck(sender *models.Client, data []byte) {
+	ack, err := models.DecodeAckData(data)
+	if err != nil {
+		log.Printf("명령 응답 디코딩 실패 - 발신자: %s, 오류: %v", sender.ID, err)
+		return
+	}
+	// 다른 회사의 명령 응답으로 위장하지 못하도록 인증된 연결의 회사코드만 사용합니다.
+	ack.Message.CompanyCode = sender.CompanyCode
+
+	baseURL := os.Getenv(config.BackendURLEnv)
+	apiKey := os.Getenv(config.InternalAPIKeyEnv)
+	if baseURL == "" || apiKey == "" {
+		log.Printf("명령 응답 전달 생략 - %s 또는 %s 미설정 (이벤트ID: %s)",
+			config.BackendURLEnv, config.InternalAPIKeyEnv, ack.EventID)
+		return
+	}
+
+	go postAck(strings.TrimRight(baseURL, "/")+config.BackendAckPath, apiKey, ack)
+}
+
+// postAck는 명령 응답을 narabackend 내부 API로 전송합니다.
+func postAck(url, apiKey string, ack models.CommandAck) {
+	body, err := json.Marshal(ack)
+	if err != nil {
+		log.Printf("명령 응답 인코딩 실패 - 이벤트ID: %s, 오류: %v", ack.EventID, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.BackendRequestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		log.Printf("명령 응답 요청 생성 실패 - 이벤트ID: %s, 오류: %v", ack.EventID, err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(config.InternalAPIKeyHeader, apiKey)
+
+	resp, err := ackHTTPClient.Do(req)
+	if err != nil {
+		log.Printf("명령 응답 전달 실패 - 이벤트ID: %s, 오류: %v", ack.EventID, err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		log.Printf("명령 응답 전달 실패 - 이벤트ID: %s, 상태 코드: %d", ack.EventID, resp.StatusCode)
+		return
+	}
+	log.Printf("명령 응답 전달 완료 - 회사코드: %s, 이벤트ID: %s, 상태: %s",
+		ack.Message.CompanyCode, ack.EventID, ack.Status)
+}
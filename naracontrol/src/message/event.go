@@ -0,0 +1,66 @@
+package message
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/soinfree/naracontrol/src/config"
+	"github.com/soinfree/naracontrol/src/models"
+)
+
+// 변경 이벤트 목록 (허용된 이벤트만 전달)
+var changeEvents = map[string]bool{
+	config.EventRoomUpdated:   true,
+	config.EventSeatUpdated:   true,
+	config.EventSeatOccupied:  true,
+	config.EventSeatVacated:   true,
+	config.EventPowerStateOn:  true,
+	config.EventPowerStateOff: true,
+}
+
+// IsChangeEvent는 전달할 수 있는 변경 이벤트인지 확인합니다.
+func IsChangeEvent(event string) bool {
+	return changeEvents[event]
+}
+
+// deliveredEvents는 최근 전달한 이벤트ID와 전달 시각입니다.
+var (
+	deliveredMu     sync.Mutex
+	deliveredEvents = map[string]time.Time{}
+)
+
+// markDelivered는 이벤트ID를 기록하고, 중복 제거 기간 안에 이미 전달한 이벤트면 false를 반환합니다.
+func markDelivered(eventID string, now time.Time) bool {
+	deliveredMu.Lock()
+	defer deliveredMu.Unlock()
+
+	for id, at := range deliveredEvents {
+		if now.Sub(at) > config.EventDedupWindow {
+			delete(deliveredEvents, id)
+		}
+	}
+	if _, ok := deliveredEvents[eventID]; ok {
+		return false
+	}
+	deliveredEvents[eventID] = now
+	return true
+}
+
+// DeliverEvent는 변경 이벤트를 회사의 대상 클라이언트들에게 명령 메시지로 전달하고 수신자 수를 반환합니다.
+// narabackend는 응답을 받지 못하면 같은 이벤트를 다시 보내므로, 최근 전달한 이벤트ID는 다시 전달하지 않습니다(duplicate=true).
+// 변경 이벤트는 장치 응답(Ack)을 받지 않으므로 이벤트ID를 메시지에 넣지 않습니다.
+func DeliverEvent(s ServerInterface, req models.EventRequest) (recipients int, duplicate bool) {
+	if req.EventID != "" && !markDelivered(req.EventID, time.Now()) {
+		log.Printf("중복 변경 이벤트 무시 - 이벤트ID: %s, 이벤트: %s", req.EventID, req.Event)
+		return 0, true
+	}
+	for _, target := range req.Targets {
+		recipients += SendCommand(s, models.CommandRequest{
+			Command: req.Event,
+			Target:  target,
+			Message: req.Message,
+		})
+	}
+	return recipients, false
+}
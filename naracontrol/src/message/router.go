@@ -32,6 +32,12 @@ func RouteMessage(s ServerInterface, sender *models.Client, message []byte) {
 
 	log.Printf("인증된 클라이언트 타입: %s - 발신자: %s", clientType, sender.ID)
 
+	// 명령 응답은 다른 클라이언트로 라우팅하지 않고 narabackend로 전달합니다.
+	if binaryMsg.Type == models.BinaryMessageTypeAck {
+		ForwardAck(sender, binaryMsg.Data)
+		return
+	}
+
 	if binaryMsg.Type != models.BinaryMessageTypeMessage {
 		typeName := models.GetBinaryMessageTypeName(binaryMsg.Type)
 		log.Printf("라우팅 대상이 아닌 메시지 타입: %s (타입 코드: %d)", typeName, binaryMsg.Type)
@@ -109,3 +115,41 @@ func RouteMessage(s ServerInterface, sender *models.Client, message []byte) {
 		log.Printf("바이너리 메시지 라우팅 실패 - 발신자: %s (%s), 수신자 없음", companyCode, userCode)
 	}
 }
+
+// SendCommand는 회사의 대상 타입 클라이언트 모두에게 제어 명령을 전송하고 수신자 수를 반환합니다.
+// 클라이언트 타입마다 인증 헤더가 다르므로 수신자별로 다시 인코딩합니다.
+func SendCommand(s ServerInterface, req models.CommandRequest) int {
+	s.CompanyMutexRLock()
+	companyClients := s.GetClientsByCompany()[req.Message.CompanyCode]
+	targets := make([]*models.Client, 0, len(companyClients))
+	for client := range companyClients {
+		if client.Type == req.Target {
+			targets = append(targets, client)
+		}
+	}
+	s.CompanyMutexRUnlock()
+
+	var recipientCount int
+	for _, client := range targets {
+		encodedMessage, err := models.CreateBinaryCommandMessage(req.Command, req.EventID, req.Message, client.Type)
+		if err != nil {
+			log.Printf("명령 인코딩 실패 - 클라이언트: %s, 오류: %v", client.ID, err)
+			continue
+		}
+
+		if client.Type == config.ClientTypeNaradesk {
+			err = s.SendWSMessage(client.ID, encodedMessage)
+		} else {
+			err = s.SendTCPMessage(client.ID, encodedMessage)
+		}
+		if err != nil {
+			log.Printf("명령 전송 실패 - 클라이언트: %s, 오류: %v", client.ID, err)
+			continue
+		}
+		recipientCount++
+	}
+
+	log.Printf("명령 전송 완료 - 회사코드: %s, 명령: %s, 대상타입: %s, 좌석: %s, 전원: %s, 수신자 수: %d",
+		req.Message.CompanyCode, req.Command, req.Target, req.Message.SeatNumber, req.Message.PowerNumber, recipientCount)
+	return recipientCount
+}
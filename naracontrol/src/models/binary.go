@@ -15,6 +15,8 @@ const (
 	BinaryMessageTypePing    = byte(3)
 	BinaryMessageTypePong    = byte(4)
 	BinaryMessageTypeWelcome = byte(5)
+	BinaryMessageTypeCommand = byte(6) // 서버(narabackend)가 발행하는 제어 명령
+	BinaryMessageTypeAck     = byte(7) // 장치가 제어 명령 처리 결과를 알리는 응답
 )
 
 // GetBinaryMessageTypeName은 메시지 타입을 한국어 텍스트로 변환합니다
@@ -30,6 +32,10 @@ func GetBinaryMessageTypeName(msgType byte) string {
 		return "퐁(Pong)"
 	case BinaryMessageTypeWelcome:
 		return "환영(Welcome)"
+	case BinaryMessageTypeCommand:
+		return "명령(Command)"
+	case BinaryMessageTypeAck:
+		return "명령 응답(Ack)"
 	default:
 		return "알 수 없음(Unknown)"
 	}
@@ -401,6 +407,109 @@ func DecodeWelcomeData(data []byte) (message string, err error) {
 	return message, nil
 }
 
+// EncodeCommandData는 제어 명령 데이터를 인코딩합니다
+// 형식: [명령 길이(1바이트)][명령][메시지 데이터(EncodeMessageData 형식)][이벤트ID 길이(1바이트)][이벤트ID]
+// 이벤트ID가 없으면 마지막 두 필드를 생략합니다. 이벤트ID를 모르는 장치는 뒤쪽 바이트를 무시하면 됩니다.
+func EncodeCommandData(command, eventID string, msg Message) []byte {
+	var buf bytes.Buffer
+
+	// 명령
+	buf.WriteByte(byte(len(command)))
+	buf.WriteString(command)
+
+	// 대상 좌석 정보
+	buf.Write(EncodeMessageData(msg.CompanyCode, msg.UserCode, msg.Source, msg.RoomCode,
+		msg.SeatNumber, msg.PowerNumber, msg.Timestamp))
+
+	// 이벤트ID (장치가 응답(Ack)에 그대로 돌려줍니다)
+	if eventID != "" {
+		buf.WriteByte(byte(len(eventID)))
+		buf.WriteString(eventID)
+	}
+
+	return buf.Bytes()
+}
+
+// DecodeCommandData는 제어 명령 데이터를 디코딩합니다
+func DecodeCommandData(data []byte) (command, eventID string, msg Message, err error) {
+	if len(data) < 1 { // 최소 1바이트 필요 (명령 길이 필드)
+		return "", "", msg, errors.New("명령 데이터가 너무 짧습니다")
+	}
+
+	commandLen := int(data[0])
+	if commandLen > len(data)-1 {
+		return "", "", msg, errors.New("명령 길이가 잘못되었습니다")
+	}
+	command = string(data[1 : 1+commandLen])
+
+	rest := data[1+commandLen:]
+	msg.CompanyCode, msg.UserCode, msg.Source, msg.RoomCode, msg.SeatNumber, msg.PowerNumber, msg.Timestamp, err =
+		DecodeMessageData(rest)
+	if err != nil {
+		return command, "", msg, err
+	}
+
+	// 메시지 데이터 뒤에 이벤트ID가 있으면 읽습니다.
+	consumed := len(EncodeMessageData(msg.CompanyCode, msg.UserCode, msg.Source, msg.RoomCode,
+		msg.SeatNumber, msg.PowerNumber, msg.Timestamp))
+	eventID, err = decodeOptionalString(rest[consumed:], "이벤트ID")
+	return command, eventID, msg, err
+}
+
+// EncodeAckData는 제어 명령 응답 데이터를 인코딩합니다
+// 형식: [이벤트ID 길이(1바이트)][이벤트ID][상태 길이(1바이트)][상태][상세 길이(1바이트)][상세]
+//
+//	[메시지 데이터(EncodeMessageData 형식)]
+func EncodeAckData(ack CommandAck) []byte {
+	var buf bytes.Buffer
+
+	for _, field := range []string{ack.EventID, ack.Status, ack.Detail} {
+		buf.WriteByte(byte(len(field)))
+		buf.WriteString(field)
+	}
+	buf.Write(EncodeMessageData(ack.Message.CompanyCode, ack.Message.UserCode, ack.Message.Source,
+		ack.Message.RoomCode, ack.Message.SeatNumber, ack.Message.PowerNumber, ack.Message.Timestamp))
+
+	return buf.Bytes()
+}
+
+// DecodeAckData는 제어 명령 응답 데이터를 디코딩합니다
+func DecodeAckData(data []byte) (ack CommandAck, err error) {
+	buf := bytes.NewBuffer(data)
+	fields := []*string{&ack.EventID, &ack.Status, &ack.Detail}
+	names := []string{"이벤트ID", "상태", "상세"}
+	for i, field := range fields {
+		length, err := buf.ReadByte()
+		if err != nil {
+			return ack, errors.New("응답 데이터가 너무 짧습니다")
+		}
+		if int(length) > buf.Len() {
+			return ack, errors.New(names[i] + " 길이가 잘못되었습니다")
+		}
+		*field = string(buf.Next(int(length)))
+	}
+	if ack.EventID == "" {
+		return ack, errors.New("응답에 이벤트ID가 없습니다")
+	}
+
+	m := &ack.Message
+	m.CompanyCode, m.UserCode, m.Source, m.RoomCode, m.SeatNumber, m.PowerNumber, m.Timestamp, err =
+		DecodeMessageData(buf.Bytes())
+	return ack, err
+}
+
+// decodeOptionalString은 [길이(1바이트)][값] 형식의 선택 필드를 읽습니다. 데이터가 없으면 빈 문자열입니다.
+func decodeOptionalString(data []byte, name string) (string, error) {
+	if len(data) == 0 {
+		return "", nil
+	}
+	length := int(data[0])
+	if length > len(data)-1 {
+		return "", errors.New(name + " 길이가 잘못되었습니다")
+	}
+	return string(data[1 : 1+length]), nil
+}
+
 // 편의 함수들
 
 // CreateBinaryConnectMessage는 바이너리 연결 메시지를 생성합니다
@@ -465,3 +574,19 @@ func CreateBinaryWelcomeMessage(message string, clientType string) []byte {
 	result, _ := EncodeBinaryMessage(msg, clientType)
 	return result
 }
+
+// CreateBinaryCommandMessage는 바이너리 제어 명령 메시지를 생성합니다
+func CreateBinaryCommandMessage(command, eventID string, msg Message, clientType string) ([]byte, error) {
+	return EncodeBinaryMessage(&BinaryMessage{
+		Type: BinaryMessageTypeCommand,
+		Data: EncodeCommandData(command, eventID, msg),
+	}, clientType)
+}
+
+// CreateBinaryAckMessage는 바이너리 제어 명령 응답 메시지를 생성합니다
+func CreateBinaryAckMessage(ack CommandAck, clientType string) ([]byte, error) {
+	return EncodeBinaryMessage(&BinaryMessage{
+		Type: BinaryMessageTypeAck,
+		Data: EncodeAckData(ack),
+	}, clientType)
+}
@@ -39,3 +39,27 @@ type ConnectMessage struct {
 	UserCode    string `json:"userCode"`
 	Source      string `json:"source"` // naradesk 또는 naradevice
 }
+
+// 제어 명령 요청 구조체 (narabackend -> naracontrol 내부 API)
+type CommandRequest struct {
+	Command string  `json:"command"`           // power_on, power_off, seat_released 등
+	Target  string  `json:"target"`            // 수신 클라이언트 타입 (naradesk 또는 naradevice)
+	EventID string  `json:"eventId,omitempty"` // 응답(Ack)을 받을 명령의 이벤트ID (narabackend가 발급)
+	Message Message `json:"message"`           // 대상 회사/좌석 정보
+}
+
+// 변경 이벤트 요청 구조체 (narabackend outbox -> naracontrol 내부 API)
+type EventRequest struct {
+	EventID string   `json:"eventId"` // narabackend가 발급한 이벤트ID (재전송 중복 제거용)
+	Event   string   `json:"event"`   // room_updated, seat_occupied 등
+	Targets []string `json:"targets"` // 수신 클라이언트 타입 목록 (naradesk, naradevice)
+	Message Message  `json:"message"` // 대상 회사/좌석 정보
+}
+
+// 제어 명령 응답 구조체 (장치 -> naracontrol -> narabackend)
+type CommandAck struct {
+	EventID string  `json:"eventId"` // 명령에 포함된 이벤트ID
+	Status  string  `json:"status"`  // ok 또는 error
+	Detail  string  `json:"detail"`  // 장치가 보낸 상세 내용 (오류 사유 등)
+	Message Message `json:"message"` // 응답한 장치의 회사/좌석 정보
+}
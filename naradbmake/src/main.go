@@ -181,6 +181,116 @@ func main() {
 		log.Fatalf("manager_access_table 생성 오류: %v", err)
 	}
 
+	err = tables.CreatePlanTable(db)
+	if err != nil {
+		log.Fatalf("plan_table 생성 오류: %v", err)
+	}
+
+	err = tables.CreateMemberPassTable(db)
+	if err != nil {
+		log.Fatalf("member_pass_table 생성 오류: %v", err)
+	}
+
+	err = tables.CreateSeatSessionTable(db)
+	if err != nil {
+		log.Fatalf("seat_session_table 생성 오류: %v", err)
+	}
+
+	err = tables.CreatePaymentTable(db)
+	if err != nil {
+		log.Fatalf("payment_table 생성 오류: %v", err)
+	}
+
+	err = tables.CreateSettlementTable(db)
+	if err != nil {
+		log.Fatalf("settlement_table 생성 오류: %v", err)
+	}
+
+	err = tables.CreatePaymentGatewayTables(db)
+	if err != nil {
+		log.Fatalf("payment_gateway 테이블 생성 오류: %v", err)
+	}
+
+	err = tables.CreateOutingRuleTables(db)
+	if err != nil {
+		log.Fatalf("outing 테이블 생성 오류: %v", err)
+	}
+
+	err = tables.CreateReservationTables(db)
+	if err != nil {
+		log.Fatalf("reservation 테이블 생성 오류: %v", err)
+	}
+
+	err = tables.CreateLockerTables(db)
+	if err != nil {
+		log.Fatalf("locker 테이블 생성 오류: %v", err)
+	}
+
+	err = tables.CreateCleaningTables(db)
+	if err != nil {
+		log.Fatalf("cleaning 테이블 생성 오류: %v", err)
+	}
+
+	err = tables.CreateDashboardRollupTables(db)
+	if err != nil {
+		log.Fatalf("dashboard 집계 테이블 생성 오류: %v", err)
+	}
+
+	err = tables.CreateReportRunTable(db)
+	if err != nil {
+		log.Fatalf("report_run_table 생성 오류: %v", err)
+	}
+
+	err = tables.CreateMemberProfileTable(db)
+	if err != nil {
+		log.Fatalf("member_profile_table 생성 오류: %v", err)
+	}
+
+	err = tables.CreateCredentialTable(db)
+	if err != nil {
+		log.Fatalf("credential_table 생성 오류: %v", err)
+	}
+
+	err = tables.CreateUnmannedTables(db)
+	if err != nil {
+		log.Fatalf("unmanned 테이블 생성 오류: %v", err)
+	}
+
+	err = tables.CreatePowerTables(db)
+	if err != nil {
+		log.Fatalf("power 테이블 생성 오류: %v", err)
+	}
+
+	err = tables.CreateJobTable(db)
+	if err != nil {
+		log.Fatalf("job_table 생성 오류: %v", err)
+	}
+
+	err = tables.CreateJobScheduleTable(db)
+	if err != nil {
+		log.Fatalf("job_schedule_table 생성 오류: %v", err)
+	}
+
+	err = tables.CreateOutboxTable(db)
+	if err != nil {
+		log.Fatalf("outbox_table 생성 오류: %v", err)
+	}
+
+	err = tables.CreateChangeEventTable(db)
+	if err != nil {
+		log.Fatalf("change_event_table 생성 오류: %v", err)
+	}
+
+	err = tables.CreateWebhookTables(db)
+	if err != nil {
+		log.Fatalf("webhook 테이블 생성 오류: %v", err)
+	}
+
+	err = tables.CreateNotificationTables(db)
+	if err != nil {
+		log.Fatalf("notification 테이블 생성 오류: %v", err)
+	}
+
 	log.Println("naradb 생성이 완료되었습니다.")
 
 	//------------------------------------------------------------------------
@@ -0,0 +1,144 @@
+package tables
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// CreateChangeEventTable 실시간 변경 피드(change_event_table) 테이블, 인덱스 및 트리거를 생성합니다.
+// 함수 이름을 대문자로 시작하여 외부에서 접근 가능하게 만듭니다.
+// room_table, seat_table, seat_session_table, payment_table의 추가/수정/삭제를 트리거로 기록하고
+// NOTIFY로 narabackend에 알립니다. 해당 테이블들을 먼저 생성한 뒤 호출해야 합니다.
+func CreateChangeEventTable(db *sql.DB) error {
+	log.Println("change_event_table 테이블을 생성합니다...")
+
+	// 테이블 생성
+	createBaseTableQuery := `CREATE TABLE IF NOT EXISTS change_event_table();`
+	_, err := db.Exec(createBaseTableQuery)
+	if err != nil {
+		return err
+	}
+	log.Println("change_event_table 테이블 기본 구조 생성 완료")
+
+	tableName := "change_event_table"
+	alterPrefix := fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS ", tableName)
+
+	// 각 필드 개별 추가
+	fieldDefinitions := []string{
+		// 기본키 (회사별로 커밋 순서와 같게 증가하는 이벤트ID)
+		"serial_number BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY",
+		// 회사코드
+		"company_code TEXT NOT NULL",
+		// 대상(room, seat, seat_session, payment)
+		"entity TEXT NOT NULL",
+		// 변경 종류(created, updated, deleted)
+		"action TEXT NOT NULL",
+		// 대상 식별자 (room_code, seat_code, serial_number)
+		"entity_id TEXT",
+		// 변경 후 행 데이터 (삭제는 삭제 전 행)
+		"data JSONB NOT NULL DEFAULT '{}'",
+		// 생성일
+		"created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP",
+	}
+
+	// 각 필드 추가 쿼리 생성
+	fieldQueries := make([]string, len(fieldDefinitions))
+	for i, field := range fieldDefinitions {
+		fieldQueries[i] = alterPrefix + field + ";"
+	}
+
+	// 각 필드 추가 실행 및 진행 상황 로깅
+	for i, query := range fieldQueries {
+		_, err = db.Exec(query)
+		if err != nil {
+			return err
+		}
+		log.Printf("change_event_table 필드 추가 진행 중: %d/%d 완료", i+1, len(fieldQueries))
+	}
+
+	// 인덱스 생성 쿼리 목록
+	indexQueries := []string{
+		`CREATE INDEX IF NOT EXISTS idx_change_event_company ON change_event_table (company_code, serial_number);`,
+		`CREATE INDEX IF NOT EXISTS idx_change_event_created ON change_event_table (created_at);`,
+	}
+
+	// 인덱스 생성 실행
+	for _, query := range indexQueries {
+		_, err = db.Exec(query)
+		if err != nil {
+			return err
+		}
+	}
+
+	// 변경 기록 트리거 (TG_ARGV[0]=대상 이름, TG_ARGV[1]=식별자 컬럼, TG_ARGV[2...]=피드에서 제외할 민감 컬럼)
+	// 변경 피드는 업체 사용자 누구나 받을 수 있으므로 비밀번호, 카드번호 등은 기록하지 않습니다.
+	// 제외 컬럼만 바뀐 수정은 이벤트를 남기지 않습니다.
+	// 회사별 advisory lock을 커밋까지 잡아 같은 회사의 이벤트ID가 커밋 순서대로 증가하게 합니다.
+	// 그래서 클라이언트는 마지막으로 받은 이벤트ID 이후만 조회해도 이벤트를 놓치지 않습니다.
+	// room_table은 회사코드 컬럼 이름이 comapny_code인 DB도 있으므로 둘 다 확인합니다.
+	triggerQueries := []string{
+		`CREATE OR REPLACE FUNCTION record_change_event() RETURNS trigger AS $$
+		DECLARE
+			row_data JSONB;
+			old_data JSONB;
+			company TEXT;
+			event_id BIGINT;
+			i INT;
+		BEGIN
+			IF TG_OP = 'DELETE' THEN
+				row_data := to_jsonb(OLD);
+			ELSE
+				row_data := to_jsonb(NEW);
+			END IF;
+			IF TG_OP = 'UPDATE' THEN
+				old_data := to_jsonb(OLD);
+			END IF;
+			FOR i IN 2 .. TG_NARGS - 1 LOOP
+				row_data := row_data - TG_ARGV[i];
+				old_data := old_data - TG_ARGV[i];
+			END LOOP;
+			IF TG_OP = 'UPDATE' AND old_data IS NOT DISTINCT FROM row_data THEN
+				RETURN NULL;
+			END IF;
+			company := COALESCE(row_data->>'company_code', row_data->>'comapny_code');
+			IF company IS NULL THEN
+				RETURN NULL;
+			END IF;
+
+			PERFORM pg_advisory_xact_lock(7260046, hashtext(company));
+			INSERT INTO change_event_table (company_code, entity, action, entity_id, data)
+			VALUES (company, TG_ARGV[0],
+				CASE TG_OP WHEN 'INSERT' THEN 'created' WHEN 'UPDATE' THEN 'updated' ELSE 'deleted' END,
+				row_data->>TG_ARGV[1], row_data)
+			RETURNING serial_number INTO event_id;
+			PERFORM pg_notify('change_event', company || ':' || event_id);
+			RETURN NULL;
+		END;
+		$$ LANGUAGE plpgsql;`,
+		`DROP TRIGGER IF EXISTS trg_room_change_event ON room_table;`,
+		`CREATE TRIGGER trg_room_change_event AFTER INSERT OR UPDATE OR DELETE ON room_table
+		FOR EACH ROW EXECUTE FUNCTION record_change_event('room', 'room_code');`,
+		`DROP TRIGGER IF EXISTS trg_seat_change_event ON seat_table;`,
+		`CREATE TRIGGER trg_seat_change_event AFTER INSERT OR UPDATE OR DELETE ON seat_table
+		FOR EACH ROW EXECUTE FUNCTION record_change_event('seat', 'seat_code', 'password', 'card_number');`,
+		// 제외 컬럼을 추가하기 전에 기록된 이벤트에서도 민감 컬럼을 지웁니다.
+		`UPDATE change_event_table SET data = data - 'password' - 'card_number'
+		WHERE entity = 'seat' AND (data ? 'password' OR data ? 'card_number');`,
+		`DROP TRIGGER IF EXISTS trg_seat_session_change_event ON seat_session_table;`,
+		`CREATE TRIGGER trg_seat_session_change_event AFTER INSERT OR UPDATE OR DELETE ON seat_session_table
+		FOR EACH ROW EXECUTE FUNCTION record_change_event('seat_session', 'serial_number');`,
+		`DROP TRIGGER IF EXISTS trg_payment_change_event ON payment_table;`,
+		`CREATE TRIGGER trg_payment_change_event AFTER INSERT ON payment_table
+		FOR EACH ROW EXECUTE FUNCTION record_change_event('payment', 'serial_number');`,
+	}
+	for _, query := range triggerQueries {
+		_, err = db.Exec(query)
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Println("change_event_table 테이블과 트리거가 성공적으로 생성되었습니다.")
+	return nil
+}
@@ -0,0 +1,103 @@
+package tables
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// CreateCleaningTables 청소 규칙 테이블과 청소 작업 테이블 및 인덱스를 생성합니다.
+// 함수 이름을 대문자로 시작하여 외부에서 접근 가능하게 만듭니다.
+// 퇴실로 비게 된 좌석은 청소 작업으로 등록되며, 좌석당 대기 중인 작업은 하나만 존재합니다.
+func CreateCleaningTables(db *sql.DB) error {
+	log.Println("cleaning_task_table 테이블을 생성합니다...")
+
+	// 테이블 생성
+	createBaseTableQueries := []string{
+		`CREATE TABLE IF NOT EXISTS cleaning_rule_table();`,
+		`CREATE TABLE IF NOT EXISTS cleaning_task_table();`,
+	}
+	for _, query := range createBaseTableQueries {
+		_, err := db.Exec(query)
+		if err != nil {
+			return err
+		}
+	}
+	log.Println("cleaning 테이블 기본 구조 생성 완료")
+
+	// 각 필드 개별 추가 (테이블명 -> 필드 목록)
+	tableFields := []struct {
+		tableName        string
+		fieldDefinitions []string
+	}{
+		{
+			tableName: "cleaning_rule_table",
+			fieldDefinitions: []string{
+				// 기본키
+				"serial_number BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY",
+				// 회사 코드 (회사당 1개 규칙)
+				"company_code TEXT NOT NULL",
+				// 청소 완료 전까지 키오스크 입실/대기열 제안 차단 여부
+				"block_until_cleaned BOOLEAN NOT NULL DEFAULT TRUE",
+				// 생성일
+				"created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP",
+				// 수정일
+				"updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP",
+			},
+		},
+		{
+			tableName: "cleaning_task_table",
+			fieldDefinitions: []string{
+				// 기본키
+				"serial_number BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY",
+				// 회사 코드
+				"company_code TEXT NOT NULL",
+				// 좌석 코드
+				"seat_code INTEGER NOT NULL",
+				// 청소를 발생시킨 세션 (직접 요청한 경우 NULL)
+				"session_id BIGINT REFERENCES seat_session_table(serial_number)",
+				// 상태(pending, cleaned, cancelled)
+				"status TEXT NOT NULL DEFAULT 'pending'",
+				// 청소 요청 시각
+				"requested_at TIMESTAMP NOT NULL",
+				// 청소 완료 시각
+				"cleaned_at TIMESTAMP",
+				// 청소 담당자
+				"cleaned_by TEXT",
+				// 메모
+				"memo TEXT",
+				// 생성일
+				"created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP",
+			},
+		},
+	}
+
+	for _, table := range tableFields {
+		alterPrefix := fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS ", table.tableName)
+		for i, field := range table.fieldDefinitions {
+			_, err := db.Exec(alterPrefix + field + ";")
+			if err != nil {
+				return err
+			}
+			log.Printf("%s 필드 추가 진행 중: %d/%d 완료", table.tableName, i+1, len(table.fieldDefinitions))
+		}
+	}
+
+	// 인덱스 생성 쿼리 목록
+	indexQueries := []string{
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_cleaning_rule_company ON cleaning_rule_table (company_code);`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_cleaning_task_pending ON cleaning_task_table (company_code, seat_code) WHERE status = 'pending';`,
+		`CREATE INDEX IF NOT EXISTS idx_cleaning_task_requested ON cleaning_task_table (company_code, requested_at);`,
+	}
+
+	// 인덱스 생성 실행
+	for _, query := range indexQueries {
+		_, err := db.Exec(query)
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Println("cleaning 테이블과 인덱스가 성공적으로 생성되었습니다.")
+	return nil
+}
@@ -39,6 +39,22 @@ func CreateCompanyImageTable(db *sql.DB) error {
 		"title TEXT",
 		// 설명
 		"description TEXT",
+		// 원본 파일 이름
+		"image_name TEXT",
+		// 저장소 키 (원본)
+		"image_path TEXT",
+		// 저장소 키 (썸네일)
+		"thumbnail_path TEXT",
+		// 파일 크기(바이트)
+		"image_size INTEGER",
+		// MIME 타입 (image/jpeg, image/png, image/gif)
+		"content_type TEXT",
+		// 내용 해시 (SHA-256 hex, 회사별 중복 업로드 확인)
+		"content_hash TEXT",
+		// 가로 픽셀
+		"width INTEGER",
+		// 세로 픽셀
+		"height INTEGER",
 		// 생성일
 		"created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP",
 		// 수정일
@@ -65,6 +81,8 @@ func CreateCompanyImageTable(db *sql.DB) error {
 	// 인덱스 생성 쿼리 목록
 	indexQueries := []string{
 		`CREATE INDEX IF NOT EXISTS idx_company_id ON company_image_table (company_id);`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_company_image_hash ON company_image_table (company_id, content_hash) WHERE content_hash IS NOT NULL;`,
+		`CREATE INDEX IF NOT EXISTS idx_company_image_order ON company_image_table (company_id, image_type, image_order);`,
 	}
 
 	// 인덱스 생성 실행
@@ -0,0 +1,97 @@
+package tables
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// CreateCredentialTable 회원 인증 수단(RFID 카드, QR, PIN) 테이블 및 인덱스를 생성합니다.
+// 함수 이름을 대문자로 시작하여 외부에서 접근 가능하게 만듭니다.
+// 분실 카드는 status를 revoked로 바꾸는 즉시 키오스크에서 사용할 수 없습니다.
+func CreateCredentialTable(db *sql.DB) error {
+	log.Println("credential_table 테이블을 생성합니다...")
+
+	// 테이블 생성
+	createBaseTableQuery := `CREATE TABLE IF NOT EXISTS credential_table();`
+
+	_, err := db.Exec(createBaseTableQuery)
+	if err != nil {
+		return err
+	}
+	log.Println("credential_table 테이블 기본 구조 생성 완료")
+
+	tableName := "credential_table"
+	alterPrefix := fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS ", tableName)
+
+	// 각 필드 개별 추가
+	fieldDefinitions := []string{
+		// 기본키
+		"serial_number BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY",
+		// 회사 코드
+		"company_code TEXT NOT NULL",
+		// 회원 번호 (user_table)
+		"member_id BIGINT NOT NULL REFERENCES user_table(serial_number)",
+		// 인증 수단(card, qr, pin)
+		"credential_type TEXT NOT NULL",
+		// RFID 카드 번호 (card)
+		"card_number TEXT",
+		// 비밀 값 (qr: 서명 키, pin: pbkdf2-sha256$반복 횟수$솔트$해시)
+		"secret TEXT",
+		// 마지막으로 사용한 QR 토큰 주기 (qr, 같은 토큰 재사용 방지)
+		"qr_last_step BIGINT",
+		// 표시 이름
+		"label TEXT",
+		// 상태(active, revoked)
+		"status TEXT NOT NULL DEFAULT 'active'",
+		// 연속 인증 실패 횟수 (pin)
+		"failed_attempts INTEGER NOT NULL DEFAULT 0",
+		// 잠금 해제 시각 (pin)
+		"locked_until TIMESTAMP",
+		// 마지막 사용 시각
+		"last_used_at TIMESTAMP",
+		// 폐기 시각
+		"revoked_at TIMESTAMP",
+		// 폐기 처리자
+		"revoked_by TEXT",
+		// 폐기 사유
+		"revoke_reason TEXT",
+		// 생성일
+		"created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP",
+		// 수정일
+		"updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP",
+	}
+
+	// 각 필드 추가 쿼리 생성
+	fieldQueries := make([]string, len(fieldDefinitions))
+	for i, field := range fieldDefinitions {
+		fieldQueries[i] = alterPrefix + field + ";"
+	}
+
+	// 각 필드 추가 실행 및 진행 상황 로깅
+	for i, query := range fieldQueries {
+		_, err = db.Exec(query)
+		if err != nil {
+			return err
+		}
+		log.Printf("credential_table 필드 추가 진행 중: %d/%d 완료", i+1, len(fieldQueries))
+	}
+
+	// 인덱스 생성 쿼리 목록
+	indexQueries := []string{
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_credential_active_card ON credential_table (company_code, card_number) WHERE credential_type = 'card' AND status = 'active';`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_credential_active_member_type ON credential_table (company_code, member_id, credential_type) WHERE credential_type IN ('qr', 'pin') AND status = 'active';`,
+		`CREATE INDEX IF NOT EXISTS idx_credential_member ON credential_table (company_code, member_id);`,
+	}
+
+	// 인덱스 생성 실행
+	for _, query := range indexQueries {
+		_, err = db.Exec(query)
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Println("credential_table 테이블과 인덱스가 성공적으로 생성되었습니다.")
+	return nil
+}
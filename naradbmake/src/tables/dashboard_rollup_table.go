@@ -0,0 +1,103 @@
+package tables
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// CreateDashboardRollupTables 대시보드 일별/시간대별 집계 테이블 및 인덱스를 생성합니다.
+// 함수 이름을 대문자로 시작하여 외부에서 접근 가능하게 만듭니다.
+// 집계 테이블은 narabackend 집계 작업이 일자 단위로 다시 계산하여 채웁니다.
+func CreateDashboardRollupTables(db *sql.DB) error {
+	log.Println("dashboard 집계 테이블을 생성합니다...")
+
+	// 테이블 생성
+	createBaseTableQueries := []string{
+		`CREATE TABLE IF NOT EXISTS daily_usage_rollup_table();`,
+		`CREATE TABLE IF NOT EXISTS hourly_usage_rollup_table();`,
+	}
+	for _, query := range createBaseTableQueries {
+		_, err := db.Exec(query)
+		if err != nil {
+			return err
+		}
+	}
+	log.Println("dashboard 집계 테이블 기본 구조 생성 완료")
+
+	// 각 필드 개별 추가 (테이블명 -> 필드 목록)
+	tableFields := []struct {
+		tableName        string
+		fieldDefinitions []string
+	}{
+		{
+			tableName: "daily_usage_rollup_table",
+			fieldDefinitions: []string{
+				// 회사 코드
+				"company_code TEXT NOT NULL",
+				// 집계 일자
+				"usage_date DATE NOT NULL",
+				// 해당 일자에 시작된 세션 수
+				"session_count INTEGER NOT NULL DEFAULT 0",
+				// 해당 일자에 이용한 회원 수(중복 제외)
+				"user_count INTEGER NOT NULL DEFAULT 0",
+				// 해당 일자에 해당하는 이용 시간 합계(분)
+				"used_minutes INTEGER NOT NULL DEFAULT 0",
+				// 결제 건수(환불 제외)
+				"payment_count INTEGER NOT NULL DEFAULT 0",
+				// 순매출(결제 - 환불)
+				"revenue BIGINT NOT NULL DEFAULT 0",
+				// 집계 시각
+				"refreshed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP",
+			},
+		},
+		{
+			tableName: "hourly_usage_rollup_table",
+			fieldDefinitions: []string{
+				// 회사 코드
+				"company_code TEXT NOT NULL",
+				// 집계 일자
+				"usage_date DATE NOT NULL",
+				// 시간대(0~23)
+				"hour SMALLINT NOT NULL CHECK (hour BETWEEN 0 AND 23)",
+				// 해당 시간대에 이용 중이던 회원 수(중복 제외)
+				"user_count INTEGER NOT NULL DEFAULT 0",
+				// 집계 시각
+				"refreshed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP",
+			},
+		},
+	}
+
+	for _, table := range tableFields {
+		alterPrefix := fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS ", table.tableName)
+		for i, field := range table.fieldDefinitions {
+			_, err := db.Exec(alterPrefix + field + ";")
+			if err != nil {
+				return err
+			}
+			log.Printf("%s 필드 추가 진행 중: %d/%d 완료", table.tableName, i+1, len(table.fieldDefinitions))
+		}
+	}
+
+	// 인덱스 생성 쿼리 목록
+	indexQueries := []string{
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_daily_usage_rollup ON daily_usage_rollup_table (company_code, usage_date);`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_hourly_usage_rollup ON hourly_usage_rollup_table (company_code, usage_date, hour);`,
+		`CREATE INDEX IF NOT EXISTS idx_daily_usage_rollup_date ON daily_usage_rollup_table (usage_date);`,
+		`CREATE INDEX IF NOT EXISTS idx_hourly_usage_rollup_date ON hourly_usage_rollup_table (usage_date);`,
+		// 집계 쿼리가 일자 범위로 원본 테이블을 읽기 위한 인덱스
+		`CREATE INDEX IF NOT EXISTS idx_seat_session_check_in ON seat_session_table (check_in_at);`,
+		`CREATE INDEX IF NOT EXISTS idx_payment_created_at ON payment_table (created_at);`,
+	}
+
+	// 인덱스 생성 실행
+	for _, query := range indexQueries {
+		_, err := db.Exec(query)
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Println("dashboard 집계 테이블과 인덱스가 성공적으로 생성되었습니다.")
+	return nil
+}
@@ -0,0 +1,91 @@
+package tables
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// CreateJobScheduleTable narabackend 반복 작업 일정(cron) 테이블 및 인덱스를 생성합니다.
+// 함수 이름을 대문자로 시작하여 외부에서 접근 가능하게 만듭니다.
+// company_code가 빈 값이면 전체 공통 일정이며, 일정이 되면 job_table에 작업을 넣습니다.
+func CreateJobScheduleTable(db *sql.DB) error {
+	log.Println("job_schedule_table 테이블을 생성합니다...")
+
+	// 테이블 생성
+	createBaseTableQuery := `CREATE TABLE IF NOT EXISTS job_schedule_table();`
+	_, err := db.Exec(createBaseTableQuery)
+	if err != nil {
+		return err
+	}
+	log.Println("job_schedule_table 테이블 기본 구조 생성 완료")
+
+	tableName := "job_schedule_table"
+	alterPrefix := fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS ", tableName)
+
+	// 각 필드 개별 추가
+	fieldDefinitions := []string{
+		// 기본키
+		"serial_number BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY",
+		// 일정 키 (예: dashboard-rollup-daily)
+		"schedule_key TEXT NOT NULL",
+		// 업체코드 (빈 값이면 전체 공통)
+		"company_code TEXT NOT NULL DEFAULT ''",
+		// cron 식 (분 시 일 월 요일)
+		"cron_expr TEXT NOT NULL",
+		// cron 식을 해석할 시간대 (예: Asia/Seoul, 없으면 서버 시간대)
+		"timezone TEXT",
+		// 넣을 작업 이름 (job_table.name)
+		"job_name TEXT NOT NULL",
+		// 작업 데이터
+		"payload JSONB NOT NULL DEFAULT '{}'",
+		// 설명
+		"description TEXT",
+		// 사용 여부
+		"enabled BOOLEAN NOT NULL DEFAULT TRUE",
+		// 마지막 실행 예정 시각 (작업을 넣은 일정 시각)
+		"last_run_at TIMESTAMP",
+		// 다음 실행 예정 시각
+		"next_run_at TIMESTAMP",
+		// 마지막으로 넣은 작업 (job_table.serial_number)
+		"last_job_id BIGINT",
+		// 마지막 일정 처리 오류 (잘못된 cron 식 등)
+		"last_error TEXT",
+		// 생성일
+		"created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP",
+		// 수정일
+		"updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP",
+	}
+
+	// 각 필드 추가 쿼리 생성
+	fieldQueries := make([]string, len(fieldDefinitions))
+	for i, field := range fieldDefinitions {
+		fieldQueries[i] = alterPrefix + field + ";"
+	}
+
+	// 각 필드 추가 실행 및 진행 상황 로깅
+	for i, query := range fieldQueries {
+		_, err = db.Exec(query)
+		if err != nil {
+			return err
+		}
+		log.Printf("job_schedule_table 필드 추가 진행 중: %d/%d 완료", i+1, len(fieldQueries))
+	}
+
+	// 인덱스 생성 쿼리 목록
+	indexQueries := []string{
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_job_schedule_key ON job_schedule_table (schedule_key, company_code);`,
+		`CREATE INDEX IF NOT EXISTS idx_job_schedule_due ON job_schedule_table (next_run_at) WHERE enabled;`,
+	}
+
+	// 인덱스 생성 실행
+	for _, query := range indexQueries {
+		_, err = db.Exec(query)
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Println("job_schedule_table 테이블과 인덱스가 성공적으로 생성되었습니다.")
+	return nil
+}
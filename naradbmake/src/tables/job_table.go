@@ -0,0 +1,98 @@
+package tables
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// CreateJobTable narabackend 비동기 작업 큐 테이블 및 인덱스를 생성합니다.
+// 함수 이름을 대문자로 시작하여 외부에서 접근 가능하게 만듭니다.
+// 워커는 SELECT ... FOR UPDATE SKIP LOCKED로 작업을 가져가며, 서버가 재시작되어도 작업이 유지됩니다.
+func CreateJobTable(db *sql.DB) error {
+	log.Println("job_table 테이블을 생성합니다...")
+
+	// 테이블 생성
+	createBaseTableQuery := `CREATE TABLE IF NOT EXISTS job_table();`
+	_, err := db.Exec(createBaseTableQuery)
+	if err != nil {
+		return err
+	}
+	log.Println("job_table 테이블 기본 구조 생성 완료")
+
+	tableName := "job_table"
+	alterPrefix := fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS ", tableName)
+
+	// 각 필드 개별 추가
+	fieldDefinitions := []string{
+		// 기본키
+		"serial_number BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY",
+		// 작업 이름 (예: RoomUpdated)
+		"name TEXT NOT NULL",
+		// 작업 데이터
+		"payload JSONB NOT NULL DEFAULT '{}'",
+		// 멱등 키 (같은 작업 이름과 키로 다시 넣으면 기존 작업을 사용)
+		"idempotency_key TEXT",
+		// 우선순위 (높을수록 먼저 처리)
+		"priority INTEGER NOT NULL DEFAULT 0",
+		// 상태(queued, running, succeeded, dead, cancelled)
+		"status TEXT NOT NULL DEFAULT 'queued'",
+		// 실행 시도 횟수
+		"attempts INTEGER NOT NULL DEFAULT 0",
+		// 최대 실행 시도 횟수 (넘으면 dead)
+		"max_attempts INTEGER NOT NULL DEFAULT 5",
+		// 실행 가능 시각 (재시도 대기 포함)
+		"run_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP",
+		// 실행 중인 워커
+		"locked_by TEXT",
+		// 실행 잠금 만료 시각 (지나면 워커가 중단된 것으로 보고 다시 실행)
+		"locked_until TIMESTAMP",
+		// 마지막 오류
+		"last_error TEXT",
+		// 실패 이력 (시도마다 {attempt, error, worker, at})
+		"error_history JSONB NOT NULL DEFAULT '[]'",
+		// 생성일
+		"created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP",
+		// 마지막 실행 시작 시각
+		"started_at TIMESTAMP",
+		// 완료 시각 (succeeded, dead)
+		"finished_at TIMESTAMP",
+		// 수정일
+		"updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP",
+	}
+
+	// 각 필드 추가 쿼리 생성
+	fieldQueries := make([]string, len(fieldDefinitions))
+	for i, field := range fieldDefinitions {
+		fieldQueries[i] = alterPrefix + field + ";"
+	}
+
+	// 각 필드 추가 실행 및 진행 상황 로깅
+	for i, query := range fieldQueries {
+		_, err = db.Exec(query)
+		if err != nil {
+			return err
+		}
+		log.Printf("job_table 필드 추가 진행 중: %d/%d 완료", i+1, len(fieldQueries))
+	}
+
+	// 인덱스 생성 쿼리 목록
+	indexQueries := []string{
+		`CREATE INDEX IF NOT EXISTS idx_job_ready ON job_table (priority DESC, run_at, serial_number) WHERE status = 'queued';`,
+		`CREATE INDEX IF NOT EXISTS idx_job_running ON job_table (locked_until) WHERE status = 'running';`,
+		`CREATE INDEX IF NOT EXISTS idx_job_status_name ON job_table (status, name, created_at);`,
+		// 멱등 키는 완료된 작업에도 유지됩니다 (반복 작업 일정, 웹훅 전송, 알림 발송이 한 번만 들어가도록). 완료 작업이 보관 기간 후 삭제되면 다시 쓸 수 있습니다.
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_job_idempotency ON job_table (name, idempotency_key) WHERE idempotency_key IS NOT NULL;`,
+	}
+
+	// 인덱스 생성 실행
+	for _, query := range indexQueries {
+		_, err = db.Exec(query)
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Println("job_table 테이블과 인덱스가 성공적으로 생성되었습니다.")
+	return nil
+}
@@ -0,0 +1,119 @@
+package tables
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// CreateLockerTables 사물함 테이블과 사물함 대여 테이블 및 인덱스를 생성합니다.
+// 함수 이름을 대문자로 시작하여 외부에서 접근 가능하게 만듭니다.
+// 사물함은 좌석과 별개의 대여 자산이며, 대여는 회원/이용권과 연결되고 만료일을 가집니다.
+func CreateLockerTables(db *sql.DB) error {
+	log.Println("locker_table 테이블을 생성합니다...")
+
+	// 테이블 생성
+	createBaseTableQueries := []string{
+		`CREATE TABLE IF NOT EXISTS locker_table();`,
+		`CREATE TABLE IF NOT EXISTS locker_rental_table();`,
+	}
+	for _, query := range createBaseTableQueries {
+		_, err := db.Exec(query)
+		if err != nil {
+			return err
+		}
+	}
+	log.Println("locker 테이블 기본 구조 생성 완료")
+
+	// 각 필드 개별 추가 (테이블명 -> 필드 목록)
+	tableFields := []struct {
+		tableName        string
+		fieldDefinitions []string
+	}{
+		{
+			tableName: "locker_table",
+			fieldDefinitions: []string{
+				// 기본키
+				"serial_number BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY",
+				// 회사 코드
+				"company_code TEXT NOT NULL",
+				// 사물함 번호
+				"locker_number INTEGER NOT NULL",
+				// 열람실 코드 (없으면 공용 구역)
+				"room_code INTEGER",
+				// 구역 이름
+				"zone TEXT",
+				// 크기(small, medium, large)
+				"size TEXT NOT NULL DEFAULT 'medium'",
+				// 상태(available, occupied, expired, maintenance)
+				"status TEXT NOT NULL DEFAULT 'available'",
+				// 메모
+				"memo TEXT",
+				// 생성일
+				"created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP",
+				// 수정일
+				"updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP",
+			},
+		},
+		{
+			tableName: "locker_rental_table",
+			fieldDefinitions: []string{
+				// 기본키
+				"serial_number BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY",
+				// 회사 코드
+				"company_code TEXT NOT NULL",
+				// 사물함 번호
+				"locker_id BIGINT NOT NULL REFERENCES locker_table(serial_number)",
+				// 회원 번호
+				"member_id BIGINT NOT NULL REFERENCES user_table(serial_number)",
+				// 연결된 이용권 번호
+				"pass_id BIGINT REFERENCES member_pass_table(serial_number)",
+				// 대여 결제 번호
+				"payment_id BIGINT REFERENCES payment_table(serial_number)",
+				// 대여 시작 시각
+				"start_at TIMESTAMP NOT NULL",
+				// 만료 시각
+				"expires_at TIMESTAMP NOT NULL",
+				// 반납 시각
+				"returned_at TIMESTAMP",
+				// 상태(active, expired, returned)
+				"status TEXT NOT NULL DEFAULT 'active'",
+				// 메모
+				"memo TEXT",
+				// 생성일
+				"created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP",
+			},
+		},
+	}
+
+	for _, table := range tableFields {
+		alterPrefix := fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS ", table.tableName)
+		for i, field := range table.fieldDefinitions {
+			_, err := db.Exec(alterPrefix + field + ";")
+			if err != nil {
+				return err
+			}
+			log.Printf("%s 필드 추가 진행 중: %d/%d 완료", table.tableName, i+1, len(table.fieldDefinitions))
+		}
+	}
+
+	// 인덱스 생성 쿼리 목록
+	indexQueries := []string{
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_locker_company_number ON locker_table (company_code, locker_number);`,
+		`CREATE INDEX IF NOT EXISTS idx_locker_status ON locker_table (company_code, status);`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_locker_rental_open ON locker_rental_table (locker_id) WHERE status IN ('active', 'expired');`,
+		`CREATE INDEX IF NOT EXISTS idx_locker_rental_member ON locker_rental_table (member_id, start_at);`,
+		`CREATE INDEX IF NOT EXISTS idx_locker_rental_expires ON locker_rental_table (expires_at) WHERE status = 'active';`,
+	}
+
+	// 인덱스 생성 실행
+	for _, query := range indexQueries {
+		_, err := db.Exec(query)
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Println("locker 테이블과 인덱스가 성공적으로 생성되었습니다.")
+	return nil
+}
@@ -0,0 +1,89 @@
+package tables
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// CreateMemberPassTable 회원 이용권 원장 테이블 및 인덱스를 생성합니다.
+// 함수 이름을 대문자로 시작하여 외부에서 접근 가능하게 만듭니다.
+// 회원이 구매한 이용권의 잔여 시간/유효 기간을 관리하는 테이블
+func CreateMemberPassTable(db *sql.DB) error {
+	log.Println("member_pass_table 테이블을 생성합니다...")
+
+	// 테이블 생성
+	createBaseTableQuery := `CREATE TABLE IF NOT EXISTS member_pass_table();`
+
+	_, err := db.Exec(createBaseTableQuery)
+	if err != nil {
+		return err
+	}
+	log.Println("member_pass_table 테이블 기본 구조 생성 완료")
+
+	tableName := "member_pass_table"
+	alterPrefix := fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS ", tableName)
+
+	// 각 필드 개별 추가
+	fieldDefinitions := []string{
+		// 기본키
+		"serial_number BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY",
+		// 회사 코드
+		"company_code TEXT NOT NULL",
+		// 회원 번호(user_table.serial_number)
+		"member_id BIGINT NOT NULL REFERENCES user_table(serial_number)",
+		// 상품 번호
+		"plan_id BIGINT NOT NULL REFERENCES plan_table(serial_number)",
+		// 상품 구분(구매 시점의 plan_type 복사본)
+		"pass_type TEXT NOT NULL",
+		// 잔여 시간(분), 시간제 이용권만 사용. 기간권은 NULL
+		"remaining_minutes INTEGER",
+		// 유효 시작 시간
+		"valid_from TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP",
+		// 유효 종료 시간
+		"valid_until TIMESTAMP",
+		// 고정석 좌석 코드
+		"seat_code INTEGER",
+		// 상태(active=사용 가능, exhausted=시간 소진, expired=기간 만료, cancelled=취소)
+		"status TEXT NOT NULL DEFAULT 'active'",
+		// 구매 금액
+		"purchased_amount INTEGER NOT NULL DEFAULT 0",
+		// 생성일
+		"created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP",
+		// 수정일
+		"updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP",
+	}
+
+	// 각 필드 추가 쿼리 생성
+	fieldQueries := make([]string, len(fieldDefinitions))
+	for i, field := range fieldDefinitions {
+		fieldQueries[i] = alterPrefix + field + ";"
+	}
+
+	// 각 필드 추가 실행 및 진행 상황 로깅
+	for i, query := range fieldQueries {
+		_, err = db.Exec(query)
+		if err != nil {
+			return err
+		}
+		log.Printf("member_pass_table 필드 추가 진행 중: %d/%d 완료", i+1, len(fieldQueries))
+	}
+
+	// 인덱스 생성 쿼리 목록
+	indexQueries := []string{
+		`CREATE INDEX IF NOT EXISTS idx_member_pass_company_code ON member_pass_table (company_code);`,
+		`CREATE INDEX IF NOT EXISTS idx_member_pass_member_id ON member_pass_table (member_id);`,
+		`CREATE INDEX IF NOT EXISTS idx_member_pass_status_valid_until ON member_pass_table (status, valid_until);`,
+	}
+
+	// 인덱스 생성 실행
+	for _, query := range indexQueries {
+		_, err = db.Exec(query)
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Println("member_pass_table 테이블과 인덱스가 성공적으로 생성되었습니다.")
+	return nil
+}
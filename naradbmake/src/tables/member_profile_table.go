@@ -0,0 +1,86 @@
+package tables
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// CreateMemberProfileTable 업체별 회원 정보(회원 등급, 상태) 테이블 및 인덱스를 생성합니다.
+// 함수 이름을 대문자로 시작하여 외부에서 접근 가능하게 만듭니다.
+// user_table은 업체와 무관한 본인 정보만 가지므로, 업체마다 달라지는 회원 정보는 이 테이블에 보관합니다.
+func CreateMemberProfileTable(db *sql.DB) error {
+	log.Println("member_profile_table 테이블을 생성합니다...")
+
+	// 테이블 생성
+	createBaseTableQuery := `CREATE TABLE IF NOT EXISTS member_profile_table();`
+
+	_, err := db.Exec(createBaseTableQuery)
+	if err != nil {
+		return err
+	}
+	log.Println("member_profile_table 테이블 기본 구조 생성 완료")
+
+	tableName := "member_profile_table"
+	alterPrefix := fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS ", tableName)
+
+	// 각 필드 개별 추가
+	fieldDefinitions := []string{
+		// 기본키
+		"serial_number BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY",
+		// 회사 코드
+		"company_code TEXT NOT NULL",
+		// 회원 번호 (user_table)
+		"member_id BIGINT NOT NULL REFERENCES user_table(serial_number)",
+		// 회원 구분(basic, premium, vip)
+		"membership_type TEXT NOT NULL DEFAULT 'basic'",
+		// 회원 상태(active, inactive, suspended)
+		"status TEXT NOT NULL DEFAULT 'active'",
+		// 등급 번호
+		"grade_number INTEGER",
+		// 등급명
+		"grade_name TEXT",
+		// 무인 운영 등급
+		"unmanned_grade INTEGER",
+		// 메모
+		"memo TEXT",
+		// 업체 가입일
+		"registered_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP",
+		// 생성일
+		"created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP",
+		// 수정일
+		"updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP",
+	}
+
+	// 각 필드 추가 쿼리 생성
+	fieldQueries := make([]string, len(fieldDefinitions))
+	for i, field := range fieldDefinitions {
+		fieldQueries[i] = alterPrefix + field + ";"
+	}
+
+	// 각 필드 추가 실행 및 진행 상황 로깅
+	for i, query := range fieldQueries {
+		_, err = db.Exec(query)
+		if err != nil {
+			return err
+		}
+		log.Printf("member_profile_table 필드 추가 진행 중: %d/%d 완료", i+1, len(fieldQueries))
+	}
+
+	// 인덱스 생성 쿼리 목록
+	indexQueries := []string{
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_member_profile_company_member ON member_profile_table (company_code, member_id);`,
+		`CREATE INDEX IF NOT EXISTS idx_member_profile_status ON member_profile_table (company_code, status);`,
+	}
+
+	// 인덱스 생성 실행
+	for _, query := range indexQueries {
+		_, err = db.Exec(query)
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Println("member_profile_table 테이블과 인덱스가 성공적으로 생성되었습니다.")
+	return nil
+}
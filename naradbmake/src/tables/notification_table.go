@@ -0,0 +1,134 @@
+package tables
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// CreateNotificationTables 회원별 알림 수신 설정 테이블과 알림 발송 기록 테이블 및 인덱스를 생성합니다.
+// 함수 이름을 대문자로 시작하여 외부에서 접근 가능하게 만듭니다.
+// 알림은 발송 기록에 먼저 저장된 뒤 narabackend 작업 큐(job_table)에서 발송되며, 중복 방지 키가 같은 알림은 한 번만 저장됩니다.
+func CreateNotificationTables(db *sql.DB) error {
+	log.Println("notification 테이블을 생성합니다...")
+
+	// 테이블 생성
+	createBaseTableQueries := []string{
+		`CREATE TABLE IF NOT EXISTS notification_preference_table();`,
+		`CREATE TABLE IF NOT EXISTS notification_table();`,
+	}
+	for _, query := range createBaseTableQueries {
+		_, err := db.Exec(query)
+		if err != nil {
+			return err
+		}
+	}
+	log.Println("notification 테이블 기본 구조 생성 완료")
+
+	// 각 필드 개별 추가 (테이블명 -> 필드 목록)
+	tableFields := []struct {
+		tableName        string
+		fieldDefinitions []string
+	}{
+		{
+			tableName: "notification_preference_table",
+			fieldDefinitions: []string{
+				// 기본키
+				"serial_number BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY",
+				// 회사 코드
+				"company_code TEXT NOT NULL",
+				// 회원 번호 (user_table.serial_number)
+				"member_id BIGINT NOT NULL",
+				// 문자 수신 여부
+				"sms_enabled BOOLEAN NOT NULL DEFAULT TRUE",
+				// 알림톡 수신 여부
+				"kakao_enabled BOOLEAN NOT NULL DEFAULT TRUE",
+				// 이메일 수신 여부
+				"email_enabled BOOLEAN NOT NULL DEFAULT TRUE",
+				// 방해 금지 시작 시각 (HH:MM, NULL이면 기본값)
+				"quiet_start TEXT",
+				// 방해 금지 종료 시각 (HH:MM)
+				"quiet_end TEXT",
+				// 알림 언어 (ko, en)
+				"locale TEXT NOT NULL DEFAULT 'ko'",
+				// 생성일
+				"created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP",
+				// 수정일
+				"updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP",
+			},
+		},
+		{
+			tableName: "notification_table",
+			fieldDefinitions: []string{
+				// 기본키 (알림ID)
+				"serial_number BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY",
+				// 회사 코드 (관리자 비밀번호 재설정처럼 업체와 무관하면 빈 문자열)
+				"company_code TEXT NOT NULL DEFAULT ''",
+				// 수신자 종류 (member, manager)
+				"recipient_type TEXT NOT NULL",
+				// 수신자 ID (회원 번호 또는 관리자 아이디)
+				"recipient_id TEXT NOT NULL",
+				// 발송 채널 (sms, kakao, email)
+				"channel TEXT NOT NULL",
+				// 수신 주소 (전화번호 또는 이메일)
+				"address TEXT NOT NULL",
+				// 템플릿 키
+				"template_key TEXT NOT NULL",
+				// 알림 언어
+				"locale TEXT NOT NULL DEFAULT 'ko'",
+				// 제목
+				"subject TEXT",
+				// 본문
+				"body TEXT NOT NULL",
+				// 중복 방지 키 (같은 업체에서 같은 키는 한 번만 발송)
+				"dedup_key TEXT",
+				// 상태(queued, sent, failed, skipped)
+				"status TEXT NOT NULL DEFAULT 'queued'",
+				// 발송 시도 횟수
+				"attempts INTEGER NOT NULL DEFAULT 0",
+				// 발송 어댑터 이름
+				"provider TEXT",
+				// 대행사 메시지 ID
+				"provider_message_id TEXT",
+				// 마지막 오류
+				"last_error TEXT",
+				// 발송 예정 시각 (방해 금지 시간이면 종료 시각으로 미뤄짐)
+				"scheduled_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP",
+				// 발송 완료 시각
+				"sent_at TIMESTAMP",
+				// 생성일
+				"created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP",
+			},
+		},
+	}
+
+	for _, table := range tableFields {
+		alterPrefix := fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS ", table.tableName)
+		for i, field := range table.fieldDefinitions {
+			_, err := db.Exec(alterPrefix + field + ";")
+			if err != nil {
+				return err
+			}
+			log.Printf("%s 필드 추가 진행 중: %d/%d 완료", table.tableName, i+1, len(table.fieldDefinitions))
+		}
+	}
+
+	// 인덱스 생성 쿼리 목록
+	indexQueries := []string{
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_notification_preference_member ON notification_preference_table (company_code, member_id);`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_notification_dedup ON notification_table (company_code, dedup_key) WHERE dedup_key IS NOT NULL;`,
+		`CREATE INDEX IF NOT EXISTS idx_notification_recipient ON notification_table (company_code, recipient_type, recipient_id, created_at);`,
+		`CREATE INDEX IF NOT EXISTS idx_notification_created ON notification_table (created_at);`,
+	}
+
+	// 인덱스 생성 실행
+	for _, query := range indexQueries {
+		_, err := db.Exec(query)
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Println("notification 테이블과 인덱스가 성공적으로 생성되었습니다.")
+	return nil
+}
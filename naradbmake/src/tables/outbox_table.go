@@ -0,0 +1,85 @@
+package tables
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// CreateOutboxTable narabackend 변경 이벤트 outbox 테이블 및 인덱스를 생성합니다.
+// 함수 이름을 대문자로 시작하여 외부에서 접근 가능하게 만듭니다.
+// 데이터 변경과 같은 트랜잭션에서 기록되며, 릴레이가 naracontrol로 전달한 뒤 delivered로 표시합니다.
+func CreateOutboxTable(db *sql.DB) error {
+	log.Println("outbox_table 테이블을 생성합니다...")
+
+	// 테이블 생성
+	createBaseTableQuery := `CREATE TABLE IF NOT EXISTS outbox_table();`
+	_, err := db.Exec(createBaseTableQuery)
+	if err != nil {
+		return err
+	}
+	log.Println("outbox_table 테이블 기본 구조 생성 완료")
+
+	tableName := "outbox_table"
+	alterPrefix := fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS ", tableName)
+
+	// 각 필드 개별 추가
+	fieldDefinitions := []string{
+		// 기본키 (전달 순서)
+		"serial_number BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY",
+		// 회사코드
+		"company_code TEXT NOT NULL",
+		// 이벤트 이름 (예: room_updated, seat_occupied)
+		"event TEXT NOT NULL",
+		// 수신 클라이언트 타입 (naradesk, naradevice)
+		"targets TEXT[] NOT NULL DEFAULT '{}'",
+		// naracontrol 메시지
+		"message JSONB NOT NULL DEFAULT '{}'",
+		// 상태(pending, delivered, failed)
+		"status TEXT NOT NULL DEFAULT 'pending'",
+		// 전달 시도 횟수
+		"attempts INTEGER NOT NULL DEFAULT 0",
+		// 다음 전달 시도 시각 (재시도 대기 포함)
+		"next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP",
+		// 마지막 오류
+		"last_error TEXT",
+		// 전달받은 클라이언트 수
+		"recipients INTEGER",
+		// 생성일
+		"created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP",
+		// 전달 완료 시각
+		"delivered_at TIMESTAMP",
+	}
+
+	// 각 필드 추가 쿼리 생성
+	fieldQueries := make([]string, len(fieldDefinitions))
+	for i, field := range fieldDefinitions {
+		fieldQueries[i] = alterPrefix + field + ";"
+	}
+
+	// 각 필드 추가 실행 및 진행 상황 로깅
+	for i, query := range fieldQueries {
+		_, err = db.Exec(query)
+		if err != nil {
+			return err
+		}
+		log.Printf("outbox_table 필드 추가 진행 중: %d/%d 완료", i+1, len(fieldQueries))
+	}
+
+	// 인덱스 생성 쿼리 목록
+	indexQueries := []string{
+		`CREATE INDEX IF NOT EXISTS idx_outbox_pending ON outbox_table (next_attempt_at, serial_number) WHERE status = 'pending';`,
+		`CREATE INDEX IF NOT EXISTS idx_outbox_status_created ON outbox_table (status, created_at);`,
+	}
+
+	// 인덱스 생성 실행
+	for _, query := range indexQueries {
+		_, err = db.Exec(query)
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Println("outbox_table 테이블과 인덱스가 성공적으로 생성되었습니다.")
+	return nil
+}
@@ -0,0 +1,107 @@
+package tables
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// CreateOutingRuleTables 외출 규칙 테이블과 외출 이벤트 테이블 및 인덱스를 생성합니다.
+// 함수 이름을 대문자로 시작하여 외부에서 접근 가능하게 만듭니다.
+// 외출 이벤트는 (좌석, 외출 시작 시각, 이벤트 종류)마다 한 번만 기록되어 경고/위약금이 중복되지 않습니다.
+func CreateOutingRuleTables(db *sql.DB) error {
+	log.Println("outing_rule_table 테이블을 생성합니다...")
+
+	// 테이블 생성
+	createBaseTableQueries := []string{
+		`CREATE TABLE IF NOT EXISTS outing_rule_table();`,
+		`CREATE TABLE IF NOT EXISTS outing_event_table();`,
+	}
+	for _, query := range createBaseTableQueries {
+		_, err := db.Exec(query)
+		if err != nil {
+			return err
+		}
+	}
+	log.Println("outing 테이블 기본 구조 생성 완료")
+
+	// 각 필드 개별 추가 (테이블명 -> 필드 목록)
+	tableFields := []struct {
+		tableName        string
+		fieldDefinitions []string
+	}{
+		{
+			tableName: "outing_rule_table",
+			fieldDefinitions: []string{
+				// 기본키
+				"serial_number BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY",
+				// 회사 코드 (회사당 1개 규칙)
+				"company_code TEXT NOT NULL",
+				// 최대 외출 시간(분)
+				"max_outing_minutes INTEGER NOT NULL CHECK (max_outing_minutes > 0)",
+				// 만료 몇 분 전에 경고할지 (0이면 경고 없음)
+				"warning_before_minutes INTEGER NOT NULL DEFAULT 0 CHECK (warning_before_minutes >= 0)",
+				// 초과 시 좌석 자동 해제 여부
+				"auto_release BOOLEAN NOT NULL DEFAULT TRUE",
+				// 초과 시 위약금 (0이면 부과하지 않음)
+				"penalty_amount INTEGER NOT NULL DEFAULT 0 CHECK (penalty_amount >= 0)",
+				// 위약금 결제 수단
+				"penalty_payment_method TEXT NOT NULL DEFAULT 'point'",
+				// 사용 여부
+				"enabled BOOLEAN NOT NULL DEFAULT TRUE",
+				// 생성일
+				"created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP",
+				// 수정일
+				"updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP",
+			},
+		},
+		{
+			tableName: "outing_event_table",
+			fieldDefinitions: []string{
+				// 기본키
+				"serial_number BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY",
+				// 회사 코드
+				"company_code TEXT NOT NULL",
+				// 좌석 코드
+				"seat_code INTEGER NOT NULL",
+				// 외출 시작 시각 (seat_table.outing_datetime)
+				"outing_datetime TIMESTAMP NOT NULL",
+				// 이벤트 종류(warning, overrun)
+				"event_type TEXT NOT NULL",
+				// 위약금 결제 번호
+				"payment_id BIGINT REFERENCES payment_table(serial_number)",
+				// 생성일
+				"created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP",
+			},
+		},
+	}
+
+	for _, table := range tableFields {
+		alterPrefix := fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS ", table.tableName)
+		for i, field := range table.fieldDefinitions {
+			_, err := db.Exec(alterPrefix + field + ";")
+			if err != nil {
+				return err
+			}
+			log.Printf("%s 필드 추가 진행 중: %d/%d 완료", table.tableName, i+1, len(table.fieldDefinitions))
+		}
+	}
+
+	// 인덱스 생성 쿼리 목록
+	indexQueries := []string{
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_outing_rule_company ON outing_rule_table (company_code);`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_outing_event_unique ON outing_event_table (company_code, seat_code, outing_datetime, event_type);`,
+		`CREATE INDEX IF NOT EXISTS idx_outing_event_created_at ON outing_event_table (company_code, created_at);`,
+	}
+
+	// 인덱스 생성 실행
+	for _, query := range indexQueries {
+		_, err := db.Exec(query)
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Println("outing 테이블과 인덱스가 성공적으로 생성되었습니다.")
+	return nil
+}
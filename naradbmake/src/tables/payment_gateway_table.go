@@ -0,0 +1,115 @@
+package tables
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// CreatePaymentGatewayTables PG 거래 테이블과 웹훅 이벤트 테이블 및 인덱스를 생성합니다.
+// 함수 이름을 대문자로 시작하여 외부에서 접근 가능하게 만듭니다.
+// PG 거래는 승인~매입~환불 상태를 추적하고, 매입/환불이 확정되면 payment_table에 원장 행을 남깁니다.
+func CreatePaymentGatewayTables(db *sql.DB) error {
+	log.Println("payment_gateway_transaction_table 테이블을 생성합니다...")
+
+	// 테이블 생성
+	createBaseTableQueries := []string{
+		`CREATE TABLE IF NOT EXISTS payment_gateway_transaction_table();`,
+		`CREATE TABLE IF NOT EXISTS payment_gateway_event_table();`,
+	}
+	for _, query := range createBaseTableQueries {
+		_, err := db.Exec(query)
+		if err != nil {
+			return err
+		}
+	}
+	log.Println("payment_gateway 테이블 기본 구조 생성 완료")
+
+	// 각 필드 개별 추가 (테이블명 -> 필드 목록)
+	tableFields := []struct {
+		tableName        string
+		fieldDefinitions []string
+	}{
+		{
+			tableName: "payment_gateway_transaction_table",
+			fieldDefinitions: []string{
+				// 기본키
+				"serial_number BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY",
+				// 회사 코드
+				"company_code TEXT NOT NULL",
+				// PG 이름(fake, ...)
+				"gateway TEXT NOT NULL",
+				// PG 거래 번호
+				"transaction_id TEXT NOT NULL",
+				// 회원 번호
+				"member_id BIGINT REFERENCES user_table(serial_number)",
+				// 이용권 번호
+				"pass_id BIGINT REFERENCES member_pass_table(serial_number)",
+				// 결제 구분(payment_table.payment_type)
+				"payment_type TEXT NOT NULL",
+				// 승인 금액
+				"amount INTEGER NOT NULL",
+				// 환불 금액 합계
+				"refunded_amount INTEGER NOT NULL DEFAULT 0",
+				// 거래 상태(authorized, captured, cancelled, partially_refunded, refunded, failed)
+				"status TEXT NOT NULL",
+				// 매입 시 생성된 원장 결제 번호
+				"payment_id BIGINT REFERENCES payment_table(serial_number)",
+				// 가맹점 주문 번호(승인 멱등 키)
+				"order_id TEXT NOT NULL",
+				// PG 응답 메시지
+				"message TEXT",
+				// 생성일
+				"created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP",
+				// 수정일
+				"updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP",
+			},
+		},
+		{
+			tableName: "payment_gateway_event_table",
+			fieldDefinitions: []string{
+				// 기본키
+				"serial_number BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY",
+				// PG 이름
+				"gateway TEXT NOT NULL",
+				// PG 이벤트 번호(중복 수신 방지)
+				"event_id TEXT NOT NULL",
+				// PG 거래 번호
+				"transaction_id TEXT NOT NULL",
+				// 이벤트 본문
+				"payload JSONB",
+				// 수신 시간
+				"received_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP",
+			},
+		},
+	}
+
+	for _, table := range tableFields {
+		alterPrefix := fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS ", table.tableName)
+		for i, field := range table.fieldDefinitions {
+			_, err := db.Exec(alterPrefix + field + ";")
+			if err != nil {
+				return err
+			}
+			log.Printf("%s 필드 추가 진행 중: %d/%d 완료", table.tableName, i+1, len(table.fieldDefinitions))
+		}
+	}
+
+	// 인덱스 생성 쿼리 목록
+	indexQueries := []string{
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_pg_transaction_gateway_tx ON payment_gateway_transaction_table (gateway, transaction_id);`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_pg_transaction_order_id ON payment_gateway_transaction_table (company_code, order_id);`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_pg_event_gateway_event ON payment_gateway_event_table (gateway, event_id);`,
+	}
+
+	// 인덱스 생성 실행
+	for _, query := range indexQueries {
+		_, err := db.Exec(query)
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Println("payment_gateway 테이블과 인덱스가 성공적으로 생성되었습니다.")
+	return nil
+}
@@ -0,0 +1,106 @@
+package tables
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// CreatePaymentTable 결제 원장 테이블 및 인덱스를 생성합니다.
+// 함수 이름을 대문자로 시작하여 외부에서 접근 가능하게 만듭니다.
+// 결제 원장은 추가 전용(append-only)이며, 환불도 원 결제를 참조하는 새 행으로 기록합니다.
+func CreatePaymentTable(db *sql.DB) error {
+	log.Println("payment_table 테이블을 생성합니다...")
+
+	// 테이블 생성
+	createBaseTableQuery := `CREATE TABLE IF NOT EXISTS payment_table();`
+
+	_, err := db.Exec(createBaseTableQuery)
+	if err != nil {
+		return err
+	}
+	log.Println("payment_table 테이블 기본 구조 생성 완료")
+
+	tableName := "payment_table"
+	alterPrefix := fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS ", tableName)
+
+	// 각 필드 개별 추가
+	fieldDefinitions := []string{
+		// 기본키
+		"serial_number BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY",
+		// 회사 코드
+		"company_code TEXT NOT NULL",
+		// 회원 번호(비회원 결제는 NULL)
+		"member_id BIGINT REFERENCES user_table(serial_number)",
+		// 연결된 이용권 번호
+		"pass_id BIGINT REFERENCES member_pass_table(serial_number)",
+		// 결제 구분(time_charge=시간충전, membership=멤버십, deposit=보증금, penalty=위약금, refund=환불)
+		"payment_type TEXT NOT NULL",
+		// 결제 수단(card=카드, cash=현금, transfer=계좌이체, point=포인트)
+		"payment_method TEXT NOT NULL",
+		// 금액(원), 환불도 양수로 기록하고 payment_type으로 구분
+		"amount INTEGER NOT NULL CHECK (amount > 0)",
+		// 환불 시 원 결제 번호
+		"original_payment_id BIGINT REFERENCES payment_table(serial_number)",
+		// 중복 결제 방지 키
+		"idempotency_key TEXT",
+		// 설명/환불 사유
+		"description TEXT",
+		// 처리자(매니저 아이디 또는 kiosk)
+		"created_by TEXT",
+		// 생성일
+		"created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP",
+	}
+
+	// 각 필드 추가 쿼리 생성
+	fieldQueries := make([]string, len(fieldDefinitions))
+	for i, field := range fieldDefinitions {
+		fieldQueries[i] = alterPrefix + field + ";"
+	}
+
+	// 각 필드 추가 실행 및 진행 상황 로깅
+	for i, query := range fieldQueries {
+		_, err = db.Exec(query)
+		if err != nil {
+			return err
+		}
+		log.Printf("payment_table 필드 추가 진행 중: %d/%d 완료", i+1, len(fieldQueries))
+	}
+
+	// 인덱스 생성 쿼리 목록
+	indexQueries := []string{
+		`CREATE INDEX IF NOT EXISTS idx_payment_company_created ON payment_table (company_code, created_at);`,
+		`CREATE INDEX IF NOT EXISTS idx_payment_member_id ON payment_table (member_id);`,
+		`CREATE INDEX IF NOT EXISTS idx_payment_original_payment_id ON payment_table (original_payment_id);`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_payment_idempotency_key ON payment_table (company_code, idempotency_key) WHERE idempotency_key IS NOT NULL;`,
+	}
+
+	// 인덱스 생성 실행
+	for _, query := range indexQueries {
+		_, err = db.Exec(query)
+		if err != nil {
+			return err
+		}
+	}
+
+	// 추가 전용 원장 보호: UPDATE/DELETE를 트리거로 차단합니다.
+	triggerQueries := []string{
+		`CREATE OR REPLACE FUNCTION payment_table_append_only() RETURNS trigger AS $$
+		BEGIN
+			RAISE EXCEPTION 'payment_table은 추가 전용입니다 (%)', TG_OP;
+		END;
+		$$ LANGUAGE plpgsql;`,
+		`DROP TRIGGER IF EXISTS trg_payment_append_only ON payment_table;`,
+		`CREATE TRIGGER trg_payment_append_only BEFORE UPDATE OR DELETE ON payment_table
+		FOR EACH ROW EXECUTE FUNCTION payment_table_append_only();`,
+	}
+	for _, query := range triggerQueries {
+		_, err = db.Exec(query)
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Println("payment_table 테이블과 인덱스가 성공적으로 생성되었습니다.")
+	return nil
+}
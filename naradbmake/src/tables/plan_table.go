@@ -0,0 +1,88 @@
+package tables
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// CreatePlanTable 이용권 상품(요금제) 테이블 및 인덱스를 생성합니다.
+// 함수 이름을 대문자로 시작하여 외부에서 접근 가능하게 만듭니다.
+// 업체별 이용권 상품 테이블
+func CreatePlanTable(db *sql.DB) error {
+	log.Println("plan_table 테이블을 생성합니다...")
+
+	// 테이블 생성
+	createBaseTableQuery := `CREATE TABLE IF NOT EXISTS plan_table();`
+
+	_, err := db.Exec(createBaseTableQuery)
+	if err != nil {
+		return err
+	}
+	log.Println("plan_table 테이블 기본 구조 생성 완료")
+
+	tableName := "plan_table"
+	alterPrefix := fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS ", tableName)
+
+	// 각 필드 개별 추가
+	fieldDefinitions := []string{
+		// 기본키
+		"serial_number BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY",
+		// 회사 코드
+		"company_code TEXT NOT NULL",
+		// 상품명
+		"plan_name TEXT NOT NULL",
+		// 상품 구분(hourly=시간제, daily=당일권, fixed_monthly=고정석 월정액, free_period=자유석 기간권, time_bundle=시간 묶음)
+		"plan_type TEXT NOT NULL",
+		// 판매 가격(원)
+		"price INTEGER NOT NULL DEFAULT 0",
+		// 제공 시간(분), 시간제/시간 묶음 상품에서 사용
+		"duration_minutes INTEGER",
+		// 유효 기간(일), 당일권/기간권/월정액 상품에서 사용
+		"validity_days INTEGER",
+		// 고정석 여부
+		"fixed_seat BOOLEAN DEFAULT FALSE",
+		// 판매 여부
+		"active BOOLEAN DEFAULT TRUE",
+		// 표시 순서
+		"sort_order SMALLINT DEFAULT 0",
+		// 설명
+		"description TEXT",
+		// 생성일
+		"created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP",
+		// 수정일
+		"updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP",
+	}
+
+	// 각 필드 추가 쿼리 생성
+	fieldQueries := make([]string, len(fieldDefinitions))
+	for i, field := range fieldDefinitions {
+		fieldQueries[i] = alterPrefix + field + ";"
+	}
+
+	// 각 필드 추가 실행 및 진행 상황 로깅
+	for i, query := range fieldQueries {
+		_, err = db.Exec(query)
+		if err != nil {
+			return err
+		}
+		log.Printf("plan_table 필드 추가 진행 중: %d/%d 완료", i+1, len(fieldQueries))
+	}
+
+	// 인덱스 생성 쿼리 목록
+	indexQueries := []string{
+		`CREATE INDEX IF NOT EXISTS idx_plan_company_code ON plan_table (company_code);`,
+		`CREATE INDEX IF NOT EXISTS idx_plan_type ON plan_table (plan_type);`,
+	}
+
+	// 인덱스 생성 실행
+	for _, query := range indexQueries {
+		_, err = db.Exec(query)
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Println("plan_table 테이블과 인덱스가 성공적으로 생성되었습니다.")
+	return nil
+}
@@ -0,0 +1,140 @@
+package tables
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// CreatePowerTables 열람실 차단기 전원 시간표, 좌석 전원 규칙, 전원 제어 이벤트 테이블 및 인덱스를 생성합니다.
+// 함수 이름을 대문자로 시작하여 외부에서 접근 가능하게 만듭니다.
+// 전원 제어 이벤트에는 naracontrol로 보낸 명령과 장치의 응답(Ack)이 함께 기록됩니다.
+func CreatePowerTables(db *sql.DB) error {
+	log.Println("power 테이블을 생성합니다...")
+
+	// 테이블 생성
+	createBaseTableQueries := []string{
+		`CREATE TABLE IF NOT EXISTS power_schedule_table();`,
+		`CREATE TABLE IF NOT EXISTS power_rule_table();`,
+		`CREATE TABLE IF NOT EXISTS power_event_table();`,
+	}
+	for _, query := range createBaseTableQueries {
+		_, err := db.Exec(query)
+		if err != nil {
+			return err
+		}
+	}
+	log.Println("power 테이블 기본 구조 생성 완료")
+
+	// 각 필드 개별 추가 (테이블명 -> 필드 목록)
+	tableFields := []struct {
+		tableName        string
+		fieldDefinitions []string
+	}{
+		{
+			tableName: "power_schedule_table",
+			fieldDefinitions: []string{
+				// 기본키
+				"serial_number BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY",
+				// 회사 코드
+				"company_code TEXT NOT NULL",
+				// 열람실 코드 (room_table.room_code)
+				"room_code INTEGER NOT NULL",
+				// 요일 (0=일요일 ~ 6=토요일)
+				"day_of_week SMALLINT NOT NULL CHECK (day_of_week BETWEEN 0 AND 6)",
+				// 차단기 켜는 시각
+				"power_on_at TIME NOT NULL",
+				// 차단기 끄는 시각 (켜는 시각보다 이르면 다음 날, 같으면 하루 종일)
+				"power_off_at TIME NOT NULL",
+				// 사용 여부
+				"enabled BOOLEAN NOT NULL DEFAULT TRUE",
+				// 생성일
+				"created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP",
+			},
+		},
+		{
+			tableName: "power_rule_table",
+			fieldDefinitions: []string{
+				// 기본키
+				"serial_number BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY",
+				// 회사 코드 (회사당 1개 규칙)
+				"company_code TEXT NOT NULL",
+				// 입실 시 좌석 전원 켜기
+				"seat_power_on_check_in BOOLEAN NOT NULL DEFAULT TRUE",
+				// 퇴실/자동 해제 시 좌석 전원 끄기
+				"seat_power_off_on_release BOOLEAN NOT NULL DEFAULT TRUE",
+				// 생성일
+				"created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP",
+				// 수정일
+				"updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP",
+			},
+		},
+		{
+			tableName: "power_event_table",
+			fieldDefinitions: []string{
+				// 기본키
+				"serial_number BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY",
+				// 회사 코드
+				"company_code TEXT NOT NULL",
+				// 대상 종류(room, seat)
+				"target_type TEXT NOT NULL",
+				// 열람실 코드
+				"room_code INTEGER",
+				// 좌석 코드 (seat)
+				"seat_code INTEGER",
+				// 전원 번호 (room: 차단기 번호, seat: 좌석 전원 번호)
+				"power_number INTEGER",
+				// 명령(power_on, power_off)
+				"command TEXT NOT NULL",
+				// 발생 원인(schedule, occupancy, release, manual)
+				"source TEXT NOT NULL",
+				// 요청자 (manual)
+				"requested_by TEXT",
+				// 상태(pending, sent, no_recipient, failed, acknowledged, rejected)
+				"status TEXT NOT NULL DEFAULT 'pending'",
+				// 명령 수신 장치 수
+				"recipients INTEGER NOT NULL DEFAULT 0",
+				// 전송 오류 메시지
+				"error_message TEXT",
+				// 장치 응답 상태(ok, error)
+				"ack_status TEXT",
+				// 장치 응답 상세
+				"ack_message TEXT",
+				// 장치 응답 시각
+				"acked_at TIMESTAMP",
+				// 생성일
+				"created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP",
+			},
+		},
+	}
+
+	for _, table := range tableFields {
+		alterPrefix := fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS ", table.tableName)
+		for i, field := range table.fieldDefinitions {
+			_, err := db.Exec(alterPrefix + field + ";")
+			if err != nil {
+				return err
+			}
+			log.Printf("%s 필드 추가 진행 중: %d/%d 완료", table.tableName, i+1, len(table.fieldDefinitions))
+		}
+	}
+
+	// 인덱스 생성 쿼리 목록
+	indexQueries := []string{
+		`CREATE INDEX IF NOT EXISTS idx_power_schedule_company ON power_schedule_table (company_code, room_code, day_of_week);`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_power_rule_company ON power_rule_table (company_code);`,
+		`CREATE INDEX IF NOT EXISTS idx_power_event_company_created ON power_event_table (company_code, created_at DESC);`,
+		`CREATE INDEX IF NOT EXISTS idx_power_event_schedule ON power_event_table (company_code, room_code, created_at) WHERE source = 'schedule';`,
+	}
+
+	// 인덱스 생성 실행
+	for _, query := range indexQueries {
+		_, err := db.Exec(query)
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Println("power 테이블과 인덱스가 성공적으로 생성되었습니다.")
+	return nil
+}
@@ -0,0 +1,93 @@
+package tables
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// CreateReportRunTable 보고서 생성 요청(비동기 실행 결과) 테이블 및 인덱스를 생성합니다.
+// 함수 이름을 대문자로 시작하여 외부에서 접근 가능하게 만듭니다.
+// 생성이 끝난 파일(CSV/XLSX/JSON)은 content에 저장되어 다운로드됩니다.
+func CreateReportRunTable(db *sql.DB) error {
+	log.Println("report_run_table 테이블을 생성합니다...")
+
+	// 테이블 생성
+	createBaseTableQuery := `CREATE TABLE IF NOT EXISTS report_run_table();`
+	_, err := db.Exec(createBaseTableQuery)
+	if err != nil {
+		return err
+	}
+	log.Println("report_run_table 테이블 기본 구조 생성 완료")
+
+	tableName := "report_run_table"
+	alterPrefix := fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS ", tableName)
+
+	// 각 필드 개별 추가
+	fieldDefinitions := []string{
+		// 기본키
+		"serial_number BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY",
+		// 회사 코드 (보고서 범위)
+		"company_code TEXT NOT NULL",
+		// 보고서 이름 (narabackend reports 패키지 정의 이름)
+		"report_name TEXT NOT NULL",
+		// 출력 형식(json, csv, xlsx)
+		"format TEXT NOT NULL",
+		// 조회 시작 일자
+		"range_from DATE NOT NULL",
+		// 조회 종료 일자(포함)
+		"range_to DATE NOT NULL",
+		// 상태(queued, running, completed, failed)
+		"status TEXT NOT NULL DEFAULT 'queued'",
+		// 결과 행 수
+		"row_count INTEGER",
+		// 생성된 파일 내용
+		"content BYTEA",
+		// 파일 MIME 타입
+		"content_type TEXT",
+		// 다운로드 파일 이름
+		"file_name TEXT",
+		// 실패 사유
+		"error_message TEXT",
+		// 요청자
+		"requested_by TEXT",
+		// 요청 시각
+		"created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP",
+		// 생성 시작 시각
+		"started_at TIMESTAMP",
+		// 생성 종료 시각
+		"finished_at TIMESTAMP",
+	}
+
+	// 각 필드 추가 쿼리 생성
+	fieldQueries := make([]string, len(fieldDefinitions))
+	for i, field := range fieldDefinitions {
+		fieldQueries[i] = alterPrefix + field + ";"
+	}
+
+	// 각 필드 추가 실행 및 진행 상황 로깅
+	for i, query := range fieldQueries {
+		_, err = db.Exec(query)
+		if err != nil {
+			return err
+		}
+		log.Printf("report_run_table 필드 추가 진행 중: %d/%d 완료", i+1, len(fieldQueries))
+	}
+
+	// 인덱스 생성 쿼리 목록
+	indexQueries := []string{
+		`CREATE INDEX IF NOT EXISTS idx_report_run_company ON report_run_table (company_code, created_at);`,
+		`CREATE INDEX IF NOT EXISTS idx_report_run_status ON report_run_table (status) WHERE status IN ('queued', 'running');`,
+	}
+
+	// 인덱스 생성 실행
+	for _, query := range indexQueries {
+		_, err = db.Exec(query)
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Println("report_run_table 테이블과 인덱스가 성공적으로 생성되었습니다.")
+	return nil
+}
@@ -0,0 +1,156 @@
+package tables
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// CreateReservationTables 예약 테이블과 대기열 테이블 및 인덱스/제약조건을 생성합니다.
+// 함수 이름을 대문자로 시작하여 외부에서 접근 가능하게 만듭니다.
+// 같은 좌석/룸의 유효한 예약 시간대가 겹치지 않도록 exclusion constraint(btree_gist)를 사용합니다.
+func CreateReservationTables(db *sql.DB) error {
+	log.Println("reservation_table 테이블을 생성합니다...")
+
+	// exclusion constraint에서 텍스트/정수 동등 비교를 위해 필요합니다.
+	_, err := db.Exec(`CREATE EXTENSION IF NOT EXISTS btree_gist;`)
+	if err != nil {
+		return err
+	}
+
+	// 테이블 생성
+	createBaseTableQueries := []string{
+		`CREATE TABLE IF NOT EXISTS reservation_table();`,
+		`CREATE TABLE IF NOT EXISTS waiting_list_table();`,
+	}
+	for _, query := range createBaseTableQueries {
+		_, err := db.Exec(query)
+		if err != nil {
+			return err
+		}
+	}
+	log.Println("reservation 테이블 기본 구조 생성 완료")
+
+	// 각 필드 개별 추가 (테이블명 -> 필드 목록)
+	tableFields := []struct {
+		tableName        string
+		fieldDefinitions []string
+	}{
+		{
+			tableName: "reservation_table",
+			fieldDefinitions: []string{
+				// 기본키
+				"serial_number BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY",
+				// 회사 코드
+				"company_code TEXT NOT NULL",
+				// 예약 대상 구분(seat: 좌석, room: 스터디룸/회의실)
+				"resource_type TEXT NOT NULL",
+				// 예약 대상 코드(seat_code 또는 room_code)
+				"resource_code INTEGER NOT NULL",
+				// 회원 번호
+				"member_id BIGINT REFERENCES user_table(serial_number)",
+				// 예약 시작 시각
+				"start_at TIMESTAMP NOT NULL",
+				// 예약 종료 시각
+				"end_at TIMESTAMP NOT NULL",
+				// 상태(reserved, checked_in, completed, cancelled, no_show)
+				"status TEXT NOT NULL DEFAULT 'reserved'",
+				// 입실 확인 시각
+				"checked_in_at TIMESTAMP",
+				// 좌석 예약 입실로 시작된 좌석 세션
+				"seat_session_id BIGINT REFERENCES seat_session_table(serial_number)",
+				// 취소 시각
+				"cancelled_at TIMESTAMP",
+				// 취소 사유
+				"cancel_reason TEXT",
+				// 메모
+				"memo TEXT",
+				// 생성일
+				"created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP",
+				// 수정일
+				"updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP",
+			},
+		},
+		{
+			tableName: "waiting_list_table",
+			fieldDefinitions: []string{
+				// 기본키
+				"serial_number BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY",
+				// 회사 코드
+				"company_code TEXT NOT NULL",
+				// 열람실 코드
+				"room_code INTEGER NOT NULL",
+				// 회원 번호
+				"member_id BIGINT NOT NULL REFERENCES user_table(serial_number)",
+				// 상태(waiting, offered, accepted, expired, cancelled)
+				"status TEXT NOT NULL DEFAULT 'waiting'",
+				// 제안된 좌석 코드
+				"offered_seat_code INTEGER",
+				// 제안 시각
+				"offered_at TIMESTAMP",
+				// 제안 수락 기한
+				"offer_expires_at TIMESTAMP",
+				// 수락/거절 시각
+				"responded_at TIMESTAMP",
+				// 생성일(대기 순번 기준)
+				"created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP",
+			},
+		},
+	}
+
+	for _, table := range tableFields {
+		alterPrefix := fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS ", table.tableName)
+		for i, field := range table.fieldDefinitions {
+			_, err := db.Exec(alterPrefix + field + ";")
+			if err != nil {
+				return err
+			}
+			log.Printf("%s 필드 추가 진행 중: %d/%d 완료", table.tableName, i+1, len(table.fieldDefinitions))
+		}
+	}
+
+	// 제약조건은 IF NOT EXISTS를 지원하지 않으므로 pg_constraint를 확인한 뒤 추가합니다.
+	constraintQueries := []string{
+		`DO $$ BEGIN
+			IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'reservation_time_check') THEN
+				ALTER TABLE reservation_table ADD CONSTRAINT reservation_time_check CHECK (end_at > start_at);
+			END IF;
+		END $$;`,
+		`DO $$ BEGIN
+			IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'reservation_no_overlap') THEN
+				ALTER TABLE reservation_table ADD CONSTRAINT reservation_no_overlap EXCLUDE USING gist (
+					company_code WITH =,
+					resource_type WITH =,
+					resource_code WITH =,
+					tsrange(start_at, end_at) WITH &&
+				) WHERE (status IN ('reserved', 'checked_in'));
+			END IF;
+		END $$;`,
+	}
+	for _, query := range constraintQueries {
+		_, err := db.Exec(query)
+		if err != nil {
+			return err
+		}
+	}
+
+	// 인덱스 생성 쿼리 목록
+	indexQueries := []string{
+		`CREATE INDEX IF NOT EXISTS idx_reservation_member ON reservation_table (member_id, start_at);`,
+		`CREATE INDEX IF NOT EXISTS idx_reservation_no_show ON reservation_table (start_at) WHERE status = 'reserved';`,
+		`CREATE INDEX IF NOT EXISTS idx_waiting_list_queue ON waiting_list_table (company_code, room_code, created_at) WHERE status = 'waiting';`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_waiting_list_active_member ON waiting_list_table (company_code, room_code, member_id) WHERE status IN ('waiting', 'offered');`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_waiting_list_offered_seat ON waiting_list_table (company_code, offered_seat_code) WHERE status = 'offered';`,
+	}
+
+	// 인덱스 생성 실행
+	for _, query := range indexQueries {
+		_, err := db.Exec(query)
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Println("reservation 테이블과 인덱스가 성공적으로 생성되었습니다.")
+	return nil
+}
@@ -0,0 +1,84 @@
+package tables
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// CreateSeatSessionTable 좌석 이용(입실/퇴실) 기록 테이블 및 인덱스를 생성합니다.
+// 함수 이름을 대문자로 시작하여 외부에서 접근 가능하게 만듭니다.
+// 입실 시 생성되고 퇴실 시 종료되며, 이용권 차감의 기준이 됩니다.
+func CreateSeatSessionTable(db *sql.DB) error {
+	log.Println("seat_session_table 테이블을 생성합니다...")
+
+	// 테이블 생성
+	createBaseTableQuery := `CREATE TABLE IF NOT EXISTS seat_session_table();`
+
+	_, err := db.Exec(createBaseTableQuery)
+	if err != nil {
+		return err
+	}
+	log.Println("seat_session_table 테이블 기본 구조 생성 완료")
+
+	tableName := "seat_session_table"
+	alterPrefix := fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS ", tableName)
+
+	// 각 필드 개별 추가
+	fieldDefinitions := []string{
+		// 기본키
+		"serial_number BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY",
+		// 회사 코드
+		"company_code TEXT NOT NULL",
+		// 좌석 코드
+		"seat_code INTEGER NOT NULL",
+		// 회원 번호
+		"member_id BIGINT NOT NULL REFERENCES user_table(serial_number)",
+		// 사용한 이용권 번호
+		"pass_id BIGINT NOT NULL REFERENCES member_pass_table(serial_number)",
+		// 입실 시간
+		"check_in_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP",
+		// 퇴실 시간(이용 중이면 NULL)
+		"check_out_at TIMESTAMP",
+		// 사용 시간(분)
+		"used_minutes INTEGER NOT NULL DEFAULT 0",
+		// 종료 사유(checkout=정상 퇴실, expired=만료 해제, manual=관리자 해제)
+		"release_reason TEXT",
+	}
+
+	// 각 필드 추가 쿼리 생성
+	fieldQueries := make([]string, len(fieldDefinitions))
+	for i, field := range fieldDefinitions {
+		fieldQueries[i] = alterPrefix + field + ";"
+	}
+
+	// 각 필드 추가 실행 및 진행 상황 로깅
+	for i, query := range fieldQueries {
+		_, err = db.Exec(query)
+		if err != nil {
+			return err
+		}
+		log.Printf("seat_session_table 필드 추가 진행 중: %d/%d 완료", i+1, len(fieldQueries))
+	}
+
+	// 인덱스 생성 쿼리 목록
+	indexQueries := []string{
+		`CREATE INDEX IF NOT EXISTS idx_seat_session_company_code ON seat_session_table (company_code, check_in_at);`,
+		`CREATE INDEX IF NOT EXISTS idx_seat_session_member_id ON seat_session_table (member_id);`,
+		// 한 좌석에는 동시에 하나의 이용 중 세션만 존재할 수 있습니다.
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_seat_session_open_seat ON seat_session_table (company_code, seat_code) WHERE check_out_at IS NULL;`,
+		// 한 이용권으로 동시에 두 좌석을 사용할 수 없습니다.
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_seat_session_open_pass ON seat_session_table (pass_id) WHERE check_out_at IS NULL;`,
+	}
+
+	// 인덱스 생성 실행
+	for _, query := range indexQueries {
+		_, err = db.Exec(query)
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Println("seat_session_table 테이블과 인덱스가 성공적으로 생성되었습니다.")
+	return nil
+}
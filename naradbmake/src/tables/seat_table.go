@@ -132,6 +132,10 @@ func CreateSeatTable(db *sql.DB) error {
 		"move_grade INTEGER",
 		// 이동 등급2
 		"move_grade2 INTEGER",
+		// 회사 코드 (narabackend 좌석 식별자)
+		"company_code TEXT",
+		// 좌석 코드 (narabackend 좌석 식별자, seat_session_table.seat_code와 대응)
+		"seat_code INTEGER",
 	}
 
 	// 각 필드 추가 쿼리 생성
@@ -163,6 +167,8 @@ func CreateSeatTable(db *sql.DB) error {
 		`CREATE INDEX IF NOT EXISTS idx_check_in_type ON seat_table (check_in_type);`,
 		`CREATE INDEX IF NOT EXISTS idx_outing_datetime ON seat_table (outing_datetime);`,
 		`CREATE INDEX IF NOT EXISTS idx_seat_release_datetime ON seat_table (seat_release_datetime);`,
+		`CREATE INDEX IF NOT EXISTS idx_seat_company_seat_code ON seat_table (company_code, seat_code);`,
+		`CREATE INDEX IF NOT EXISTS idx_seat_expiration ON seat_table (expiration_date, expiration_time) WHERE member_id IS NOT NULL;`,
 	}
 
 	// 인덱스 생성 실행
@@ -0,0 +1,83 @@
+package tables
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// CreateSettlementTable 일일 마감(정산) 테이블 및 인덱스를 생성합니다.
+// 함수 이름을 대문자로 시작하여 외부에서 접근 가능하게 만듭니다.
+// 업체/일자/결제수단별로 한 행씩 마감 금액을 보관합니다.
+func CreateSettlementTable(db *sql.DB) error {
+	log.Println("settlement_table 테이블을 생성합니다...")
+
+	// 테이블 생성
+	createBaseTableQuery := `CREATE TABLE IF NOT EXISTS settlement_table();`
+
+	_, err := db.Exec(createBaseTableQuery)
+	if err != nil {
+		return err
+	}
+	log.Println("settlement_table 테이블 기본 구조 생성 완료")
+
+	tableName := "settlement_table"
+	alterPrefix := fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS ", tableName)
+
+	// 각 필드 개별 추가
+	fieldDefinitions := []string{
+		// 기본키
+		"serial_number BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY",
+		// 회사 코드
+		"company_code TEXT NOT NULL",
+		// 마감 일자
+		"settlement_date DATE NOT NULL",
+		// 결제 수단
+		"payment_method TEXT NOT NULL",
+		// 결제 건수
+		"payment_count INTEGER NOT NULL DEFAULT 0",
+		// 결제 금액 합계
+		"gross_amount BIGINT NOT NULL DEFAULT 0",
+		// 환불 건수
+		"refund_count INTEGER NOT NULL DEFAULT 0",
+		// 환불 금액 합계
+		"refund_amount BIGINT NOT NULL DEFAULT 0",
+		// 순매출(결제 - 환불)
+		"net_amount BIGINT NOT NULL DEFAULT 0",
+		// 마감 처리자
+		"closed_by TEXT",
+		// 마감 시간
+		"closed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP",
+	}
+
+	// 각 필드 추가 쿼리 생성
+	fieldQueries := make([]string, len(fieldDefinitions))
+	for i, field := range fieldDefinitions {
+		fieldQueries[i] = alterPrefix + field + ";"
+	}
+
+	// 각 필드 추가 실행 및 진행 상황 로깅
+	for i, query := range fieldQueries {
+		_, err = db.Exec(query)
+		if err != nil {
+			return err
+		}
+		log.Printf("settlement_table 필드 추가 진행 중: %d/%d 완료", i+1, len(fieldQueries))
+	}
+
+	// 인덱스 생성 쿼리 목록
+	indexQueries := []string{
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_settlement_company_date_method ON settlement_table (company_code, settlement_date, payment_method);`,
+	}
+
+	// 인덱스 생성 실행
+	for _, query := range indexQueries {
+		_, err = db.Exec(query)
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Println("settlement_table 테이블과 인덱스가 성공적으로 생성되었습니다.")
+	return nil
+}
@@ -0,0 +1,104 @@
+package tables
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// CreateUnmannedTables 업체 운영 시간표(유인 시간대)와 무인 운영 규칙 테이블 및 인덱스를 생성합니다.
+// 함수 이름을 대문자로 시작하여 외부에서 접근 가능하게 만듭니다.
+// 운영 시간표에 없는 시간은 무인 운영으로 보고, 무인 운영 규칙에 따라 키오스크 입장/입실을 제한합니다.
+func CreateUnmannedTables(db *sql.DB) error {
+	log.Println("unmanned 테이블을 생성합니다...")
+
+	// 테이블 생성
+	createBaseTableQueries := []string{
+		`CREATE TABLE IF NOT EXISTS operating_schedule_table();`,
+		`CREATE TABLE IF NOT EXISTS unmanned_rule_table();`,
+	}
+	for _, query := range createBaseTableQueries {
+		_, err := db.Exec(query)
+		if err != nil {
+			return err
+		}
+	}
+	log.Println("unmanned 테이블 기본 구조 생성 완료")
+
+	// 각 필드 개별 추가 (테이블명 -> 필드 목록)
+	tableFields := []struct {
+		tableName        string
+		fieldDefinitions []string
+	}{
+		{
+			tableName: "operating_schedule_table",
+			fieldDefinitions: []string{
+				// 기본키
+				"serial_number BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY",
+				// 회사 코드
+				"company_code TEXT NOT NULL",
+				// 요일 (0=일요일 ~ 6=토요일)
+				"day_of_week SMALLINT NOT NULL CHECK (day_of_week BETWEEN 0 AND 6)",
+				// 유인 운영 시작 시각
+				"staffed_from TIME NOT NULL",
+				// 유인 운영 종료 시각 (시작보다 이르면 다음 날까지, 같으면 하루 종일)
+				"staffed_until TIME NOT NULL",
+				// 생성일
+				"created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP",
+			},
+		},
+		{
+			tableName: "unmanned_rule_table",
+			fieldDefinitions: []string{
+				// 기본키
+				"serial_number BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY",
+				// 회사 코드 (회사당 1개 규칙)
+				"company_code TEXT NOT NULL",
+				// 무인 시간 입장 허용 등급 (비어 있으면 모든 등급)
+				"entry_grades INTEGER[] NOT NULL DEFAULT '{}'",
+				// 무인 시간 입실 허용 등급 (비어 있으면 모든 등급)
+				"check_in_grades INTEGER[] NOT NULL DEFAULT '{}'",
+				// 무인 등급이 없는 회원 허용 여부
+				"allow_ungraded BOOLEAN NOT NULL DEFAULT FALSE",
+				// 무인 시간 입실 허용 여부
+				"allow_check_in BOOLEAN NOT NULL DEFAULT TRUE",
+				// 무인 시간 이용권 구매 허용 여부
+				"allow_purchase BOOLEAN NOT NULL DEFAULT TRUE",
+				// 사용 여부
+				"enabled BOOLEAN NOT NULL DEFAULT TRUE",
+				// 생성일
+				"created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP",
+				// 수정일
+				"updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP",
+			},
+		},
+	}
+
+	for _, table := range tableFields {
+		alterPrefix := fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS ", table.tableName)
+		for i, field := range table.fieldDefinitions {
+			_, err := db.Exec(alterPrefix + field + ";")
+			if err != nil {
+				return err
+			}
+			log.Printf("%s 필드 추가 진행 중: %d/%d 완료", table.tableName, i+1, len(table.fieldDefinitions))
+		}
+	}
+
+	// 인덱스 생성 쿼리 목록
+	indexQueries := []string{
+		`CREATE INDEX IF NOT EXISTS idx_operating_schedule_company ON operating_schedule_table (company_code, day_of_week);`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_unmanned_rule_company ON unmanned_rule_table (company_code);`,
+	}
+
+	// 인덱스 생성 실행
+	for _, query := range indexQueries {
+		_, err := db.Exec(query)
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Println("unmanned 테이블과 인덱스가 성공적으로 생성되었습니다.")
+	return nil
+}
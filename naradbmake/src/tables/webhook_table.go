@@ -0,0 +1,119 @@
+package tables
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// CreateWebhookTables 업체 웹훅 구독 테이블과 웹훅 전송 기록 테이블 및 인덱스를 생성합니다.
+// 함수 이름을 대문자로 시작하여 외부에서 접근 가능하게 만듭니다.
+// 전송은 narabackend 작업 큐(job_table)에서 재시도되며, 시도마다 응답 코드를 전송 기록에 남깁니다.
+func CreateWebhookTables(db *sql.DB) error {
+	log.Println("webhook_table 테이블을 생성합니다...")
+
+	// 테이블 생성
+	createBaseTableQueries := []string{
+		`CREATE TABLE IF NOT EXISTS webhook_table();`,
+		`CREATE TABLE IF NOT EXISTS webhook_delivery_table();`,
+	}
+	for _, query := range createBaseTableQueries {
+		_, err := db.Exec(query)
+		if err != nil {
+			return err
+		}
+	}
+	log.Println("webhook 테이블 기본 구조 생성 완료")
+
+	// 각 필드 개별 추가 (테이블명 -> 필드 목록)
+	tableFields := []struct {
+		tableName        string
+		fieldDefinitions []string
+	}{
+		{
+			tableName: "webhook_table",
+			fieldDefinitions: []string{
+				// 기본키
+				"serial_number BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY",
+				// 회사 코드
+				"company_code TEXT NOT NULL",
+				// 수신 URL
+				"url TEXT NOT NULL",
+				// 서명 비밀키 (HMAC-SHA256)
+				"secret TEXT NOT NULL",
+				// 구독 이벤트 목록 (비어 있으면 전체)
+				"event_types TEXT[] NOT NULL DEFAULT '{}'",
+				// 사용 여부
+				"enabled BOOLEAN NOT NULL DEFAULT TRUE",
+				// 설명
+				"description TEXT",
+				// 생성일
+				"created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP",
+				// 수정일
+				"updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP",
+			},
+		},
+		{
+			tableName: "webhook_delivery_table",
+			fieldDefinitions: []string{
+				// 기본키 (전송ID)
+				"serial_number BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY",
+				// 웹훅 번호
+				"webhook_id BIGINT NOT NULL REFERENCES webhook_table(serial_number) ON DELETE CASCADE",
+				// 회사 코드
+				"company_code TEXT NOT NULL",
+				// 이벤트 종류 (예: session.checked_in, payment.created)
+				"event_type TEXT NOT NULL",
+				// 전송 본문
+				"payload JSONB NOT NULL DEFAULT '{}'",
+				// 상태(pending, succeeded, failed)
+				"status TEXT NOT NULL DEFAULT 'pending'",
+				// 전송 시도 횟수
+				"attempts INTEGER NOT NULL DEFAULT 0",
+				// 마지막 응답 코드 (연결 실패 시 NULL)
+				"response_code INTEGER",
+				// 마지막 응답 본문 (앞부분만 저장)
+				"response_body TEXT",
+				// 마지막 오류
+				"last_error TEXT",
+				// 마지막 시도 소요 시간(밀리초)
+				"duration_ms INTEGER",
+				// 생성일
+				"created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP",
+				// 마지막 시도 시각
+				"attempted_at TIMESTAMP",
+				// 전송 성공 시각
+				"delivered_at TIMESTAMP",
+			},
+		},
+	}
+
+	for _, table := range tableFields {
+		alterPrefix := fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS ", table.tableName)
+		for i, field := range table.fieldDefinitions {
+			_, err := db.Exec(alterPrefix + field + ";")
+			if err != nil {
+				return err
+			}
+			log.Printf("%s 필드 추가 진행 중: %d/%d 완료", table.tableName, i+1, len(table.fieldDefinitions))
+		}
+	}
+
+	// 인덱스 생성 쿼리 목록
+	indexQueries := []string{
+		`CREATE INDEX IF NOT EXISTS idx_webhook_company ON webhook_table (company_code) WHERE enabled;`,
+		`CREATE INDEX IF NOT EXISTS idx_webhook_delivery_webhook ON webhook_delivery_table (webhook_id, created_at);`,
+		`CREATE INDEX IF NOT EXISTS idx_webhook_delivery_created ON webhook_delivery_table (created_at);`,
+	}
+
+	// 인덱스 생성 실행
+	for _, query := range indexQueries {
+		_, err := db.Exec(query)
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Println("webhook 테이블과 인덱스가 성공적으로 생성되었습니다.")
+	return nil
+}